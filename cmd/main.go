@@ -1,10 +1,18 @@
 package main
 
 import (
+	"os"
+
 	"github.com/niklvrr/myMarketplace/internal/app"
 )
 
 func main() {
+	// "reindex" rebuilds the search index of the running server instead of starting one.
+	if len(os.Args) > 1 && os.Args[1] == "reindex" {
+		app.Reindex()
+		return
+	}
+
 	app.Run()
 
 	//time.Sleep(1 * time.Minute)
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/bundleHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerBundleRouter(router *gin.RouterGroup, bundleHandler *bundleHandler.BundleHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	components := router.Group("/products/:id/components")
+	components.Use(middleware.JWTRegister(jwtManager, cache))
+	{
+		components.GET("", bundleHandler.Components)
+
+		seller := components.Group("")
+		seller.Use(middleware.RequireRole("seller", "admin"))
+		{
+			seller.PUT("", bundleHandler.SetComponents)
+		}
+	}
+}
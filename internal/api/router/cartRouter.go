@@ -13,7 +13,7 @@ func registerCartRouter(router *gin.RouterGroup, cartHandler *cartHandler.CartHa
 	cart.Use(middleware.JWTRegister(jwtManager, cache))
 	{
 		cart.GET("", cartHandler.GetCartByUserId)
-		cart.GET("/:id", cartHandler.GetCartItemsByCartId)
+		cart.GET("/:cart_id", cartHandler.GetCartItemsByCartId)
 		cart.POST("", cartHandler.AddItem)
 		cart.DELETE("", cartHandler.RemoveItem)
 		cart.DELETE("/clear", cartHandler.ClearCart)
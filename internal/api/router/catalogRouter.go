@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/catalogChangeHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/exportHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerCatalogRouter(router *gin.RouterGroup, exportHandler *exportHandler.ExportHandler, catalogChangeHandler *catalogChangeHandler.CatalogChangeHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	catalog := router.Group("/catalog")
+	{
+		catalog.GET("/feed.yml", exportHandler.FeedYML)
+		catalog.GET("/feed.csv", exportHandler.FeedCSV)
+		catalog.GET("/snapshot", catalogChangeHandler.Snapshot)
+		catalog.GET("/changes", catalogChangeHandler.Changes)
+	}
+
+	admin := catalog.Group("")
+	admin.Use(middleware.JWTRegister(jwtManager, cache))
+	admin.Use(middleware.RequireRole("admin"))
+	{
+		admin.GET("/export", exportHandler.Export)
+	}
+}
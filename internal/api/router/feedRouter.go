@@ -0,0 +1,17 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/productHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerFeedRouter(router *gin.RouterGroup, productHandler *productHandler.ProductHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	feed := router.Group("/feed")
+	feed.Use(middleware.JWTRegister(jwtManager, cache))
+	{
+		feed.GET("", productHandler.Feed)
+	}
+}
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/bulkInventoryHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/inventoryHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerInventoryRouter(router *gin.RouterGroup, inventoryHandler *inventoryHandler.InventoryHandler, bulkInventoryHandler *bulkInventoryHandler.BulkInventoryHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	seller := router.Group("/seller")
+	seller.Use(middleware.JWTRegister(jwtManager, cache))
+	seller.Use(middleware.RequireRole("seller", "admin"))
+	{
+		seller.GET("/warehouses", inventoryHandler.ListWarehouses)
+		seller.POST("/warehouses", inventoryHandler.CreateWarehouse)
+		seller.PATCH("/warehouses/:id", inventoryHandler.UpdateWarehouse)
+		seller.DELETE("/warehouses/:id", inventoryHandler.DeleteWarehouse)
+		seller.GET("/inventory/products/:id", inventoryHandler.ProductInventory)
+		seller.PUT("/inventory/products/:id/warehouses/:warehouseId", inventoryHandler.SetLevel)
+		seller.POST("/inventory/transfers", inventoryHandler.Transfer)
+		seller.GET("/inventory/products/:id/movements", inventoryHandler.ProductMovements)
+		seller.PATCH("/inventory", bulkInventoryHandler.Update)
+	}
+
+	admin := router.Group("/inventory")
+	admin.Use(middleware.JWTRegister(jwtManager, cache))
+	admin.Use(middleware.RequireRole("admin"))
+	{
+		admin.GET("/reconciliation", inventoryHandler.Reconciliation)
+	}
+}
@@ -1,43 +1,108 @@
 package router
 
 import (
+	"context"
+	"github.com/niklvrr/myMarketplace/internal/handler/bulkInventoryHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/bundleHandler"
 	"github.com/niklvrr/myMarketplace/internal/handler/cartHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/catalogChangeHandler"
 	"github.com/niklvrr/myMarketplace/internal/handler/categoriesHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/discountHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/exportHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/importHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/inventoryHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/lowStockHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/moderationHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/notificationHandler"
 	"github.com/niklvrr/myMarketplace/internal/handler/orderHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/priceTierHandler"
 	"github.com/niklvrr/myMarketplace/internal/handler/productHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/questionHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/reviewHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/stockAlertHandler"
 	"github.com/niklvrr/myMarketplace/internal/handler/userHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/wishlistHandler"
+	"github.com/niklvrr/myMarketplace/internal/service/bulkInventoryService"
+	"github.com/niklvrr/myMarketplace/internal/service/bundleService"
 	"github.com/niklvrr/myMarketplace/internal/service/cartService"
+	"github.com/niklvrr/myMarketplace/internal/service/catalogChangeService"
 	"github.com/niklvrr/myMarketplace/internal/service/categoriesService"
+	"github.com/niklvrr/myMarketplace/internal/service/importService"
+	"github.com/niklvrr/myMarketplace/internal/service/inventoryService"
+	"github.com/niklvrr/myMarketplace/internal/service/moderationService"
 	"github.com/niklvrr/myMarketplace/internal/service/orderService"
+	"github.com/niklvrr/myMarketplace/internal/service/priceTierService"
 	"github.com/niklvrr/myMarketplace/internal/service/productService"
+	"github.com/niklvrr/myMarketplace/internal/service/questionService"
+	"github.com/niklvrr/myMarketplace/internal/service/reviewService"
 	"github.com/niklvrr/myMarketplace/internal/service/userService"
+	"github.com/niklvrr/myMarketplace/internal/service/wishlistService"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/niklvrr/myMarketplace/internal/config"
 	"github.com/niklvrr/myMarketplace/internal/repository"
+	"github.com/niklvrr/myMarketplace/internal/search"
 	"github.com/niklvrr/myMarketplace/pkg/jwt"
 	"github.com/redis/go-redis/v9"
 )
 
-func NewRouter(db *pgxpool.Pool, rdb *redis.Client, JWTConfig config.JWTConfig) http.Handler {
+func NewRouter(cfg *config.Config, db *pgxpool.Pool, rdb *redis.Client, shared *Shared, lgr *slog.Logger) http.Handler {
 	// Repository init
-	productRepo := repository.NewProductRepo(db)
+	productRepo := shared.ProductRepo
 	userRepo := repository.NewUserRepo(db)
-	categoryRepo := repository.NewCategoryRepo(db)
+	categoryRepo := shared.CategoryRepo
 	cartRepo := repository.NewCartRepo(db)
 	orderRepo := repository.NewOrderRepo(db)
+	moderationRepo := repository.NewModerationRepo(db)
+	importRepo := repository.NewImportRepo(db)
+	reviewRepo := repository.NewReviewRepo(db)
+	questionRepo := repository.NewQuestionRepo(db)
+	wishlistRepo := shared.WishlistRepo
+	inventoryRepo := repository.NewInventoryRepo(db)
+	movementRepo := repository.NewMovementRepo(db)
+	bulkInventoryRepo := shared.BulkInventoryRepo
+	catalogChangeRepo := shared.CatalogChangeRepo
+	bundleRepo := repository.NewBundleRepo(db)
+	priceTierRepo := shared.PriceTierRepo
+
+	// Search index init, the catalog is loaded in the background so the server
+	// starts without waiting for it.
+	searchIndex := newSearchIndex(db, productRepo, cfg.Search, lgr)
+	go func() {
+		if err := searchIndex.Reindex(context.Background()); err != nil {
+			lgr.Error("search index rebuild error", slog.Any("error", err))
+		}
+	}()
 
 	// JWTManager init
-	jwtManager := jwt.NewJWTManager(JWTConfig.Secret, JWTConfig.Expiration)
+	jwtManager := jwt.NewJWTManager(cfg.JWT.Secret, cfg.JWT.Expiration)
 
 	// Service init
-	productService := productService.NewProductService(productRepo, rdb)
+	moderationService := moderationService.NewModerationService(moderationRepo, cfg.Moderation)
+	discountService := shared.Discounts
+	recommendationService := shared.Recommendations
+	viewService := shared.Views
+	notificationService := shared.Notifications
+	stockAlertService := shared.StockAlerts
+	lowStockService := shared.LowStock
+	productService := productService.NewProductService(productRepo, rdb, searchIndex, moderationService, discountService, recommendationService, viewService, stockAlertService, lowStockService)
 	userService := userService.NewUserService(userRepo, rdb, jwtManager)
 	categoryService := categoriesService.NewCategoriesService(categoryRepo)
-	cartService := cartService.NewCartService(cartRepo)
-	orderService := orderService.NewOrderService(orderRepo)
+	cartService := cartService.NewCartService(cartRepo, discountService)
+	orderService := orderService.NewOrderService(orderRepo, discountService, lowStockService, productRepo, searchIndex)
+	importService := importService.NewImportService(importRepo, moderationService, rdb, searchIndex)
+	exportService := shared.Export
+	reviewService := reviewService.NewReviewService(reviewRepo, productRepo, rdb, searchIndex, cfg.Reviews)
+	questionService := questionService.NewQuestionService(questionRepo, productRepo, moderationService)
+	wishlistService := wishlistService.NewWishlistService(wishlistRepo, productService, cfg.Wishlists)
+	inventoryService := inventoryService.NewInventoryService(inventoryRepo, movementRepo, productRepo, searchIndex, rdb, stockAlertService, lowStockService)
+	bulkInventoryService := bulkInventoryService.NewBulkInventoryService(bulkInventoryRepo, productRepo, searchIndex, rdb, stockAlertService, lowStockService, cfg.BulkInventory)
+	catalogChangeService := catalogChangeService.NewCatalogChangeService(catalogChangeRepo, cfg.CatalogChanges)
+	bundleService := bundleService.NewBundleService(bundleRepo, productRepo, searchIndex, rdb, stockAlertService, lowStockService)
+	priceTierService := priceTierService.NewPriceTierService(priceTierRepo, productRepo)
 
 	// Handler init
 	productHandler := productHandler.NewProductsHandler(productService)
@@ -45,17 +110,64 @@ func NewRouter(db *pgxpool.Pool, rdb *redis.Client, JWTConfig config.JWTConfig)
 	categoryHandler := categoriesHandler.NewCategoryHandler(categoryService)
 	cartHandler := cartHandler.NewCartHandler(cartService)
 	orderHandler := orderHandler.NewOrderHandler(orderService)
+	moderationHandler := moderationHandler.NewModerationHandler(moderationService)
+	importHandler := importHandler.NewImportHandler(importService)
+	exportHandler := exportHandler.NewExportHandler(exportService)
+	discountHandler := discountHandler.NewDiscountHandler(discountService)
+	reviewHandler := reviewHandler.NewReviewHandler(reviewService)
+	questionHandler := questionHandler.NewQuestionHandler(questionService)
+	notificationHandler := notificationHandler.NewNotificationHandler(notificationService)
+	wishlistHandler := wishlistHandler.NewWishlistHandler(wishlistService)
+	stockAlertHandler := stockAlertHandler.NewStockAlertHandler(stockAlertService)
+	lowStockHandler := lowStockHandler.NewLowStockHandler(lowStockService)
+	inventoryHandler := inventoryHandler.NewInventoryHandler(inventoryService)
+	bulkInventoryHandler := bulkInventoryHandler.NewBulkInventoryHandler(bulkInventoryService)
+	catalogChangeHandler := catalogChangeHandler.NewCatalogChangeHandler(catalogChangeService)
+	bundleHandler := bundleHandler.NewBundleHandler(bundleService)
+	priceTierHandler := priceTierHandler.NewPriceTierHandler(priceTierService)
 
 	r := gin.Default()
 
+	// Search engines look for the sitemap at the root of the site.
+	r.GET("/sitemap.xml", exportHandler.Sitemap)
+
 	api := r.Group("/api")
 	v1 := api.Group("/v1")
 
 	registerProductRouter(v1, productHandler, jwtManager, rdb)
-	registerUserRouter(v1, userHandler, jwtManager, rdb)
+	registerUserRouter(v1, userHandler, productHandler, jwtManager, rdb)
+	registerFeedRouter(v1, productHandler, jwtManager, rdb)
 	registerCategoriesRouter(v1, categoryHandler, jwtManager, rdb)
 	registerCartRouter(v1, cartHandler, jwtManager, rdb)
 	registerOrderRouter(v1, orderHandler, jwtManager, rdb)
+	registerModerationRouter(v1, moderationHandler, jwtManager, rdb)
+	registerSellerRouter(v1, productHandler, importHandler, exportHandler, discountHandler, questionHandler, lowStockHandler, jwtManager, rdb)
+	registerCatalogRouter(v1, exportHandler, catalogChangeHandler, jwtManager, rdb)
+	registerReviewRouter(v1, reviewHandler, jwtManager, rdb)
+	registerQuestionRouter(v1, questionHandler, jwtManager, rdb)
+	registerWishlistRouter(v1, wishlistHandler, jwtManager, rdb)
+	registerNotificationRouter(v1, notificationHandler, jwtManager, rdb)
+	registerStockAlertRouter(v1, stockAlertHandler, jwtManager, rdb)
+	registerInventoryRouter(v1, inventoryHandler, bulkInventoryHandler, jwtManager, rdb)
+	registerBundleRouter(v1, bundleHandler, jwtManager, rdb)
+	registerPriceTierRouter(v1, priceTierHandler, jwtManager, rdb)
 
 	return r
 }
+
+func newSearchIndex(db *pgxpool.Pool, productRepo *repository.ProductRepo, searchConfig config.SearchConfig, lgr *slog.Logger) search.SearchIndex {
+	if searchConfig.Engine == search.EngineMemory {
+		return search.NewMemoryIndex(productRepo)
+	}
+
+	if searchConfig.ReplicaUrl != "" {
+		replica, err := pgxpool.New(context.Background(), searchConfig.ReplicaUrl)
+		if err != nil {
+			lgr.Error("search replica init error", slog.Any("error", err))
+		} else {
+			return search.NewPostgresIndex(repository.NewProductRepo(replica))
+		}
+	}
+
+	return search.NewPostgresIndex(productRepo)
+}
@@ -0,0 +1,22 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/moderationHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerModerationRouter(router *gin.RouterGroup, moderationHandler *moderationHandler.ModerationHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	moderation := router.Group("/moderation")
+	moderation.Use(middleware.JWTRegister(jwtManager, cache))
+	moderation.Use(middleware.RequireRole("admin"))
+	{
+		moderation.GET("/rules", moderationHandler.GetAllRules)
+		moderation.POST("/rules", moderationHandler.CreateRule)
+		moderation.PUT("/rules/:id", moderationHandler.UpdateRule)
+		moderation.DELETE("/rules/:id", moderationHandler.DeleteRule)
+		moderation.GET("/products/:id/flags", moderationHandler.GetProductFlags)
+	}
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/notificationHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerNotificationRouter(router *gin.RouterGroup, notificationHandler *notificationHandler.NotificationHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	notifications := router.Group("/notifications")
+	notifications.Use(middleware.JWTRegister(jwtManager, cache))
+	{
+		notifications.GET("", notificationHandler.List)
+		notifications.POST("/read-all", notificationHandler.MarkAllRead)
+		notifications.POST("/:id/read", notificationHandler.MarkRead)
+	}
+}
@@ -18,4 +18,10 @@ func registerOrderRouter(router *gin.RouterGroup, orderHandler *orderHandler.Ord
 		order.GET("/:id", orderHandler.GetOrderById)
 		order.DELETE("/:id", orderHandler.DeleteOrderById)
 	}
+
+	admin := order.Group("")
+	admin.Use(middleware.RequireRole("admin"))
+	{
+		admin.PUT("/:id/status", orderHandler.UpdateStatus)
+	}
 }
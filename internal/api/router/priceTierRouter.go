@@ -0,0 +1,36 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/priceTierHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerPriceTierRouter(router *gin.RouterGroup, priceTierHandler *priceTierHandler.PriceTierHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	tiers := router.Group("/products/:id/price-tiers")
+	tiers.Use(middleware.JWTRegister(jwtManager, cache))
+	tiers.Use(middleware.RequireRole("seller", "admin"))
+	{
+		tiers.GET("", priceTierHandler.Tiers)
+		tiers.PUT("", priceTierHandler.SetTiers)
+	}
+
+	lists := router.Group("/price-lists")
+	lists.Use(middleware.JWTRegister(jwtManager, cache))
+	{
+		seller := lists.Group("")
+		seller.Use(middleware.RequireRole("seller", "admin"))
+		{
+			seller.GET("", priceTierHandler.PriceLists)
+		}
+
+		admin := lists.Group("")
+		admin.Use(middleware.RequireRole("admin"))
+		{
+			admin.POST("", priceTierHandler.CreatePriceList)
+			admin.PUT("/customers", priceTierHandler.AssignPriceList)
+		}
+	}
+}
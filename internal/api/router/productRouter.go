@@ -13,13 +13,31 @@ func registerProductRouter(router *gin.RouterGroup, productHandler *productHandl
 	products.Use(middleware.JWTRegister(jwtManager, cache))
 	{
 		products.GET("/:id", productHandler.Get)
+		products.GET("/by-slug/:slug", productHandler.GetBySlug)
+		products.GET("/:id/price-history", productHandler.PriceHistory)
+		products.GET("/:id/related", productHandler.Related)
+		products.GET("/:id/bought-together", productHandler.BoughtTogether)
 		products.GET("", productHandler.GetAll)
 		products.GET("/search", productHandler.Search)
 
-		seller := products.Group("/")
+		seller := products.Group("")
 		seller.Use(middleware.RequireRole("seller", "admin"))
-		products.POST("", productHandler.Create)
-		products.PUT("/:id", productHandler.Update)
-		products.DELETE("/:id", productHandler.Delete)
+		{
+			seller.POST("", productHandler.Create)
+			seller.PUT("/:id", productHandler.Update)
+			seller.DELETE("/:id", productHandler.Delete)
+			seller.POST("/:id/submit", productHandler.Submit)
+			seller.POST("/:id/restock", productHandler.Restock)
+			seller.POST("/:id/archive", productHandler.Archive)
+			seller.POST("/:id/restore", productHandler.Restore)
+		}
+
+		admin := products.Group("")
+		admin.Use(middleware.RequireRole("admin"))
+		{
+			admin.POST("/reindex", productHandler.Reindex)
+			admin.GET("/moderation", productHandler.ModerationQueue)
+			admin.PUT("/:id/moderation", productHandler.Moderate)
+		}
 	}
 }
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/questionHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerQuestionRouter(router *gin.RouterGroup, questionHandler *questionHandler.QuestionHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	questions := router.Group("/products/:id/questions")
+	questions.Use(middleware.JWTRegister(jwtManager, cache))
+	{
+		questions.GET("", questionHandler.List)
+		questions.POST("", questionHandler.Ask)
+		questions.DELETE("/:questionId", questionHandler.Delete)
+		questions.POST("/:questionId/upvote", questionHandler.Upvote)
+		questions.DELETE("/:questionId/upvote", questionHandler.RemoveUpvote)
+
+		seller := questions.Group("")
+		seller.Use(middleware.RequireRole("seller", "admin"))
+		{
+			seller.PUT("/:questionId/answer", questionHandler.Answer)
+		}
+	}
+}
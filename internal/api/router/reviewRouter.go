@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/reviewHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerReviewRouter(router *gin.RouterGroup, reviewHandler *reviewHandler.ReviewHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	reviews := router.Group("/products/:id/reviews")
+	reviews.Use(middleware.JWTRegister(jwtManager, cache))
+	{
+		reviews.GET("", reviewHandler.List)
+		reviews.POST("", reviewHandler.Create)
+		reviews.PATCH("/:reviewId", reviewHandler.Update)
+		reviews.DELETE("/:reviewId", reviewHandler.Delete)
+		reviews.POST("/:reviewId/vote", reviewHandler.Vote)
+		reviews.DELETE("/:reviewId/vote", reviewHandler.Unvote)
+
+		seller := reviews.Group("")
+		seller.Use(middleware.RequireRole("seller", "admin"))
+		{
+			seller.PUT("/:reviewId/reply", reviewHandler.Reply)
+		}
+	}
+}
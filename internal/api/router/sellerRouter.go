@@ -0,0 +1,34 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/discountHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/exportHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/importHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/lowStockHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/productHandler"
+	"github.com/niklvrr/myMarketplace/internal/handler/questionHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerSellerRouter(router *gin.RouterGroup, productHandler *productHandler.ProductHandler, importHandler *importHandler.ImportHandler, exportHandler *exportHandler.ExportHandler, discountHandler *discountHandler.DiscountHandler, questionHandler *questionHandler.QuestionHandler, lowStockHandler *lowStockHandler.LowStockHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	seller := router.Group("/seller")
+	seller.Use(middleware.JWTRegister(jwtManager, cache))
+	seller.Use(middleware.RequireRole("seller", "admin"))
+	{
+		seller.GET("/products", productHandler.SellerProducts)
+		seller.POST("/imports", importHandler.Start)
+		seller.GET("/imports/:id", importHandler.Get)
+		seller.GET("/imports/:id/errors", importHandler.Errors)
+		seller.GET("/export", exportHandler.SellerExport)
+		seller.POST("/discounts", discountHandler.Create)
+		seller.GET("/discounts", discountHandler.List)
+		seller.DELETE("/discounts/:id", discountHandler.Delete)
+		seller.GET("/questions", questionHandler.Inbox)
+		seller.GET("/low-stock/alerts", lowStockHandler.Alerts)
+		seller.PUT("/low-stock/threshold", lowStockHandler.SellerThreshold)
+		seller.PUT("/low-stock/products/:id/threshold", lowStockHandler.ProductThreshold)
+	}
+}
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"github.com/niklvrr/myMarketplace/internal/repository"
+	"github.com/niklvrr/myMarketplace/internal/service/discountService"
+	"github.com/niklvrr/myMarketplace/internal/service/exportService"
+	"github.com/niklvrr/myMarketplace/internal/service/lowStockService"
+	"github.com/niklvrr/myMarketplace/internal/service/notificationService"
+	"github.com/niklvrr/myMarketplace/internal/service/recommendationService"
+	"github.com/niklvrr/myMarketplace/internal/service/stockAlertService"
+	"github.com/niklvrr/myMarketplace/internal/service/viewService"
+)
+
+// Shared holds the repositories and services used both by the handlers and by the
+// background jobs. They are built once at startup, so the jobs work on the same
+// instances as the requests.
+type Shared struct {
+	ProductRepo       *repository.ProductRepo
+	CategoryRepo      *repository.CategoryRepo
+	WishlistRepo      *repository.WishlistRepo
+	PriceTierRepo     *repository.PriceTierRepo
+	BulkInventoryRepo *repository.BulkInventoryRepo
+	CatalogChangeRepo *repository.CatalogChangeRepo
+
+	Discounts       *discountService.DiscountService
+	Notifications   *notificationService.NotificationService
+	Recommendations *recommendationService.RecommendationService
+	Views           *viewService.ViewService
+	StockAlerts     *stockAlertService.StockAlertService
+	LowStock        *lowStockService.LowStockService
+	Export          *exportService.ExportService
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/stockAlertHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerStockAlertRouter(router *gin.RouterGroup, stockAlertHandler *stockAlertHandler.StockAlertHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	notifyMe := router.Group("/products/:id/notify-me")
+	notifyMe.Use(middleware.JWTRegister(jwtManager, cache))
+	{
+		notifyMe.POST("", stockAlertHandler.Subscribe)
+		notifyMe.DELETE("", stockAlertHandler.Unsubscribe)
+	}
+}
@@ -3,12 +3,13 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/productHandler"
 	"github.com/niklvrr/myMarketplace/internal/handler/userHandler"
 	"github.com/niklvrr/myMarketplace/pkg/jwt"
 	"github.com/redis/go-redis/v9"
 )
 
-func registerUserRouter(router *gin.RouterGroup, userHandler *userHandler.UserHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+func registerUserRouter(router *gin.RouterGroup, userHandler *userHandler.UserHandler, productHandler *productHandler.ProductHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
 	user := router.Group("/user")
 	{
 		user.POST("/signup", userHandler.SignUp)
@@ -22,6 +23,7 @@ func registerUserRouter(router *gin.RouterGroup, userHandler *userHandler.UserHa
 			auth.POST("", userHandler.GetUserByEmail)
 			auth.PUT("/role", userHandler.UpdateUserRole)
 			auth.POST("/logout", userHandler.Logout)
+			auth.GET("/recently-viewed", productHandler.RecentlyViewed)
 
 			admin := auth.Group("/admin")
 			admin.Use(middleware.RequireRole("admin"))
@@ -0,0 +1,30 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/api/middleware"
+	"github.com/niklvrr/myMarketplace/internal/handler/wishlistHandler"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func registerWishlistRouter(router *gin.RouterGroup, wishlistHandler *wishlistHandler.WishlistHandler, jwtManager *jwt.JWTManager, cache *redis.Client) {
+	wishlists := router.Group("/wishlists")
+	{
+		wishlists.GET("/shared/:token", wishlistHandler.Shared)
+	}
+
+	owner := wishlists.Group("")
+	owner.Use(middleware.JWTRegister(jwtManager, cache))
+	{
+		owner.GET("", wishlistHandler.List)
+		owner.POST("", wishlistHandler.Create)
+		owner.GET("/:id", wishlistHandler.Get)
+		owner.PATCH("/:id", wishlistHandler.Rename)
+		owner.DELETE("/:id", wishlistHandler.Delete)
+		owner.POST("/:id/items", wishlistHandler.AddItem)
+		owner.DELETE("/:id/items/:productId", wishlistHandler.RemoveItem)
+		owner.POST("/:id/share", wishlistHandler.Share)
+		owner.DELETE("/:id/share", wishlistHandler.Unshare)
+	}
+}
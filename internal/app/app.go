@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -10,12 +11,24 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	"github.com/niklvrr/myMarketplace/internal/api/router"
 	"github.com/niklvrr/myMarketplace/internal/config"
 	"github.com/niklvrr/myMarketplace/internal/db"
+	"github.com/niklvrr/myMarketplace/internal/jobs"
 	"github.com/niklvrr/myMarketplace/internal/rdb"
+	"github.com/niklvrr/myMarketplace/internal/repository"
+	"github.com/niklvrr/myMarketplace/internal/service/discountService"
+	"github.com/niklvrr/myMarketplace/internal/service/exportService"
+	"github.com/niklvrr/myMarketplace/internal/service/lowStockService"
+	"github.com/niklvrr/myMarketplace/internal/service/notificationService"
+	"github.com/niklvrr/myMarketplace/internal/service/recommendationService"
+	"github.com/niklvrr/myMarketplace/internal/service/stockAlertService"
+	"github.com/niklvrr/myMarketplace/internal/service/viewService"
+	"github.com/niklvrr/myMarketplace/internal/service/wishlistService"
 	"github.com/niklvrr/myMarketplace/pkg/logger"
+	"github.com/redis/go-redis/v9"
 )
 
 func Run() {
@@ -39,7 +52,10 @@ func Run() {
 
 	rdb.NewRDB(cfg.Cache.Address, lgr)
 
-	r := router.NewRouter(db.Db, rdb.CacheDB, cfg.JWT)
+	shared := newShared(db.Db, rdb.CacheDB, cfg, lgr)
+	startJobs(context.Background(), cfg, shared, lgr)
+
+	r := router.NewRouter(cfg, db.Db, rdb.CacheDB, shared, lgr)
 	lgr.Info("Starting server")
 
 	srv := &http.Server{
@@ -56,6 +72,50 @@ func Run() {
 	lgr.Info("Server stopped")
 }
 
+// newShared builds the repositories and services used both by the router and by the
+// background jobs.
+func newShared(pool *pgxpool.Pool, cache *redis.Client, cfg *config.Config, lgr *slog.Logger) *router.Shared {
+	productRepo := repository.NewProductRepo(pool)
+	categoryRepo := repository.NewCategoryRepo(pool)
+	priceTierRepo := repository.NewPriceTierRepo(pool)
+	notifications := notificationService.NewNotificationService(repository.NewNotificationRepo(pool))
+
+	return &router.Shared{
+		ProductRepo:       productRepo,
+		CategoryRepo:      categoryRepo,
+		WishlistRepo:      repository.NewWishlistRepo(pool),
+		PriceTierRepo:     priceTierRepo,
+		BulkInventoryRepo: repository.NewBulkInventoryRepo(pool),
+		CatalogChangeRepo: repository.NewCatalogChangeRepo(pool),
+
+		Discounts:     discountService.NewDiscountService(repository.NewDiscountRepo(pool), productRepo, priceTierRepo),
+		Notifications: notifications,
+		Recommendations: recommendationService.NewRecommendationService(
+			repository.NewRecommendationRepo(pool), productRepo, cache, cfg.Recommendations),
+		Views: viewService.NewViewService(productRepo, cache, cfg.Views, lgr),
+		StockAlerts: stockAlertService.NewStockAlertService(
+			repository.NewStockSubscriptionRepo(pool), productRepo, notifications, cfg.StockAlerts),
+		LowStock: lowStockService.NewLowStockService(
+			repository.NewLowStockRepo(pool), productRepo, notifications, cfg.LowStock),
+		Export: exportService.NewExportService(productRepo, categoryRepo, cache, cfg.Export),
+	}
+}
+
+// startJobs runs the background jobs until ctx is canceled.
+func startJobs(ctx context.Context, cfg *config.Config, shared *router.Shared, lgr *slog.Logger) {
+	alertChecker := wishlistService.NewAlertChecker(shared.WishlistRepo, shared.Discounts, shared.Notifications, cfg.Wishlists)
+
+	go jobs.NewRetentionJob(shared.ProductRepo, cfg.Retention, lgr).Run(ctx)
+	go jobs.NewFeedJob(shared.Export, cfg.Export.FeedInterval, lgr).Run(ctx)
+	go jobs.NewRecommendationJob(shared.Recommendations, cfg.Recommendations.Interval, lgr).Run(ctx)
+	go shared.Views.Run(ctx)
+	go jobs.NewWishlistAlertJob(alertChecker, cfg.Wishlists.AlertInterval, lgr).Run(ctx)
+	go jobs.NewStockSubscriptionJob(shared.StockAlerts, cfg.StockAlerts.CleanupInterval, lgr).Run(ctx)
+	go jobs.NewLowStockDigestJob(shared.LowStock, cfg.LowStock.DigestInterval, lgr).Run(ctx)
+	go jobs.NewBulkInventoryCleanupJob(shared.BulkInventoryRepo, cfg.BulkInventory, lgr).Run(ctx)
+	go jobs.NewCatalogChangeCleanupJob(shared.CatalogChangeRepo, cfg.CatalogChanges, lgr).Run(ctx)
+}
+
 func mustRunMigrations(dbUrl string, logger *slog.Logger) {
 	if dbUrl == "" {
 		logger.Error("dbUrl is empty")
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/pkg/jwt"
+	"github.com/niklvrr/myMarketplace/pkg/logger"
+)
+
+// Reindex asks the running server to rebuild its search index from the database.
+// The memory index lives in the server process, so the command goes through the
+// admin endpoint with a short-lived admin token signed by the server secret.
+func Reindex() {
+	if err := godotenv.Load(); err != nil {
+		log.Print("No .env file found")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lgr := logger.NewLog(cfg.App.Env)
+
+	token, err := jwt.NewJWTManager(cfg.JWT.Secret, time.Minute).GenerateToken(0, "admin")
+	if err != nil {
+		lgr.Error("reindex token error", "err", err)
+		os.Exit(1)
+	}
+
+	host := cfg.Server.Host
+	if host == "" || host == "0.0.0.0" {
+		host = "localhost"
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s:%d/api/v1/products/reindex", host, cfg.Server.Port), nil)
+	if err != nil {
+		lgr.Error("reindex request error", "err", err)
+		os.Exit(1)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		lgr.Error("reindex request error", "err", err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		lgr.Error("reindex failed", "status", resp.StatusCode)
+		os.Exit(1)
+	}
+
+	lgr.Info("search index rebuilt")
+}
@@ -44,13 +44,98 @@ type CacheConfig struct {
 	Address string `yaml:"addr"`
 }
 
+type SearchConfig struct {
+	Engine     string `yaml:"engine"`
+	ReplicaUrl string `yaml:"replica_url"`
+}
+
+type ModerationConfig struct {
+	PriceLowRatio   float64 `yaml:"price_low_ratio"`
+	PriceHighRatio  float64 `yaml:"price_high_ratio"`
+	PriceMinSamples int     `yaml:"price_min_samples"`
+	PriceSeverity   string  `yaml:"price_severity"`
+	ContactSeverity string  `yaml:"contact_severity"`
+}
+
+type RetentionConfig struct {
+	PurgeAfter time.Duration `yaml:"purge_after"`
+	Interval   time.Duration `yaml:"interval"`
+	BatchSize  int           `yaml:"batch_size"`
+}
+
+type ExportConfig struct {
+	ShopName     string        `yaml:"shop_name"`
+	Company      string        `yaml:"company"`
+	BaseUrl      string        `yaml:"base_url"`
+	Currency     string        `yaml:"currency"`
+	FeedInterval time.Duration `yaml:"feed_interval"`
+}
+
+type ReviewConfig struct {
+	EditWindow time.Duration `yaml:"edit_window"`
+}
+
+type RecommendationConfig struct {
+	Interval       time.Duration `yaml:"interval"`
+	Size           int           `yaml:"size"`
+	MinCoPurchases int           `yaml:"min_co_purchases"`
+}
+
+type ViewConfig struct {
+	HistorySize   int           `yaml:"history_size"`
+	HistoryTTL    time.Duration `yaml:"history_ttl"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	QueueSize     int           `yaml:"queue_size"`
+}
+
+type WishlistConfig struct {
+	ShareBaseUrl   string        `yaml:"share_base_url"`
+	AlertInterval  time.Duration `yaml:"alert_interval"`
+	AlertBatchSize int           `yaml:"alert_batch_size"`
+}
+
+type StockAlertConfig struct {
+	SubscriptionTTL time.Duration `yaml:"subscription_ttl"`
+	BatchSize       int           `yaml:"batch_size"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+}
+
+type LowStockConfig struct {
+	DefaultThreshold int           `yaml:"default_threshold"`
+	DigestInterval   time.Duration `yaml:"digest_interval"`
+	DigestBatchSize  int           `yaml:"digest_batch_size"`
+}
+
+type BulkInventoryConfig struct {
+	MaxItems        int           `yaml:"max_items"`
+	IdempotencyTTL  time.Duration `yaml:"idempotency_ttl"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+}
+
+type CatalogChangesConfig struct {
+	Retention       time.Duration `yaml:"retention"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+}
+
 type Config struct {
-	App      AppConfig      `yaml:"app"`
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	JWT      JWTConfig      `yaml:"jwt"`
-	Log      LogConfig      `yaml:"logging"`
-	Cache    CacheConfig    `yaml:"cache"`
+	App             AppConfig            `yaml:"app"`
+	Server          ServerConfig         `yaml:"server"`
+	Database        DatabaseConfig       `yaml:"database"`
+	JWT             JWTConfig            `yaml:"jwt"`
+	Log             LogConfig            `yaml:"logging"`
+	Cache           CacheConfig          `yaml:"cache"`
+	Search          SearchConfig         `yaml:"search"`
+	Moderation      ModerationConfig     `yaml:"moderation"`
+	Retention       RetentionConfig      `yaml:"retention"`
+	Export          ExportConfig         `yaml:"export"`
+	Reviews         ReviewConfig         `yaml:"reviews"`
+	Recommendations RecommendationConfig `yaml:"recommendations"`
+	Views           ViewConfig           `yaml:"views"`
+	Wishlists       WishlistConfig       `yaml:"wishlists"`
+	StockAlerts     StockAlertConfig     `yaml:"stock_alerts"`
+	LowStock        LowStockConfig       `yaml:"low_stock"`
+	BulkInventory   BulkInventoryConfig  `yaml:"bulk_inventory"`
+	CatalogChanges  CatalogChangesConfig `yaml:"catalog_changes"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -111,5 +196,17 @@ func LoadConfig() (*Config, error) {
 		cfg.Cache.Address = address
 	}
 
+	if searchEngine := os.Getenv("SEARCH_ENGINE"); searchEngine != "" {
+		cfg.Search.Engine = searchEngine
+	}
+
+	if replicaUrl := os.Getenv("SEARCH_REPLICA_URL"); replicaUrl != "" {
+		cfg.Search.ReplicaUrl = replicaUrl
+	}
+
+	if exportBaseUrl := os.Getenv("EXPORT_BASE_URL"); exportBaseUrl != "" {
+		cfg.Export.BaseUrl = exportBaseUrl
+	}
+
 	return &cfg, nil
 }
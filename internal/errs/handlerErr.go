@@ -2,6 +2,7 @@ package errs
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,9 @@ var (
 	NotAuthorizedError = errors.New("not authorized")
 	ForbiddenError     = errors.New("forbidden")
 	ValidationError    = errors.New("validation error")
+	ConflictError      = errors.New("conflict")
+	GoneError          = errors.New("gone")
+	NotOwnerError      = fmt.Errorf("%w: not the owner", ForbiddenError)
 )
 
 func RespondError(ctx *gin.Context, status int, code string, message string) {
@@ -27,10 +31,16 @@ func RespondServiceError(ctx *gin.Context, err error) {
 		RespondError(ctx, http.StatusNotFound, "not_found", err.Error())
 	case errors.Is(err, NotAuthorizedError):
 		RespondError(ctx, http.StatusUnauthorized, "unauthorized", err.Error())
+	case errors.Is(err, NotOwnerError):
+		RespondError(ctx, http.StatusForbidden, "not_owner", err.Error())
 	case errors.Is(err, ForbiddenError):
 		RespondError(ctx, http.StatusForbidden, "forbidden", err.Error())
 	case errors.Is(err, ValidationError):
 		RespondError(ctx, http.StatusBadRequest, "validation_error", err.Error())
+	case errors.Is(err, ConflictError):
+		RespondError(ctx, http.StatusConflict, "conflict", err.Error())
+	case errors.Is(err, GoneError):
+		RespondError(ctx, http.StatusGone, "gone", err.Error())
 	default:
 		RespondError(ctx, http.StatusInternalServerError, "internal_error", err.Error())
 	}
@@ -0,0 +1,41 @@
+package bulkInventoryHandler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IBulkInventoryService interface {
+	Update(ctx context.Context, sellerId int64, req *model.BulkInventoryRequest) (model.BulkInventoryResponse, error)
+}
+
+type BulkInventoryHandler struct {
+	svc IBulkInventoryService
+}
+
+func NewBulkInventoryHandler(svc IBulkInventoryService) *BulkInventoryHandler {
+	return &BulkInventoryHandler{svc: svc}
+}
+
+// Update applies a batch of stock and price changes. The outcome of every item is in the
+// response body, so the status is 200 even when some of the items failed.
+func (h *BulkInventoryHandler) Update(ctx *gin.Context) {
+	var req model.BulkInventoryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.IdempotencyKey = ctx.GetHeader("Idempotency-Key")
+
+	resp, err := h.svc.Update(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": resp})
+}
@@ -0,0 +1,67 @@
+package bulkInventoryHandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockBulkInventoryService struct {
+	UpdateFn func(ctx context.Context, sellerId int64, req *model.BulkInventoryRequest) (model.BulkInventoryResponse, error)
+}
+
+func (m *mockBulkInventoryService) Update(ctx context.Context, sellerId int64, req *model.BulkInventoryRequest) (model.BulkInventoryResponse, error) {
+	return m.UpdateFn(ctx, sellerId, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func TestBulkInventoryHandler_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", `{"items":[{"product_id":7,"stock":5}]}`, nil, http.StatusOK},
+		{"no items", `{"items":[]}`, nil, http.StatusBadRequest},
+		{"malformed body", `{"items":`, nil, http.StatusBadRequest},
+		{"too many items", `{"items":[{"product_id":7,"stock":5}]}`, errs.ValidationError, http.StatusBadRequest},
+		{"key reused", `{"items":[{"product_id":7,"stock":5}]}`, errs.ConflictError, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockBulkInventoryService{
+				UpdateFn: func(ctx context.Context, sellerId int64, req *model.BulkInventoryRequest) (model.BulkInventoryResponse, error) {
+					if sellerId != 2 || req.IdempotencyKey != "sync-1" || len(req.Items) != 1 {
+						t.Fatalf("unexpected args: %d %+v", sellerId, req)
+					}
+					return model.BulkInventoryResponse{Updated: 1}, tt.serviceErr
+				},
+			}
+			h := NewBulkInventoryHandler(svc)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/seller/inventory", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Header.Set("Idempotency-Key", "sync-1")
+			c.Set("user_id", int64(2))
+
+			h.Update(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
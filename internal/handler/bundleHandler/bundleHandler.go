@@ -0,0 +1,72 @@
+package bundleHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IBundleService interface {
+	Components(ctx context.Context, userId int64, role string, req *model.BundleRequest) (model.BundleResponse, error)
+	SetComponents(ctx context.Context, userId int64, role string, req *model.SetBundleComponentsRequest) (model.BundleResponse, error)
+}
+
+type BundleHandler struct {
+	svc IBundleService
+}
+
+func NewBundleHandler(svc IBundleService) *BundleHandler {
+	return &BundleHandler{svc: svc}
+}
+
+func (h *BundleHandler) Components(ctx *gin.Context) {
+	productId, ok := paramId(ctx, "id")
+	if !ok {
+		return
+	}
+	req := model.BundleRequest{ProductId: productId}
+
+	bundle, err := h.svc.Components(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": bundle})
+}
+
+func (h *BundleHandler) SetComponents(ctx *gin.Context) {
+	productId, ok := paramId(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req model.SetBundleComponentsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.ProductId = productId
+
+	bundle, err := h.svc.SetComponents(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": bundle})
+}
+
+func paramId(ctx *gin.Context, name string) (int64, bool) {
+	id, err := strconv.Atoi(ctx.Param(name))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return 0, false
+	}
+
+	return int64(id), true
+}
@@ -0,0 +1,110 @@
+package bundleHandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockBundleService struct {
+	ComponentsFn    func(ctx context.Context, userId int64, role string, req *model.BundleRequest) (model.BundleResponse, error)
+	SetComponentsFn func(ctx context.Context, userId int64, role string, req *model.SetBundleComponentsRequest) (model.BundleResponse, error)
+}
+
+func (m *mockBundleService) Components(ctx context.Context, userId int64, role string, req *model.BundleRequest) (model.BundleResponse, error) {
+	return m.ComponentsFn(ctx, userId, role, req)
+}
+func (m *mockBundleService) SetComponents(ctx context.Context, userId int64, role string, req *model.SetBundleComponentsRequest) (model.BundleResponse, error) {
+	return m.SetComponentsFn(ctx, userId, role, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(method, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("user_id", int64(2))
+	c.Set("role", "seller")
+	return c, w
+}
+
+func TestBundleHandler_Components(t *testing.T) {
+	tests := []struct {
+		name           string
+		productId      string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "7", nil, http.StatusOK},
+		{"bad product id", "x", nil, http.StatusBadRequest},
+		{"not a bundle", "7", errs.NotFoundError, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockBundleService{
+				ComponentsFn: func(ctx context.Context, userId int64, role string, req *model.BundleRequest) (model.BundleResponse, error) {
+					if req.ProductId != 7 {
+						t.Fatalf("unexpected request: %+v", req)
+					}
+					return model.BundleResponse{ProductId: 7, Components: []model.BundleComponentResponse{{ProductId: 8, Quantity: 2}}}, tt.serviceErr
+				},
+			}
+			h := NewBundleHandler(svc)
+			c, w := makeCtx(http.MethodGet, "", gin.Params{{Key: "id", Value: tt.productId}})
+
+			h.Components(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBundleHandler_SetComponents(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", `{"components":[{"product_id":8,"quantity":2}]}`, nil, http.StatusOK},
+		{"unbundle", `{"components":[]}`, nil, http.StatusOK},
+		{"missing components", `{}`, nil, http.StatusBadRequest},
+		{"zero quantity", `{"components":[{"product_id":8,"quantity":0}]}`, nil, http.StatusBadRequest},
+		{"nested bundle", `{"components":[{"product_id":8,"quantity":1}]}`, errs.ConflictError, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockBundleService{
+				SetComponentsFn: func(ctx context.Context, userId int64, role string, req *model.SetBundleComponentsRequest) (model.BundleResponse, error) {
+					if req.ProductId != 7 || userId != 2 {
+						t.Fatalf("unexpected request: %+v", req)
+					}
+					return model.BundleResponse{ProductId: 7}, tt.serviceErr
+				},
+			}
+			h := NewBundleHandler(svc)
+			c, w := makeCtx(http.MethodPut, tt.body, gin.Params{{Key: "id", Value: "7"}})
+
+			h.SetComponents(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -51,7 +51,7 @@ func (h *CartHandler) GetCartItemsByCartId(c *gin.Context) {
 		return
 	}
 
-	req := model.GetCartItemsByCartIdRequest{CartId: int64(cartIdInt)}
+	req := model.GetCartItemsByCartIdRequest{CartId: int64(cartIdInt), UserId: c.GetInt64("user_id")}
 	cart, err := h.svc.GetCartItemsByCartId(c, &req)
 	if err != nil {
 		errs.RespondServiceError(c, err)
@@ -126,11 +126,15 @@ func TestCartHandler_GetCartItemsByCartId(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			svc := &mockCartService{
 				GetCartItemsByCartIdFn: func(ctx context.Context, req *model.GetCartItemsByCartIdRequest) (*[]model.CartItemResponse, error) {
+					if req.UserId != 11 {
+						t.Fatalf("items must be priced for the customer, got user %d", req.UserId)
+					}
 					return tt.serviceItems, tt.serviceErr
 				},
 			}
 			h := NewCartHandler(svc)
 			c, w := makeCtx("", http.MethodGet)
+			c.Set("user_id", int64(11))
 			c.Params = gin.Params{{Key: "cart_id", Value: tt.paramValue}}
 			h.GetCartItemsByCartId(c)
 			if tt.expectedStatus == http.StatusInternalServerError {
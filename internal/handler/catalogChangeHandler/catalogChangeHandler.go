@@ -0,0 +1,79 @@
+package catalogChangeHandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+// Partners sync in large pages, so the feed allows more than the usual lists.
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type ICatalogChangeService interface {
+	Changes(ctx context.Context, limit int, req *model.CatalogChangesRequest) ([]model.CatalogChangeResponse, string, error)
+	Snapshot(ctx context.Context, w io.Writer) error
+}
+
+type CatalogChangeHandler struct {
+	svc ICatalogChangeService
+}
+
+func NewCatalogChangeHandler(svc ICatalogChangeService) *CatalogChangeHandler {
+	return &CatalogChangeHandler{svc: svc}
+}
+
+func (h *CatalogChangeHandler) Changes(ctx *gin.Context) {
+	var req model.CatalogChangesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	changes, nextCursor, err := h.svc.Changes(ctx, limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        changes,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+// Snapshot streams the whole public catalog with the cursor to follow the changes from.
+func (h *CatalogChangeHandler) Snapshot(ctx *gin.Context) {
+	// Large catalogs take longer than the server write timeout allows for a regular response.
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+
+	ctx.Header("Content-Type", "application/json; charset=utf-8")
+
+	err := h.svc.Snapshot(ctx, ctx.Writer)
+	if err == nil {
+		return
+	}
+
+	// Once the body has started the status is already sent, the client sees a truncated document.
+	if ctx.Writer.Written() {
+		slog.Error("catalog snapshot interrupted", "error", err)
+		return
+	}
+
+	errs.RespondServiceError(ctx, err)
+}
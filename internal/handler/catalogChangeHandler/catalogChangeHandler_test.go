@@ -0,0 +1,99 @@
+package catalogChangeHandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockCatalogChangeService struct {
+	ChangesFn  func(ctx context.Context, limit int, req *model.CatalogChangesRequest) ([]model.CatalogChangeResponse, string, error)
+	SnapshotFn func(ctx context.Context, w io.Writer) error
+}
+
+func (m *mockCatalogChangeService) Changes(ctx context.Context, limit int, req *model.CatalogChangesRequest) ([]model.CatalogChangeResponse, string, error) {
+	return m.ChangesFn(ctx, limit, req)
+}
+func (m *mockCatalogChangeService) Snapshot(ctx context.Context, w io.Writer) error {
+	return m.SnapshotFn(ctx, w)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c, w
+}
+
+func TestCatalogChangeHandler_Changes(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		expectedLimit  int
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "/catalog/changes?since=abc&limit=500", 500, nil, http.StatusOK},
+		{"default limit", "/catalog/changes?since=abc&limit=5000", defaultLimit, nil, http.StatusOK},
+		{"expired cursor", "/catalog/changes?since=abc", defaultLimit, errs.GoneError, http.StatusGone},
+		{"bad cursor", "/catalog/changes?since=abc", defaultLimit, errs.ValidationError, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockCatalogChangeService{
+				ChangesFn: func(ctx context.Context, limit int, req *model.CatalogChangesRequest) ([]model.CatalogChangeResponse, string, error) {
+					if limit != tt.expectedLimit || req.Since != "abc" {
+						t.Fatalf("unexpected args: %d %+v", limit, req)
+					}
+					return []model.CatalogChangeResponse{}, "next", tt.serviceErr
+				},
+			}
+			c, w := makeCtx(tt.target)
+
+			NewCatalogChangeHandler(svc).Changes(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCatalogChangeHandler_Snapshot(t *testing.T) {
+	svc := &mockCatalogChangeService{
+		SnapshotFn: func(ctx context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, `{"data":{}}`)
+			return err
+		},
+	}
+	c, w := makeCtx("/catalog/snapshot")
+
+	NewCatalogChangeHandler(svc).Snapshot(c)
+
+	if w.Code != http.StatusOK || w.Body.String() != `{"data":{}}` {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+
+	svc.SnapshotFn = func(ctx context.Context, w io.Writer) error {
+		return errors.New("db is down")
+	}
+	c, w = makeCtx("/catalog/snapshot")
+
+	NewCatalogChangeHandler(svc).Snapshot(c)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
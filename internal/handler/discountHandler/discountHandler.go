@@ -0,0 +1,79 @@
+package discountHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IDiscountService interface {
+	Create(ctx context.Context, userId int64, role string, req *model.CreateDiscountRequest) (model.DiscountResponse, error)
+	GetBySeller(ctx context.Context, sellerId int64) ([]model.DiscountResponse, error)
+	Delete(ctx context.Context, userId int64, role string, req *model.DeleteDiscountRequest) error
+}
+
+type DiscountHandler struct {
+	svc IDiscountService
+}
+
+func NewDiscountHandler(svc IDiscountService) *DiscountHandler {
+	return &DiscountHandler{svc: svc}
+}
+
+func (h *DiscountHandler) Create(ctx *gin.Context) {
+	var req model.CreateDiscountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	discount, err := h.svc.Create(ctx, userId.(int64), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": discount})
+}
+
+func (h *DiscountHandler) List(ctx *gin.Context) {
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	discounts, err := h.svc.GetBySeller(ctx, userId.(int64))
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": discounts})
+}
+
+func (h *DiscountHandler) Delete(ctx *gin.Context) {
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req := model.DeleteDiscountRequest{Id: int64(idInt)}
+
+	if err = h.svc.Delete(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": "discount deleted"})
+}
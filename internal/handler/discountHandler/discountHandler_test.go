@@ -0,0 +1,139 @@
+package discountHandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockDiscountService struct {
+	CreateFn      func(ctx context.Context, userId int64, role string, req *model.CreateDiscountRequest) (model.DiscountResponse, error)
+	GetBySellerFn func(ctx context.Context, sellerId int64) ([]model.DiscountResponse, error)
+	DeleteFn      func(ctx context.Context, userId int64, role string, req *model.DeleteDiscountRequest) error
+}
+
+func (m *mockDiscountService) Create(ctx context.Context, userId int64, role string, req *model.CreateDiscountRequest) (model.DiscountResponse, error) {
+	return m.CreateFn(ctx, userId, role, req)
+}
+func (m *mockDiscountService) GetBySeller(ctx context.Context, sellerId int64) ([]model.DiscountResponse, error) {
+	return m.GetBySellerFn(ctx, sellerId)
+}
+func (m *mockDiscountService) Delete(ctx context.Context, userId int64, role string, req *model.DeleteDiscountRequest) error {
+	return m.DeleteFn(ctx, userId, role, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func TestDiscountHandler_Create(t *testing.T) {
+	validBody := `{"scope":"seller","kind":"percent","value":10,"starts_at":"2030-01-01T00:00:00Z","ends_at":"2030-01-08T00:00:00Z"}`
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", validBody, nil, http.StatusCreated},
+		{"unknown scope", `{"scope":"brand","kind":"percent","value":10,"starts_at":"2030-01-01T00:00:00Z","ends_at":"2030-01-08T00:00:00Z"}`, nil, http.StatusBadRequest},
+		{"ends before start", `{"scope":"seller","kind":"fixed","value":10,"starts_at":"2030-01-08T00:00:00Z","ends_at":"2030-01-01T00:00:00Z"}`, nil, http.StatusBadRequest},
+		{"zero value", `{"scope":"seller","kind":"fixed","value":0,"starts_at":"2030-01-01T00:00:00Z","ends_at":"2030-01-08T00:00:00Z"}`, nil, http.StatusBadRequest},
+		{"not owner", validBody, errs.NotOwnerError, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockDiscountService{
+				CreateFn: func(ctx context.Context, userId int64, role string, req *model.CreateDiscountRequest) (model.DiscountResponse, error) {
+					if userId != 7 || role != "seller" {
+						t.Fatalf("unexpected args: %d %q", userId, role)
+					}
+					if tt.serviceErr != nil {
+						return model.DiscountResponse{}, tt.serviceErr
+					}
+					return model.DiscountResponse{Id: 1, Scope: req.Scope, Kind: req.Kind, Value: req.Value}, nil
+				},
+			}
+			h := NewDiscountHandler(svc)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("user_id", int64(7))
+			c.Set("role", "seller")
+
+			h.Create(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDiscountHandler_List(t *testing.T) {
+	svc := &mockDiscountService{
+		GetBySellerFn: func(ctx context.Context, sellerId int64) ([]model.DiscountResponse, error) {
+			if sellerId != 7 {
+				t.Fatalf("unexpected seller: %d", sellerId)
+			}
+			return []model.DiscountResponse{{Id: 1}, {Id: 2}}, nil
+		},
+	}
+	h := NewDiscountHandler(svc)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Set("user_id", int64(7))
+
+	h.List(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestDiscountHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "3", nil, http.StatusOK},
+		{"bad id", "x", nil, http.StatusBadRequest},
+		{"not owner", "3", errs.NotOwnerError, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockDiscountService{
+				DeleteFn: func(ctx context.Context, userId int64, role string, req *model.DeleteDiscountRequest) error {
+					if req.Id != 3 {
+						t.Fatalf("unexpected id: %d", req.Id)
+					}
+					return tt.serviceErr
+				},
+			}
+			h := NewDiscountHandler(svc)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/", nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+			c.Set("user_id", int64(7))
+			c.Set("role", "seller")
+
+			h.Delete(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package exportHandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IExportService interface {
+	Export(ctx context.Context, w io.Writer, format string, sellerId *int64) error
+	Feed(ctx context.Context, format string) ([]byte, error)
+}
+
+type ExportHandler struct {
+	svc IExportService
+}
+
+func NewExportHandler(svc IExportService) *ExportHandler {
+	return &ExportHandler{svc: svc}
+}
+
+var contentTypes = map[string]string{
+	model.ExportFormatCSV:     "text/csv; charset=utf-8",
+	model.ExportFormatYML:     "application/xml; charset=utf-8",
+	model.ExportFormatSitemap: "application/xml; charset=utf-8",
+}
+
+var extensions = map[string]string{
+	model.ExportFormatCSV: "csv",
+	model.ExportFormatYML: "xml",
+}
+
+// SellerExport streams the listed products of the current seller.
+func (h *ExportHandler) SellerExport(ctx *gin.Context) {
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	sellerId := userId.(int64)
+	h.export(ctx, &sellerId)
+}
+
+// Export streams the whole public catalog.
+func (h *ExportHandler) Export(ctx *gin.Context) {
+	h.export(ctx, nil)
+}
+
+func (h *ExportHandler) FeedYML(ctx *gin.Context) {
+	h.feed(ctx, model.ExportFormatYML)
+}
+
+func (h *ExportHandler) FeedCSV(ctx *gin.Context) {
+	h.feed(ctx, model.ExportFormatCSV)
+}
+
+// Sitemap serves the sitemap of the public catalog for search engines.
+func (h *ExportHandler) Sitemap(ctx *gin.Context) {
+	h.feed(ctx, model.ExportFormatSitemap)
+}
+
+func (h *ExportHandler) export(ctx *gin.Context, sellerId *int64) {
+	var req model.ExportProductsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = model.ExportFormatCSV
+	}
+
+	// Large catalogs take longer than the server write timeout allows for a regular response.
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+
+	ctx.Header("Content-Type", contentTypes[format])
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="catalog.%s"`, extensions[format]))
+
+	err := h.svc.Export(ctx, ctx.Writer, format, sellerId)
+	if err == nil {
+		return
+	}
+
+	// Once the body has started the status is already sent, the client sees a truncated file.
+	if ctx.Writer.Written() {
+		slog.Error("catalog export interrupted", "error", err)
+		return
+	}
+
+	ctx.Header("Content-Type", "")
+	ctx.Header("Content-Disposition", "")
+	errs.RespondServiceError(ctx, err)
+}
+
+func (h *ExportHandler) feed(ctx *gin.Context, format string) {
+	feed, err := h.svc.Feed(ctx, format)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=600")
+	ctx.Data(http.StatusOK, contentTypes[format], feed)
+}
@@ -0,0 +1,144 @@
+package exportHandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockExportService struct {
+	ExportFn func(ctx context.Context, w io.Writer, format string, sellerId *int64) error
+	FeedFn   func(ctx context.Context, format string) ([]byte, error)
+}
+
+func (m *mockExportService) Export(ctx context.Context, w io.Writer, format string, sellerId *int64) error {
+	return m.ExportFn(ctx, w, format, sellerId)
+}
+func (m *mockExportService) Feed(ctx context.Context, format string) ([]byte, error) {
+	return m.FeedFn(ctx, format)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c, w
+}
+
+func TestExportHandler_SellerExport(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		serviceErr     error
+		expectedFormat string
+		expectedType   string
+		expectedStatus int
+	}{
+		{"default csv", "/", nil, model.ExportFormatCSV, "text/csv; charset=utf-8", http.StatusOK},
+		{"yml", "/?format=yml", nil, model.ExportFormatYML, "application/xml; charset=utf-8", http.StatusOK},
+		{"bad format", "/?format=xlsx", nil, "", "application/json; charset=utf-8", http.StatusBadRequest},
+		{"service error", "/", errs.ValidationError, model.ExportFormatCSV, "application/json; charset=utf-8", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockExportService{
+				ExportFn: func(ctx context.Context, w io.Writer, format string, sellerId *int64) error {
+					if format != tt.expectedFormat || sellerId == nil || *sellerId != 7 {
+						t.Fatalf("unexpected args: %q %v", format, sellerId)
+					}
+					if tt.serviceErr != nil {
+						return tt.serviceErr
+					}
+					_, err := io.WriteString(w, "feed")
+					return err
+				},
+			}
+			h := NewExportHandler(svc)
+			c, w := makeCtx(tt.target)
+			c.Set("user_id", int64(7))
+
+			h.SellerExport(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if w.Header().Get("Content-Type") != tt.expectedType {
+				t.Fatalf("unexpected content type %q", w.Header().Get("Content-Type"))
+			}
+			if tt.expectedStatus == http.StatusOK && w.Body.String() != "feed" {
+				t.Fatalf("unexpected body %q", w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK && w.Header().Get("Content-Disposition") != "" {
+				t.Fatalf("error response must not be an attachment")
+			}
+		})
+	}
+}
+
+func TestExportHandler_Export(t *testing.T) {
+	svc := &mockExportService{
+		ExportFn: func(ctx context.Context, w io.Writer, format string, sellerId *int64) error {
+			if sellerId != nil {
+				t.Fatalf("global export must not be limited to a seller")
+			}
+			_, _ = io.WriteString(w, "partial")
+			return errors.New("db")
+		},
+	}
+	h := NewExportHandler(svc)
+	c, w := makeCtx("/")
+
+	h.Export(c)
+
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Fatalf("a started stream must not be replaced with an error: %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestExportHandler_Feed(t *testing.T) {
+	svc := &mockExportService{
+		FeedFn: func(ctx context.Context, format string) ([]byte, error) {
+			switch format {
+			case model.ExportFormatYML:
+				return []byte("<yml_catalog/>"), nil
+			case model.ExportFormatSitemap:
+				return []byte("<urlset/>"), nil
+			}
+			return nil, errors.New("unexpected format")
+		},
+	}
+	h := NewExportHandler(svc)
+
+	c, w := makeCtx("/")
+	h.FeedYML(c)
+	if w.Code != http.StatusOK || w.Body.String() != "<yml_catalog/>" {
+		t.Fatalf("unexpected response: %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "application/xml; charset=utf-8" || w.Header().Get("Cache-Control") == "" {
+		t.Fatalf("unexpected headers: %v", w.Header())
+	}
+
+	c, w = makeCtx("/")
+	h.Sitemap(c)
+	if w.Code != http.StatusOK || w.Body.String() != "<urlset/>" || w.Header().Get("Content-Type") != "application/xml; charset=utf-8" {
+		t.Fatalf("unexpected sitemap response: %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	c, w = makeCtx("/")
+	h.FeedCSV(c)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,128 @@
+package importHandler
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IImportService interface {
+	StartImport(ctx context.Context, sellerId int64, format string, file io.Reader) (model.ImportJobResponse, error)
+	GetJob(ctx context.Context, userId int64, role string, req *model.GetImportJobRequest) (model.ImportJobResponse, error)
+	GetErrors(ctx context.Context, userId int64, role string, req *model.GetImportJobRequest) ([]model.ImportRowErrorResponse, error)
+}
+
+type ImportHandler struct {
+	svc IImportService
+}
+
+func NewImportHandler(svc IImportService) *ImportHandler {
+	return &ImportHandler{svc: svc}
+}
+
+func (h *ImportHandler) Start(ctx *gin.Context) {
+	var req model.StartImportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", "file is required")
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = formatByExtension(header.Filename)
+	}
+	if format == "" {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", "format must be csv or jsonl")
+		return
+	}
+
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	defer file.Close()
+
+	job, err := h.svc.StartImport(ctx, userId.(int64), format, file)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"data": job})
+}
+
+func (h *ImportHandler) Get(ctx *gin.Context) {
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req := model.GetImportJobRequest{Id: int64(idInt)}
+
+	job, err := h.svc.GetJob(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// Errors returns the error report of the job as a csv file.
+func (h *ImportHandler) Errors(ctx *gin.Context) {
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req := model.GetImportJobRequest{Id: int64(idInt)}
+
+	rowErrors, err := h.svc.GetErrors(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%d-errors.csv"`, req.Id))
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Status(http.StatusOK)
+
+	writer := csv.NewWriter(ctx.Writer)
+	_ = writer.Write([]string{"row_number", "sku", "reason"})
+	for _, rowErr := range rowErrors {
+		_ = writer.Write([]string{strconv.Itoa(rowErr.RowNumber), rowErr.Sku, rowErr.Reason})
+	}
+	writer.Flush()
+}
+
+func formatByExtension(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return model.ImportFormatCSV
+	case ".jsonl", ".ndjson":
+		return model.ImportFormatJSONL
+	}
+	return ""
+}
@@ -0,0 +1,180 @@
+package importHandler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockImportService struct {
+	StartImportFn func(ctx context.Context, sellerId int64, format string, file io.Reader) (model.ImportJobResponse, error)
+	GetJobFn      func(ctx context.Context, userId int64, role string, req *model.GetImportJobRequest) (model.ImportJobResponse, error)
+	GetErrorsFn   func(ctx context.Context, userId int64, role string, req *model.GetImportJobRequest) ([]model.ImportRowErrorResponse, error)
+}
+
+func (m *mockImportService) StartImport(ctx context.Context, sellerId int64, format string, file io.Reader) (model.ImportJobResponse, error) {
+	return m.StartImportFn(ctx, sellerId, format, file)
+}
+func (m *mockImportService) GetJob(ctx context.Context, userId int64, role string, req *model.GetImportJobRequest) (model.ImportJobResponse, error) {
+	return m.GetJobFn(ctx, userId, role, req)
+}
+func (m *mockImportService) GetErrors(ctx context.Context, userId int64, role string, req *model.GetImportJobRequest) ([]model.ImportRowErrorResponse, error) {
+	return m.GetErrorsFn(ctx, userId, role, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeUploadCtx(t *testing.T, query, filename, content string) (*gin.Context, *httptest.ResponseRecorder) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if filename != "" {
+		part, err := writer.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		_, _ = part.Write([]byte(content))
+	}
+	_ = writer.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodPost, "/"+query, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Request = req
+	return c, w
+}
+
+func TestImportHandler_Start(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		filename       string
+		serviceErr     error
+		expectedFormat string
+		expectedStatus int
+	}{
+		{"csv by extension", "", "products.csv", nil, model.ImportFormatCSV, http.StatusAccepted},
+		{"jsonl by extension", "", "products.ndjson", nil, model.ImportFormatJSONL, http.StatusAccepted},
+		{"explicit format", "?format=jsonl", "export.txt", nil, model.ImportFormatJSONL, http.StatusAccepted},
+		{"unknown format", "", "products.xlsx", nil, "", http.StatusBadRequest},
+		{"bad format param", "?format=xml", "products.csv", nil, "", http.StatusBadRequest},
+		{"no file", "", "", nil, "", http.StatusBadRequest},
+		{"invalid file", "", "products.csv", errs.ValidationError, model.ImportFormatCSV, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			svc := &mockImportService{
+				StartImportFn: func(ctx context.Context, sellerId int64, format string, file io.Reader) (model.ImportJobResponse, error) {
+					called = true
+					if sellerId != 7 || format != tt.expectedFormat {
+						t.Fatalf("unexpected args: %d %q", sellerId, format)
+					}
+					data, _ := io.ReadAll(file)
+					if string(data) != "content" {
+						t.Fatalf("unexpected file: %q", data)
+					}
+					if tt.serviceErr != nil {
+						return model.ImportJobResponse{}, tt.serviceErr
+					}
+					return model.ImportJobResponse{Id: 1, Status: model.ImportStatusPending}, nil
+				},
+			}
+			h := NewImportHandler(svc)
+			c, w := makeUploadCtx(t, tt.query, tt.filename, "content")
+			c.Set("user_id", int64(7))
+
+			h.Start(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if called != (tt.expectedFormat != "") {
+				t.Fatalf("unexpected service call: %v", called)
+			}
+		})
+	}
+}
+
+func TestImportHandler_Get(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "3", nil, http.StatusOK},
+		{"bad id", "x", nil, http.StatusBadRequest},
+		{"not owner", "3", errs.NotOwnerError, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockImportService{
+				GetJobFn: func(ctx context.Context, userId int64, role string, req *model.GetImportJobRequest) (model.ImportJobResponse, error) {
+					if userId != 7 || role != "seller" || req.Id != 3 {
+						t.Fatalf("unexpected args: %d %q %d", userId, role, req.Id)
+					}
+					if tt.serviceErr != nil {
+						return model.ImportJobResponse{}, tt.serviceErr
+					}
+					return model.ImportJobResponse{Id: 3, TotalRows: 10, ProcessedRows: 5}, nil
+				},
+			}
+			h := NewImportHandler(svc)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+			c.Set("user_id", int64(7))
+			c.Set("role", "seller")
+
+			h.Get(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestImportHandler_Errors(t *testing.T) {
+	svc := &mockImportService{
+		GetErrorsFn: func(ctx context.Context, userId int64, role string, req *model.GetImportJobRequest) ([]model.ImportRowErrorResponse, error) {
+			return []model.ImportRowErrorResponse{
+				{RowNumber: 2, Sku: "A-1", Reason: "price is not a number"},
+				{RowNumber: 5, Sku: "B,2", Reason: "sku is required"},
+			}, nil
+		},
+	}
+	h := NewImportHandler(svc)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	h.Errors(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Header().Get("Content-Disposition"), "import-3-errors.csv") {
+		t.Fatalf("unexpected disposition: %q", w.Header().Get("Content-Disposition"))
+	}
+	expected := "row_number,sku,reason\n2,A-1,price is not a number\n5,\"B,2\",sku is required\n"
+	if w.Body.String() != expected {
+		t.Fatalf("unexpected body: %q", w.Body.String())
+	}
+}
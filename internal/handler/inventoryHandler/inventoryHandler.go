@@ -0,0 +1,221 @@
+package inventoryHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IInventoryService interface {
+	CreateWarehouse(ctx context.Context, userId int64, req *model.CreateWarehouseRequest) (model.WarehouseResponse, error)
+	ListWarehouses(ctx context.Context, userId int64) ([]model.WarehouseResponse, error)
+	UpdateWarehouse(ctx context.Context, userId int64, role string, req *model.UpdateWarehouseRequest) (model.WarehouseResponse, error)
+	DeleteWarehouse(ctx context.Context, userId int64, role string, req *model.WarehouseRequest) error
+	ProductInventory(ctx context.Context, userId int64, role string, req *model.ProductInventoryRequest) (model.ProductInventoryResponse, error)
+	SetLevel(ctx context.Context, userId int64, role string, req *model.SetInventoryLevelRequest) (model.ProductInventoryResponse, error)
+	Transfer(ctx context.Context, userId int64, role string, req *model.TransferStockRequest) (model.InventoryTransferResponse, error)
+	ProductMovements(ctx context.Context, userId int64, role string, limit int, req *model.ProductMovementsRequest) ([]model.InventoryMovementResponse, string, error)
+	Reconcile(ctx context.Context, limit int, req *model.ReconciliationRequest) ([]model.StockDriftResponse, string, error)
+}
+
+type InventoryHandler struct {
+	svc IInventoryService
+}
+
+func NewInventoryHandler(svc IInventoryService) *InventoryHandler {
+	return &InventoryHandler{svc: svc}
+}
+
+func (h *InventoryHandler) CreateWarehouse(ctx *gin.Context) {
+	var req model.CreateWarehouseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	warehouse, err := h.svc.CreateWarehouse(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": warehouse})
+}
+
+func (h *InventoryHandler) ListWarehouses(ctx *gin.Context) {
+	warehouses, err := h.svc.ListWarehouses(ctx, ctx.GetInt64("user_id"))
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": warehouses})
+}
+
+func (h *InventoryHandler) UpdateWarehouse(ctx *gin.Context) {
+	id, ok := paramId(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateWarehouseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.Id = id
+
+	warehouse, err := h.svc.UpdateWarehouse(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": warehouse})
+}
+
+func (h *InventoryHandler) DeleteWarehouse(ctx *gin.Context) {
+	id, ok := paramId(ctx, "id")
+	if !ok {
+		return
+	}
+	req := model.WarehouseRequest{Id: id}
+
+	if err := h.svc.DeleteWarehouse(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": "warehouse deleted"})
+}
+
+func (h *InventoryHandler) ProductInventory(ctx *gin.Context) {
+	productId, ok := paramId(ctx, "id")
+	if !ok {
+		return
+	}
+	req := model.ProductInventoryRequest{ProductId: productId}
+
+	inventory, err := h.svc.ProductInventory(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": inventory})
+}
+
+func (h *InventoryHandler) SetLevel(ctx *gin.Context) {
+	productId, ok := paramId(ctx, "id")
+	if !ok {
+		return
+	}
+	warehouseId, ok := paramId(ctx, "warehouseId")
+	if !ok {
+		return
+	}
+
+	var req model.SetInventoryLevelRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.ProductId = productId
+	req.WarehouseId = warehouseId
+
+	inventory, err := h.svc.SetLevel(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": inventory})
+}
+
+func (h *InventoryHandler) Transfer(ctx *gin.Context) {
+	var req model.TransferStockRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	transfer, err := h.svc.Transfer(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": transfer})
+}
+
+func (h *InventoryHandler) ProductMovements(ctx *gin.Context) {
+	productId, ok := paramId(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req model.ProductMovementsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.ProductId = productId
+
+	limit := queryLimit(ctx)
+	movements, nextCursor, err := h.svc.ProductMovements(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        movements,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+// Reconciliation lists the products whose stock drifted away from the ledger.
+func (h *InventoryHandler) Reconciliation(ctx *gin.Context) {
+	var req model.ReconciliationRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	limit := queryLimit(ctx)
+	drifts, nextCursor, err := h.svc.Reconcile(ctx, limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        drifts,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+func queryLimit(ctx *gin.Context) int {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		return 20
+	}
+
+	return limit
+}
+
+func paramId(ctx *gin.Context, name string) (int64, bool) {
+	id, err := strconv.Atoi(ctx.Param(name))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return 0, false
+	}
+
+	return int64(id), true
+}
@@ -0,0 +1,230 @@
+package inventoryHandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockInventoryService struct {
+	CreateWarehouseFn  func(ctx context.Context, userId int64, req *model.CreateWarehouseRequest) (model.WarehouseResponse, error)
+	ListWarehousesFn   func(ctx context.Context, userId int64) ([]model.WarehouseResponse, error)
+	UpdateWarehouseFn  func(ctx context.Context, userId int64, role string, req *model.UpdateWarehouseRequest) (model.WarehouseResponse, error)
+	DeleteWarehouseFn  func(ctx context.Context, userId int64, role string, req *model.WarehouseRequest) error
+	ProductInventoryFn func(ctx context.Context, userId int64, role string, req *model.ProductInventoryRequest) (model.ProductInventoryResponse, error)
+	SetLevelFn         func(ctx context.Context, userId int64, role string, req *model.SetInventoryLevelRequest) (model.ProductInventoryResponse, error)
+	TransferFn         func(ctx context.Context, userId int64, role string, req *model.TransferStockRequest) (model.InventoryTransferResponse, error)
+	MovementsFn        func(ctx context.Context, userId int64, role string, limit int, req *model.ProductMovementsRequest) ([]model.InventoryMovementResponse, string, error)
+	ReconcileFn        func(ctx context.Context, limit int, req *model.ReconciliationRequest) ([]model.StockDriftResponse, string, error)
+}
+
+func (m *mockInventoryService) CreateWarehouse(ctx context.Context, userId int64, req *model.CreateWarehouseRequest) (model.WarehouseResponse, error) {
+	return m.CreateWarehouseFn(ctx, userId, req)
+}
+func (m *mockInventoryService) ListWarehouses(ctx context.Context, userId int64) ([]model.WarehouseResponse, error) {
+	return m.ListWarehousesFn(ctx, userId)
+}
+func (m *mockInventoryService) UpdateWarehouse(ctx context.Context, userId int64, role string, req *model.UpdateWarehouseRequest) (model.WarehouseResponse, error) {
+	return m.UpdateWarehouseFn(ctx, userId, role, req)
+}
+func (m *mockInventoryService) DeleteWarehouse(ctx context.Context, userId int64, role string, req *model.WarehouseRequest) error {
+	return m.DeleteWarehouseFn(ctx, userId, role, req)
+}
+func (m *mockInventoryService) ProductInventory(ctx context.Context, userId int64, role string, req *model.ProductInventoryRequest) (model.ProductInventoryResponse, error) {
+	return m.ProductInventoryFn(ctx, userId, role, req)
+}
+func (m *mockInventoryService) SetLevel(ctx context.Context, userId int64, role string, req *model.SetInventoryLevelRequest) (model.ProductInventoryResponse, error) {
+	return m.SetLevelFn(ctx, userId, role, req)
+}
+func (m *mockInventoryService) Transfer(ctx context.Context, userId int64, role string, req *model.TransferStockRequest) (model.InventoryTransferResponse, error) {
+	return m.TransferFn(ctx, userId, role, req)
+}
+func (m *mockInventoryService) ProductMovements(ctx context.Context, userId int64, role string, limit int, req *model.ProductMovementsRequest) ([]model.InventoryMovementResponse, string, error) {
+	return m.MovementsFn(ctx, userId, role, limit, req)
+}
+func (m *mockInventoryService) Reconcile(ctx context.Context, limit int, req *model.ReconciliationRequest) ([]model.StockDriftResponse, string, error) {
+	return m.ReconcileFn(ctx, limit, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(method, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("user_id", int64(2))
+	c.Set("role", "seller")
+	return c, w
+}
+
+func TestInventoryHandler_CreateWarehouse(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", `{"name":"Moscow","priority":1}`, nil, http.StatusCreated},
+		{"empty name", `{"priority":1}`, nil, http.StatusBadRequest},
+		{"duplicate name", `{"name":"Moscow"}`, errs.ConflictError, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockInventoryService{
+				CreateWarehouseFn: func(ctx context.Context, userId int64, req *model.CreateWarehouseRequest) (model.WarehouseResponse, error) {
+					if userId != 2 || req.Name != "Moscow" {
+						t.Fatalf("unexpected args: %d %+v", userId, req)
+					}
+					return model.WarehouseResponse{Id: 1, Name: req.Name}, tt.serviceErr
+				},
+			}
+			h := NewInventoryHandler(svc)
+			c, w := makeCtx(http.MethodPost, tt.body, nil)
+
+			h.CreateWarehouse(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestInventoryHandler_SetLevel(t *testing.T) {
+	tests := []struct {
+		name           string
+		productId      string
+		warehouseId    string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "7", "10", `{"quantity":5}`, nil, http.StatusOK},
+		{"zero", "7", "10", `{"quantity":0}`, nil, http.StatusOK},
+		{"missing quantity", "7", "10", `{}`, nil, http.StatusBadRequest},
+		{"negative quantity", "7", "10", `{"quantity":-1}`, nil, http.StatusBadRequest},
+		{"bad warehouse id", "7", "x", `{"quantity":5}`, nil, http.StatusBadRequest},
+		{"foreign warehouse", "7", "10", `{"quantity":5}`, errs.NotFoundError, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockInventoryService{
+				SetLevelFn: func(ctx context.Context, userId int64, role string, req *model.SetInventoryLevelRequest) (model.ProductInventoryResponse, error) {
+					if req.ProductId != 7 || req.WarehouseId != 10 {
+						t.Fatalf("unexpected request: %+v", req)
+					}
+					return model.ProductInventoryResponse{ProductId: 7, Stock: *req.Quantity}, tt.serviceErr
+				},
+			}
+			h := NewInventoryHandler(svc)
+			c, w := makeCtx(http.MethodPut, tt.body, gin.Params{{Key: "id", Value: tt.productId}, {Key: "warehouseId", Value: tt.warehouseId}})
+
+			h.SetLevel(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestInventoryHandler_Transfer(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", `{"product_id":7,"from_warehouse_id":10,"to_warehouse_id":11,"quantity":2}`, nil, http.StatusCreated},
+		{"zero quantity", `{"product_id":7,"from_warehouse_id":10,"to_warehouse_id":11,"quantity":0}`, nil, http.StatusBadRequest},
+		{"not enough stock", `{"product_id":7,"from_warehouse_id":10,"to_warehouse_id":11,"quantity":2}`, errs.ConflictError, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockInventoryService{
+				TransferFn: func(ctx context.Context, userId int64, role string, req *model.TransferStockRequest) (model.InventoryTransferResponse, error) {
+					return model.InventoryTransferResponse{Id: 1, Quantity: req.Quantity}, tt.serviceErr
+				},
+			}
+			h := NewInventoryHandler(svc)
+			c, w := makeCtx(http.MethodPost, tt.body, nil)
+
+			h.Transfer(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestInventoryHandler_ProductMovements(t *testing.T) {
+	tests := []struct {
+		name           string
+		productId      string
+		query          string
+		serviceErr     error
+		expectedLimit  int
+		expectedStatus int
+	}{
+		{"success", "7", "limit=5&cursor=abc", nil, 5, http.StatusOK},
+		{"limit out of range", "7", "limit=1000", nil, 20, http.StatusOK},
+		{"bad product id", "x", "", nil, 20, http.StatusBadRequest},
+		{"another seller's product", "7", "", errs.NotOwnerError, 20, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockInventoryService{
+				MovementsFn: func(ctx context.Context, userId int64, role string, limit int, req *model.ProductMovementsRequest) ([]model.InventoryMovementResponse, string, error) {
+					if req.ProductId != 7 || limit != tt.expectedLimit {
+						t.Fatalf("unexpected request: %+v, limit %d", req, limit)
+					}
+					return []model.InventoryMovementResponse{{Id: 1, Quantity: -2, Reason: model.MovementSale}}, req.Cursor, tt.serviceErr
+				},
+			}
+			h := NewInventoryHandler(svc)
+			c, w := makeCtx(http.MethodGet, "", gin.Params{{Key: "id", Value: tt.productId}})
+			c.Request = httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+
+			h.ProductMovements(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestInventoryHandler_Reconciliation(t *testing.T) {
+	svc := &mockInventoryService{
+		ReconcileFn: func(ctx context.Context, limit int, req *model.ReconciliationRequest) ([]model.StockDriftResponse, string, error) {
+			if limit != 50 || req.Cursor != "abc" {
+				t.Fatalf("unexpected request: %+v, limit %d", req, limit)
+			}
+			return []model.StockDriftResponse{{ProductId: 4, Stock: 10, LedgerStock: 7, Drift: 3}}, "", nil
+		},
+	}
+	h := NewInventoryHandler(svc)
+	c, w := makeCtx(http.MethodGet, "", nil)
+	c.Request = httptest.NewRequest(http.MethodGet, "/?limit=50&cursor=abc", nil)
+
+	h.Reconciliation(c)
+
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"drift":3`)) {
+		t.Fatalf("unexpected response %d: %s", w.Code, w.Body.String())
+	}
+}
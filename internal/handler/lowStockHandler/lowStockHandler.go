@@ -0,0 +1,88 @@
+package lowStockHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type ILowStockService interface {
+	List(ctx context.Context, sellerId int64, limit int, req *model.ListLowStockAlertsRequest) ([]model.LowStockAlertResponse, string, error)
+	SetThreshold(ctx context.Context, userId int64, role string, req *model.LowStockThresholdRequest) (model.LowStockThresholdResponse, error)
+}
+
+type LowStockHandler struct {
+	svc ILowStockService
+}
+
+func NewLowStockHandler(svc ILowStockService) *LowStockHandler {
+	return &LowStockHandler{svc: svc}
+}
+
+func (h *LowStockHandler) Alerts(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var req model.ListLowStockAlertsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	alerts, nextCursor, err := h.svc.List(ctx, ctx.GetInt64("user_id"), limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        alerts,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+// SellerThreshold sets the default threshold for all products of the seller.
+func (h *LowStockHandler) SellerThreshold(ctx *gin.Context) {
+	var req model.LowStockThresholdRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.ProductId = 0
+
+	h.setThreshold(ctx, &req)
+}
+
+func (h *LowStockHandler) ProductThreshold(ctx *gin.Context) {
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	var req model.LowStockThresholdRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.ProductId = int64(productId)
+
+	h.setThreshold(ctx, &req)
+}
+
+func (h *LowStockHandler) setThreshold(ctx *gin.Context, req *model.LowStockThresholdRequest) {
+	threshold, err := h.svc.SetThreshold(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": threshold})
+}
@@ -0,0 +1,115 @@
+package lowStockHandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockLowStockService struct {
+	ListFn         func(ctx context.Context, sellerId int64, limit int, req *model.ListLowStockAlertsRequest) ([]model.LowStockAlertResponse, string, error)
+	SetThresholdFn func(ctx context.Context, userId int64, role string, req *model.LowStockThresholdRequest) (model.LowStockThresholdResponse, error)
+}
+
+func (m *mockLowStockService) List(ctx context.Context, sellerId int64, limit int, req *model.ListLowStockAlertsRequest) ([]model.LowStockAlertResponse, string, error) {
+	return m.ListFn(ctx, sellerId, limit, req)
+}
+func (m *mockLowStockService) SetThreshold(ctx context.Context, userId int64, role string, req *model.LowStockThresholdRequest) (model.LowStockThresholdResponse, error) {
+	return m.SetThresholdFn(ctx, userId, role, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(method, target, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("user_id", int64(2))
+	c.Set("role", "seller")
+	return c, w
+}
+
+func TestLowStockHandler_Alerts(t *testing.T) {
+	svc := &mockLowStockService{
+		ListFn: func(ctx context.Context, sellerId int64, limit int, req *model.ListLowStockAlertsRequest) ([]model.LowStockAlertResponse, string, error) {
+			if sellerId != 2 || limit != 20 || req.Cursor != "abc" {
+				t.Fatalf("unexpected args: %d %d %+v", sellerId, limit, req)
+			}
+			return []model.LowStockAlertResponse{{Id: 1}}, "", nil
+		},
+	}
+	h := NewLowStockHandler(svc)
+	c, w := makeCtx(http.MethodGet, "/?cursor=abc&limit=500", "", nil)
+
+	h.Alerts(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestLowStockHandler_ProductThreshold(t *testing.T) {
+	tests := []struct {
+		name           string
+		productId      string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "7", `{"threshold":3}`, nil, http.StatusOK},
+		{"clear", "7", `{"threshold":null}`, nil, http.StatusOK},
+		{"bad product id", "x", `{"threshold":3}`, nil, http.StatusBadRequest},
+		{"negative threshold", "7", `{"threshold":-1}`, nil, http.StatusBadRequest},
+		{"another seller's product", "7", `{"threshold":3}`, errs.NotOwnerError, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockLowStockService{
+				SetThresholdFn: func(ctx context.Context, userId int64, role string, req *model.LowStockThresholdRequest) (model.LowStockThresholdResponse, error) {
+					if userId != 2 || role != "seller" || req.ProductId != 7 {
+						t.Fatalf("unexpected args: %d %s %+v", userId, role, req)
+					}
+					return model.LowStockThresholdResponse{ProductId: 7, Threshold: req.Threshold}, tt.serviceErr
+				},
+			}
+			h := NewLowStockHandler(svc)
+			c, w := makeCtx(http.MethodPut, "/", tt.body, gin.Params{{Key: "id", Value: tt.productId}})
+
+			h.ProductThreshold(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLowStockHandler_SellerThreshold(t *testing.T) {
+	svc := &mockLowStockService{
+		SetThresholdFn: func(ctx context.Context, userId int64, role string, req *model.LowStockThresholdRequest) (model.LowStockThresholdResponse, error) {
+			if req.ProductId != 0 || *req.Threshold != 4 {
+				t.Fatalf("the seller default must not target a product: %+v", req)
+			}
+			return model.LowStockThresholdResponse{Threshold: req.Threshold}, nil
+		},
+	}
+	h := NewLowStockHandler(svc)
+	c, w := makeCtx(http.MethodPut, "/", `{"product_id":7,"threshold":4}`, nil)
+
+	h.SellerThreshold(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
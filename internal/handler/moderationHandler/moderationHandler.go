@@ -0,0 +1,112 @@
+package moderationHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IModerationService interface {
+	CreateRule(ctx context.Context, req *model.CreateModerationRuleRequest) (*model.ModerationRuleResponse, error)
+	GetAllRules(ctx context.Context) (*[]model.ModerationRuleResponse, error)
+	UpdateRule(ctx context.Context, req *model.UpdateModerationRuleRequest) (*model.ModerationRuleResponse, error)
+	DeleteRule(ctx context.Context, req *model.DeleteModerationRuleRequest) error
+	GetProductFlags(ctx context.Context, req *model.GetProductFlagsRequest) (*[]model.ModerationFlagResponse, error)
+}
+
+type ModerationHandler struct {
+	svc IModerationService
+}
+
+func NewModerationHandler(svc IModerationService) *ModerationHandler {
+	return &ModerationHandler{svc: svc}
+}
+
+func (h *ModerationHandler) CreateRule(c *gin.Context) {
+	var req model.CreateModerationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	rule, err := h.svc.CreateRule(c, &req)
+	if err != nil {
+		errs.RespondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": rule})
+}
+
+func (h *ModerationHandler) GetAllRules(c *gin.Context) {
+	rules, err := h.svc.GetAllRules(c)
+	if err != nil {
+		errs.RespondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+func (h *ModerationHandler) UpdateRule(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errs.RespondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	var req model.UpdateModerationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.Id = int64(idInt)
+
+	rule, err := h.svc.UpdateRule(c, &req)
+	if err != nil {
+		errs.RespondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rule})
+}
+
+func (h *ModerationHandler) DeleteRule(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errs.RespondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	req := model.DeleteModerationRuleRequest{Id: int64(idInt)}
+
+	err = h.svc.DeleteRule(c, &req)
+	if err != nil {
+		errs.RespondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": true})
+}
+
+func (h *ModerationHandler) GetProductFlags(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errs.RespondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	req := model.GetProductFlagsRequest{ProductId: int64(idInt)}
+
+	flags, err := h.svc.GetProductFlags(c, &req)
+	if err != nil {
+		errs.RespondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": flags})
+}
@@ -0,0 +1,147 @@
+package moderationHandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockModerationService struct {
+	CreateRuleFn      func(ctx context.Context, req *model.CreateModerationRuleRequest) (*model.ModerationRuleResponse, error)
+	GetAllRulesFn     func(ctx context.Context) (*[]model.ModerationRuleResponse, error)
+	UpdateRuleFn      func(ctx context.Context, req *model.UpdateModerationRuleRequest) (*model.ModerationRuleResponse, error)
+	DeleteRuleFn      func(ctx context.Context, req *model.DeleteModerationRuleRequest) error
+	GetProductFlagsFn func(ctx context.Context, req *model.GetProductFlagsRequest) (*[]model.ModerationFlagResponse, error)
+}
+
+func (m *mockModerationService) CreateRule(ctx context.Context, req *model.CreateModerationRuleRequest) (*model.ModerationRuleResponse, error) {
+	return m.CreateRuleFn(ctx, req)
+}
+func (m *mockModerationService) GetAllRules(ctx context.Context) (*[]model.ModerationRuleResponse, error) {
+	return m.GetAllRulesFn(ctx)
+}
+func (m *mockModerationService) UpdateRule(ctx context.Context, req *model.UpdateModerationRuleRequest) (*model.ModerationRuleResponse, error) {
+	return m.UpdateRuleFn(ctx, req)
+}
+func (m *mockModerationService) DeleteRule(ctx context.Context, req *model.DeleteModerationRuleRequest) error {
+	return m.DeleteRuleFn(ctx, req)
+}
+func (m *mockModerationService) GetProductFlags(ctx context.Context, req *model.GetProductFlagsRequest) (*[]model.ModerationFlagResponse, error) {
+	return m.GetProductFlagsFn(ctx, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(body string, method string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, "/", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, "/", nil)
+	}
+	c.Request = req
+	return c, w
+}
+
+func TestModerationHandler_CreateRule(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", `{"kind":"banned_word","pattern":"fake","severity":"high"}`, nil, http.StatusCreated},
+		{"bad kind", `{"kind":"word","pattern":"fake","severity":"high"}`, nil, http.StatusBadRequest},
+		{"invalid pattern", `{"kind":"regex","pattern":"(","severity":"low"}`, errs.ValidationError, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockModerationService{
+				CreateRuleFn: func(ctx context.Context, req *model.CreateModerationRuleRequest) (*model.ModerationRuleResponse, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					return &model.ModerationRuleResponse{Id: 1, Kind: req.Kind}, nil
+				},
+			}
+			h := NewModerationHandler(svc)
+			c, w := makeCtx(tt.body, http.MethodPost)
+			h.CreateRule(c)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestModerationHandler_UpdateRule(t *testing.T) {
+	tests := []struct {
+		name           string
+		paramValue     string
+		body           string
+		expectedStatus int
+	}{
+		{"success", "3", `{"enabled":false}`, http.StatusOK},
+		{"bad param", "x", `{"enabled":false}`, http.StatusBadRequest},
+		{"bad severity", "3", `{"severity":"critical"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockModerationService{
+				UpdateRuleFn: func(ctx context.Context, req *model.UpdateModerationRuleRequest) (*model.ModerationRuleResponse, error) {
+					if req.Id != 3 || req.Enabled == nil || *req.Enabled {
+						t.Fatalf("unexpected request: %+v", req)
+					}
+					return &model.ModerationRuleResponse{Id: req.Id}, nil
+				},
+			}
+			h := NewModerationHandler(svc)
+			c, w := makeCtx(tt.body, http.MethodPut)
+			c.Params = gin.Params{{Key: "id", Value: tt.paramValue}}
+			h.UpdateRule(c)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestModerationHandler_GetProductFlags(t *testing.T) {
+	svc := &mockModerationService{
+		GetProductFlagsFn: func(ctx context.Context, req *model.GetProductFlagsRequest) (*[]model.ModerationFlagResponse, error) {
+			if req.ProductId != 8 {
+				return nil, errors.New("svc")
+			}
+			return &[]model.ModerationFlagResponse{{ProductId: 8, Code: model.FlagCodeContactDetails}}, nil
+		},
+	}
+	h := NewModerationHandler(svc)
+
+	c, w := makeCtx("", http.MethodGet)
+	c.Params = gin.Params{{Key: "id", Value: "8"}}
+	h.GetProductFlags(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status got %d body: %s", w.Code, w.Body.String())
+	}
+
+	c, w = makeCtx("", http.MethodGet)
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+	h.GetProductFlags(c)
+	if w.Code < 500 {
+		t.Fatalf("expected 5xx status, got %d", w.Code)
+	}
+}
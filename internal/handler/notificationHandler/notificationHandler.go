@@ -0,0 +1,75 @@
+package notificationHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type INotificationService interface {
+	List(ctx context.Context, userId int64, limit int, req *model.ListNotificationsRequest) ([]model.NotificationResponse, string, error)
+	MarkRead(ctx context.Context, userId int64, req *model.ReadNotificationRequest) error
+	MarkAllRead(ctx context.Context, userId int64) error
+}
+
+type NotificationHandler struct {
+	svc INotificationService
+}
+
+func NewNotificationHandler(svc INotificationService) *NotificationHandler {
+	return &NotificationHandler{svc: svc}
+}
+
+func (h *NotificationHandler) List(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var req model.ListNotificationsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	notifications, nextCursor, err := h.svc.List(ctx, ctx.GetInt64("user_id"), limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        notifications,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (h *NotificationHandler) MarkRead(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req := model.ReadNotificationRequest{Id: int64(id)}
+
+	if err := h.svc.MarkRead(ctx, ctx.GetInt64("user_id"), &req); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": "notification read"})
+}
+
+func (h *NotificationHandler) MarkAllRead(ctx *gin.Context) {
+	if err := h.svc.MarkAllRead(ctx, ctx.GetInt64("user_id")); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": "notifications read"})
+}
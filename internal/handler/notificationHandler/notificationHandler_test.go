@@ -0,0 +1,94 @@
+package notificationHandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockNotificationService struct {
+	ListFn        func(ctx context.Context, userId int64, limit int, req *model.ListNotificationsRequest) ([]model.NotificationResponse, string, error)
+	MarkReadFn    func(ctx context.Context, userId int64, req *model.ReadNotificationRequest) error
+	MarkAllReadFn func(ctx context.Context, userId int64) error
+}
+
+func (m *mockNotificationService) List(ctx context.Context, userId int64, limit int, req *model.ListNotificationsRequest) ([]model.NotificationResponse, string, error) {
+	return m.ListFn(ctx, userId, limit, req)
+}
+func (m *mockNotificationService) MarkRead(ctx context.Context, userId int64, req *model.ReadNotificationRequest) error {
+	return m.MarkReadFn(ctx, userId, req)
+}
+func (m *mockNotificationService) MarkAllRead(ctx context.Context, userId int64) error {
+	return m.MarkAllReadFn(ctx, userId)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(method, target string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, nil)
+	c.Params = params
+	c.Set("user_id", int64(9))
+	return c, w
+}
+
+func TestNotificationHandler_List(t *testing.T) {
+	svc := &mockNotificationService{
+		ListFn: func(ctx context.Context, userId int64, limit int, req *model.ListNotificationsRequest) ([]model.NotificationResponse, string, error) {
+			if userId != 9 || limit != 20 || !req.Unread || req.Cursor != "abc" {
+				t.Fatalf("unexpected args: %d %d %+v", userId, limit, req)
+			}
+			return []model.NotificationResponse{{Id: 1, Kind: model.NotificationPriceDrop}}, "", nil
+		},
+	}
+	h := NewNotificationHandler(svc)
+	c, w := makeCtx(http.MethodGet, "/?unread=true&cursor=abc&limit=0", nil)
+
+	h.List(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestNotificationHandler_MarkRead(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "1", nil, http.StatusOK},
+		{"bad id", "x", nil, http.StatusBadRequest},
+		{"not found", "1", errs.NotFoundError, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockNotificationService{
+				MarkReadFn: func(ctx context.Context, userId int64, req *model.ReadNotificationRequest) error {
+					if userId != 9 || req.Id != 1 {
+						t.Fatalf("unexpected args: %d %+v", userId, req)
+					}
+					return tt.serviceErr
+				},
+			}
+			h := NewNotificationHandler(svc)
+			c, w := makeCtx(http.MethodPost, "/", gin.Params{{Key: "id", Value: tt.id}})
+
+			h.MarkRead(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ type IOrderService interface {
 	GetOrderById(ctx context.Context, req *model.GetOrderByIdRequest) (*model.OrderResponse, error)
 	GetOrderItemsByOrderId(ctx context.Context, req *model.GetOrderItemsByOrderIdRequest) (*[]model.OrderItemResponse, error)
 	DeleteOrderById(ctx context.Context, req *model.DeleteOrderByIdRequest) error
+	UpdateStatus(ctx context.Context, actorId int64, req *model.UpdateOrderStatusRequest) (*model.OrderResponse, error)
 }
 
 type OrderHandler struct {
@@ -112,3 +113,26 @@ func (h *OrderHandler) DeleteOrderById(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": true})
 }
+
+func (h *OrderHandler) UpdateStatus(c *gin.Context) {
+	orderId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		errs.RespondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	var req model.UpdateOrderStatusRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(c, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.Id = orderId
+
+	order, err := h.svc.UpdateStatus(c.Request.Context(), c.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"order": order})
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
 	"github.com/niklvrr/myMarketplace/internal/model"
 )
 
@@ -19,6 +20,7 @@ type mockOrderService struct {
 	GetOrderByIdFn           func(ctx context.Context, req *model.GetOrderByIdRequest) (*model.OrderResponse, error)
 	GetOrderItemsByOrderIdFn func(ctx context.Context, req *model.GetOrderItemsByOrderIdRequest) (*[]model.OrderItemResponse, error)
 	DeleteOrderByIdFn        func(ctx context.Context, req *model.DeleteOrderByIdRequest) error
+	UpdateStatusFn           func(ctx context.Context, actorId int64, req *model.UpdateOrderStatusRequest) (*model.OrderResponse, error)
 }
 
 func (m *mockOrderService) CreateOrder(ctx context.Context, req *model.CreateOrderRequest) (int64, error) {
@@ -36,6 +38,9 @@ func (m *mockOrderService) GetOrderItemsByOrderId(ctx context.Context, req *mode
 func (m *mockOrderService) DeleteOrderById(ctx context.Context, req *model.DeleteOrderByIdRequest) error {
 	return m.DeleteOrderByIdFn(ctx, req)
 }
+func (m *mockOrderService) UpdateStatus(ctx context.Context, actorId int64, req *model.UpdateOrderStatusRequest) (*model.OrderResponse, error) {
+	return m.UpdateStatusFn(ctx, actorId, req)
+}
 
 func init() {
 	gin.SetMode(gin.ReleaseMode)
@@ -287,3 +292,48 @@ func TestOrderHandler_DeleteOrderById(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderHandler_UpdateStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		paramValue     string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "5", `{"status":"shipped"}`, nil, http.StatusOK},
+		{"bad param", "x", `{"status":"shipped"}`, nil, http.StatusBadRequest},
+		{"missing status", "5", `{}`, nil, http.StatusBadRequest},
+		{"wrong transition", "5", `{"status":"completed"}`, errs.ConflictError, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockOrderService{
+				UpdateStatusFn: func(ctx context.Context, actorId int64, req *model.UpdateOrderStatusRequest) (*model.OrderResponse, error) {
+					if actorId != 3 || req.Id != 5 {
+						t.Fatalf("unexpected call: %d %+v", actorId, req)
+					}
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					return &model.OrderResponse{Id: req.Id, Status: req.Status}, nil
+				},
+			}
+			h := NewOrderHandler(svc)
+			c, w := makeCtx(tt.body, http.MethodPut)
+			c.Params = gin.Params{{Key: "id", Value: tt.paramValue}}
+			c.Set("user_id", int64(3))
+			h.UpdateStatus(c)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus == http.StatusOK {
+				out := parseJSONBody(t, w)
+				if _, ok := out["order"]; !ok {
+					t.Fatalf("expected order field")
+				}
+			}
+		})
+	}
+}
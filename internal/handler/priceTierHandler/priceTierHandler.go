@@ -0,0 +1,116 @@
+package priceTierHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IPriceTierService interface {
+	Tiers(ctx context.Context, userId int64, role string, req *model.PriceTiersRequest) (model.ProductPriceTiersResponse, error)
+	SetTiers(ctx context.Context, userId int64, role string, req *model.SetPriceTiersRequest) (model.ProductPriceTiersResponse, error)
+	CreatePriceList(ctx context.Context, req *model.CreatePriceListRequest) (model.PriceListResponse, error)
+	PriceLists(ctx context.Context) ([]model.PriceListResponse, error)
+	AssignPriceList(ctx context.Context, req *model.AssignPriceListRequest) error
+}
+
+type PriceTierHandler struct {
+	svc IPriceTierService
+}
+
+func NewPriceTierHandler(svc IPriceTierService) *PriceTierHandler {
+	return &PriceTierHandler{svc: svc}
+}
+
+func (h *PriceTierHandler) Tiers(ctx *gin.Context) {
+	productId, ok := paramId(ctx, "id")
+	if !ok {
+		return
+	}
+	req := model.PriceTiersRequest{ProductId: productId}
+
+	tiers, err := h.svc.Tiers(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": tiers})
+}
+
+func (h *PriceTierHandler) SetTiers(ctx *gin.Context) {
+	productId, ok := paramId(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req model.SetPriceTiersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.ProductId = productId
+
+	tiers, err := h.svc.SetTiers(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": tiers})
+}
+
+func (h *PriceTierHandler) CreatePriceList(ctx *gin.Context) {
+	var req model.CreatePriceListRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	list, err := h.svc.CreatePriceList(ctx, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": list})
+}
+
+func (h *PriceTierHandler) PriceLists(ctx *gin.Context) {
+	lists, err := h.svc.PriceLists(ctx)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": lists})
+}
+
+func (h *PriceTierHandler) AssignPriceList(ctx *gin.Context) {
+	var req model.AssignPriceListRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	if err := h.svc.AssignPriceList(ctx, &req); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": true})
+}
+
+func paramId(ctx *gin.Context, name string) (int64, bool) {
+	id, err := strconv.Atoi(ctx.Param(name))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return 0, false
+	}
+
+	return int64(id), true
+}
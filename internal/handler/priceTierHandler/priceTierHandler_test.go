@@ -0,0 +1,127 @@
+package priceTierHandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockPriceTierService struct {
+	TiersFn           func(ctx context.Context, userId int64, role string, req *model.PriceTiersRequest) (model.ProductPriceTiersResponse, error)
+	SetTiersFn        func(ctx context.Context, userId int64, role string, req *model.SetPriceTiersRequest) (model.ProductPriceTiersResponse, error)
+	CreatePriceListFn func(ctx context.Context, req *model.CreatePriceListRequest) (model.PriceListResponse, error)
+	PriceListsFn      func(ctx context.Context) ([]model.PriceListResponse, error)
+	AssignPriceListFn func(ctx context.Context, req *model.AssignPriceListRequest) error
+}
+
+func (m *mockPriceTierService) Tiers(ctx context.Context, userId int64, role string, req *model.PriceTiersRequest) (model.ProductPriceTiersResponse, error) {
+	return m.TiersFn(ctx, userId, role, req)
+}
+func (m *mockPriceTierService) SetTiers(ctx context.Context, userId int64, role string, req *model.SetPriceTiersRequest) (model.ProductPriceTiersResponse, error) {
+	return m.SetTiersFn(ctx, userId, role, req)
+}
+func (m *mockPriceTierService) CreatePriceList(ctx context.Context, req *model.CreatePriceListRequest) (model.PriceListResponse, error) {
+	return m.CreatePriceListFn(ctx, req)
+}
+func (m *mockPriceTierService) PriceLists(ctx context.Context) ([]model.PriceListResponse, error) {
+	return m.PriceListsFn(ctx)
+}
+func (m *mockPriceTierService) AssignPriceList(ctx context.Context, req *model.AssignPriceListRequest) error {
+	return m.AssignPriceListFn(ctx, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(method, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("user_id", int64(2))
+	c.Set("role", "seller")
+	return c, w
+}
+
+func TestPriceTierHandler_SetTiers(t *testing.T) {
+	tests := []struct {
+		name           string
+		productId      string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "7", `{"tiers":[{"min_quantity":10,"price":90},{"min_quantity":50,"price":80}]}`, nil, http.StatusOK},
+		{"price list", "7", `{"price_list_id":3,"tiers":[{"min_quantity":1,"price":85}]}`, nil, http.StatusOK},
+		{"remove", "7", `{"tiers":[]}`, nil, http.StatusOK},
+		{"bad product id", "x", `{"tiers":[]}`, nil, http.StatusBadRequest},
+		{"missing tiers", "7", `{}`, nil, http.StatusBadRequest},
+		{"zero price", "7", `{"tiers":[{"min_quantity":10,"price":0}]}`, nil, http.StatusBadRequest},
+		{"more units cost more", "7", `{"tiers":[{"min_quantity":10,"price":80},{"min_quantity":50,"price":90}]}`, errs.ValidationError, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockPriceTierService{
+				SetTiersFn: func(ctx context.Context, userId int64, role string, req *model.SetPriceTiersRequest) (model.ProductPriceTiersResponse, error) {
+					if req.ProductId != 7 || userId != 2 {
+						t.Fatalf("unexpected request: %+v", req)
+					}
+					return model.ProductPriceTiersResponse{ProductId: 7}, tt.serviceErr
+				},
+			}
+			h := NewPriceTierHandler(svc)
+			c, w := makeCtx(http.MethodPut, tt.body, gin.Params{{Key: "id", Value: tt.productId}})
+
+			h.SetTiers(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPriceTierHandler_AssignPriceList(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+		expectedList   *int64
+	}{
+		{"join", `{"user_id":5,"price_list_id":3}`, nil, http.StatusOK, new(int64)},
+		{"leave", `{"user_id":5,"price_list_id":null}`, nil, http.StatusOK, nil},
+		{"missing user", `{"price_list_id":3}`, nil, http.StatusBadRequest, nil},
+		{"unknown user", `{"user_id":6,"price_list_id":3}`, errs.NotFoundError, http.StatusNotFound, new(int64)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockPriceTierService{
+				AssignPriceListFn: func(ctx context.Context, req *model.AssignPriceListRequest) error {
+					if (req.PriceListId == nil) != (tt.expectedList == nil) {
+						t.Fatalf("unexpected request: %+v", req)
+					}
+					return tt.serviceErr
+				},
+			}
+			h := NewPriceTierHandler(svc)
+			c, w := makeCtx(http.MethodPut, tt.body, nil)
+
+			h.AssignPriceList(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package productHandler
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/niklvrr/myMarketplace/internal/errs"
@@ -12,13 +14,30 @@ import (
 
 type IProductService interface {
 	Create(ctx context.Context, sellerId int64, req *model.CreateProductRequest) (model.ProductResponse, error)
-	GetById(ctx context.Context, req *model.GetProductsRequest) (model.ProductResponse, error)
-	UpdateById(ctx context.Context, sellerId int64, req *model.UpdateProductRequest) (model.ProductResponse, error)
-	DeleteById(ctx context.Context, req *model.DeleteProductRequest) error
-	GetAll(ctx context.Context, page, limit int) ([]model.ProductResponse, int64, error)
-	Search(ctx context.Context, page, limit int, req *model.SearchProductsRequest) ([]model.ProductResponse, int64, error)
+	GetById(ctx context.Context, userId int64, role string, req *model.GetProductsRequest) (model.ProductResponse, error)
+	GetBySlug(ctx context.Context, userId int64, role string, req *model.GetProductBySlugRequest) (model.ProductResponse, string, error)
+	UpdateById(ctx context.Context, userId int64, role string, req *model.UpdateProductRequest) (model.ProductResponse, error)
+	DeleteById(ctx context.Context, userId int64, role string, req *model.DeleteProductRequest) error
+	Restock(ctx context.Context, userId int64, role string, req *model.RestockProductRequest) (model.ProductResponse, error)
+	PriceHistory(ctx context.Context, userId int64, role string, req *model.PriceHistoryRequest) ([]model.PricePointResponse, error)
+	Related(ctx context.Context, limit int, req *model.RecommendationsRequest) ([]model.ProductResponse, error)
+	BoughtTogether(ctx context.Context, limit int, req *model.RecommendationsRequest) ([]model.ProductResponse, error)
+	RecentlyViewed(ctx context.Context, userId int64, limit int) ([]model.ProductResponse, error)
+	Feed(ctx context.Context, userId int64, limit int) ([]model.FeedItemResponse, error)
+	Archive(ctx context.Context, userId int64, role string, req *model.ArchiveProductRequest) (model.ProductResponse, error)
+	Restore(ctx context.Context, userId int64, role string, req *model.RestoreProductRequest) (model.ProductResponse, error)
+	GetAll(ctx context.Context, limit int, req *model.ListProductsRequest) ([]model.ProductResponse, string, error)
+	Search(ctx context.Context, limit int, req *model.SearchProductsRequest) ([]model.ProductResponse, string, error)
+	Reindex(ctx context.Context) error
+	Submit(ctx context.Context, userId int64, role string, req *model.SubmitProductRequest) (model.ProductResponse, error)
+	Moderate(ctx context.Context, req *model.ModerateProductRequest) (model.ProductResponse, error)
+	ModerationQueue(ctx context.Context, limit int, req *model.ModerationQueueRequest) ([]model.ProductResponse, string, error)
+	SellerProducts(ctx context.Context, sellerId int64, limit int, req *model.SellerProductsRequest) ([]model.SellerProductResponse, string, error)
 }
 
+// maxRecommendations matches the size of the precomputed recommendation lists.
+const maxRecommendations = 20
+
 type ProductHandler struct {
 	svc IProductService
 }
@@ -56,7 +75,7 @@ func (h *ProductHandler) Get(ctx *gin.Context) {
 		Id: int64(idInt),
 	}
 
-	product, err := h.svc.GetById(ctx, &req)
+	product, err := h.svc.GetById(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
 	if err != nil {
 		errs.RespondServiceError(ctx, err)
 		return
@@ -65,6 +84,28 @@ func (h *ProductHandler) Get(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"data": product})
 }
 
+// GetBySlug answers a former slug with a permanent redirect to the current one, so old
+// links to a renamed product keep working.
+func (h *ProductHandler) GetBySlug(ctx *gin.Context) {
+	req := model.GetProductBySlugRequest{
+		Slug: ctx.Param("slug"),
+	}
+
+	product, redirect, err := h.svc.GetBySlug(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	if redirect != "" {
+		path := strings.TrimSuffix(ctx.Request.URL.Path, req.Slug) + url.PathEscape(redirect)
+		ctx.Redirect(http.StatusMovedPermanently, path)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": product})
+}
+
 func (h *ProductHandler) Update(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	idInt, err := strconv.Atoi(idStr)
@@ -82,7 +123,7 @@ func (h *ProductHandler) Update(ctx *gin.Context) {
 		return
 	}
 
-	product, err := h.svc.UpdateById(ctx, userId.(int64), &req)
+	product, err := h.svc.UpdateById(ctx, userId.(int64), ctx.GetString("role"), &req)
 	if err != nil {
 		errs.RespondServiceError(ctx, err)
 		return
@@ -98,7 +139,13 @@ func (h *ProductHandler) Delete(ctx *gin.Context) {
 		Id: int64(idInt),
 	}
 
-	err = h.svc.DeleteById(ctx, &req)
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	err = h.svc.DeleteById(ctx, userId.(int64), ctx.GetString("role"), &req)
 	if err != nil {
 		errs.RespondServiceError(ctx, err)
 		return
@@ -113,22 +160,21 @@ func (h *ProductHandler) GetAll(ctx *gin.Context) {
 		limit = 20
 	}
 
-	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	if err != nil || page <= 0 {
-		page = 1
+	var req model.ListProductsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
 	}
 
-	products, total, err := h.svc.GetAll(ctx, page, limit)
+	products, nextCursor, err := h.svc.GetAll(ctx, limit, &req)
 	if err != nil {
 		errs.RespondServiceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
-		"data":       products,
-		"page":       page,
-		"limit":      limit,
-		"total":      total,
-		"totalPages": (total + int64(limit) - 1) / int64(limit),
+		"data":        products,
+		"limit":       limit,
+		"next_cursor": nextCursor,
 	})
 }
 
@@ -138,27 +184,284 @@ func (h *ProductHandler) Search(ctx *gin.Context) {
 		limit = 20
 	}
 
-	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	if err != nil || page <= 0 {
-		page = 1
+	var req model.SearchProductsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
 	}
 
-	var req model.SearchProductsRequest
+	products, nextCursor, err := h.svc.Search(ctx, limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        products,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (h *ProductHandler) Reindex(ctx *gin.Context) {
+	if err := h.svc.Reindex(ctx); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": "search index rebuilt"})
+}
+
+func (h *ProductHandler) Submit(ctx *gin.Context) {
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	req := model.SubmitProductRequest{Id: int64(idInt)}
+	product, err := h.svc.Submit(ctx, userId.(int64), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": product})
+}
+
+func (h *ProductHandler) Restock(ctx *gin.Context) {
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	var req model.RestockProductRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.Id = int64(idInt)
+
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	product, err := h.svc.Restock(ctx, userId.(int64), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": product})
+}
+
+func (h *ProductHandler) PriceHistory(ctx *gin.Context) {
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req := model.PriceHistoryRequest{Id: int64(idInt)}
+
+	history, err := h.svc.PriceHistory(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": history})
+}
+
+func (h *ProductHandler) Related(ctx *gin.Context) {
+	h.recommendations(ctx, h.svc.Related)
+}
+
+func (h *ProductHandler) BoughtTogether(ctx *gin.Context) {
+	h.recommendations(ctx, h.svc.BoughtTogether)
+}
+
+func (h *ProductHandler) recommendations(
+	ctx *gin.Context,
+	list func(ctx context.Context, limit int, req *model.RecommendationsRequest) ([]model.ProductResponse, error),
+) {
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req := model.RecommendationsRequest{Id: int64(idInt)}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > maxRecommendations {
+		limit = 10
+	}
+
+	products, err := list(ctx, limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": products})
+}
+
+func (h *ProductHandler) RecentlyViewed(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	products, err := h.svc.RecentlyViewed(ctx, ctx.GetInt64("user_id"), limit)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": products, "limit": limit})
+}
+
+func (h *ProductHandler) Feed(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	items, err := h.svc.Feed(ctx, ctx.GetInt64("user_id"), limit)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": items, "limit": limit})
+}
+
+func (h *ProductHandler) Moderate(ctx *gin.Context) {
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	var req model.ModerateProductRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.Id = int64(idInt)
+
+	product, err := h.svc.Moderate(ctx, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": product})
+}
+
+func (h *ProductHandler) ModerationQueue(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var req model.ModerationQueueRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
 		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
 		return
 	}
 
-	products, total, err := h.svc.Search(ctx, page, limit, &req)
+	products, nextCursor, err := h.svc.ModerationQueue(ctx, limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        products,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (h *ProductHandler) Archive(ctx *gin.Context) {
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	req := model.ArchiveProductRequest{Id: int64(idInt)}
+	product, err := h.svc.Archive(ctx, userId.(int64), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": product})
+}
+
+func (h *ProductHandler) Restore(ctx *gin.Context) {
+	idInt, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	req := model.RestoreProductRequest{Id: int64(idInt)}
+	product, err := h.svc.Restore(ctx, userId.(int64), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": product})
+}
+
+func (h *ProductHandler) SellerProducts(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var req model.SellerProductsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	products, nextCursor, err := h.svc.SellerProducts(ctx, userId.(int64), limit, &req)
 	if err != nil {
 		errs.RespondServiceError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
-		"data":       products,
-		"page":       page,
-		"limit":      limit,
-		"total":      total,
-		"totalPages": (total + int64(limit) - 1) / int64(limit),
+		"data":        products,
+		"limit":       limit,
+		"next_cursor": nextCursor,
 	})
 }
@@ -10,35 +10,96 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
 	"github.com/niklvrr/myMarketplace/internal/model"
 )
 
 type mockProductService struct {
 	CreateFn     func(ctx context.Context, sellerId int64, req *model.CreateProductRequest) (model.ProductResponse, error)
-	GetByIdFn    func(ctx context.Context, req *model.GetProductsRequest) (model.ProductResponse, error)
-	UpdateByIdFn func(ctx context.Context, sellerId int64, req *model.UpdateProductRequest) (model.ProductResponse, error)
-	DeleteByIdFn func(ctx context.Context, req *model.DeleteProductRequest) error
-	GetAllFn     func(ctx context.Context, page, limit int) ([]model.ProductResponse, int64, error)
-	SearchFn     func(ctx context.Context, page, limit int, req *model.SearchProductsRequest) ([]model.ProductResponse, int64, error)
+	GetByIdFn    func(ctx context.Context, userId int64, role string, req *model.GetProductsRequest) (model.ProductResponse, error)
+	GetBySlugFn  func(ctx context.Context, userId int64, role string, req *model.GetProductBySlugRequest) (model.ProductResponse, string, error)
+	UpdateByIdFn func(ctx context.Context, userId int64, role string, req *model.UpdateProductRequest) (model.ProductResponse, error)
+	DeleteByIdFn func(ctx context.Context, userId int64, role string, req *model.DeleteProductRequest) error
+	RestockFn    func(ctx context.Context, userId int64, role string, req *model.RestockProductRequest) (model.ProductResponse, error)
+	PriceHistFn  func(ctx context.Context, userId int64, role string, req *model.PriceHistoryRequest) ([]model.PricePointResponse, error)
+	ArchiveFn    func(ctx context.Context, userId int64, role string, req *model.ArchiveProductRequest) (model.ProductResponse, error)
+	RestoreFn    func(ctx context.Context, userId int64, role string, req *model.RestoreProductRequest) (model.ProductResponse, error)
+	GetAllFn     func(ctx context.Context, limit int, req *model.ListProductsRequest) ([]model.ProductResponse, string, error)
+	SearchFn     func(ctx context.Context, limit int, req *model.SearchProductsRequest) ([]model.ProductResponse, string, error)
+	ReindexFn    func(ctx context.Context) error
+	SubmitFn     func(ctx context.Context, userId int64, role string, req *model.SubmitProductRequest) (model.ProductResponse, error)
+	ModerateFn   func(ctx context.Context, req *model.ModerateProductRequest) (model.ProductResponse, error)
+	QueueFn      func(ctx context.Context, limit int, req *model.ModerationQueueRequest) ([]model.ProductResponse, string, error)
+	SellerFn     func(ctx context.Context, sellerId int64, limit int, req *model.SellerProductsRequest) ([]model.SellerProductResponse, string, error)
+	RelatedFn    func(ctx context.Context, limit int, req *model.RecommendationsRequest) ([]model.ProductResponse, error)
+	TogetherFn   func(ctx context.Context, limit int, req *model.RecommendationsRequest) ([]model.ProductResponse, error)
+	RecentFn     func(ctx context.Context, userId int64, limit int) ([]model.ProductResponse, error)
+	FeedFn       func(ctx context.Context, userId int64, limit int) ([]model.FeedItemResponse, error)
 }
 
 func (m *mockProductService) Create(ctx context.Context, sellerId int64, req *model.CreateProductRequest) (model.ProductResponse, error) {
 	return m.CreateFn(ctx, sellerId, req)
 }
-func (m *mockProductService) GetById(ctx context.Context, req *model.GetProductsRequest) (model.ProductResponse, error) {
-	return m.GetByIdFn(ctx, req)
+func (m *mockProductService) GetById(ctx context.Context, userId int64, role string, req *model.GetProductsRequest) (model.ProductResponse, error) {
+	return m.GetByIdFn(ctx, userId, role, req)
 }
-func (m *mockProductService) UpdateById(ctx context.Context, sellerId int64, req *model.UpdateProductRequest) (model.ProductResponse, error) {
-	return m.UpdateByIdFn(ctx, sellerId, req)
+func (m *mockProductService) GetBySlug(ctx context.Context, userId int64, role string, req *model.GetProductBySlugRequest) (model.ProductResponse, string, error) {
+	return m.GetBySlugFn(ctx, userId, role, req)
 }
-func (m *mockProductService) DeleteById(ctx context.Context, req *model.DeleteProductRequest) error {
-	return m.DeleteByIdFn(ctx, req)
+func (m *mockProductService) UpdateById(ctx context.Context, userId int64, role string, req *model.UpdateProductRequest) (model.ProductResponse, error) {
+	return m.UpdateByIdFn(ctx, userId, role, req)
 }
-func (m *mockProductService) GetAll(ctx context.Context, page, limit int) ([]model.ProductResponse, int64, error) {
-	return m.GetAllFn(ctx, page, limit)
+func (m *mockProductService) DeleteById(ctx context.Context, userId int64, role string, req *model.DeleteProductRequest) error {
+	return m.DeleteByIdFn(ctx, userId, role, req)
 }
-func (m *mockProductService) Search(ctx context.Context, page, limit int, req *model.SearchProductsRequest) ([]model.ProductResponse, int64, error) {
-	return m.SearchFn(ctx, page, limit, req)
+func (m *mockProductService) Restock(ctx context.Context, userId int64, role string, req *model.RestockProductRequest) (model.ProductResponse, error) {
+	return m.RestockFn(ctx, userId, role, req)
+}
+func (m *mockProductService) PriceHistory(ctx context.Context, userId int64, role string, req *model.PriceHistoryRequest) ([]model.PricePointResponse, error) {
+	return m.PriceHistFn(ctx, userId, role, req)
+}
+func (m *mockProductService) GetAll(ctx context.Context, limit int, req *model.ListProductsRequest) ([]model.ProductResponse, string, error) {
+	return m.GetAllFn(ctx, limit, req)
+}
+func (m *mockProductService) Search(ctx context.Context, limit int, req *model.SearchProductsRequest) ([]model.ProductResponse, string, error) {
+	return m.SearchFn(ctx, limit, req)
+}
+func (m *mockProductService) Reindex(ctx context.Context) error {
+	return m.ReindexFn(ctx)
+}
+func (m *mockProductService) Submit(ctx context.Context, userId int64, role string, req *model.SubmitProductRequest) (model.ProductResponse, error) {
+	return m.SubmitFn(ctx, userId, role, req)
+}
+func (m *mockProductService) Moderate(ctx context.Context, req *model.ModerateProductRequest) (model.ProductResponse, error) {
+	return m.ModerateFn(ctx, req)
+}
+func (m *mockProductService) ModerationQueue(ctx context.Context, limit int, req *model.ModerationQueueRequest) ([]model.ProductResponse, string, error) {
+	return m.QueueFn(ctx, limit, req)
+}
+
+func (m *mockProductService) SellerProducts(ctx context.Context, sellerId int64, limit int, req *model.SellerProductsRequest) ([]model.SellerProductResponse, string, error) {
+	return m.SellerFn(ctx, sellerId, limit, req)
+}
+
+func (m *mockProductService) Archive(ctx context.Context, userId int64, role string, req *model.ArchiveProductRequest) (model.ProductResponse, error) {
+	return m.ArchiveFn(ctx, userId, role, req)
+}
+func (m *mockProductService) Restore(ctx context.Context, userId int64, role string, req *model.RestoreProductRequest) (model.ProductResponse, error) {
+	return m.RestoreFn(ctx, userId, role, req)
+}
+
+func (m *mockProductService) Related(ctx context.Context, limit int, req *model.RecommendationsRequest) ([]model.ProductResponse, error) {
+	return m.RelatedFn(ctx, limit, req)
+}
+func (m *mockProductService) BoughtTogether(ctx context.Context, limit int, req *model.RecommendationsRequest) ([]model.ProductResponse, error) {
+	return m.TogetherFn(ctx, limit, req)
+}
+
+func (m *mockProductService) RecentlyViewed(ctx context.Context, userId int64, limit int) ([]model.ProductResponse, error) {
+	return m.RecentFn(ctx, userId, limit)
+}
+func (m *mockProductService) Feed(ctx context.Context, userId int64, limit int) ([]model.FeedItemResponse, error) {
+	return m.FeedFn(ctx, userId, limit)
 }
 
 func init() {
@@ -131,7 +192,7 @@ func TestProductHandler_Get(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			svc := &mockProductService{
-				GetByIdFn: func(ctx context.Context, req *model.GetProductsRequest) (model.ProductResponse, error) {
+				GetByIdFn: func(ctx context.Context, userId int64, role string, req *model.GetProductsRequest) (model.ProductResponse, error) {
 					return tt.serviceResp, tt.serviceErr
 				},
 			}
@@ -158,6 +219,44 @@ func TestProductHandler_Get(t *testing.T) {
 	}
 }
 
+func TestProductHandler_GetBySlug(t *testing.T) {
+	tests := []struct {
+		name             string
+		redirect         string
+		serviceErr       error
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{"current slug", "", nil, http.StatusOK, ""},
+		{"former slug", "novyy-chaynik", nil, http.StatusMovedPermanently, "/api/v1/products/by-slug/novyy-chaynik"},
+		{"unknown slug", "", errs.NotFoundError, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockProductService{
+				GetBySlugFn: func(ctx context.Context, userId int64, role string, req *model.GetProductBySlugRequest) (model.ProductResponse, string, error) {
+					if req.Slug != "chaynik" {
+						t.Fatalf("unexpected slug: %q", req.Slug)
+					}
+					return model.ProductResponse{Id: 5, Slug: "chaynik"}, tt.redirect, tt.serviceErr
+				},
+			}
+			h := NewProductsHandler(svc)
+			c, w := makeCtx("", http.MethodGet)
+			c.Request.URL.Path = "/api/v1/products/by-slug/chaynik"
+			c.Params = gin.Params{{Key: "slug", Value: "chaynik"}}
+			h.GetBySlug(c)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if location := w.Header().Get("Location"); location != tt.expectedLocation {
+				t.Fatalf("unexpected location: %q", location)
+			}
+		})
+	}
+}
+
 func TestProductHandler_Update(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -171,13 +270,14 @@ func TestProductHandler_Update(t *testing.T) {
 		{"success", "10", `{"id":10,"name":"Up","price":150,"category_id":3,"stock":5}`, true, model.ProductResponse{}, nil, http.StatusOK},
 		{"bind error", "10", `{"name":`, true, model.ProductResponse{}, nil, http.StatusBadRequest},
 		{"unauthorized", "10", `{"id":10,"name":"Up","price":150,"category_id":3,"stock":5}`, false, model.ProductResponse{}, nil, http.StatusUnauthorized},
+		{"not owner", "10", `{"id":10,"name":"Up","price":150,"category_id":3,"stock":5}`, true, model.ProductResponse{}, errs.NotOwnerError, http.StatusForbidden},
 		{"service error", "10", `{"id":10,"name":"Up","price":150,"category_id":3,"stock":5}`, true, model.ProductResponse{}, errors.New("svc"), http.StatusInternalServerError},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			svc := &mockProductService{
-				UpdateByIdFn: func(ctx context.Context, sellerId int64, req *model.UpdateProductRequest) (model.ProductResponse, error) {
+				UpdateByIdFn: func(ctx context.Context, userId int64, role string, req *model.UpdateProductRequest) (model.ProductResponse, error) {
 					return tt.serviceResp, tt.serviceErr
 				},
 			}
@@ -211,24 +311,31 @@ func TestProductHandler_Delete(t *testing.T) {
 	tests := []struct {
 		name           string
 		paramValue     string
+		setUserID      bool
 		serviceErr     error
 		expectedStatus int
 	}{
-		{"success", "8", nil, http.StatusOK},
-		{"empty id", "", nil, http.StatusOK},
-		{"service error", "11", errors.New("svc"), http.StatusInternalServerError},
+		{"success", "8", true, nil, http.StatusOK},
+		{"empty id", "", true, nil, http.StatusOK},
+		{"unauthorized", "8", false, nil, http.StatusUnauthorized},
+		{"not owner", "8", true, errs.NotOwnerError, http.StatusForbidden},
+		{"service error", "11", true, errors.New("svc"), http.StatusInternalServerError},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			svc := &mockProductService{
-				DeleteByIdFn: func(ctx context.Context, req *model.DeleteProductRequest) error {
+				DeleteByIdFn: func(ctx context.Context, userId int64, role string, req *model.DeleteProductRequest) error {
 					return tt.serviceErr
 				},
 			}
 			h := NewProductsHandler(svc)
 			c, w := makeCtx("", http.MethodDelete)
 			c.Params = gin.Params{{Key: "id", Value: tt.paramValue}}
+			if tt.setUserID {
+				c.Set("user_id", int64(55))
+				c.Set("role", "seller")
+			}
 			h.Delete(c)
 			if tt.expectedStatus == http.StatusInternalServerError {
 				if w.Code < 500 || w.Code >= 600 {
@@ -254,22 +361,26 @@ func TestProductHandler_GetAll(t *testing.T) {
 		name           string
 		query          string
 		serviceResp    []model.ProductResponse
-		serviceTotal   int64
+		serviceCursor  string
 		serviceErr     error
 		expectedStatus int
-		expectedPage   int
 		expectedLimit  int
+		expectedSort   string
 	}{
-		{"defaults", "", []model.ProductResponse{{}}, 5, nil, http.StatusOK, 1, 20},
-		{"with params", "page=2&limit=10", []model.ProductResponse{{}, {}}, 50, nil, http.StatusOK, 2, 10},
-		{"service error", "", nil, 0, errors.New("svc"), http.StatusInternalServerError, 1, 20},
+		{"defaults", "", []model.ProductResponse{{}}, "", nil, http.StatusOK, 20, ""},
+		{"with params", "limit=10&sort=price_desc&cursor=abc", []model.ProductResponse{{}, {}}, "next", nil, http.StatusOK, 10, "price_desc"},
+		{"invalid sort", "sort=name", nil, "", nil, http.StatusBadRequest, 20, ""},
+		{"service error", "", nil, "", errors.New("svc"), http.StatusInternalServerError, 20, ""},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			svc := &mockProductService{
-				GetAllFn: func(ctx context.Context, page, limit int) ([]model.ProductResponse, int64, error) {
-					return tt.serviceResp, tt.serviceTotal, tt.serviceErr
+				GetAllFn: func(ctx context.Context, limit int, req *model.ListProductsRequest) ([]model.ProductResponse, string, error) {
+					if req.Sort != tt.expectedSort {
+						t.Fatalf("sort got %s want %s", req.Sort, tt.expectedSort)
+					}
+					return tt.serviceResp, tt.serviceCursor, tt.serviceErr
 				},
 			}
 			h := NewProductsHandler(svc)
@@ -289,10 +400,9 @@ func TestProductHandler_GetAll(t *testing.T) {
 			}
 			if tt.expectedStatus == http.StatusOK {
 				out := parseJSONBody(t, w)
-				page := int(out["page"].(float64))
 				limit := int(out["limit"].(float64))
-				if page != tt.expectedPage || limit != tt.expectedLimit {
-					t.Fatalf("page/limit got %d/%d want %d/%d", page, limit, tt.expectedPage, tt.expectedLimit)
+				if limit != tt.expectedLimit || out["next_cursor"] != tt.serviceCursor {
+					t.Fatalf("limit/cursor got %d/%v want %d/%s", limit, out["next_cursor"], tt.expectedLimit, tt.serviceCursor)
 				}
 				if _, ok := out["data"]; !ok {
 					t.Fatalf("expected data")
@@ -307,22 +417,22 @@ func TestProductHandler_Search(t *testing.T) {
 		name           string
 		query          string
 		serviceResp    []model.ProductResponse
-		serviceTotal   int64
+		serviceCursor  string
 		serviceErr     error
 		expectedStatus int
-		expectedPage   int
 		expectedLimit  int
 	}{
-		{"success", "q=phone&page=2&limit=5", []model.ProductResponse{{}}, 12, nil, http.StatusOK, 2, 5},
-		{"defaults", "", []model.ProductResponse{{}}, 3, nil, http.StatusOK, 1, 20},
-		{"service error", "q=phone", nil, 0, errors.New("svc"), http.StatusInternalServerError, 1, 20},
+		{"success", "text=phone&limit=5&sort=rating", []model.ProductResponse{{}}, "next", nil, http.StatusOK, 5},
+		{"defaults", "", []model.ProductResponse{{}}, "", nil, http.StatusOK, 20},
+		{"invalid sort", "sort=cheap", nil, "", nil, http.StatusBadRequest, 20},
+		{"service error", "text=phone", nil, "", errors.New("svc"), http.StatusInternalServerError, 20},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			svc := &mockProductService{
-				SearchFn: func(ctx context.Context, page, limit int, req *model.SearchProductsRequest) ([]model.ProductResponse, int64, error) {
-					return tt.serviceResp, tt.serviceTotal, tt.serviceErr
+				SearchFn: func(ctx context.Context, limit int, req *model.SearchProductsRequest) ([]model.ProductResponse, string, error) {
+					return tt.serviceResp, tt.serviceCursor, tt.serviceErr
 				},
 			}
 			h := NewProductsHandler(svc)
@@ -342,10 +452,9 @@ func TestProductHandler_Search(t *testing.T) {
 			}
 			if tt.expectedStatus == http.StatusOK {
 				out := parseJSONBody(t, w)
-				page := int(out["page"].(float64))
 				limit := int(out["limit"].(float64))
-				if page != tt.expectedPage || limit != tt.expectedLimit {
-					t.Fatalf("page/limit got %d/%d want %d/%d", page, limit, tt.expectedPage, tt.expectedLimit)
+				if limit != tt.expectedLimit || out["next_cursor"] != tt.serviceCursor {
+					t.Fatalf("limit/cursor got %d/%v want %d/%s", limit, out["next_cursor"], tt.expectedLimit, tt.serviceCursor)
 				}
 				if _, ok := out["data"]; !ok {
 					t.Fatalf("expected data")
@@ -354,3 +463,379 @@ func TestProductHandler_Search(t *testing.T) {
 		})
 	}
 }
+
+func TestProductHandler_Reindex(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", nil, http.StatusOK},
+		{"service error", errors.New("svc"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockProductService{
+				ReindexFn: func(ctx context.Context) error {
+					return tt.serviceErr
+				},
+			}
+			h := NewProductsHandler(svc)
+			c, w := makeCtx("", http.MethodPost)
+			h.Reindex(c)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestProductHandler_Submit(t *testing.T) {
+	tests := []struct {
+		name           string
+		paramValue     string
+		setUserID      bool
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "3", true, nil, http.StatusOK},
+		{"bad id", "x", true, nil, http.StatusBadRequest},
+		{"unauthorized", "3", false, nil, http.StatusUnauthorized},
+		{"foreign product", "3", true, errs.NotOwnerError, http.StatusForbidden},
+		{"wrong status", "3", true, errs.ConflictError, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockProductService{
+				SubmitFn: func(ctx context.Context, userId int64, role string, req *model.SubmitProductRequest) (model.ProductResponse, error) {
+					return model.ProductResponse{Id: req.Id}, tt.serviceErr
+				},
+			}
+			h := NewProductsHandler(svc)
+			c, w := makeCtx("", http.MethodPost)
+			c.Params = gin.Params{{Key: "id", Value: tt.paramValue}}
+			if tt.setUserID {
+				c.Set("user_id", int64(5))
+			}
+			h.Submit(c)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestProductHandler_Moderate(t *testing.T) {
+	tests := []struct {
+		name           string
+		paramValue     string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"approve", "3", `{"action":"approve"}`, nil, http.StatusOK},
+		{"reject", "3", `{"action":"reject","reason":"no photos"}`, nil, http.StatusOK},
+		{"unknown action", "3", `{"action":"delete"}`, nil, http.StatusBadRequest},
+		{"bad id", "x", `{"action":"approve"}`, nil, http.StatusBadRequest},
+		{"missing reason", "3", `{"action":"reject"}`, errs.ValidationError, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockProductService{
+				ModerateFn: func(ctx context.Context, req *model.ModerateProductRequest) (model.ProductResponse, error) {
+					if req.Id != 3 {
+						t.Fatalf("unexpected id %d", req.Id)
+					}
+					return model.ProductResponse{Id: req.Id}, tt.serviceErr
+				},
+			}
+			h := NewProductsHandler(svc)
+			c, w := makeCtx(tt.body, http.MethodPut)
+			c.Params = gin.Params{{Key: "id", Value: tt.paramValue}}
+			h.Moderate(c)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestProductHandler_ModerationQueue(t *testing.T) {
+	svc := &mockProductService{
+		QueueFn: func(ctx context.Context, limit int, req *model.ModerationQueueRequest) ([]model.ProductResponse, string, error) {
+			return []model.ProductResponse{{Id: 1}}, "next", nil
+		},
+	}
+	h := NewProductsHandler(svc)
+	c, w := makeCtx("", http.MethodGet)
+	c.Request.URL.RawQuery = "limit=5"
+	h.ModerationQueue(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status got %d body: %s", w.Code, w.Body.String())
+	}
+	out := parseJSONBody(t, w)
+	if out["next_cursor"] != "next" || int(out["limit"].(float64)) != 5 {
+		t.Fatalf("unexpected body: %v", out)
+	}
+}
+
+func TestProductHandler_Restock(t *testing.T) {
+	tests := []struct {
+		name           string
+		paramValue     string
+		body           string
+		setUserID      bool
+		serviceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"success", "4", `{"quantity":10}`, true, nil, http.StatusOK, ""},
+		{"bad id", "x", `{"quantity":10}`, true, nil, http.StatusBadRequest, "invalid_request"},
+		{"zero quantity", "4", `{"quantity":0}`, true, nil, http.StatusBadRequest, "invalid_request"},
+		{"unauthorized", "4", `{"quantity":10}`, false, nil, http.StatusUnauthorized, "unauthorized"},
+		{"not owner", "4", `{"quantity":10}`, true, errs.NotOwnerError, http.StatusForbidden, "not_owner"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockProductService{
+				RestockFn: func(ctx context.Context, userId int64, role string, req *model.RestockProductRequest) (model.ProductResponse, error) {
+					if userId != 5 || role != "seller" || req.Id != 4 {
+						t.Fatalf("unexpected args: %d %s %+v", userId, role, req)
+					}
+					return model.ProductResponse{Id: req.Id, Stock: req.Quantity}, tt.serviceErr
+				},
+			}
+			h := NewProductsHandler(svc)
+			c, w := makeCtx(tt.body, http.MethodPost)
+			c.Params = gin.Params{{Key: "id", Value: tt.paramValue}}
+			if tt.setUserID {
+				c.Set("user_id", int64(5))
+				c.Set("role", "seller")
+			}
+			h.Restock(c)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedCode != "" {
+				out := parseJSONBody(t, w)
+				errBody, _ := out["error"].(map[string]interface{})
+				if errBody["code"] != tt.expectedCode {
+					t.Fatalf("code got %v want %s", errBody["code"], tt.expectedCode)
+				}
+			}
+		})
+	}
+}
+
+func TestProductHandler_PriceHistory(t *testing.T) {
+	tests := []struct {
+		name           string
+		paramValue     string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "4", nil, http.StatusOK},
+		{"bad id", "x", nil, http.StatusBadRequest},
+		{"hidden product", "4", errs.NotFoundError, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockProductService{
+				PriceHistFn: func(ctx context.Context, userId int64, role string, req *model.PriceHistoryRequest) ([]model.PricePointResponse, error) {
+					if userId != 5 || req.Id != 4 {
+						t.Fatalf("unexpected args: %d %+v", userId, req)
+					}
+					return []model.PricePointResponse{{Price: 100}, {Price: 90}}, tt.serviceErr
+				},
+			}
+			h := NewProductsHandler(svc)
+			c, w := makeCtx("", http.MethodGet)
+			c.Params = gin.Params{{Key: "id", Value: tt.paramValue}}
+			c.Set("user_id", int64(5))
+			h.PriceHistory(c)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus == http.StatusOK {
+				out := parseJSONBody(t, w)
+				if data, _ := out["data"].([]interface{}); len(data) != 2 {
+					t.Fatalf("unexpected data: %v", out["data"])
+				}
+			}
+		})
+	}
+}
+
+func TestProductHandler_SellerProducts(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		setUserID      bool
+		expectedStatus int
+	}{
+		{"success", "?status=draft&stock=out_of_stock&category_id=2&sort=price_desc&limit=5", true, http.StatusOK},
+		{"bad status", "?status=deleted", true, http.StatusBadRequest},
+		{"bad stock", "?stock=low", true, http.StatusBadRequest},
+		{"unauthorized", "", false, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockProductService{
+				SellerFn: func(ctx context.Context, sellerId int64, limit int, req *model.SellerProductsRequest) ([]model.SellerProductResponse, string, error) {
+					if sellerId != 5 || limit != 5 || req.Status != "draft" || req.Stock != "out_of_stock" || *req.CategoryId != 2 {
+						t.Fatalf("unexpected args: %d %d %+v", sellerId, limit, req)
+					}
+					return []model.SellerProductResponse{{Views: 1}}, "next", nil
+				},
+			}
+			h := NewProductsHandler(svc)
+			c, w := makeCtx("", http.MethodGet)
+			c.Request = httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			if tt.setUserID {
+				c.Set("user_id", int64(5))
+			}
+			h.SellerProducts(c)
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if tt.expectedStatus == http.StatusOK {
+				out := parseJSONBody(t, w)
+				if out["next_cursor"] != "next" {
+					t.Fatalf("expected next_cursor, got %v", out)
+				}
+			}
+		})
+	}
+}
+
+func TestProductHandler_ArchiveRestore(t *testing.T) {
+	tests := []struct {
+		name           string
+		paramValue     string
+		setUserID      bool
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "3", true, nil, http.StatusOK},
+		{"bad id", "x", true, nil, http.StatusBadRequest},
+		{"unauthorized", "3", false, nil, http.StatusUnauthorized},
+		{"not owner", "3", true, errs.NotOwnerError, http.StatusForbidden},
+		{"wrong state", "3", true, errs.ConflictError, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockProductService{
+				ArchiveFn: func(ctx context.Context, userId int64, role string, req *model.ArchiveProductRequest) (model.ProductResponse, error) {
+					return model.ProductResponse{Id: req.Id}, tt.serviceErr
+				},
+				RestoreFn: func(ctx context.Context, userId int64, role string, req *model.RestoreProductRequest) (model.ProductResponse, error) {
+					return model.ProductResponse{Id: req.Id}, tt.serviceErr
+				},
+			}
+			h := NewProductsHandler(svc)
+			for _, handle := range []gin.HandlerFunc{h.Archive, h.Restore} {
+				c, w := makeCtx("", http.MethodPost)
+				c.Params = gin.Params{{Key: "id", Value: tt.paramValue}}
+				if tt.setUserID {
+					c.Set("user_id", int64(5))
+					c.Set("role", "seller")
+				}
+				handle(c)
+				if w.Code != tt.expectedStatus {
+					t.Fatalf("status got %d want %d body: %s", w.Code, tt.expectedStatus, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestProductHandler_Recommendations(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		query          string
+		expectedLimit  int
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"default limit", "5", "", 10, nil, http.StatusOK},
+		{"custom limit", "5", "?limit=4", 4, nil, http.StatusOK},
+		{"limit above list size", "5", "?limit=50", 10, nil, http.StatusOK},
+		{"bad id", "x", "", 10, nil, http.StatusBadRequest},
+		{"unlisted product", "5", "", 10, errs.NotFoundError, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			list := func(ctx context.Context, limit int, req *model.RecommendationsRequest) ([]model.ProductResponse, error) {
+				calls++
+				if req.Id != 5 || limit != tt.expectedLimit {
+					t.Fatalf("unexpected args: %d %d", req.Id, limit)
+				}
+				return []model.ProductResponse{{Id: 7}}, tt.serviceErr
+			}
+			h := NewProductsHandler(&mockProductService{RelatedFn: list, TogetherFn: list})
+
+			for _, handle := range []gin.HandlerFunc{h.Related, h.BoughtTogether} {
+				c, w := makeCtx("", http.MethodGet)
+				c.Request = httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+				c.Params = gin.Params{{Key: "id", Value: tt.id}}
+
+				handle(c)
+
+				if w.Code != tt.expectedStatus {
+					t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+				}
+			}
+			if tt.expectedStatus != http.StatusBadRequest && calls != 2 {
+				t.Fatalf("expected both lists to be requested, got %d calls", calls)
+			}
+		})
+	}
+}
+
+func TestProductHandler_Feed(t *testing.T) {
+	svc := &mockProductService{
+		FeedFn: func(ctx context.Context, userId int64, limit int) ([]model.FeedItemResponse, error) {
+			if userId != 7 || limit != 20 {
+				t.Fatalf("unexpected args: %d %d", userId, limit)
+			}
+			return []model.FeedItemResponse{{ProductResponse: model.ProductResponse{Id: 3}, Source: model.FeedSourceBestseller}}, nil
+		},
+		RecentFn: func(ctx context.Context, userId int64, limit int) ([]model.ProductResponse, error) {
+			if userId != 7 || limit != 5 {
+				t.Fatalf("unexpected args: %d %d", userId, limit)
+			}
+			return nil, errors.New("redis is down")
+		},
+	}
+	h := NewProductsHandler(svc)
+
+	c, w := makeCtx("", http.MethodGet)
+	c.Request = httptest.NewRequest(http.MethodGet, "/?limit=0", nil)
+	c.Set("user_id", int64(7))
+	h.Feed(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	items := parseJSONBody(t, w)["data"].([]interface{})
+	if item := items[0].(map[string]interface{}); item["id"] != float64(3) || item["source"] != model.FeedSourceBestseller {
+		t.Fatalf("feed item must contain product fields and source: %v", item)
+	}
+
+	c, w = makeCtx("", http.MethodGet)
+	c.Request = httptest.NewRequest(http.MethodGet, "/?limit=5", nil)
+	c.Set("user_id", int64(7))
+	h.RecentlyViewed(c)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,208 @@
+package questionHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IQuestionService interface {
+	Ask(ctx context.Context, userId int64, req *model.AskQuestionRequest) (model.QuestionResponse, error)
+	List(ctx context.Context, limit int, req *model.ListQuestionsRequest) ([]model.QuestionResponse, string, error)
+	Inbox(ctx context.Context, sellerId int64, limit int, req *model.QuestionInboxRequest) ([]model.QuestionResponse, string, error)
+	Answer(ctx context.Context, userId int64, role string, req *model.AnswerQuestionRequest) (model.QuestionResponse, error)
+	Delete(ctx context.Context, userId int64, role string, req *model.QuestionActionRequest) error
+	Upvote(ctx context.Context, userId int64, req *model.QuestionActionRequest) (model.QuestionResponse, error)
+	RemoveUpvote(ctx context.Context, userId int64, req *model.QuestionActionRequest) (model.QuestionResponse, error)
+}
+
+type QuestionHandler struct {
+	svc IQuestionService
+}
+
+func NewQuestionHandler(svc IQuestionService) *QuestionHandler {
+	return &QuestionHandler{svc: svc}
+}
+
+func (h *QuestionHandler) List(ctx *gin.Context) {
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var req model.ListQuestionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.ProductId = int64(productId)
+
+	questions, nextCursor, err := h.svc.List(ctx, limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        questions,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (h *QuestionHandler) Ask(ctx *gin.Context) {
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	var req model.AskQuestionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.ProductId = int64(productId)
+
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	question, err := h.svc.Ask(ctx, userId.(int64), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": question})
+}
+
+// Inbox returns the unanswered questions about the products of the current seller.
+func (h *QuestionHandler) Inbox(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var req model.QuestionInboxRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	questions, nextCursor, err := h.svc.Inbox(ctx, userId.(int64), limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        questions,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (h *QuestionHandler) Answer(ctx *gin.Context) {
+	productId, questionId, ok := questionIds(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.AnswerQuestionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.Id, req.ProductId = questionId, productId
+
+	question, err := h.svc.Answer(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": question})
+}
+
+func (h *QuestionHandler) Delete(ctx *gin.Context) {
+	productId, questionId, ok := questionIds(ctx)
+	if !ok {
+		return
+	}
+	req := model.QuestionActionRequest{Id: questionId, ProductId: productId}
+
+	if err := h.svc.Delete(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": "question deleted"})
+}
+
+func (h *QuestionHandler) Upvote(ctx *gin.Context) {
+	productId, questionId, ok := questionIds(ctx)
+	if !ok {
+		return
+	}
+	req := model.QuestionActionRequest{Id: questionId, ProductId: productId}
+
+	question, err := h.svc.Upvote(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": question})
+}
+
+func (h *QuestionHandler) RemoveUpvote(ctx *gin.Context) {
+	productId, questionId, ok := questionIds(ctx)
+	if !ok {
+		return
+	}
+	req := model.QuestionActionRequest{Id: questionId, ProductId: productId}
+
+	question, err := h.svc.RemoveUpvote(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": question})
+}
+
+// questionIds parses the product and question ids from the url and responds with 400 if either is invalid.
+func questionIds(ctx *gin.Context) (int64, int64, bool) {
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return 0, 0, false
+	}
+
+	questionId, err := strconv.Atoi(ctx.Param("questionId"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return 0, 0, false
+	}
+
+	return int64(productId), int64(questionId), true
+}
@@ -0,0 +1,209 @@
+package questionHandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockQuestionService struct {
+	AskFn          func(ctx context.Context, userId int64, req *model.AskQuestionRequest) (model.QuestionResponse, error)
+	ListFn         func(ctx context.Context, limit int, req *model.ListQuestionsRequest) ([]model.QuestionResponse, string, error)
+	InboxFn        func(ctx context.Context, sellerId int64, limit int, req *model.QuestionInboxRequest) ([]model.QuestionResponse, string, error)
+	AnswerFn       func(ctx context.Context, userId int64, role string, req *model.AnswerQuestionRequest) (model.QuestionResponse, error)
+	DeleteFn       func(ctx context.Context, userId int64, role string, req *model.QuestionActionRequest) error
+	UpvoteFn       func(ctx context.Context, userId int64, req *model.QuestionActionRequest) (model.QuestionResponse, error)
+	RemoveUpvoteFn func(ctx context.Context, userId int64, req *model.QuestionActionRequest) (model.QuestionResponse, error)
+}
+
+func (m *mockQuestionService) Ask(ctx context.Context, userId int64, req *model.AskQuestionRequest) (model.QuestionResponse, error) {
+	return m.AskFn(ctx, userId, req)
+}
+func (m *mockQuestionService) List(ctx context.Context, limit int, req *model.ListQuestionsRequest) ([]model.QuestionResponse, string, error) {
+	return m.ListFn(ctx, limit, req)
+}
+func (m *mockQuestionService) Inbox(ctx context.Context, sellerId int64, limit int, req *model.QuestionInboxRequest) ([]model.QuestionResponse, string, error) {
+	return m.InboxFn(ctx, sellerId, limit, req)
+}
+func (m *mockQuestionService) Answer(ctx context.Context, userId int64, role string, req *model.AnswerQuestionRequest) (model.QuestionResponse, error) {
+	return m.AnswerFn(ctx, userId, role, req)
+}
+func (m *mockQuestionService) Delete(ctx context.Context, userId int64, role string, req *model.QuestionActionRequest) error {
+	return m.DeleteFn(ctx, userId, role, req)
+}
+func (m *mockQuestionService) Upvote(ctx context.Context, userId int64, req *model.QuestionActionRequest) (model.QuestionResponse, error) {
+	return m.UpvoteFn(ctx, userId, req)
+}
+func (m *mockQuestionService) RemoveUpvote(ctx context.Context, userId int64, req *model.QuestionActionRequest) (model.QuestionResponse, error) {
+	return m.RemoveUpvoteFn(ctx, userId, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(method, target, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("user_id", int64(9))
+	c.Set("role", "user")
+	return c, w
+}
+
+func TestQuestionHandler_Ask(t *testing.T) {
+	tests := []struct {
+		name           string
+		productId      string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "5", `{"text":"is it waterproof?"}`, nil, http.StatusCreated},
+		{"bad product id", "x", `{"text":"is it waterproof?"}`, nil, http.StatusBadRequest},
+		{"empty text", "5", `{}`, nil, http.StatusBadRequest},
+		{"rejected by moderation", "5", `{"text":"call me"}`, errs.ValidationError, http.StatusBadRequest},
+		{"product not listed", "5", `{"text":"is it waterproof?"}`, errs.NotFoundError, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockQuestionService{
+				AskFn: func(ctx context.Context, userId int64, req *model.AskQuestionRequest) (model.QuestionResponse, error) {
+					if userId != 9 || req.ProductId != 5 {
+						t.Fatalf("unexpected args: %d %d", userId, req.ProductId)
+					}
+					if tt.serviceErr != nil {
+						return model.QuestionResponse{}, tt.serviceErr
+					}
+					return model.QuestionResponse{Id: 1, ProductId: 5, Text: req.Text}, nil
+				},
+			}
+			h := NewQuestionHandler(svc)
+			c, w := makeCtx(http.MethodPost, "/", tt.body, gin.Params{{Key: "id", Value: tt.productId}})
+
+			h.Ask(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestQuestionHandler_List(t *testing.T) {
+	svc := &mockQuestionService{
+		ListFn: func(ctx context.Context, limit int, req *model.ListQuestionsRequest) ([]model.QuestionResponse, string, error) {
+			if limit != 20 || req.ProductId != 5 || req.Sort != model.QuestionSortTop {
+				t.Fatalf("unexpected args: %d %+v", limit, req)
+			}
+			return []model.QuestionResponse{{Id: 1}}, "next", nil
+		},
+	}
+	h := NewQuestionHandler(svc)
+	c, w := makeCtx(http.MethodGet, "/?sort=top&limit=500", "", gin.Params{{Key: "id", Value: "5"}})
+
+	h.List(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	c, w = makeCtx(http.MethodGet, "/?sort=oldest", "", gin.Params{{Key: "id", Value: "5"}})
+	h.List(c)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for inbox-only sort, got %d", w.Code)
+	}
+}
+
+func TestQuestionHandler_Inbox(t *testing.T) {
+	svc := &mockQuestionService{
+		InboxFn: func(ctx context.Context, sellerId int64, limit int, req *model.QuestionInboxRequest) ([]model.QuestionResponse, string, error) {
+			if sellerId != 9 || limit != 10 || req.Cursor != "abc" {
+				t.Fatalf("unexpected args: %d %d %+v", sellerId, limit, req)
+			}
+			return []model.QuestionResponse{{Id: 1}}, "", nil
+		},
+	}
+	h := NewQuestionHandler(svc)
+	c, w := makeCtx(http.MethodGet, "/?limit=10&cursor=abc", "", nil)
+
+	h.Inbox(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestQuestionHandler_Answer(t *testing.T) {
+	tests := []struct {
+		name           string
+		questionId     string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "3", `{"text":"yes"}`, nil, http.StatusOK},
+		{"bad question id", "x", `{"text":"yes"}`, nil, http.StatusBadRequest},
+		{"empty answer", "3", `{}`, nil, http.StatusBadRequest},
+		{"not product owner", "3", `{"text":"yes"}`, errs.NotOwnerError, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockQuestionService{
+				AnswerFn: func(ctx context.Context, userId int64, role string, req *model.AnswerQuestionRequest) (model.QuestionResponse, error) {
+					if req.Id != 3 || req.ProductId != 5 || req.Text != "yes" {
+						t.Fatalf("unexpected request: %+v", req)
+					}
+					return model.QuestionResponse{Id: 3, Answer: req.Text}, tt.serviceErr
+				},
+			}
+			h := NewQuestionHandler(svc)
+			c, w := makeCtx(http.MethodPut, "/", tt.body,
+				gin.Params{{Key: "id", Value: "5"}, {Key: "questionId", Value: tt.questionId}})
+
+			h.Answer(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestQuestionHandler_Upvote(t *testing.T) {
+	upvoted, removed := false, false
+	svc := &mockQuestionService{
+		UpvoteFn: func(ctx context.Context, userId int64, req *model.QuestionActionRequest) (model.QuestionResponse, error) {
+			upvoted = true
+			return model.QuestionResponse{Id: req.Id, Upvotes: 1}, nil
+		},
+		RemoveUpvoteFn: func(ctx context.Context, userId int64, req *model.QuestionActionRequest) (model.QuestionResponse, error) {
+			removed = true
+			return model.QuestionResponse{Id: req.Id}, nil
+		},
+	}
+	h := NewQuestionHandler(svc)
+	params := gin.Params{{Key: "id", Value: "5"}, {Key: "questionId", Value: "3"}}
+
+	c, w := makeCtx(http.MethodPost, "/", "", params)
+	h.Upvote(c)
+	if w.Code != http.StatusOK || !upvoted {
+		t.Fatalf("expected upvote, got %d", w.Code)
+	}
+
+	c, w = makeCtx(http.MethodDelete, "/", "", params)
+	h.RemoveUpvote(c)
+	if w.Code != http.StatusOK || !removed {
+		t.Fatalf("expected upvote removal, got %d", w.Code)
+	}
+}
@@ -0,0 +1,198 @@
+package reviewHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IReviewService interface {
+	Create(ctx context.Context, userId int64, req *model.CreateReviewRequest) (model.ReviewResponse, error)
+	List(ctx context.Context, limit int, req *model.ListReviewsRequest) ([]model.ReviewResponse, string, error)
+	Update(ctx context.Context, userId int64, req *model.UpdateReviewRequest) (model.ReviewResponse, error)
+	Delete(ctx context.Context, userId int64, role string, req *model.DeleteReviewRequest) error
+	Reply(ctx context.Context, userId int64, role string, req *model.ReplyReviewRequest) (model.ReviewResponse, error)
+	Vote(ctx context.Context, userId int64, req *model.VoteReviewRequest) (model.ReviewResponse, error)
+	Unvote(ctx context.Context, userId int64, req *model.VoteReviewRequest) (model.ReviewResponse, error)
+}
+
+type ReviewHandler struct {
+	svc IReviewService
+}
+
+func NewReviewHandler(svc IReviewService) *ReviewHandler {
+	return &ReviewHandler{svc: svc}
+}
+
+func (h *ReviewHandler) List(ctx *gin.Context) {
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var req model.ListReviewsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.ProductId = int64(productId)
+
+	reviews, nextCursor, err := h.svc.List(ctx, limit, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        reviews,
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+func (h *ReviewHandler) Create(ctx *gin.Context) {
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	var req model.CreateReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.ProductId = int64(productId)
+
+	userId, exist := ctx.Get("user_id")
+	if !exist {
+		errs.RespondError(ctx, http.StatusUnauthorized, "unauthorized", "user is not authorized")
+		return
+	}
+
+	review, err := h.svc.Create(ctx, userId.(int64), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": review})
+}
+
+func (h *ReviewHandler) Update(ctx *gin.Context) {
+	productId, reviewId, ok := reviewIds(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.Id, req.ProductId = reviewId, productId
+
+	review, err := h.svc.Update(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": review})
+}
+
+func (h *ReviewHandler) Delete(ctx *gin.Context) {
+	productId, reviewId, ok := reviewIds(ctx)
+	if !ok {
+		return
+	}
+	req := model.DeleteReviewRequest{Id: reviewId, ProductId: productId}
+
+	if err := h.svc.Delete(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": "review deleted"})
+}
+
+func (h *ReviewHandler) Reply(ctx *gin.Context) {
+	productId, reviewId, ok := reviewIds(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.ReplyReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.Id, req.ProductId = reviewId, productId
+
+	review, err := h.svc.Reply(ctx, ctx.GetInt64("user_id"), ctx.GetString("role"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": review})
+}
+
+func (h *ReviewHandler) Vote(ctx *gin.Context) {
+	productId, reviewId, ok := reviewIds(ctx)
+	if !ok {
+		return
+	}
+	req := model.VoteReviewRequest{Id: reviewId, ProductId: productId}
+
+	review, err := h.svc.Vote(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": review})
+}
+
+func (h *ReviewHandler) Unvote(ctx *gin.Context) {
+	productId, reviewId, ok := reviewIds(ctx)
+	if !ok {
+		return
+	}
+	req := model.VoteReviewRequest{Id: reviewId, ProductId: productId}
+
+	review, err := h.svc.Unvote(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": review})
+}
+
+// reviewIds parses the product and review ids from the url and responds with 400 if either is invalid.
+func reviewIds(ctx *gin.Context) (int64, int64, bool) {
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return 0, 0, false
+	}
+
+	reviewId, err := strconv.Atoi(ctx.Param("reviewId"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return 0, 0, false
+	}
+
+	return int64(productId), int64(reviewId), true
+}
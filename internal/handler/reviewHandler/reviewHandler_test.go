@@ -0,0 +1,215 @@
+package reviewHandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockReviewService struct {
+	CreateFn func(ctx context.Context, userId int64, req *model.CreateReviewRequest) (model.ReviewResponse, error)
+	ListFn   func(ctx context.Context, limit int, req *model.ListReviewsRequest) ([]model.ReviewResponse, string, error)
+	UpdateFn func(ctx context.Context, userId int64, req *model.UpdateReviewRequest) (model.ReviewResponse, error)
+	DeleteFn func(ctx context.Context, userId int64, role string, req *model.DeleteReviewRequest) error
+	ReplyFn  func(ctx context.Context, userId int64, role string, req *model.ReplyReviewRequest) (model.ReviewResponse, error)
+	VoteFn   func(ctx context.Context, userId int64, req *model.VoteReviewRequest) (model.ReviewResponse, error)
+	UnvoteFn func(ctx context.Context, userId int64, req *model.VoteReviewRequest) (model.ReviewResponse, error)
+}
+
+func (m *mockReviewService) Create(ctx context.Context, userId int64, req *model.CreateReviewRequest) (model.ReviewResponse, error) {
+	return m.CreateFn(ctx, userId, req)
+}
+func (m *mockReviewService) List(ctx context.Context, limit int, req *model.ListReviewsRequest) ([]model.ReviewResponse, string, error) {
+	return m.ListFn(ctx, limit, req)
+}
+func (m *mockReviewService) Update(ctx context.Context, userId int64, req *model.UpdateReviewRequest) (model.ReviewResponse, error) {
+	return m.UpdateFn(ctx, userId, req)
+}
+func (m *mockReviewService) Delete(ctx context.Context, userId int64, role string, req *model.DeleteReviewRequest) error {
+	return m.DeleteFn(ctx, userId, role, req)
+}
+func (m *mockReviewService) Reply(ctx context.Context, userId int64, role string, req *model.ReplyReviewRequest) (model.ReviewResponse, error) {
+	return m.ReplyFn(ctx, userId, role, req)
+}
+func (m *mockReviewService) Vote(ctx context.Context, userId int64, req *model.VoteReviewRequest) (model.ReviewResponse, error) {
+	return m.VoteFn(ctx, userId, req)
+}
+func (m *mockReviewService) Unvote(ctx context.Context, userId int64, req *model.VoteReviewRequest) (model.ReviewResponse, error) {
+	return m.UnvoteFn(ctx, userId, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(method, target, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("user_id", int64(9))
+	c.Set("role", "user")
+	return c, w
+}
+
+func TestReviewHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		productId      string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "5", `{"rating":5,"text":"great","photos":["https://img.example.com/1.jpg"]}`, nil, http.StatusCreated},
+		{"bad product id", "x", `{"rating":5}`, nil, http.StatusBadRequest},
+		{"rating out of range", "5", `{"rating":6}`, nil, http.StatusBadRequest},
+		{"bad photo url", "5", `{"rating":4,"photos":["not a url"]}`, nil, http.StatusBadRequest},
+		{"not a buyer", "5", `{"rating":4}`, errs.ForbiddenError, http.StatusForbidden},
+		{"already reviewed", "5", `{"rating":4}`, errs.ConflictError, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockReviewService{
+				CreateFn: func(ctx context.Context, userId int64, req *model.CreateReviewRequest) (model.ReviewResponse, error) {
+					if userId != 9 || req.ProductId != 5 {
+						t.Fatalf("unexpected args: %d %d", userId, req.ProductId)
+					}
+					if tt.serviceErr != nil {
+						return model.ReviewResponse{}, tt.serviceErr
+					}
+					return model.ReviewResponse{Id: 1, ProductId: 5, Rating: req.Rating}, nil
+				},
+			}
+			h := NewReviewHandler(svc)
+			c, w := makeCtx(http.MethodPost, "/", tt.body, gin.Params{{Key: "id", Value: tt.productId}})
+
+			h.Create(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestReviewHandler_List(t *testing.T) {
+	svc := &mockReviewService{
+		ListFn: func(ctx context.Context, limit int, req *model.ListReviewsRequest) ([]model.ReviewResponse, string, error) {
+			if limit != 20 || req.ProductId != 5 || req.Sort != model.ReviewSortHelpful {
+				t.Fatalf("unexpected args: %d %+v", limit, req)
+			}
+			return []model.ReviewResponse{{Id: 1}}, "next", nil
+		},
+	}
+	h := NewReviewHandler(svc)
+	c, w := makeCtx(http.MethodGet, "/?sort=helpful&limit=500", "", gin.Params{{Key: "id", Value: "5"}})
+
+	h.List(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	c, w = makeCtx(http.MethodGet, "/?sort=rating", "", gin.Params{{Key: "id", Value: "5"}})
+	h.List(c)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown sort, got %d", w.Code)
+	}
+}
+
+func TestReviewHandler_Update(t *testing.T) {
+	tests := []struct {
+		name           string
+		reviewId       string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "3", nil, http.StatusOK},
+		{"bad review id", "x", nil, http.StatusBadRequest},
+		{"window expired", "3", errs.ForbiddenError, http.StatusForbidden},
+		{"other user", "3", errs.NotOwnerError, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockReviewService{
+				UpdateFn: func(ctx context.Context, userId int64, req *model.UpdateReviewRequest) (model.ReviewResponse, error) {
+					if req.Id != 3 || req.ProductId != 5 || req.Text == nil || *req.Text != "edited" {
+						t.Fatalf("unexpected request: %+v", req)
+					}
+					return model.ReviewResponse{Id: 3}, tt.serviceErr
+				},
+			}
+			h := NewReviewHandler(svc)
+			c, w := makeCtx(http.MethodPatch, "/", `{"text":"edited"}`,
+				gin.Params{{Key: "id", Value: "5"}, {Key: "reviewId", Value: tt.reviewId}})
+
+			h.Update(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestReviewHandler_Reply(t *testing.T) {
+	svc := &mockReviewService{
+		ReplyFn: func(ctx context.Context, userId int64, role string, req *model.ReplyReviewRequest) (model.ReviewResponse, error) {
+			if req.Text != "thanks" {
+				t.Fatalf("unexpected request: %+v", req)
+			}
+			return model.ReviewResponse{Id: 3, SellerReply: req.Text}, nil
+		},
+	}
+	h := NewReviewHandler(svc)
+	params := gin.Params{{Key: "id", Value: "5"}, {Key: "reviewId", Value: "3"}}
+
+	c, w := makeCtx(http.MethodPut, "/", `{"text":"thanks"}`, params)
+	h.Reply(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	c, w = makeCtx(http.MethodPut, "/", `{}`, params)
+	h.Reply(c)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for empty reply, got %d", w.Code)
+	}
+}
+
+func TestReviewHandler_Vote(t *testing.T) {
+	voted, unvoted := false, false
+	svc := &mockReviewService{
+		VoteFn: func(ctx context.Context, userId int64, req *model.VoteReviewRequest) (model.ReviewResponse, error) {
+			voted = true
+			return model.ReviewResponse{Id: req.Id, HelpfulCount: 1}, nil
+		},
+		UnvoteFn: func(ctx context.Context, userId int64, req *model.VoteReviewRequest) (model.ReviewResponse, error) {
+			unvoted = true
+			return model.ReviewResponse{Id: req.Id}, nil
+		},
+	}
+	h := NewReviewHandler(svc)
+	params := gin.Params{{Key: "id", Value: "5"}, {Key: "reviewId", Value: "3"}}
+
+	c, w := makeCtx(http.MethodPost, "/", "", params)
+	h.Vote(c)
+	if w.Code != http.StatusOK || !voted {
+		t.Fatalf("expected vote, got %d", w.Code)
+	}
+
+	c, w = makeCtx(http.MethodDelete, "/", "", params)
+	h.Unvote(c)
+	if w.Code != http.StatusOK || !unvoted {
+		t.Fatalf("expected unvote, got %d", w.Code)
+	}
+}
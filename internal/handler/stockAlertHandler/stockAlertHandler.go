@@ -0,0 +1,57 @@
+package stockAlertHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IStockAlertService interface {
+	Subscribe(ctx context.Context, userId int64, req *model.StockSubscriptionRequest) (model.StockSubscriptionResponse, error)
+	Unsubscribe(ctx context.Context, userId int64, req *model.StockSubscriptionRequest) error
+}
+
+type StockAlertHandler struct {
+	svc IStockAlertService
+}
+
+func NewStockAlertHandler(svc IStockAlertService) *StockAlertHandler {
+	return &StockAlertHandler{svc: svc}
+}
+
+func (h *StockAlertHandler) Subscribe(ctx *gin.Context) {
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req := model.StockSubscriptionRequest{ProductId: int64(productId)}
+
+	subscription, err := h.svc.Subscribe(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": subscription})
+}
+
+func (h *StockAlertHandler) Unsubscribe(ctx *gin.Context) {
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req := model.StockSubscriptionRequest{ProductId: int64(productId)}
+
+	if err := h.svc.Unsubscribe(ctx, ctx.GetInt64("user_id"), &req); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": "subscription removed"})
+}
@@ -0,0 +1,89 @@
+package stockAlertHandler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockStockAlertService struct {
+	SubscribeFn   func(ctx context.Context, userId int64, req *model.StockSubscriptionRequest) (model.StockSubscriptionResponse, error)
+	UnsubscribeFn func(ctx context.Context, userId int64, req *model.StockSubscriptionRequest) error
+}
+
+func (m *mockStockAlertService) Subscribe(ctx context.Context, userId int64, req *model.StockSubscriptionRequest) (model.StockSubscriptionResponse, error) {
+	return m.SubscribeFn(ctx, userId, req)
+}
+func (m *mockStockAlertService) Unsubscribe(ctx context.Context, userId int64, req *model.StockSubscriptionRequest) error {
+	return m.UnsubscribeFn(ctx, userId, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(method, productId string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/", nil)
+	c.Params = gin.Params{{Key: "id", Value: productId}}
+	c.Set("user_id", int64(9))
+	c.Set("role", "user")
+	return c, w
+}
+
+func TestStockAlertHandler_Subscribe(t *testing.T) {
+	tests := []struct {
+		name           string
+		productId      string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "5", nil, http.StatusOK},
+		{"bad product id", "x", nil, http.StatusBadRequest},
+		{"in stock", "5", errs.ConflictError, http.StatusConflict},
+		{"not listed", "5", errs.NotFoundError, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockStockAlertService{
+				SubscribeFn: func(ctx context.Context, userId int64, req *model.StockSubscriptionRequest) (model.StockSubscriptionResponse, error) {
+					if userId != 9 || req.ProductId != 5 {
+						t.Fatalf("unexpected args: %d %+v", userId, req)
+					}
+					return model.StockSubscriptionResponse{ProductId: 5}, tt.serviceErr
+				},
+			}
+			h := NewStockAlertHandler(svc)
+			c, w := makeCtx(http.MethodPost, tt.productId)
+
+			h.Subscribe(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestStockAlertHandler_Unsubscribe(t *testing.T) {
+	svc := &mockStockAlertService{
+		UnsubscribeFn: func(ctx context.Context, userId int64, req *model.StockSubscriptionRequest) error {
+			return errs.NotFoundError
+		},
+	}
+	h := NewStockAlertHandler(svc)
+	c, w := makeCtx(http.MethodDelete, "5")
+
+	h.Unsubscribe(c)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
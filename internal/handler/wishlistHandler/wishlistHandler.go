@@ -0,0 +1,210 @@
+package wishlistHandler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IWishlistService interface {
+	Create(ctx context.Context, userId int64, req *model.CreateWishlistRequest) (model.WishlistResponse, error)
+	List(ctx context.Context, userId int64) ([]model.WishlistResponse, error)
+	Get(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistDetailsResponse, error)
+	Rename(ctx context.Context, userId int64, req *model.RenameWishlistRequest) (model.WishlistResponse, error)
+	Delete(ctx context.Context, userId int64, req *model.WishlistRequest) error
+	AddItem(ctx context.Context, userId int64, req *model.AddWishlistItemRequest) (model.WishlistItemResponse, error)
+	RemoveItem(ctx context.Context, userId int64, req *model.RemoveWishlistItemRequest) error
+	Share(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistResponse, error)
+	Unshare(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistResponse, error)
+	Shared(ctx context.Context, req *model.SharedWishlistRequest) (model.SharedWishlistResponse, error)
+}
+
+type WishlistHandler struct {
+	svc IWishlistService
+}
+
+func NewWishlistHandler(svc IWishlistService) *WishlistHandler {
+	return &WishlistHandler{svc: svc}
+}
+
+func (h *WishlistHandler) Create(ctx *gin.Context) {
+	var req model.CreateWishlistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	wishlist, err := h.svc.Create(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": wishlist})
+}
+
+func (h *WishlistHandler) List(ctx *gin.Context) {
+	wishlists, err := h.svc.List(ctx, ctx.GetInt64("user_id"))
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": wishlists})
+}
+
+func (h *WishlistHandler) Get(ctx *gin.Context) {
+	id, ok := wishlistId(ctx)
+	if !ok {
+		return
+	}
+	req := model.WishlistRequest{Id: id}
+
+	wishlist, err := h.svc.Get(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": wishlist})
+}
+
+func (h *WishlistHandler) Rename(ctx *gin.Context) {
+	id, ok := wishlistId(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.RenameWishlistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.Id = id
+
+	wishlist, err := h.svc.Rename(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": wishlist})
+}
+
+func (h *WishlistHandler) Delete(ctx *gin.Context) {
+	id, ok := wishlistId(ctx)
+	if !ok {
+		return
+	}
+	req := model.WishlistRequest{Id: id}
+
+	if err := h.svc.Delete(ctx, ctx.GetInt64("user_id"), &req); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": "wishlist deleted"})
+}
+
+func (h *WishlistHandler) AddItem(ctx *gin.Context) {
+	id, ok := wishlistId(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.AddWishlistItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req.WishlistId = id
+
+	item, err := h.svc.AddItem(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+func (h *WishlistHandler) RemoveItem(ctx *gin.Context) {
+	id, ok := wishlistId(ctx)
+	if !ok {
+		return
+	}
+
+	productId, err := strconv.Atoi(ctx.Param("productId"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	req := model.RemoveWishlistItemRequest{WishlistId: id, ProductId: int64(productId)}
+
+	if err := h.svc.RemoveItem(ctx, ctx.GetInt64("user_id"), &req); err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": "product removed from wishlist"})
+}
+
+func (h *WishlistHandler) Share(ctx *gin.Context) {
+	id, ok := wishlistId(ctx)
+	if !ok {
+		return
+	}
+	req := model.WishlistRequest{Id: id}
+
+	wishlist, err := h.svc.Share(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": wishlist})
+}
+
+func (h *WishlistHandler) Unshare(ctx *gin.Context) {
+	id, ok := wishlistId(ctx)
+	if !ok {
+		return
+	}
+	req := model.WishlistRequest{Id: id}
+
+	wishlist, err := h.svc.Unshare(ctx, ctx.GetInt64("user_id"), &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": wishlist})
+}
+
+// Shared returns the public view of a wishlist by its share token, no login is needed.
+func (h *WishlistHandler) Shared(ctx *gin.Context) {
+	req := model.SharedWishlistRequest{Token: ctx.Param("token")}
+
+	wishlist, err := h.svc.Shared(ctx, &req)
+	if err != nil {
+		errs.RespondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": wishlist})
+}
+
+// wishlistId parses the wishlist id from the url and responds with 400 if it is invalid.
+func wishlistId(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		errs.RespondError(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return 0, false
+	}
+
+	return int64(id), true
+}
@@ -0,0 +1,190 @@
+package wishlistHandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockWishlistService struct {
+	CreateFn     func(ctx context.Context, userId int64, req *model.CreateWishlistRequest) (model.WishlistResponse, error)
+	ListFn       func(ctx context.Context, userId int64) ([]model.WishlistResponse, error)
+	GetFn        func(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistDetailsResponse, error)
+	RenameFn     func(ctx context.Context, userId int64, req *model.RenameWishlistRequest) (model.WishlistResponse, error)
+	DeleteFn     func(ctx context.Context, userId int64, req *model.WishlistRequest) error
+	AddItemFn    func(ctx context.Context, userId int64, req *model.AddWishlistItemRequest) (model.WishlistItemResponse, error)
+	RemoveItemFn func(ctx context.Context, userId int64, req *model.RemoveWishlistItemRequest) error
+	ShareFn      func(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistResponse, error)
+	UnshareFn    func(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistResponse, error)
+	SharedFn     func(ctx context.Context, req *model.SharedWishlistRequest) (model.SharedWishlistResponse, error)
+}
+
+func (m *mockWishlistService) Create(ctx context.Context, userId int64, req *model.CreateWishlistRequest) (model.WishlistResponse, error) {
+	return m.CreateFn(ctx, userId, req)
+}
+func (m *mockWishlistService) List(ctx context.Context, userId int64) ([]model.WishlistResponse, error) {
+	return m.ListFn(ctx, userId)
+}
+func (m *mockWishlistService) Get(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistDetailsResponse, error) {
+	return m.GetFn(ctx, userId, req)
+}
+func (m *mockWishlistService) Rename(ctx context.Context, userId int64, req *model.RenameWishlistRequest) (model.WishlistResponse, error) {
+	return m.RenameFn(ctx, userId, req)
+}
+func (m *mockWishlistService) Delete(ctx context.Context, userId int64, req *model.WishlistRequest) error {
+	return m.DeleteFn(ctx, userId, req)
+}
+func (m *mockWishlistService) AddItem(ctx context.Context, userId int64, req *model.AddWishlistItemRequest) (model.WishlistItemResponse, error) {
+	return m.AddItemFn(ctx, userId, req)
+}
+func (m *mockWishlistService) RemoveItem(ctx context.Context, userId int64, req *model.RemoveWishlistItemRequest) error {
+	return m.RemoveItemFn(ctx, userId, req)
+}
+func (m *mockWishlistService) Share(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistResponse, error) {
+	return m.ShareFn(ctx, userId, req)
+}
+func (m *mockWishlistService) Unshare(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistResponse, error) {
+	return m.UnshareFn(ctx, userId, req)
+}
+func (m *mockWishlistService) Shared(ctx context.Context, req *model.SharedWishlistRequest) (model.SharedWishlistResponse, error) {
+	return m.SharedFn(ctx, req)
+}
+
+func init() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func makeCtx(method, target, body string, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("user_id", int64(9))
+	c.Set("role", "user")
+	return c, w
+}
+
+func TestWishlistHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", `{"name":"Birthday"}`, nil, http.StatusCreated},
+		{"empty name", `{}`, nil, http.StatusBadRequest},
+		{"duplicate name", `{"name":"Birthday"}`, errs.ConflictError, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockWishlistService{
+				CreateFn: func(ctx context.Context, userId int64, req *model.CreateWishlistRequest) (model.WishlistResponse, error) {
+					if userId != 9 || req.Name != "Birthday" {
+						t.Fatalf("unexpected args: %d %+v", userId, req)
+					}
+					return model.WishlistResponse{Id: 1, Name: req.Name}, tt.serviceErr
+				},
+			}
+			h := NewWishlistHandler(svc)
+			c, w := makeCtx(http.MethodPost, "/", tt.body, nil)
+
+			h.Create(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWishlistHandler_AddItem(t *testing.T) {
+	tests := []struct {
+		name           string
+		wishlistId     string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "1", `{"product_id":5,"notify_price_drop":true}`, nil, http.StatusOK},
+		{"bad wishlist id", "x", `{"product_id":5}`, nil, http.StatusBadRequest},
+		{"missing product", "1", `{}`, nil, http.StatusBadRequest},
+		{"another user's wishlist", "1", `{"product_id":5,"notify_price_drop":true}`, errs.NotOwnerError, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockWishlistService{
+				AddItemFn: func(ctx context.Context, userId int64, req *model.AddWishlistItemRequest) (model.WishlistItemResponse, error) {
+					if req.WishlistId != 1 || req.ProductId != 5 || !req.NotifyPriceDrop || req.NotifyBackInStock {
+						t.Fatalf("unexpected request: %+v", req)
+					}
+					return model.WishlistItemResponse{NotifyPriceDrop: true}, tt.serviceErr
+				},
+			}
+			h := NewWishlistHandler(svc)
+			c, w := makeCtx(http.MethodPost, "/", tt.body, gin.Params{{Key: "id", Value: tt.wishlistId}})
+
+			h.AddItem(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestWishlistHandler_RemoveItem(t *testing.T) {
+	svc := &mockWishlistService{
+		RemoveItemFn: func(ctx context.Context, userId int64, req *model.RemoveWishlistItemRequest) error {
+			if req.WishlistId != 1 || req.ProductId != 5 {
+				t.Fatalf("unexpected request: %+v", req)
+			}
+			return nil
+		},
+	}
+	h := NewWishlistHandler(svc)
+
+	c, w := makeCtx(http.MethodDelete, "/", "", gin.Params{{Key: "id", Value: "1"}, {Key: "productId", Value: "5"}})
+	h.RemoveItem(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	c, w = makeCtx(http.MethodDelete, "/", "", gin.Params{{Key: "id", Value: "1"}, {Key: "productId", Value: "x"}})
+	h.RemoveItem(c)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestWishlistHandler_Shared(t *testing.T) {
+	svc := &mockWishlistService{
+		SharedFn: func(ctx context.Context, req *model.SharedWishlistRequest) (model.SharedWishlistResponse, error) {
+			if req.Token != "token" {
+				return model.SharedWishlistResponse{}, errs.NotFoundError
+			}
+			return model.SharedWishlistResponse{Name: "Birthday", Items: []model.ProductResponse{}}, nil
+		},
+	}
+	h := NewWishlistHandler(svc)
+
+	c, w := makeCtx(http.MethodGet, "/", "", gin.Params{{Key: "token", Value: "token"}})
+	h.Shared(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	c, w = makeCtx(http.MethodGet, "/", "", gin.Params{{Key: "token", Value: "guess"}})
+	h.Shared(c)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
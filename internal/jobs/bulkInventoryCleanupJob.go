@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+)
+
+type IBulkInventoryPurger interface {
+	PurgeBulkInventoryUpdates(ctx context.Context, before time.Time) (int64, error)
+}
+
+// BulkInventoryCleanupJob periodically removes the stored results of bulk inventory
+// updates older than the idempotency TTL.
+type BulkInventoryCleanupJob struct {
+	repo   IBulkInventoryPurger
+	cfg    config.BulkInventoryConfig
+	logger *slog.Logger
+}
+
+func NewBulkInventoryCleanupJob(repo IBulkInventoryPurger, cfg config.BulkInventoryConfig, logger *slog.Logger) *BulkInventoryCleanupJob {
+	// Results that never expire are never purged.
+	if cfg.IdempotencyTTL <= 0 {
+		cfg.CleanupInterval = 0
+	}
+
+	return &BulkInventoryCleanupJob{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run purges right away and then every CleanupInterval until ctx is canceled.
+func (j *BulkInventoryCleanupJob) Run(ctx context.Context) {
+	runPeriodic(ctx, j.logger, j.cfg.CleanupInterval, "bulk inventory cleanup", true, j.purge)
+}
+
+func (j *BulkInventoryCleanupJob) purge(ctx context.Context) error {
+	purged, err := j.repo.PurgeBulkInventoryUpdates(ctx, time.Now().Add(-j.cfg.IdempotencyTTL))
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		j.logger.Info("expired bulk inventory results purged", "count", purged)
+	}
+	return nil
+}
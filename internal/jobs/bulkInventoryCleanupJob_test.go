@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+)
+
+type mockBulkInventoryPurger struct {
+	PurgeFn func(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (m *mockBulkInventoryPurger) PurgeBulkInventoryUpdates(ctx context.Context, before time.Time) (int64, error) {
+	return m.PurgeFn(ctx, before)
+}
+
+func TestBulkInventoryCleanupJob_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	purger := &mockBulkInventoryPurger{
+		PurgeFn: func(ctx context.Context, before time.Time) (int64, error) {
+			calls++
+			if time.Since(before) < 24*time.Hour {
+				t.Fatalf("only results older than the TTL can be purged, got %v", before)
+			}
+			cancel()
+			return 2, nil
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	cfg := config.BulkInventoryConfig{IdempotencyTTL: 24 * time.Hour, CleanupInterval: time.Hour}
+	NewBulkInventoryCleanupJob(purger, cfg, logger).Run(ctx)
+	if calls != 1 {
+		t.Fatalf("expected a purge on start, got %d calls", calls)
+	}
+
+	NewBulkInventoryCleanupJob(purger, config.BulkInventoryConfig{IdempotencyTTL: 24 * time.Hour}, logger).Run(context.Background())
+	NewBulkInventoryCleanupJob(purger, config.BulkInventoryConfig{CleanupInterval: time.Hour}, logger).Run(context.Background())
+	if calls != 1 {
+		t.Fatalf("disabled job must not purge results")
+	}
+}
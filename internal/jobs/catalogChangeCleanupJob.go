@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+)
+
+type ICatalogChangePurger interface {
+	PurgeCatalogChanges(ctx context.Context, before time.Time) (int64, error)
+}
+
+// CatalogChangeCleanupJob periodically removes the catalog changes older than the
+// retention window, cursors pointing before them are answered with 410.
+type CatalogChangeCleanupJob struct {
+	repo   ICatalogChangePurger
+	cfg    config.CatalogChangesConfig
+	logger *slog.Logger
+}
+
+func NewCatalogChangeCleanupJob(repo ICatalogChangePurger, cfg config.CatalogChangesConfig, logger *slog.Logger) *CatalogChangeCleanupJob {
+	// Changes kept forever are never purged.
+	if cfg.Retention <= 0 {
+		cfg.CleanupInterval = 0
+	}
+
+	return &CatalogChangeCleanupJob{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run purges right away and then every CleanupInterval until ctx is canceled.
+func (j *CatalogChangeCleanupJob) Run(ctx context.Context) {
+	runPeriodic(ctx, j.logger, j.cfg.CleanupInterval, "catalog change cleanup", true, j.purge)
+}
+
+func (j *CatalogChangeCleanupJob) purge(ctx context.Context) error {
+	purged, err := j.repo.PurgeCatalogChanges(ctx, time.Now().Add(-j.cfg.Retention))
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		j.logger.Info("expired catalog changes purged", "count", purged)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+)
+
+type mockCatalogChangePurger struct {
+	PurgeFn func(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (m *mockCatalogChangePurger) PurgeCatalogChanges(ctx context.Context, before time.Time) (int64, error) {
+	return m.PurgeFn(ctx, before)
+}
+
+func TestCatalogChangeCleanupJob_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	purger := &mockCatalogChangePurger{
+		PurgeFn: func(ctx context.Context, before time.Time) (int64, error) {
+			calls++
+			if time.Since(before) < 24*time.Hour {
+				t.Fatalf("only changes older than the retention can be purged, got %v", before)
+			}
+			cancel()
+			return 2, nil
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	cfg := config.CatalogChangesConfig{Retention: 24 * time.Hour, CleanupInterval: time.Hour}
+	NewCatalogChangeCleanupJob(purger, cfg, logger).Run(ctx)
+	if calls != 1 {
+		t.Fatalf("expected a purge on start, got %d calls", calls)
+	}
+
+	NewCatalogChangeCleanupJob(purger, config.CatalogChangesConfig{Retention: 24 * time.Hour}, logger).Run(context.Background())
+	NewCatalogChangeCleanupJob(purger, config.CatalogChangesConfig{CleanupInterval: time.Hour}, logger).Run(context.Background())
+	if calls != 1 {
+		t.Fatalf("disabled job must not purge changes")
+	}
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type IFeedGenerator interface {
+	RegenerateFeeds(ctx context.Context) error
+}
+
+// FeedJob keeps the cached public catalog feeds fresh for price aggregators.
+type FeedJob struct {
+	gen      IFeedGenerator
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewFeedJob(gen IFeedGenerator, interval time.Duration, logger *slog.Logger) *FeedJob {
+	return &FeedJob{
+		gen:      gen,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run regenerates the feeds right away and then every interval until ctx is canceled.
+func (j *FeedJob) Run(ctx context.Context) {
+	runPeriodic(ctx, j.logger, j.interval, "catalog feed regeneration", true, j.gen.RegenerateFeeds)
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type mockFeedGenerator struct {
+	RegenerateFn func(ctx context.Context) error
+}
+
+func (m *mockFeedGenerator) RegenerateFeeds(ctx context.Context) error {
+	return m.RegenerateFn(ctx)
+}
+
+func TestFeedJob_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	gen := &mockFeedGenerator{
+		RegenerateFn: func(ctx context.Context) error {
+			calls++
+			cancel()
+			return nil
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	NewFeedJob(gen, time.Hour, logger).Run(ctx)
+	if calls != 1 {
+		t.Fatalf("expected the feed to be generated on start, got %d calls", calls)
+	}
+
+	NewFeedJob(gen, 0, logger).Run(context.Background())
+	if calls != 1 {
+		t.Fatalf("disabled job must not regenerate feeds")
+	}
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type ILowStockDigest interface {
+	SendDigest(ctx context.Context) error
+}
+
+// LowStockDigestJob periodically sends sellers the digest of their low and out of stock products.
+type LowStockDigestJob struct {
+	digest   ILowStockDigest
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewLowStockDigestJob(digest ILowStockDigest, interval time.Duration, logger *slog.Logger) *LowStockDigestJob {
+	return &LowStockDigestJob{
+		digest:   digest,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run sends the digest every interval until ctx is canceled. Unlike the other jobs it
+// waits for the first tick, so a restart doesn't send the sellers a second digest.
+func (j *LowStockDigestJob) Run(ctx context.Context) {
+	runPeriodic(ctx, j.logger, j.interval, "low stock digest", false, j.digest.SendDigest)
+}
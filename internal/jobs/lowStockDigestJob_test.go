@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type mockLowStockDigest struct {
+	SendDigestFn func(ctx context.Context) error
+}
+
+func (m *mockLowStockDigest) SendDigest(ctx context.Context) error {
+	return m.SendDigestFn(ctx)
+}
+
+func TestLowStockDigestJob_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	digest := &mockLowStockDigest{
+		SendDigestFn: func(ctx context.Context) error {
+			calls++
+			cancel()
+			return nil
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	NewLowStockDigestJob(digest, time.Millisecond, logger).Run(ctx)
+	if calls != 1 {
+		t.Fatalf("expected a digest on the first tick, got %d calls", calls)
+	}
+
+	canceled, stop := context.WithCancel(context.Background())
+	stop()
+	NewLowStockDigestJob(digest, time.Hour, logger).Run(canceled)
+	if calls != 1 {
+		t.Fatalf("digest must not be sent before the first tick")
+	}
+
+	NewLowStockDigestJob(digest, 0, logger).Run(context.Background())
+	if calls != 1 {
+		t.Fatalf("disabled job must not send the digest")
+	}
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// runPeriodic calls run every interval until ctx is canceled, the first time right
+// away when immediate is set and on the first tick otherwise. A failed run is logged
+// and tried again on the next tick. A job without a positive interval is disabled.
+func runPeriodic(ctx context.Context, logger *slog.Logger, interval time.Duration, name string, immediate bool, run func(ctx context.Context) error) {
+	if interval <= 0 {
+		logger.Info(name + " job disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	if !immediate && !wait(ctx, ticker) {
+		return
+	}
+
+	for {
+		start := time.Now()
+		if err := run(ctx); err != nil {
+			logger.Error(name+" failed", "err", err)
+		} else {
+			logger.Info(name+" done", "took", time.Since(start))
+		}
+
+		if !wait(ctx, ticker) {
+			return
+		}
+	}
+}
+
+// wait blocks until the next tick and returns false when ctx is canceled first.
+func wait(ctx context.Context, ticker *time.Ticker) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-ticker.C:
+		return true
+	}
+}
@@ -0,0 +1,72 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestRunPeriodic(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("runs right away and keeps going after a failure", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		runPeriodic(ctx, logger, time.Millisecond, "test", true, func(ctx context.Context) error {
+			calls++
+			if calls == 3 {
+				cancel()
+			}
+			return errors.New("failed")
+		})
+		if calls != 3 {
+			t.Fatalf("expected 3 runs, got %d", calls)
+		}
+	})
+
+	t.Run("immediate run doesn't wait for a tick", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		runPeriodic(ctx, logger, time.Hour, "test", true, func(ctx context.Context) error {
+			calls++
+			cancel()
+			return nil
+		})
+		if calls != 1 {
+			t.Fatalf("expected a run on start, got %d", calls)
+		}
+	})
+
+	t.Run("delayed run waits for the first tick", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(context.Background())
+		cancel()
+		calls := 0
+		runPeriodic(canceled, logger, time.Hour, "test", false, func(ctx context.Context) error {
+			calls++
+			return nil
+		})
+		if calls != 0 {
+			t.Fatalf("must not run before the first tick, got %d runs", calls)
+		}
+
+		ctx, stop := context.WithCancel(context.Background())
+		runPeriodic(ctx, logger, time.Millisecond, "test", false, func(ctx context.Context) error {
+			calls++
+			stop()
+			return nil
+		})
+		if calls != 1 {
+			t.Fatalf("expected a run on the first tick, got %d", calls)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		runPeriodic(context.Background(), logger, 0, "test", true, func(ctx context.Context) error {
+			t.Fatalf("disabled job must not run")
+			return nil
+		})
+	})
+}
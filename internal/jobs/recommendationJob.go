@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type IRecommendationBuilder interface {
+	Rebuild(ctx context.Context) error
+}
+
+// RecommendationJob periodically precomputes product recommendations into the cache.
+type RecommendationJob struct {
+	builder  IRecommendationBuilder
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewRecommendationJob(builder IRecommendationBuilder, interval time.Duration, logger *slog.Logger) *RecommendationJob {
+	return &RecommendationJob{
+		builder:  builder,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run rebuilds the recommendations right away and then every interval until ctx is canceled.
+func (j *RecommendationJob) Run(ctx context.Context) {
+	runPeriodic(ctx, j.logger, j.interval, "recommendation rebuild", true, j.builder.Rebuild)
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type mockRecommendationBuilder struct {
+	RebuildFn func(ctx context.Context) error
+}
+
+func (m *mockRecommendationBuilder) Rebuild(ctx context.Context) error {
+	return m.RebuildFn(ctx)
+}
+
+func TestRecommendationJob_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	builder := &mockRecommendationBuilder{
+		RebuildFn: func(ctx context.Context) error {
+			calls++
+			cancel()
+			return errors.New("db is down")
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	NewRecommendationJob(builder, time.Hour, logger).Run(ctx)
+	if calls != 1 {
+		t.Fatalf("expected a rebuild on start even after a failure, got %d calls", calls)
+	}
+
+	NewRecommendationJob(builder, 0, logger).Run(context.Background())
+	if calls != 1 {
+		t.Fatalf("disabled job must not rebuild recommendations")
+	}
+}
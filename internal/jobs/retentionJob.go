@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+)
+
+const defaultBatchSize = 500
+
+type IProductPurger interface {
+	PurgeDeletedProducts(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// RetentionJob permanently removes products that stay soft deleted longer than the retention period.
+type RetentionJob struct {
+	repo   IProductPurger
+	cfg    config.RetentionConfig
+	logger *slog.Logger
+}
+
+func NewRetentionJob(repo IProductPurger, cfg config.RetentionConfig, logger *slog.Logger) *RetentionJob {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	// Deleted products kept forever are never purged.
+	if cfg.PurgeAfter <= 0 {
+		cfg.Interval = 0
+	}
+
+	return &RetentionJob{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run purges right away and then every Interval until ctx is canceled.
+func (j *RetentionJob) Run(ctx context.Context) {
+	runPeriodic(ctx, j.logger, j.cfg.Interval, "product retention purge", true, j.purge)
+}
+
+func (j *RetentionJob) purge(ctx context.Context) error {
+	purged, err := j.RunOnce(ctx)
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		j.logger.Info("purged deleted products", "count", purged)
+	}
+	return nil
+}
+
+// RunOnce purges in batches so a large backlog doesn't hold locks for long.
+func (j *RetentionJob) RunOnce(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-j.cfg.PurgeAfter)
+
+	var total int64
+	for {
+		purged, err := j.repo.PurgeDeletedProducts(ctx, before, j.cfg.BatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		total += purged
+		if purged < int64(j.cfg.BatchSize) {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+)
+
+type mockPurger struct {
+	PurgeFn func(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+func (m *mockPurger) PurgeDeletedProducts(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return m.PurgeFn(ctx, before, limit)
+}
+
+func TestRetentionJob_RunOnce(t *testing.T) {
+	batches := []int64{2, 2, 1}
+	calls := 0
+	repo := &mockPurger{
+		PurgeFn: func(ctx context.Context, before time.Time, limit int) (int64, error) {
+			if limit != 2 {
+				t.Fatalf("unexpected limit %d", limit)
+			}
+			if time.Since(before) < 24*time.Hour {
+				t.Fatalf("cutoff must respect purge_after: %v", before)
+			}
+			purged := batches[calls]
+			calls++
+			return purged, nil
+		},
+	}
+	job := NewRetentionJob(repo, config.RetentionConfig{PurgeAfter: 24 * time.Hour, BatchSize: 2}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	total, err := job.RunOnce(context.Background())
+	if err != nil || total != 5 || calls != 3 {
+		t.Fatalf("unexpected result: total=%d calls=%d err=%v", total, calls, err)
+	}
+
+	repo.PurgeFn = func(ctx context.Context, before time.Time, limit int) (int64, error) {
+		return 0, errors.New("db")
+	}
+	if _, err = job.RunOnce(context.Background()); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestRetentionJob_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	repo := &mockPurger{
+		PurgeFn: func(ctx context.Context, before time.Time, limit int) (int64, error) {
+			calls++
+			cancel()
+			return 0, nil
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Products kept forever are never purged.
+	NewRetentionJob(repo, config.RetentionConfig{Interval: time.Millisecond}, logger).Run(ctx)
+	if calls != 0 {
+		t.Fatalf("disabled job must not purge, got %d calls", calls)
+	}
+
+	NewRetentionJob(repo, config.RetentionConfig{PurgeAfter: time.Hour, Interval: time.Hour}, logger).Run(ctx)
+	if calls != 1 {
+		t.Fatalf("expected a purge on start, got %d calls", calls)
+	}
+}
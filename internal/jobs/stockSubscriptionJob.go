@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type IStockSubscriptionPurger interface {
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// StockSubscriptionJob periodically removes back in stock subscriptions that expired without a restock.
+type StockSubscriptionJob struct {
+	purger   IStockSubscriptionPurger
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewStockSubscriptionJob(purger IStockSubscriptionPurger, interval time.Duration, logger *slog.Logger) *StockSubscriptionJob {
+	return &StockSubscriptionJob{
+		purger:   purger,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run purges expired subscriptions right away and then every interval until ctx is canceled.
+func (j *StockSubscriptionJob) Run(ctx context.Context) {
+	runPeriodic(ctx, j.logger, j.interval, "stock subscription purge", true, j.purge)
+}
+
+func (j *StockSubscriptionJob) purge(ctx context.Context) error {
+	purged, err := j.purger.PurgeExpired(ctx)
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		j.logger.Info("expired stock subscriptions purged", "count", purged)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type mockStockSubscriptionPurger struct {
+	PurgeExpiredFn func(ctx context.Context) (int64, error)
+}
+
+func (m *mockStockSubscriptionPurger) PurgeExpired(ctx context.Context) (int64, error) {
+	return m.PurgeExpiredFn(ctx)
+}
+
+func TestStockSubscriptionJob_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	purger := &mockStockSubscriptionPurger{
+		PurgeExpiredFn: func(ctx context.Context) (int64, error) {
+			calls++
+			cancel()
+			return 3, nil
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	NewStockSubscriptionJob(purger, time.Hour, logger).Run(ctx)
+	if calls != 1 {
+		t.Fatalf("expected a purge on start, got %d calls", calls)
+	}
+
+	NewStockSubscriptionJob(purger, 0, logger).Run(context.Background())
+	if calls != 1 {
+		t.Fatalf("disabled job must not purge subscriptions")
+	}
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type IWishlistAlertChecker interface {
+	CheckAlerts(ctx context.Context) error
+}
+
+// WishlistAlertJob periodically notifies users about price drops and restocks of wishlisted products.
+type WishlistAlertJob struct {
+	checker  IWishlistAlertChecker
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewWishlistAlertJob(checker IWishlistAlertChecker, interval time.Duration, logger *slog.Logger) *WishlistAlertJob {
+	return &WishlistAlertJob{
+		checker:  checker,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run checks the alerts right away and then every interval until ctx is canceled.
+func (j *WishlistAlertJob) Run(ctx context.Context) {
+	runPeriodic(ctx, j.logger, j.interval, "wishlist alert check", true, j.checker.CheckAlerts)
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type mockAlertChecker struct {
+	CheckAlertsFn func(ctx context.Context) error
+}
+
+func (m *mockAlertChecker) CheckAlerts(ctx context.Context) error {
+	return m.CheckAlertsFn(ctx)
+}
+
+func TestWishlistAlertJob_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	checker := &mockAlertChecker{
+		CheckAlertsFn: func(ctx context.Context) error {
+			calls++
+			cancel()
+			return errors.New("db is down")
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	NewWishlistAlertJob(checker, time.Hour, logger).Run(ctx)
+	if calls != 1 {
+		t.Fatalf("expected a check on start even after a failure, got %d calls", calls)
+	}
+
+	NewWishlistAlertJob(checker, 0, logger).Run(context.Background())
+	if calls != 1 {
+		t.Fatalf("disabled job must not check alerts")
+	}
+}
@@ -22,15 +22,47 @@ type Category struct {
 }
 
 type Product struct {
-	Id          int64     `json:"id" db:"id"`
-	SellerId    int64     `json:"seller_id" db:"seller_id"`
-	CategoryId  int64     `json:"category_id" db:"category_id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	Price       float64   `json:"price" db:"price"`
-	Stock       int       `json:"stock" db:"stock"`
-	IsApproved  bool      `json:"is_approved" db:"is_approved"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	Id              int64      `json:"id" db:"id"`
+	SellerId        int64      `json:"seller_id" db:"seller_id"`
+	CategoryId      int64      `json:"category_id" db:"category_id"`
+	Name            string     `json:"name" db:"name"`
+	Description     string     `json:"description" db:"description"`
+	Price           float64    `json:"price" db:"price"`
+	Stock           int        `json:"stock" db:"stock"`
+	Status          string     `json:"status" db:"status"`
+	RejectionReason string     `json:"rejection_reason" db:"rejection_reason"`
+	SoldCount       int64      `json:"sold_count" db:"sold_count"`
+	Rating          float64    `json:"rating" db:"rating"`
+	ReviewCount     int        `json:"review_count" db:"review_count"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	ArchivedAt      *time.Time `json:"archived_at" db:"archived_at"`
+	DeletedAt       *time.Time `json:"deleted_at" db:"deleted_at"`
+	Sku             string     `json:"sku" db:"sku"`
+	ImageUrl        string     `json:"image_url" db:"image_url"`
+	Slug            string     `json:"slug" db:"slug"`
+	LowestPrice30d  float64    `json:"lowest_price_30d" db:"lowest_price_30d"`
+	Warehoused      bool       `json:"warehoused" db:"warehoused"`
+	Bundle          bool       `json:"bundle" db:"bundle"`
+}
+
+// Listed reports whether the product is visible in the public catalog.
+func (p *Product) Listed() bool {
+	return p.Status == ProductStatusApproved && p.ArchivedAt == nil && p.DeletedAt == nil
+}
+
+// SellerProduct is a product with the counters shown in the seller workspace.
+type SellerProduct struct {
+	Product
+	ViewCount int64   `json:"view_count" db:"view_count"`
+	UnitsSold int64   `json:"units_sold" db:"units_sold"`
+	Revenue   float64 `json:"revenue" db:"revenue"`
+}
+
+type SellerProductFilter struct {
+	State      string
+	Status     string
+	Stock      string
+	CategoryId *int64
 }
 
 type Order struct {
@@ -47,6 +79,42 @@ type OrderItem struct {
 	ProductId int64   `json:"product_id" db:"product_id"`
 	Quantity  int     `json:"quantity" db:"quantity"`
 	Price     float64 `json:"price" db:"price"`
+
+	// Components is the breakdown of a bundle, how many units of every product it took.
+	Components []OrderItemComponent `json:"components" db:"-"`
+}
+
+type OrderItemComponent struct {
+	OrderItemId int64 `json:"order_item_id" db:"order_item_id"`
+	ProductId   int64 `json:"product_id" db:"product_id"`
+	Quantity    int   `json:"quantity" db:"quantity"`
+}
+
+// BundleComponent is a product that goes into a bundle, Quantity units into every bundle.
+type BundleComponent struct {
+	BundleId  int64   `json:"bundle_id" db:"bundle_id"`
+	ProductId int64   `json:"product_id" db:"component_id"`
+	Quantity  int     `json:"quantity" db:"quantity"`
+	Name      string  `json:"name" db:"name"`
+	Slug      string  `json:"slug" db:"slug"`
+	Price     float64 `json:"price" db:"price"`
+	Stock     int     `json:"stock" db:"stock"`
+}
+
+// PriceList holds the prices of a group of customers, wholesale accounts for example.
+type PriceList struct {
+	Id        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PriceTier is the unit price of a product from MinQuantity units on. A tier without a
+// price list applies to every customer, one with a price list only to its members.
+type PriceTier struct {
+	ProductId   int64   `json:"product_id" db:"product_id"`
+	PriceListId *int64  `json:"price_list_id" db:"price_list_id"`
+	MinQuantity int     `json:"min_quantity" db:"min_quantity"`
+	Price       float64 `json:"price" db:"price"`
 }
 
 type User struct {
@@ -58,3 +126,291 @@ type User struct {
 	IsActive bool      `json:"is_active" db:"is_active"`
 	CreateAt time.Time `json:"create_at" db:"create_at"`
 }
+
+type ModerationRule struct {
+	Id          int64     `json:"id" db:"id"`
+	Kind        string    `json:"kind" db:"kind"`
+	Pattern     string    `json:"pattern" db:"pattern"`
+	Severity    string    `json:"severity" db:"severity"`
+	Description string    `json:"description" db:"description"`
+	Enabled     bool      `json:"enabled" db:"enabled"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type ModerationFlag struct {
+	Id        int64     `json:"id" db:"id"`
+	ProductId int64     `json:"product_id" db:"product_id"`
+	RuleId    *int64    `json:"rule_id" db:"rule_id"`
+	Code      string    `json:"code" db:"code"`
+	Severity  string    `json:"severity" db:"severity"`
+	Message   string    `json:"message" db:"message"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ProductView is a single view of the product page by a user.
+type ProductView struct {
+	UserId    int64 `json:"user_id"`
+	ProductId int64 `json:"product_id"`
+}
+
+// CoPurchase is the number of orders in which two products were bought together.
+type CoPurchase struct {
+	ProductId int64 `json:"product_id" db:"product_id"`
+	RelatedId int64 `json:"related_id" db:"related_id"`
+	Orders    int64 `json:"orders" db:"orders"`
+}
+
+type PriceChange struct {
+	ProductId int64     `json:"product_id" db:"product_id"`
+	Price     float64   `json:"price" db:"price"`
+	ChangedAt time.Time `json:"changed_at" db:"changed_at"`
+}
+
+type Discount struct {
+	Id         int64     `json:"id" db:"id"`
+	SellerId   int64     `json:"seller_id" db:"seller_id"`
+	Scope      string    `json:"scope" db:"scope"`
+	ProductId  *int64    `json:"product_id" db:"product_id"`
+	CategoryId *int64    `json:"category_id" db:"category_id"`
+	Kind       string    `json:"kind" db:"kind"`
+	Value      float64   `json:"value" db:"value"`
+	StartsAt   time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time `json:"ends_at" db:"ends_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type ImportJob struct {
+	Id            int64      `json:"id" db:"id"`
+	SellerId      int64      `json:"seller_id" db:"seller_id"`
+	Format        string     `json:"format" db:"format"`
+	Status        string     `json:"status" db:"status"`
+	TotalRows     int        `json:"total_rows" db:"total_rows"`
+	ProcessedRows int        `json:"processed_rows" db:"processed_rows"`
+	CreatedCount  int        `json:"created_count" db:"created_count"`
+	UpdatedCount  int        `json:"updated_count" db:"updated_count"`
+	FailedCount   int        `json:"failed_count" db:"failed_count"`
+	Error         string     `json:"error" db:"error"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at" db:"finished_at"`
+}
+
+type ImportRowError struct {
+	JobId     int64  `json:"job_id" db:"job_id"`
+	RowNumber int    `json:"row_number" db:"row_number"`
+	Sku       string `json:"sku" db:"sku"`
+	Reason    string `json:"reason" db:"reason"`
+}
+
+// ImportedProduct is a product written by an import batch.
+type ImportedProduct struct {
+	Id      int64  `json:"id" db:"id"`
+	Sku     string `json:"sku" db:"sku"`
+	Status  string `json:"status" db:"status"`
+	Created bool   `json:"created" db:"created"`
+}
+
+type Review struct {
+	Id           int64      `json:"id" db:"id"`
+	ProductId    int64      `json:"product_id" db:"product_id"`
+	UserId       int64      `json:"user_id" db:"user_id"`
+	Rating       int        `json:"rating" db:"rating"`
+	Text         string     `json:"text" db:"text"`
+	Photos       []string   `json:"photos" db:"photos"`
+	SellerReply  *string    `json:"seller_reply" db:"seller_reply"`
+	RepliedAt    *time.Time `json:"replied_at" db:"replied_at"`
+	HelpfulCount int        `json:"helpful_count" db:"helpful_count"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type Question struct {
+	Id         int64      `json:"id" db:"id"`
+	ProductId  int64      `json:"product_id" db:"product_id"`
+	UserId     int64      `json:"user_id" db:"user_id"`
+	Text       string     `json:"text" db:"text"`
+	Answer     *string    `json:"answer" db:"answer"`
+	AnsweredBy *int64     `json:"answered_by" db:"answered_by"`
+	AnsweredAt *time.Time `json:"answered_at" db:"answered_at"`
+	Upvotes    int        `json:"upvotes" db:"upvotes"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+type Notification struct {
+	Id        int64      `json:"id" db:"id"`
+	UserId    int64      `json:"user_id" db:"user_id"`
+	Kind      string     `json:"kind" db:"kind"`
+	Title     string     `json:"title" db:"title"`
+	Body      string     `json:"body" db:"body"`
+	ProductId *int64     `json:"product_id" db:"product_id"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	ReadAt    *time.Time `json:"read_at" db:"read_at"`
+}
+
+type Wishlist struct {
+	Id         int64     `json:"id" db:"id"`
+	UserId     int64     `json:"user_id" db:"user_id"`
+	Name       string    `json:"name" db:"name"`
+	ShareToken *string   `json:"share_token" db:"share_token"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ItemCount  int       `json:"item_count" db:"item_count"`
+}
+
+type WishlistItem struct {
+	WishlistId        int64     `json:"wishlist_id" db:"wishlist_id"`
+	ProductId         int64     `json:"product_id" db:"product_id"`
+	NotifyPriceDrop   bool      `json:"notify_price_drop" db:"notify_price_drop"`
+	NotifyBackInStock bool      `json:"notify_back_in_stock" db:"notify_back_in_stock"`
+	LastNotifiedPrice float64   `json:"last_notified_price" db:"last_notified_price"`
+	LastInStock       bool      `json:"last_in_stock" db:"last_in_stock"`
+	AddedAt           time.Time `json:"added_at" db:"added_at"`
+}
+
+// WishlistAlert is a wishlist item with an opted in alert together with the owner
+// and the product data needed to resolve its current price.
+type WishlistAlert struct {
+	WishlistItem
+	UserId     int64   `json:"user_id" db:"user_id"`
+	Name       string  `json:"name" db:"name"`
+	SellerId   int64   `json:"seller_id" db:"seller_id"`
+	CategoryId int64   `json:"category_id" db:"category_id"`
+	Price      float64 `json:"price" db:"price"`
+	Stock      int     `json:"stock" db:"stock"`
+}
+
+// StockSubscription is a buyer's request to be notified when the product is back in stock.
+type StockSubscription struct {
+	ProductId int64     `json:"product_id" db:"product_id"`
+	UserId    int64     `json:"user_id" db:"user_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// LowStockAlert is an open alert about a product whose stock dropped to its low stock
+// threshold. Stock is the current stock of the product.
+type LowStockAlert struct {
+	Id        int64     `json:"id" db:"id"`
+	SellerId  int64     `json:"seller_id" db:"seller_id"`
+	ProductId int64     `json:"product_id" db:"product_id"`
+	Sku       string    `json:"sku" db:"sku"`
+	Name      string    `json:"name" db:"name"`
+	Stock     int       `json:"stock" db:"stock"`
+	Threshold int       `json:"threshold" db:"threshold"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// LowStockCursor is the keyset position of the last alert on a page.
+type LowStockCursor struct {
+	Id int64 `json:"id"`
+}
+
+type Warehouse struct {
+	Id        int64     `json:"id" db:"id"`
+	SellerId  int64     `json:"seller_id" db:"seller_id"`
+	Name      string    `json:"name" db:"name"`
+	Priority  int       `json:"priority" db:"priority"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type InventoryLevel struct {
+	ProductId     int64     `json:"product_id" db:"product_id"`
+	WarehouseId   int64     `json:"warehouse_id" db:"warehouse_id"`
+	WarehouseName string    `json:"warehouse_name" db:"warehouse_name"`
+	Quantity      int       `json:"quantity" db:"quantity"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type InventoryTransfer struct {
+	Id              int64     `json:"id" db:"id"`
+	ProductId       int64     `json:"product_id" db:"product_id"`
+	FromWarehouseId int64     `json:"from_warehouse_id" db:"from_warehouse_id"`
+	ToWarehouseId   int64     `json:"to_warehouse_id" db:"to_warehouse_id"`
+	Quantity        int       `json:"quantity" db:"quantity"`
+	UserId          int64     `json:"user_id" db:"user_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// InventoryMovement is one signed change of the product stock in the ledger. WarehouseId
+// is zero for stock that isn't kept per warehouse, ActorId is zero when the user is gone.
+type InventoryMovement struct {
+	Id          int64     `json:"id" db:"id"`
+	ProductId   int64     `json:"product_id" db:"product_id"`
+	WarehouseId int64     `json:"warehouse_id" db:"warehouse_id"`
+	Quantity    int       `json:"quantity" db:"quantity"`
+	Reason      string    `json:"reason" db:"reason"`
+	ActorId     int64     `json:"actor_id" db:"actor_id"`
+	Reference   string    `json:"reference" db:"reference"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// MovementCursor is the keyset position of the last movement on a page.
+type MovementCursor struct {
+	Id int64 `json:"id"`
+}
+
+// StockDrift is a product whose stock differs from the sum of its movements.
+type StockDrift struct {
+	ProductId   int64  `json:"product_id" db:"product_id"`
+	SellerId    int64  `json:"seller_id" db:"seller_id"`
+	Sku         string `json:"sku" db:"sku"`
+	Name        string `json:"name" db:"name"`
+	Stock       int    `json:"stock" db:"stock"`
+	LedgerStock int    `json:"ledger_stock" db:"ledger_stock"`
+}
+
+// StockDriftCursor is the keyset position of the last product in a reconciliation page.
+type StockDriftCursor struct {
+	ProductId int64 `json:"product_id"`
+}
+
+// BulkInventoryItem is one item of a bulk update, found by ProductId or, when it's
+// zero, by Sku. A nil Stock or Price is left as it is.
+type BulkInventoryItem struct {
+	Index     int      `json:"index"`
+	ProductId int64    `json:"product_id"`
+	Sku       string   `json:"sku"`
+	Stock     *int     `json:"stock"`
+	Price     *float64 `json:"price"`
+}
+
+// BulkInventoryResult is the outcome of one item. It's stored with the idempotency key,
+// the stock before and after are only known when the update was applied.
+type BulkInventoryResult struct {
+	Index         int    `json:"index"`
+	ProductId     int64  `json:"product_id,omitempty"`
+	Sku           string `json:"sku,omitempty"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+	PreviousStock int    `json:"-"`
+	Stock         int    `json:"-"`
+}
+
+// BulkInventoryUpdate is a batch of stock and price changes of one seller applied in
+// one transaction. Replayed is set when the idempotency key was already used.
+type BulkInventoryUpdate struct {
+	SellerId       int64
+	IdempotencyKey string
+	RequestHash    string
+	Items          []BulkInventoryItem
+	Results        []BulkInventoryResult
+	Replayed       bool
+}
+
+// CatalogChange is one entry of the catalog change feed. Data is the entity as the
+// partners see it, it's empty for deletes.
+type CatalogChange struct {
+	Id        int64     `json:"id" db:"id"`
+	TxId      int64     `json:"tx_id" db:"tx_id"`
+	Entity    string    `json:"entity" db:"entity"`
+	EntityId  int64     `json:"entity_id" db:"entity_id"`
+	Op        string    `json:"op" db:"op"`
+	Data      []byte    `json:"data" db:"data"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CatalogChangeCursor points after the change Id of the transaction TxId. At is the
+// time of that change, a cursor older than the retention window can't be resumed.
+type CatalogChangeCursor struct {
+	TxId int64     `json:"tx"`
+	Id   int64     `json:"id"`
+	At   time.Time `json:"at"`
+}
@@ -0,0 +1,8 @@
+package model
+
+// Sources of the home feed items.
+const (
+	FeedSourceViewedCategory = "viewed_category"
+	FeedSourceBestseller     = "bestseller"
+	FeedSourceNewArrival     = "new_arrival"
+)
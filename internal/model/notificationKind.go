@@ -0,0 +1,13 @@
+package model
+
+// Kinds of user notifications.
+const (
+	NotificationPriceDrop   = "price_drop"
+	NotificationBackInStock = "back_in_stock"
+	NotificationLowStock    = "low_stock"
+)
+
+// NotificationCursor is the keyset position of the last notification on a page.
+type NotificationCursor struct {
+	Id int64 `json:"id"`
+}
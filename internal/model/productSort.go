@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+const (
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortNewest    = "newest"
+	SortPopular   = "popular"
+	SortRating    = "rating"
+
+	// SortOldest is not exposed in public listings, it orders the moderation queue.
+	SortOldest = "oldest"
+)
+
+// ProductCursor is the keyset position of the last product on a page.
+// Only the field matching Sort is filled.
+type ProductCursor struct {
+	Sort      string    `json:"s"`
+	Id        int64     `json:"id"`
+	Price     float64   `json:"p,omitempty"`
+	CreatedAt time.Time `json:"c"`
+	SoldCount int64     `json:"sc,omitempty"`
+	Rating    float64   `json:"r,omitempty"`
+}
+
+func NewProductCursor(sort string, p *Product) ProductCursor {
+	c := ProductCursor{Sort: sort, Id: p.Id}
+	switch sort {
+	case SortPriceAsc, SortPriceDesc:
+		c.Price = p.Price
+	case SortPopular:
+		c.SoldCount = p.SoldCount
+	case SortRating:
+		c.Rating = p.Rating
+	default:
+		c.CreatedAt = p.CreatedAt
+	}
+
+	return c
+}
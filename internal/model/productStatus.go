@@ -0,0 +1,113 @@
+package model
+
+const (
+	ProductStatusDraft         = "draft"
+	ProductStatusPendingReview = "pending_review"
+	ProductStatusApproved      = "approved"
+	ProductStatusRejected      = "rejected"
+	ProductStatusSuspended     = "suspended"
+)
+
+const (
+	ModerationActionApprove = "approve"
+	ModerationActionReject  = "reject"
+	ModerationActionSuspend = "suspend"
+)
+
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+const (
+	RuleKindBannedWord = "banned_word"
+	RuleKindRegex      = "regex"
+)
+
+const (
+	FlagCodeBannedWord      = "banned_word"
+	FlagCodeRegex           = "regex"
+	FlagCodeSuspiciousPrice = "suspicious_price"
+	FlagCodeContactDetails  = "contact_details"
+)
+
+const (
+	StockFilterInStock    = "in_stock"
+	StockFilterOutOfStock = "out_of_stock"
+)
+
+const (
+	ProductStateActive   = "active"
+	ProductStateArchived = "archived"
+	ProductStateDeleted  = "deleted"
+)
+
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// The sitemap is generated like the feeds but isn't offered as an export format.
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatYML     = "yml"
+	ExportFormatSitemap = "sitemap"
+)
+
+const (
+	DiscountScopeProduct  = "product"
+	DiscountScopeCategory = "category"
+	DiscountScopeSeller   = "seller"
+)
+
+const (
+	DiscountKindFixed   = "fixed"
+	DiscountKindPercent = "percent"
+)
+
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusShipped   = "shipped"
+	OrderStatusCompleted = "completed"
+	OrderStatusCanceled  = "canceled"
+)
+
+// Reasons of inventory movements. A transfer moves stock between warehouses and
+// doesn't change the product stock.
+const (
+	MovementSale        = "sale"
+	MovementRestock     = "restock"
+	MovementReturn      = "return"
+	MovementAdjustment  = "adjustment"
+	MovementReservation = "reservation"
+	MovementRelease     = "release"
+	MovementTransfer    = "transfer"
+)
+
+// Outcomes of the items of a bulk inventory update.
+const (
+	BulkItemUpdated   = "updated"
+	BulkItemUnchanged = "unchanged"
+	BulkItemNotFound  = "not_found"
+	BulkItemInvalid   = "invalid"
+	BulkItemConflict  = "conflict"
+)
+
+// Entities and operations of the catalog change feed. A product is created in the feed
+// when it enters the public catalog and deleted when it leaves it.
+const (
+	CatalogEntityProduct  = "product"
+	CatalogEntityCategory = "category"
+
+	CatalogOpCreate = "create"
+	CatalogOpUpdate = "update"
+	CatalogOpDelete = "delete"
+)
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+const (
+	QuestionSortNewest = "newest"
+	QuestionSortTop    = "top"
+
+	// QuestionSortOldest is not exposed in public listings, it orders the seller inbox.
+	QuestionSortOldest = "oldest"
+)
+
+// QuestionCursor is the keyset position of the last question on a page.
+type QuestionCursor struct {
+	Sort      string    `json:"s"`
+	Id        int64     `json:"id"`
+	CreatedAt time.Time `json:"c"`
+	Upvotes   int       `json:"u,omitempty"`
+}
+
+func NewQuestionCursor(sort string, q *Question) QuestionCursor {
+	c := QuestionCursor{Sort: sort, Id: q.Id}
+	if sort == QuestionSortTop {
+		c.Upvotes = q.Upvotes
+	} else {
+		c.CreatedAt = q.CreatedAt
+	}
+
+	return c
+}
@@ -1,16 +1,25 @@
 package model
 
+import "time"
+
 // Product model
 type GetProductsRequest struct {
 	Id int64 `json:"id" binding:"required"`
 }
 
+type GetProductBySlugRequest struct {
+	Slug string `json:"slug" binding:"required"`
+}
+
 type CreateProductRequest struct {
 	CategoryId  int64   `json:"category_id" binding:"required"`
 	Name        string  `json:"name" binding:"required,min=2,max=100"`
 	Description string  `json:"description" binding:"omitempty,max=5000"`
 	Price       float64 `json:"price" binding:"required,gt=0"`
 	Stock       int     `json:"stock" binding:"required,min=0"`
+	Draft       bool    `json:"draft"`
+	Sku         string  `json:"sku" binding:"omitempty,max=64"`
+	ImageUrl    string  `json:"image_url" binding:"omitempty,url,max=2048"`
 }
 
 type UpdateProductRequest struct {
@@ -19,18 +28,98 @@ type UpdateProductRequest struct {
 	Name        *string  `json:"name" binding:"required,min=2,max=100"`
 	Description *string  `json:"description" binding:"omitempty,max=5000"`
 	Price       *float64 `json:"price" binding:"required,gt=0"`
-	Stock       *int     `json:"stock" binding:"required,min=0"`
+	Stock       *int     `json:"stock" binding:"omitempty,min=0"`
+	ImageUrl    *string  `json:"image_url" binding:"omitempty,url,max=2048"`
 }
 
 type DeleteProductRequest struct {
 	Id int64 `json:"id" binding:"required"`
 }
 
+type RestockProductRequest struct {
+	Id       int64 `json:"id"`
+	Quantity int   `json:"quantity" binding:"required,min=1,max=1000000"`
+}
+
+type ArchiveProductRequest struct {
+	Id int64 `json:"id" binding:"required"`
+}
+
+type RestoreProductRequest struct {
+	Id int64 `json:"id" binding:"required"`
+}
+
+type ListProductsRequest struct {
+	Sort   string `form:"sort" binding:"omitempty,oneof=price_asc price_desc newest popular rating"`
+	Cursor string `form:"cursor" binding:"omitempty,max=512"`
+}
+
+type PriceHistoryRequest struct {
+	Id int64 `json:"id" binding:"required"`
+}
+
+type RecommendationsRequest struct {
+	Id int64 `json:"id" binding:"required"`
+}
+
+type SubmitProductRequest struct {
+	Id int64 `json:"id" binding:"required"`
+}
+
+type ModerateProductRequest struct {
+	Id     int64  `json:"id"`
+	Action string `json:"action" binding:"required,oneof=approve reject suspend"`
+	Reason string `json:"reason" binding:"omitempty,max=1000"`
+}
+
+type ModerationQueueRequest struct {
+	Cursor string `form:"cursor" binding:"omitempty,max=512"`
+}
+
 type SearchProductsRequest struct {
 	Text       *string  `form:"text" binding:"omitempty,min=1,max=100"`
 	CategoryId *int64   `form:"category_id" binding:"omitempty"`
 	Min        *float64 `form:"min" binding:"omitempty,gt=0"`
 	Max        *float64 `form:"max" binding:"omitempty,gt=0"`
+	Sort       string   `form:"sort" binding:"omitempty,oneof=price_asc price_desc newest popular rating"`
+	Cursor     string   `form:"cursor" binding:"omitempty,max=512"`
+}
+
+type SellerProductsRequest struct {
+	State      string `form:"state" binding:"omitempty,oneof=active archived deleted"`
+	Status     string `form:"status" binding:"omitempty,oneof=draft pending_review approved rejected suspended"`
+	Stock      string `form:"stock" binding:"omitempty,oneof=in_stock out_of_stock"`
+	CategoryId *int64 `form:"category_id" binding:"omitempty"`
+	Sort       string `form:"sort" binding:"omitempty,oneof=price_asc price_desc newest popular rating"`
+	Cursor     string `form:"cursor" binding:"omitempty,max=512"`
+}
+
+type StartImportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl"`
+}
+
+type GetImportJobRequest struct {
+	Id int64 `json:"id" binding:"required"`
+}
+
+type ExportProductsRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv yml"`
+}
+
+// Discount model
+type CreateDiscountRequest struct {
+	Scope      string    `json:"scope" binding:"required,oneof=product category seller"`
+	ProductId  *int64    `json:"product_id" binding:"omitempty,gt=0"`
+	CategoryId *int64    `json:"category_id" binding:"omitempty,gt=0"`
+	SellerId   *int64    `json:"seller_id" binding:"omitempty,gt=0"`
+	Kind       string    `json:"kind" binding:"required,oneof=fixed percent"`
+	Value      float64   `json:"value" binding:"required,gt=0"`
+	StartsAt   time.Time `json:"starts_at" binding:"required"`
+	EndsAt     time.Time `json:"ends_at" binding:"required,gtfield=StartsAt"`
+}
+
+type DeleteDiscountRequest struct {
+	Id int64 `json:"id" binding:"required"`
 }
 
 // User model
@@ -103,6 +192,7 @@ type GetCartByUserIdRequest struct {
 
 type GetCartItemsByCartIdRequest struct {
 	CartId int64 `json:"cart_id" binding:"required"`
+	UserId int64 `json:"-"`
 }
 
 // Order model
@@ -133,6 +223,11 @@ type DeleteOrderByIdRequest struct {
 	Id int64 `json:"id" binding:"required"`
 }
 
+type UpdateOrderStatusRequest struct {
+	Id     int64  `json:"-"`
+	Status string `json:"status" binding:"required"`
+}
+
 // Category model
 type CreateCategoryRequest struct {
 	Name        string `json:"name" binding:"required,min=2,max=100"`
@@ -152,3 +247,254 @@ type UpdateCategoryRequest struct {
 type DeleteCategoryRequest struct {
 	Id int64 `json:"id" binding:"required"`
 }
+
+// Moderation model
+type CreateModerationRuleRequest struct {
+	Kind        string `json:"kind" binding:"required,oneof=banned_word regex"`
+	Pattern     string `json:"pattern" binding:"required,min=1,max=500"`
+	Severity    string `json:"severity" binding:"required,oneof=low medium high"`
+	Description string `json:"description" binding:"omitempty,max=1000"`
+	Enabled     *bool  `json:"enabled"`
+}
+
+type UpdateModerationRuleRequest struct {
+	Id          int64   `json:"id"`
+	Pattern     *string `json:"pattern" binding:"omitempty,min=1,max=500"`
+	Severity    *string `json:"severity" binding:"omitempty,oneof=low medium high"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+type DeleteModerationRuleRequest struct {
+	Id int64 `json:"id" binding:"required"`
+}
+
+type GetProductFlagsRequest struct {
+	ProductId int64 `json:"product_id" binding:"required"`
+}
+
+// Review model
+type CreateReviewRequest struct {
+	ProductId int64    `json:"product_id"`
+	Rating    int      `json:"rating" binding:"required,min=1,max=5"`
+	Text      string   `json:"text" binding:"max=5000"`
+	Photos    []string `json:"photos" binding:"omitempty,max=10,dive,url,max=2048"`
+}
+
+type UpdateReviewRequest struct {
+	Id        int64     `json:"id"`
+	ProductId int64     `json:"product_id"`
+	Rating    *int      `json:"rating" binding:"omitempty,min=1,max=5"`
+	Text      *string   `json:"text" binding:"omitempty,max=5000"`
+	Photos    *[]string `json:"photos" binding:"omitempty,max=10,dive,url,max=2048"`
+}
+
+type DeleteReviewRequest struct {
+	Id        int64 `json:"id"`
+	ProductId int64 `json:"product_id"`
+}
+
+type ListReviewsRequest struct {
+	ProductId int64  `form:"-"`
+	Sort      string `form:"sort" binding:"omitempty,oneof=newest helpful"`
+	Cursor    string `form:"cursor"`
+}
+
+type ReplyReviewRequest struct {
+	Id        int64  `json:"id"`
+	ProductId int64  `json:"product_id"`
+	Text      string `json:"text" binding:"required,max=2000"`
+}
+
+type VoteReviewRequest struct {
+	Id        int64 `json:"id"`
+	ProductId int64 `json:"product_id"`
+}
+
+// Question model
+type AskQuestionRequest struct {
+	ProductId int64  `json:"product_id"`
+	Text      string `json:"text" binding:"required,min=3,max=2000"`
+}
+
+type ListQuestionsRequest struct {
+	ProductId int64  `form:"-"`
+	Sort      string `form:"sort" binding:"omitempty,oneof=newest top"`
+	Cursor    string `form:"cursor"`
+}
+
+type AnswerQuestionRequest struct {
+	Id        int64  `json:"id"`
+	ProductId int64  `json:"product_id"`
+	Text      string `json:"text" binding:"required,max=2000"`
+}
+
+type QuestionActionRequest struct {
+	Id        int64 `json:"id"`
+	ProductId int64 `json:"product_id"`
+}
+
+type QuestionInboxRequest struct {
+	Cursor string `form:"cursor"`
+}
+
+// Notification model
+type ListNotificationsRequest struct {
+	Unread bool   `form:"unread"`
+	Cursor string `form:"cursor"`
+}
+
+type ReadNotificationRequest struct {
+	Id int64 `json:"id"`
+}
+
+// Wishlist model
+type CreateWishlistRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type RenameWishlistRequest struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type WishlistRequest struct {
+	Id int64 `json:"id"`
+}
+
+type AddWishlistItemRequest struct {
+	WishlistId        int64 `json:"wishlist_id"`
+	ProductId         int64 `json:"product_id" binding:"required"`
+	NotifyPriceDrop   bool  `json:"notify_price_drop"`
+	NotifyBackInStock bool  `json:"notify_back_in_stock"`
+}
+
+type RemoveWishlistItemRequest struct {
+	WishlistId int64 `json:"wishlist_id"`
+	ProductId  int64 `json:"product_id"`
+}
+
+type SharedWishlistRequest struct {
+	Token string `json:"token"`
+}
+
+// Stock subscription model
+type StockSubscriptionRequest struct {
+	ProductId int64 `json:"product_id"`
+}
+
+// Low stock model
+type ListLowStockAlertsRequest struct {
+	Cursor string `form:"cursor"`
+}
+
+// LowStockThresholdRequest sets the threshold of a product, or the seller's default
+// when ProductId is zero. A nil threshold falls back to the next level.
+type LowStockThresholdRequest struct {
+	ProductId int64 `json:"product_id"`
+	Threshold *int  `json:"threshold" binding:"omitempty,min=0"`
+}
+
+// Inventory model
+type CreateWarehouseRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Priority int    `json:"priority"`
+}
+
+type UpdateWarehouseRequest struct {
+	Id       int64  `json:"id"`
+	Name     string `json:"name" binding:"required,max=100"`
+	Priority int    `json:"priority"`
+}
+
+type WarehouseRequest struct {
+	Id int64 `json:"id"`
+}
+
+type ProductInventoryRequest struct {
+	ProductId int64 `json:"product_id"`
+}
+
+type SetInventoryLevelRequest struct {
+	ProductId   int64 `json:"product_id"`
+	WarehouseId int64 `json:"warehouse_id"`
+	Quantity    *int  `json:"quantity" binding:"required,min=0"`
+}
+
+type TransferStockRequest struct {
+	ProductId       int64 `json:"product_id" binding:"required"`
+	FromWarehouseId int64 `json:"from_warehouse_id" binding:"required"`
+	ToWarehouseId   int64 `json:"to_warehouse_id" binding:"required"`
+	Quantity        int   `json:"quantity" binding:"required,min=1"`
+}
+
+type ProductMovementsRequest struct {
+	ProductId int64  `json:"product_id"`
+	Cursor    string `form:"cursor"`
+}
+
+type ReconciliationRequest struct {
+	Cursor string `form:"cursor"`
+}
+
+// BulkInventoryItemRequest changes the stock and/or the price of the product with the
+// given product_id or sku. Items are validated one by one, so a bad item doesn't fail the batch.
+type BulkInventoryItemRequest struct {
+	ProductId int64    `json:"product_id"`
+	Sku       string   `json:"sku"`
+	Stock     *int     `json:"stock"`
+	Price     *float64 `json:"price"`
+}
+
+type BulkInventoryRequest struct {
+	IdempotencyKey string                     `json:"-"`
+	Items          []BulkInventoryItemRequest `json:"items" binding:"required,min=1"`
+}
+
+type BundleRequest struct {
+	ProductId int64 `json:"product_id"`
+}
+
+type BundleComponentRequest struct {
+	ProductId int64 `json:"product_id" binding:"required"`
+	Quantity  int   `json:"quantity" binding:"required,min=1,max=1000"`
+}
+
+// SetBundleComponentsRequest replaces the composition of the bundle, an empty list turns
+// the bundle back into a regular product.
+type SetBundleComponentsRequest struct {
+	ProductId  int64                    `json:"product_id"`
+	Components []BundleComponentRequest `json:"components" binding:"required,max=20,dive"`
+}
+
+type PriceTiersRequest struct {
+	ProductId int64 `json:"product_id"`
+}
+
+type PriceTierRequest struct {
+	MinQuantity int     `json:"min_quantity" binding:"required,min=1"`
+	Price       float64 `json:"price" binding:"required,gt=0"`
+}
+
+// SetPriceTiersRequest replaces the tiers of one price list of the product, the tiers
+// every customer gets when PriceListId is nil. An empty list removes them.
+type SetPriceTiersRequest struct {
+	ProductId   int64              `json:"product_id"`
+	PriceListId *int64             `json:"price_list_id"`
+	Tiers       []PriceTierRequest `json:"tiers" binding:"required,max=10,dive"`
+}
+
+type CreatePriceListRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// AssignPriceListRequest puts the customer into the group of the price list, a nil
+// PriceListId takes them out of it.
+type AssignPriceListRequest struct {
+	UserId      int64  `json:"user_id" binding:"required"`
+	PriceListId *int64 `json:"price_list_id"`
+}
+
+type CatalogChangesRequest struct {
+	Since string `form:"since"`
+}
@@ -1,13 +1,60 @@
 package model
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type ProductResponse struct {
-	Id          int64   `json:"id"`
-	SellerId    int64   `json:"seller_id"`
-	CategoryId  int64   `json:"category_id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Stock       int     `json:"stock"`
+	Id              int64      `json:"id"`
+	SellerId        int64      `json:"seller_id"`
+	Sku             string     `json:"sku,omitempty"`
+	Slug            string     `json:"slug"`
+	CategoryId      int64      `json:"category_id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Price           float64    `json:"price"`
+	CurrentPrice    float64    `json:"current_price"`
+	DiscountId      *int64     `json:"discount_id,omitempty"`
+	DiscountEndsAt  *time.Time `json:"discount_ends_at,omitempty"`
+	LowestPrice30d  float64    `json:"lowest_price_30d"`
+	Rating          float64    `json:"rating"`
+	ReviewCount     int        `json:"review_count"`
+	Stock           int        `json:"stock"`
+	Bundle          bool       `json:"bundle"`
+	ImageUrl        string     `json:"image_url,omitempty"`
+	Status          string     `json:"status"`
+	RejectionReason string     `json:"rejection_reason,omitempty"`
+	ArchivedAt      *time.Time `json:"archived_at,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+
+	// PriceTiers are the quantity breaks every customer gets.
+	PriceTiers []PriceTierResponse `json:"price_tiers,omitempty"`
+}
+
+type DiscountResponse struct {
+	Id         int64     `json:"id"`
+	SellerId   int64     `json:"seller_id"`
+	Scope      string    `json:"scope"`
+	ProductId  *int64    `json:"product_id,omitempty"`
+	CategoryId *int64    `json:"category_id,omitempty"`
+	Kind       string    `json:"kind"`
+	Value      float64   `json:"value"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Active     bool      `json:"active"`
+}
+
+type PricePointResponse struct {
+	Price     float64   `json:"price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type SellerProductResponse struct {
+	ProductResponse
+	Views     int64   `json:"views"`
+	UnitsSold int64   `json:"units_sold"`
+	Revenue   float64 `json:"revenue"`
 }
 
 type UserResponse struct {
@@ -22,10 +69,12 @@ type CartResponse struct {
 }
 
 type CartItemResponse struct {
-	Id        int64 `json:"id"`
-	CartId    int64 `json:"cart_id"`
-	ProductId int64 `json:"product_id"`
-	Quantity  int64 `json:"quantity"`
+	Id        int64   `json:"id"`
+	CartId    int64   `json:"cart_id"`
+	ProductId int64   `json:"product_id"`
+	Quantity  int64   `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	Total     float64 `json:"total"`
 }
 
 type CategoryResponse struct {
@@ -47,4 +96,259 @@ type OrderItemResponse struct {
 	ProductId int64   `json:"product_id"`
 	Quantity  int     `json:"quantity"`
 	Price     float64 `json:"price"`
+
+	Components []OrderItemComponentResponse `json:"components,omitempty"`
+}
+
+// OrderItemComponentResponse is how many units of a product a bundle item took.
+type OrderItemComponentResponse struct {
+	ProductId int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+type ModerationRuleResponse struct {
+	Id          int64  `json:"id"`
+	Kind        string `json:"kind"`
+	Pattern     string `json:"pattern"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+}
+
+type ModerationFlagResponse struct {
+	Id        int64  `json:"id"`
+	ProductId int64  `json:"product_id"`
+	RuleId    *int64 `json:"rule_id"`
+	Code      string `json:"code"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+}
+
+type ImportJobResponse struct {
+	Id            int64      `json:"id"`
+	Format        string     `json:"format"`
+	Status        string     `json:"status"`
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"`
+	CreatedCount  int        `json:"created_count"`
+	UpdatedCount  int        `json:"updated_count"`
+	FailedCount   int        `json:"failed_count"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+type ImportRowErrorResponse struct {
+	RowNumber int    `json:"row_number"`
+	Sku       string `json:"sku"`
+	Reason    string `json:"reason"`
+}
+
+type ReviewResponse struct {
+	Id            int64      `json:"id"`
+	ProductId     int64      `json:"product_id"`
+	UserId        int64      `json:"user_id"`
+	Rating        int        `json:"rating"`
+	Text          string     `json:"text"`
+	Photos        []string   `json:"photos"`
+	SellerReply   string     `json:"seller_reply,omitempty"`
+	RepliedAt     *time.Time `json:"replied_at,omitempty"`
+	HelpfulCount  int        `json:"helpful_count"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	EditableUntil time.Time  `json:"editable_until"`
+}
+
+type QuestionResponse struct {
+	Id         int64      `json:"id"`
+	ProductId  int64      `json:"product_id"`
+	UserId     int64      `json:"user_id"`
+	Text       string     `json:"text"`
+	Answer     string     `json:"answer,omitempty"`
+	AnsweredAt *time.Time `json:"answered_at,omitempty"`
+	Upvotes    int        `json:"upvotes"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// FeedItemResponse is a product of the home feed with the reason it was picked.
+type FeedItemResponse struct {
+	ProductResponse
+	Source string `json:"source"`
+}
+
+type NotificationResponse struct {
+	Id        int64      `json:"id"`
+	Kind      string     `json:"kind"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body,omitempty"`
+	ProductId *int64     `json:"product_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+type WishlistResponse struct {
+	Id        int64     `json:"id"`
+	Name      string    `json:"name"`
+	ItemCount int       `json:"item_count"`
+	ShareUrl  string    `json:"share_url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WishlistItemResponse struct {
+	Product           ProductResponse `json:"product"`
+	NotifyPriceDrop   bool            `json:"notify_price_drop"`
+	NotifyBackInStock bool            `json:"notify_back_in_stock"`
+	AddedAt           time.Time       `json:"added_at"`
+}
+
+// WishlistDetailsResponse is the owner's view of a wishlist with its items.
+type WishlistDetailsResponse struct {
+	WishlistResponse
+	Items []WishlistItemResponse `json:"items"`
+}
+
+// SharedWishlistResponse is the public view of a wishlist opened by its share link.
+type SharedWishlistResponse struct {
+	Name  string            `json:"name"`
+	Items []ProductResponse `json:"items"`
+}
+
+type StockSubscriptionResponse struct {
+	ProductId int64     `json:"product_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type LowStockAlertResponse struct {
+	Id        int64     `json:"id"`
+	ProductId int64     `json:"product_id"`
+	Sku       string    `json:"sku"`
+	Name      string    `json:"name"`
+	Stock     int       `json:"stock"`
+	Threshold int       `json:"threshold"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LowStockThresholdResponse struct {
+	ProductId int64 `json:"product_id,omitempty"`
+	Threshold *int  `json:"threshold"`
+}
+
+type WarehouseResponse struct {
+	Id        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Priority  int       `json:"priority"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InventoryLevelResponse struct {
+	WarehouseId   int64  `json:"warehouse_id"`
+	WarehouseName string `json:"warehouse_name"`
+	Quantity      int    `json:"quantity"`
+}
+
+// ProductInventoryResponse is the stock of a product per warehouse, Stock is their sum.
+type ProductInventoryResponse struct {
+	ProductId int64                    `json:"product_id"`
+	Stock     int                      `json:"stock"`
+	Levels    []InventoryLevelResponse `json:"levels"`
+}
+
+type InventoryTransferResponse struct {
+	Id              int64     `json:"id"`
+	ProductId       int64     `json:"product_id"`
+	FromWarehouseId int64     `json:"from_warehouse_id"`
+	ToWarehouseId   int64     `json:"to_warehouse_id"`
+	Quantity        int       `json:"quantity"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type InventoryMovementResponse struct {
+	Id          int64     `json:"id"`
+	WarehouseId int64     `json:"warehouse_id,omitempty"`
+	Quantity    int       `json:"quantity"`
+	Reason      string    `json:"reason"`
+	ActorId     int64     `json:"actor_id,omitempty"`
+	Reference   string    `json:"reference,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StockDriftResponse flags a product whose stock doesn't match its ledger, Drift is
+// the stock minus the sum of the movements.
+type StockDriftResponse struct {
+	ProductId   int64  `json:"product_id"`
+	SellerId    int64  `json:"seller_id"`
+	Sku         string `json:"sku"`
+	Name        string `json:"name"`
+	Stock       int    `json:"stock"`
+	LedgerStock int    `json:"ledger_stock"`
+	Drift       int    `json:"drift"`
+}
+
+type BulkInventoryItemResponse struct {
+	Index     int    `json:"index"`
+	ProductId int64  `json:"product_id,omitempty"`
+	Sku       string `json:"sku,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkInventoryResponse is the outcome of every item in the order of the request.
+// Replayed tells that the result was stored for the same idempotency key earlier.
+type BulkInventoryResponse struct {
+	Replayed  bool                        `json:"replayed"`
+	Updated   int                         `json:"updated"`
+	Unchanged int                         `json:"unchanged"`
+	Failed    int                         `json:"failed"`
+	Items     []BulkInventoryItemResponse `json:"items"`
+}
+
+type CatalogChangeResponse struct {
+	Entity    string          `json:"entity"`
+	Id        int64           `json:"id"`
+	Op        string          `json:"op"`
+	Data      json.RawMessage `json:"data"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
+
+type BundleComponentResponse struct {
+	ProductId int64   `json:"product_id"`
+	Name      string  `json:"name"`
+	Slug      string  `json:"slug"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+	Stock     int     `json:"stock"`
+}
+
+// BundleResponse is the composition of a bundle. The bundle is sold at its own Price,
+// Stock is how many bundles the stock of the components makes up.
+type BundleResponse struct {
+	ProductId  int64                     `json:"product_id"`
+	Price      float64                   `json:"price"`
+	Stock      int                       `json:"stock"`
+	Components []BundleComponentResponse `json:"components"`
+}
+
+type PriceTierResponse struct {
+	MinQuantity int     `json:"min_quantity"`
+	Price       float64 `json:"price"`
+}
+
+// PriceListTiersResponse is the tiers of the product in one price list, the tiers every
+// customer gets when PriceListId is nil.
+type PriceListTiersResponse struct {
+	PriceListId *int64              `json:"price_list_id"`
+	Tiers       []PriceTierResponse `json:"tiers"`
+}
+
+type ProductPriceTiersResponse struct {
+	ProductId  int64                    `json:"product_id"`
+	Price      float64                  `json:"price"`
+	PriceLists []PriceListTiersResponse `json:"price_lists"`
+}
+
+type PriceListResponse struct {
+	Id        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
 }
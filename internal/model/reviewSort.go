@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	ReviewSortNewest  = "newest"
+	ReviewSortHelpful = "helpful"
+)
+
+// ReviewCursor is the keyset position of the last review on a page.
+type ReviewCursor struct {
+	Sort         string    `json:"s"`
+	Id           int64     `json:"id"`
+	CreatedAt    time.Time `json:"c"`
+	HelpfulCount int       `json:"h,omitempty"`
+}
+
+func NewReviewCursor(sort string, r *Review) ReviewCursor {
+	c := ReviewCursor{Sort: sort, Id: r.Id}
+	if sort == ReviewSortHelpful {
+		c.HelpfulCount = r.HelpfulCount
+	} else {
+		c.CreatedAt = r.CreatedAt
+	}
+
+	return c
+}
@@ -0,0 +1,18 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+// CanViewImportJob allows reading the progress and error report of an import
+// only to the seller who started it and to admins.
+func CanViewImportJob(userId int64, role string, job *model.ImportJob) error {
+	if role == RoleAdmin || job.SellerId == userId {
+		return nil
+	}
+
+	return fmt.Errorf("%w: import job %d belongs to another seller", errs.NotOwnerError, job.Id)
+}
@@ -0,0 +1,27 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const (
+	RoleAdmin  = "admin"
+	RoleSeller = "seller"
+)
+
+// CanManageProduct allows updating, deleting and restocking a product
+// only to the seller who owns it and to admins.
+func CanManageProduct(userId int64, role string, p *model.Product) error {
+	if role == RoleAdmin {
+		return nil
+	}
+
+	if role == RoleSeller && p.SellerId == userId {
+		return nil
+	}
+
+	return fmt.Errorf("%w: product %d belongs to another seller", errs.NotOwnerError, p.Id)
+}
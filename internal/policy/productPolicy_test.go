@@ -0,0 +1,36 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+func TestCanManageProduct(t *testing.T) {
+	p := &model.Product{Id: 1, SellerId: 7}
+	tests := []struct {
+		name    string
+		userId  int64
+		role    string
+		allowed bool
+	}{
+		{"owner", 7, RoleSeller, true},
+		{"admin", 1, RoleAdmin, true},
+		{"another seller", 8, RoleSeller, false},
+		{"owner without seller role", 7, "user", false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := CanManageProduct(tt.userId, tt.role, p)
+			if tt.allowed && err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !tt.allowed && (!errors.Is(err, errs.NotOwnerError) || !errors.Is(err, errs.ForbiddenError)) {
+				t.Fatalf("expected not owner error, got %v", err)
+			}
+		})
+	}
+}
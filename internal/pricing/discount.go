@@ -0,0 +1,70 @@
+package pricing
+
+import (
+	"math"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+// Target is what a discount rule is matched against.
+type Target struct {
+	ProductId  int64
+	SellerId   int64
+	CategoryId int64
+	Price      float64
+}
+
+// Matches reports whether the rule covers the target at the given moment.
+func Matches(d *model.Discount, t Target, now time.Time) bool {
+	if d.SellerId != t.SellerId || now.Before(d.StartsAt) || !now.Before(d.EndsAt) {
+		return false
+	}
+
+	switch d.Scope {
+	case model.DiscountScopeProduct:
+		return d.ProductId != nil && *d.ProductId == t.ProductId
+	case model.DiscountScopeCategory:
+		return d.CategoryId != nil && *d.CategoryId == t.CategoryId
+	case model.DiscountScopeSeller:
+		return true
+	}
+
+	return false
+}
+
+// Apply returns the price of the target under the rule. A fixed sale price above
+// the base price is not a discount and leaves the base price as is.
+func Apply(d *model.Discount, base float64) float64 {
+	var price float64
+	switch d.Kind {
+	case model.DiscountKindFixed:
+		price = d.Value
+	case model.DiscountKindPercent:
+		price = math.Round(base*(100-d.Value)) / 100
+	default:
+		return base
+	}
+
+	return math.Min(price, base)
+}
+
+// Resolve picks the effective price among overlapping rules: the lowest price
+// wins, so the customer always gets the best of the active promotions. It returns
+// the applied rule or nil when no rule lowers the price.
+func Resolve(t Target, rules []model.Discount, now time.Time) (float64, *model.Discount) {
+	price := t.Price
+	var applied *model.Discount
+	for i := range rules {
+		if !Matches(&rules[i], t, now) {
+			continue
+		}
+
+		if candidate := Apply(&rules[i], t.Price); candidate < price {
+			price = candidate
+			applied = &rules[i]
+		}
+	}
+
+	return price, applied
+}
@@ -0,0 +1,76 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+func TestResolve(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	productId, categoryId := int64(1), int64(5)
+	otherProduct := int64(2)
+	target := Target{ProductId: productId, SellerId: 7, CategoryId: categoryId, Price: 1000}
+	active := func(d model.Discount) model.Discount {
+		d.SellerId = 7
+		d.StartsAt = now.Add(-time.Hour)
+		d.EndsAt = now.Add(time.Hour)
+		return d
+	}
+
+	tests := []struct {
+		name        string
+		rules       []model.Discount
+		expected    float64
+		expectedIdx int
+	}{
+		{"no rules", nil, 1000, -1},
+		{"percent on seller", []model.Discount{
+			active(model.Discount{Id: 1, Scope: model.DiscountScopeSeller, Kind: model.DiscountKindPercent, Value: 15}),
+		}, 850, 0},
+		{"best of overlapping rules", []model.Discount{
+			active(model.Discount{Id: 1, Scope: model.DiscountScopeSeller, Kind: model.DiscountKindPercent, Value: 10}),
+			active(model.Discount{Id: 2, Scope: model.DiscountScopeProduct, ProductId: &productId, Kind: model.DiscountKindFixed, Value: 799}),
+			active(model.Discount{Id: 3, Scope: model.DiscountScopeCategory, CategoryId: &categoryId, Kind: model.DiscountKindPercent, Value: 20}),
+		}, 799, 1},
+		{"fixed price above base is ignored", []model.Discount{
+			active(model.Discount{Id: 1, Scope: model.DiscountScopeProduct, ProductId: &productId, Kind: model.DiscountKindFixed, Value: 1200}),
+		}, 1000, -1},
+		{"other product", []model.Discount{
+			active(model.Discount{Id: 1, Scope: model.DiscountScopeProduct, ProductId: &otherProduct, Kind: model.DiscountKindFixed, Value: 10}),
+		}, 1000, -1},
+		{"other seller", []model.Discount{
+			{Id: 1, SellerId: 8, Scope: model.DiscountScopeSeller, Kind: model.DiscountKindPercent, Value: 50,
+				StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+		}, 1000, -1},
+		{"not started and ended", []model.Discount{
+			{Id: 1, SellerId: 7, Scope: model.DiscountScopeSeller, Kind: model.DiscountKindPercent, Value: 50,
+				StartsAt: now.Add(time.Minute), EndsAt: now.Add(time.Hour)},
+			{Id: 2, SellerId: 7, Scope: model.DiscountScopeSeller, Kind: model.DiscountKindPercent, Value: 50,
+				StartsAt: now.Add(-time.Hour), EndsAt: now},
+		}, 1000, -1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			price, applied := Resolve(target, tt.rules, now)
+			if price != tt.expected {
+				t.Fatalf("price got %v want %v", price, tt.expected)
+			}
+			if tt.expectedIdx < 0 && applied != nil {
+				t.Fatalf("expected no rule, got %+v", applied)
+			}
+			if tt.expectedIdx >= 0 && (applied == nil || applied.Id != tt.rules[tt.expectedIdx].Id) {
+				t.Fatalf("unexpected rule %+v", applied)
+			}
+		})
+	}
+}
+
+func TestApply_RoundsPercent(t *testing.T) {
+	d := &model.Discount{Kind: model.DiscountKindPercent, Value: 33}
+	if got := Apply(d, 99.99); got != 66.99 {
+		t.Fatalf("got %v", got)
+	}
+}
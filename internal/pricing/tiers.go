@@ -0,0 +1,25 @@
+package pricing
+
+import "github.com/niklvrr/myMarketplace/internal/model"
+
+// TierPrice returns the unit price the tiers give the product at the quantity. Tiers
+// of several price lists may apply at once, the lowest price wins like with discounts.
+// It returns false when no tier starts at or below the quantity.
+func TierPrice(tiers []model.PriceTier, productId int64, quantity int) (float64, bool) {
+	var (
+		price float64
+		found bool
+	)
+	for _, t := range tiers {
+		if t.ProductId != productId || t.MinQuantity > quantity {
+			continue
+		}
+
+		if !found || t.Price < price {
+			price = t.Price
+			found = true
+		}
+	}
+
+	return price, found
+}
@@ -0,0 +1,43 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+func TestTierPrice(t *testing.T) {
+	wholesale := int64(3)
+	tiers := []model.PriceTier{
+		{ProductId: 1, MinQuantity: 10, Price: 90},
+		{ProductId: 1, MinQuantity: 50, Price: 80},
+		{ProductId: 1, PriceListId: &wholesale, MinQuantity: 1, Price: 95},
+		{ProductId: 1, PriceListId: &wholesale, MinQuantity: 20, Price: 85},
+		{ProductId: 2, MinQuantity: 2, Price: 10},
+	}
+
+	tests := []struct {
+		name      string
+		productId int64
+		quantity  int
+		expected  float64
+		found     bool
+	}{
+		{"below every tier", 2, 1, 0, false},
+		{"first tier", 2, 2, 10, true},
+		{"price list from one unit", 1, 1, 95, true},
+		{"best of overlapping lists", 1, 10, 90, true},
+		{"price list beats the public tier", 1, 20, 85, true},
+		{"highest tier", 1, 50, 80, true},
+		{"other product", 4, 100, 0, false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			price, found := TierPrice(tiers, tt.productId, tt.quantity)
+			if price != tt.expected || found != tt.found {
+				t.Fatalf("got %v %v want %v %v", price, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
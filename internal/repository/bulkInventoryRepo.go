@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var (
+	// A concurrent request with the same key waits here until the first one commits
+	// or rolls back, so a batch is never applied twice.
+	claimIdempotencyKeyQuery = `
+		INSERT INTO bulk_inventory_updates (seller_id, idempotency_key, request_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (seller_id, idempotency_key) DO NOTHING;`
+
+	getBulkInventoryUpdateQuery = `
+		SELECT request_hash, result
+		FROM bulk_inventory_updates
+		WHERE seller_id = $1 AND idempotency_key = $2;`
+
+	saveBulkInventoryResultQuery = `
+		UPDATE bulk_inventory_updates
+		SET result = $3
+		WHERE seller_id = $1 AND idempotency_key = $2;`
+
+	purgeBulkInventoryUpdatesQuery = `DELETE FROM bulk_inventory_updates WHERE created_at < $1;`
+
+	createBulkInventoryRowsTableQuery = `
+		CREATE TEMP TABLE bulk_inventory_rows (
+			idx INT NOT NULL,
+			product_id BIGINT,
+			sku TEXT,
+			stock INT,
+			price NUMERIC(10, 2)
+		) ON COMMIT DROP;`
+
+	// Items are found by id or by sku among the seller's products. The products are
+	// locked in the order of their ids, so concurrent batches don't deadlock.
+	lockBulkInventoryProductsQuery = `
+		SELECT r.idx, p.id, p.stock, p.price,
+		       EXISTS (SELECT 1 FROM inventory_levels il WHERE il.product_id = p.id),
+		       EXISTS (SELECT 1 FROM bundle_components bc WHERE bc.bundle_id = p.id)
+		FROM bulk_inventory_rows r
+		JOIN products p ON p.id = COALESCE(r.product_id, (SELECT s.id FROM products s WHERE s.seller_id = $1 AND s.sku = r.sku))
+		WHERE p.seller_id = $1 AND p.deleted_at IS NULL
+		ORDER BY p.id
+		FOR UPDATE OF p;`
+
+	applyBulkInventoryQuery = `
+		UPDATE products p
+		SET stock = COALESCE(r.stock, p.stock), price = COALESCE(r.price, p.price)
+		FROM unnest($1::int[], $2::bigint[]) AS u(idx, id)
+		JOIN bulk_inventory_rows r ON r.idx = u.idx
+		WHERE p.id = u.id;`
+)
+
+var (
+	bulkInventoryError      = errors.New("error applying bulk inventory update")
+	purgeBulkInventoryError = errors.New("error purging bulk inventory updates")
+)
+
+type BulkInventoryRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewBulkInventoryRepo(db *pgxpool.Pool) *BulkInventoryRepo {
+	return &BulkInventoryRepo{db: db}
+}
+
+// bulkProduct is the locked state of a product addressed by a bulk update item.
+type bulkProduct struct {
+	id         int64
+	stock      int
+	price      float64
+	warehoused bool
+	bundle     bool
+}
+
+// ApplyBulkInventoryUpdate applies the items in one transaction and fills the results.
+// With an idempotency key the results are stored, and a repeated request gets them back
+// with Replayed set instead of being applied again. It returns false when the key was
+// already used for a different request.
+func (r *BulkInventoryRepo) ApplyBulkInventoryUpdate(ctx context.Context, u *model.BulkInventoryUpdate) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", bulkInventoryError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	if u.IdempotencyKey != "" {
+		cmdTag, err := tx.Exec(ctx, claimIdempotencyKeyQuery, u.SellerId, u.IdempotencyKey, u.RequestHash, now)
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", bulkInventoryError, err)
+		}
+
+		if cmdTag.RowsAffected() == 0 {
+			return r.replay(ctx, tx, u)
+		}
+	}
+
+	products, err := r.lockProducts(ctx, tx, u)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", bulkInventoryError, err)
+	}
+
+	u.Results = bulkResults(u.Items, products)
+
+	var (
+		indexes   []int32
+		ids       []int64
+		movements []model.InventoryMovement
+	)
+	reference := "bulk"
+	if u.IdempotencyKey != "" {
+		reference = "bulk:" + u.IdempotencyKey
+	}
+	for _, res := range u.Results {
+		if res.Status != model.BulkItemUpdated {
+			continue
+		}
+
+		indexes = append(indexes, int32(res.Index))
+		ids = append(ids, res.ProductId)
+		movements = append(movements, model.InventoryMovement{
+			ProductId: res.ProductId,
+			Quantity:  res.Stock - res.PreviousStock,
+			Reason:    model.MovementAdjustment,
+			ActorId:   u.SellerId,
+			Reference: reference,
+			CreatedAt: now,
+		})
+	}
+
+	if len(ids) > 0 {
+		if _, err = tx.Exec(ctx, applyBulkInventoryQuery, indexes, ids); err != nil {
+			return false, fmt.Errorf("%w: %w", bulkInventoryError, err)
+		}
+
+		if err = recordMovements(ctx, tx, movements...); err != nil {
+			return false, fmt.Errorf("%w: %w", bulkInventoryError, err)
+		}
+	}
+
+	if u.IdempotencyKey != "" {
+		result, err := json.Marshal(u.Results)
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", bulkInventoryError, err)
+		}
+
+		if _, err = tx.Exec(ctx, saveBulkInventoryResultQuery, u.SellerId, u.IdempotencyKey, result); err != nil {
+			return false, fmt.Errorf("%w: %w", bulkInventoryError, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("%w: %w", bulkInventoryError, err)
+	}
+
+	return true, nil
+}
+
+func (r *BulkInventoryRepo) replay(ctx context.Context, tx pgx.Tx, u *model.BulkInventoryUpdate) (bool, error) {
+	var (
+		hash   string
+		result []byte
+	)
+	err := tx.QueryRow(ctx, getBulkInventoryUpdateQuery, u.SellerId, u.IdempotencyKey).Scan(&hash, &result)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", bulkInventoryError, err)
+	}
+
+	if hash != u.RequestHash {
+		return false, nil
+	}
+
+	if err = json.Unmarshal(result, &u.Results); err != nil {
+		return false, fmt.Errorf("%w: %w", bulkInventoryError, err)
+	}
+	u.Replayed = true
+
+	return true, nil
+}
+
+// lockProducts copies the items into a temporary table and locks the products they address.
+func (r *BulkInventoryRepo) lockProducts(ctx context.Context, tx pgx.Tx, u *model.BulkInventoryUpdate) (map[int]bulkProduct, error) {
+	if _, err := tx.Exec(ctx, createBulkInventoryRowsTableQuery); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]interface{}, 0, len(u.Items))
+	for _, item := range u.Items {
+		var productId, sku interface{}
+		if item.ProductId != 0 {
+			productId = item.ProductId
+		} else {
+			sku = item.Sku
+		}
+		rows = append(rows, []interface{}{item.Index, productId, sku, item.Stock, item.Price})
+	}
+
+	_, err := tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"bulk_inventory_rows"},
+		[]string{"idx", "product_id", "sku", "stock", "price"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	locked, err := tx.Query(ctx, lockBulkInventoryProductsQuery, u.SellerId)
+	if err != nil {
+		return nil, err
+	}
+	defer locked.Close()
+
+	products := make(map[int]bulkProduct, len(u.Items))
+	for locked.Next() {
+		var (
+			idx int
+			p   bulkProduct
+		)
+		if err = locked.Scan(&idx, &p.id, &p.stock, &p.price, &p.warehoused, &p.bundle); err != nil {
+			return nil, err
+		}
+		products[idx] = p
+	}
+
+	return products, locked.Err()
+}
+
+// bulkResults decides the outcome of every item. When several items address the same
+// product the last one wins, the stock of a warehoused product changes per warehouse only
+// and the stock of a bundle follows its components.
+func bulkResults(items []model.BulkInventoryItem, products map[int]bulkProduct) []model.BulkInventoryResult {
+	last := make(map[int64]int, len(products))
+	for _, item := range items {
+		if p, ok := products[item.Index]; ok {
+			last[p.id] = item.Index
+		}
+	}
+
+	results := make([]model.BulkInventoryResult, 0, len(items))
+	for _, item := range items {
+		res := model.BulkInventoryResult{Index: item.Index, ProductId: item.ProductId, Sku: item.Sku}
+		p, ok := products[item.Index]
+		if !ok {
+			res.Status = model.BulkItemNotFound
+			res.Error = "product not found"
+			results = append(results, res)
+			continue
+		}
+
+		res.ProductId = p.id
+		res.PreviousStock = p.stock
+		res.Stock = p.stock
+		stockChanged := item.Stock != nil && *item.Stock != p.stock
+		priceChanged := item.Price != nil && math.Round(*item.Price*100) != math.Round(p.price*100)
+		switch {
+		case last[p.id] != item.Index:
+			res.Status = model.BulkItemInvalid
+			res.Error = fmt.Sprintf("duplicate product, overridden by item %d", last[p.id])
+		case stockChanged && p.warehoused:
+			res.Status = model.BulkItemConflict
+			res.Error = "the product keeps its stock per warehouse"
+		case stockChanged && p.bundle:
+			res.Status = model.BulkItemConflict
+			res.Error = "the stock of a bundle comes from its components"
+		case !stockChanged && !priceChanged:
+			res.Status = model.BulkItemUnchanged
+		default:
+			res.Status = model.BulkItemUpdated
+			if item.Stock != nil {
+				res.Stock = *item.Stock
+			}
+		}
+		results = append(results, res)
+	}
+
+	return results
+}
+
+// PurgeBulkInventoryUpdates removes the stored results created before the given time,
+// their idempotency keys can be used again.
+func (r *BulkInventoryRepo) PurgeBulkInventoryUpdates(ctx context.Context, before time.Time) (int64, error) {
+	cmdTag, err := r.db.Exec(ctx, purgeBulkInventoryUpdatesQuery, before)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", purgeBulkInventoryError, err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var (
+	getBundleComponentsQuery = `
+		SELECT bc.component_id, bc.quantity, p.name, p.slug, p.price, p.stock
+		FROM bundle_components bc
+		JOIN products p ON p.id = bc.component_id
+		WHERE bc.bundle_id = $1
+		ORDER BY bc.component_id;`
+
+	getBundleCandidatesQuery = `
+		SELECT ` + productColumns + `
+		FROM products
+		WHERE id = ANY($1);`
+
+	// The compositions of one seller change one at a time, so two products can't be put
+	// into each other by concurrent requests.
+	lockSellerBundlesQuery = `SELECT pg_advisory_xact_lock(hashtext('bundles:' || $1::text));`
+
+	bundleNestingQuery = `
+		SELECT EXISTS (SELECT 1 FROM bundle_components WHERE component_id = $1)
+		    OR EXISTS (SELECT 1 FROM bundle_components WHERE bundle_id = ANY($2));`
+
+	deleteBundleComponentsQuery = `DELETE FROM bundle_components WHERE bundle_id = $1;`
+
+	createBundleComponentsQuery = `
+		INSERT INTO bundle_components (bundle_id, component_id, quantity)
+		SELECT $1, c.id, c.quantity
+		FROM unnest($2::bigint[], $3::int[]) AS c(id, quantity);`
+
+	// The trigger replaces the stock of a bundle with the one derived from its components.
+	// A product that stops being a bundle gets back its own stock, the one its ledger adds
+	// up to, since the sales of the bundle were recorded for the components.
+	refreshBundleStockQuery = `
+		UPDATE products
+		SET stock = COALESCE((SELECT SUM(m.quantity) FROM inventory_movements m WHERE m.product_id = products.id), 0)
+		WHERE id = $1
+		RETURNING stock;`
+)
+
+var (
+	getBundleComponentsError = errors.New("error getting bundle components")
+	getBundleCandidatesError = errors.New("error getting bundle candidates")
+	setBundleComponentsError = errors.New("error setting bundle components")
+)
+
+type BundleRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewBundleRepo(db *pgxpool.Pool) *BundleRepo {
+	return &BundleRepo{db: db}
+}
+
+// GetBundleComponents returns the components of the bundle with their current state,
+// none when the product isn't a bundle.
+func (r *BundleRepo) GetBundleComponents(ctx context.Context, bundleId int64) (*[]model.BundleComponent, error) {
+	rows, err := r.db.Query(ctx, getBundleComponentsQuery, bundleId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getBundleComponentsError, err)
+	}
+	defer rows.Close()
+
+	components := []model.BundleComponent{}
+	for rows.Next() {
+		c := model.BundleComponent{BundleId: bundleId}
+		if err = rows.Scan(&c.ProductId, &c.Quantity, &c.Name, &c.Slug, &c.Price, &c.Stock); err != nil {
+			return nil, fmt.Errorf("%w: %w", getBundleComponentsError, err)
+		}
+		components = append(components, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getBundleComponentsError, rowsIterationError, err)
+	}
+
+	return &components, nil
+}
+
+// GetBundleCandidates returns the products with the given ids whatever their status,
+// a bundle may be made of products that aren't listed on their own.
+func (r *BundleRepo) GetBundleCandidates(ctx context.Context, ids []int64) (*[]model.Product, error) {
+	rows, err := r.db.Query(ctx, getBundleCandidatesQuery, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getBundleCandidatesError, err)
+	}
+	defer rows.Close()
+
+	products := []model.Product{}
+	for rows.Next() {
+		var p model.Product
+		if err = scanProduct(rows, &p); err != nil {
+			return nil, fmt.Errorf("%w: %w", getBundleCandidatesError, err)
+		}
+		products = append(products, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getBundleCandidatesError, rowsIterationError, err)
+	}
+
+	return &products, nil
+}
+
+// SetBundleComponents replaces the components of the bundle in one transaction and
+// returns the stock of the product after the change. It returns false when the bundle
+// would contain a bundle or become part of one, nothing is changed then.
+func (r *BundleRepo) SetBundleComponents(ctx context.Context, sellerId, bundleId int64, components []model.BundleComponent) (int, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %w", setBundleComponentsError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, lockSellerBundlesQuery, sellerId); err != nil {
+		return 0, false, fmt.Errorf("%w: %w", setBundleComponentsError, err)
+	}
+
+	ids := make([]int64, 0, len(components))
+	quantities := make([]int32, 0, len(components))
+	for _, c := range components {
+		ids = append(ids, c.ProductId)
+		quantities = append(quantities, int32(c.Quantity))
+	}
+
+	if len(components) > 0 {
+		var nested bool
+		if err = tx.QueryRow(ctx, bundleNestingQuery, bundleId, ids).Scan(&nested); err != nil {
+			return 0, false, fmt.Errorf("%w: %w", setBundleComponentsError, err)
+		}
+
+		if nested {
+			return 0, false, nil
+		}
+	}
+
+	if _, err = tx.Exec(ctx, deleteBundleComponentsQuery, bundleId); err != nil {
+		return 0, false, fmt.Errorf("%w: %w", setBundleComponentsError, err)
+	}
+
+	if len(components) > 0 {
+		if _, err = tx.Exec(ctx, createBundleComponentsQuery, bundleId, ids, quantities); err != nil {
+			return 0, false, fmt.Errorf("%w: %w", setBundleComponentsError, err)
+		}
+	}
+
+	var stock int
+	if err = tx.QueryRow(ctx, refreshBundleStockQuery, bundleId).Scan(&stock); err != nil {
+		return 0, false, fmt.Errorf("%w: %w", setBundleComponentsError, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, false, fmt.Errorf("%w: %w", setBundleComponentsError, err)
+	}
+
+	return stock, true, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var (
+	// Every transaction below the horizon has finished, the ones at and above it may
+	// still add changes.
+	catalogHorizonQuery = `SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint;`
+
+	getCatalogChangesQuery = `
+		SELECT id, tx_id, entity, entity_id, op, data, created_at
+		FROM catalog_changes`
+
+	snapshotCategoriesQuery = `SELECT catalog_category(c) FROM categories c ORDER BY c.id;`
+
+	snapshotProductsQuery = `
+		SELECT catalog_product(p)
+		FROM products p
+		WHERE p.status = 'approved' AND p.archived_at IS NULL AND p.deleted_at IS NULL
+		ORDER BY p.id;`
+
+	purgeCatalogChangesQuery = `DELETE FROM catalog_changes WHERE created_at < $1;`
+)
+
+var (
+	getCatalogChangesError   = errors.New("error getting catalog changes")
+	catalogSnapshotError     = errors.New("error reading catalog snapshot")
+	purgeCatalogChangesError = errors.New("error purging catalog changes")
+)
+
+type CatalogChangeRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewCatalogChangeRepo(db *pgxpool.Pool) *CatalogChangeRepo {
+	return &CatalogChangeRepo{db: db}
+}
+
+// GetCatalogChanges returns a page of the changes after the cursor in the order of
+// their transactions, together with the horizon they were read at. Only the changes of
+// finished transactions are returned, so a change never shows up behind a cursor that
+// was already handed out.
+func (r *CatalogChangeRepo) GetCatalogChanges(ctx context.Context, after *model.CatalogChangeCursor, limit int) (*[]model.CatalogChange, int64, error) {
+	var horizon int64
+	if err := r.db.QueryRow(ctx, catalogHorizonQuery).Scan(&horizon); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", getCatalogChangesError, err)
+	}
+
+	where := []string{"tx_id < $1"}
+	args := []interface{}{horizon}
+	if after != nil {
+		where = append(where, "(tx_id, id) > ($2, $3)")
+		args = append(args, after.TxId, after.Id)
+	}
+
+	tail := fmt.Sprintf(" ORDER BY tx_id, id LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(ctx, getCatalogChangesQuery+whereClause(where)+tail, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", getCatalogChangesError, err)
+	}
+	defer rows.Close()
+
+	changes := []model.CatalogChange{}
+	for rows.Next() {
+		var c model.CatalogChange
+		if err = rows.Scan(&c.Id, &c.TxId, &c.Entity, &c.EntityId, &c.Op, &c.Data, &c.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", getCatalogChangesError, err)
+		}
+		changes = append(changes, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w(%w): %w", getCatalogChangesError, rowsIterationError, err)
+	}
+
+	return &changes, horizon, nil
+}
+
+// CatalogSnapshot reads the categories and the listed products from one consistent
+// snapshot and passes them to fn one by one, categories first. begin gets the horizon
+// of the snapshot before anything is read: the changes from that horizon on may or may
+// not be in the snapshot already.
+func (r *CatalogChangeRepo) CatalogSnapshot(ctx context.Context, begin func(horizon int64) error, fn func(entity string, data []byte) error) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("%w: %w", catalogSnapshotError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The first statement takes the snapshot, so the horizon belongs to it.
+	var horizon int64
+	if err = tx.QueryRow(ctx, catalogHorizonQuery).Scan(&horizon); err != nil {
+		return fmt.Errorf("%w: %w", catalogSnapshotError, err)
+	}
+
+	if err = begin(horizon); err != nil {
+		return err
+	}
+
+	if err = snapshotEntities(ctx, tx, snapshotCategoriesQuery, model.CatalogEntityCategory, fn); err != nil {
+		return err
+	}
+
+	return snapshotEntities(ctx, tx, snapshotProductsQuery, model.CatalogEntityProduct, fn)
+}
+
+func snapshotEntities(ctx context.Context, tx pgx.Tx, query, entity string, fn func(entity string, data []byte) error) error {
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("%w: %w", catalogSnapshotError, err)
+	}
+	defer rows.Close()
+
+	var data []byte
+	for rows.Next() {
+		if err = rows.Scan(&data); err != nil {
+			return fmt.Errorf("%w: %w", catalogSnapshotError, err)
+		}
+
+		if err = fn(entity, data); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%w(%w): %w", catalogSnapshotError, rowsIterationError, err)
+	}
+
+	return nil
+}
+
+// PurgeCatalogChanges removes the changes recorded before the given time.
+func (r *CatalogChangeRepo) PurgeCatalogChanges(ctx context.Context, before time.Time) (int64, error) {
+	cmdTag, err := r.db.Exec(ctx, purgeCatalogChangesQuery, before)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", purgeCatalogChangesError, err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const discountColumns = `id, seller_id, scope, product_id, category_id, kind, value, starts_at, ends_at, created_at`
+
+var (
+	createDiscountQuery = `
+		INSERT INTO discounts (seller_id, scope, product_id, category_id, kind, value, starts_at, ends_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id;`
+
+	getDiscountByIdQuery = `
+		SELECT ` + discountColumns + `
+		FROM discounts
+		WHERE id = $1;`
+
+	getDiscountsBySellerQuery = `
+		SELECT ` + discountColumns + `
+		FROM discounts
+		WHERE seller_id = $1
+		ORDER BY starts_at DESC, id DESC;`
+
+	getActiveDiscountsQuery = `
+		SELECT ` + discountColumns + `
+		FROM discounts
+		WHERE seller_id = ANY($1) AND starts_at <= $2 AND ends_at > $2;`
+
+	deleteDiscountQuery = `DELETE FROM discounts WHERE id = $1;`
+)
+
+var (
+	createDiscountError  = errors.New("error creating discount")
+	discountNotFound     = errors.New("discount not found")
+	getDiscountError     = errors.New("error getting discount")
+	getDiscountsError    = errors.New("error getting discounts")
+	activeDiscountsError = errors.New("error getting active discounts")
+	deleteDiscountError  = errors.New("error deleting discount")
+)
+
+type DiscountRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewDiscountRepo(db *pgxpool.Pool) *DiscountRepo {
+	return &DiscountRepo{db: db}
+}
+
+func (r *DiscountRepo) CreateDiscount(ctx context.Context, d *model.Discount) error {
+	d.CreatedAt = time.Now()
+	err := r.db.QueryRow(
+		ctx, createDiscountQuery,
+		d.SellerId, d.Scope, d.ProductId, d.CategoryId, d.Kind, d.Value, d.StartsAt, d.EndsAt, d.CreatedAt,
+	).Scan(&d.Id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", createDiscountError, err)
+	}
+
+	return nil
+}
+
+func (r *DiscountRepo) GetDiscountById(ctx context.Context, id int64) (*model.Discount, error) {
+	d := new(model.Discount)
+	err := scanDiscount(r.db.QueryRow(ctx, getDiscountByIdQuery, id), d)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %w", getDiscountError, discountNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getDiscountError, err)
+	}
+
+	return d, nil
+}
+
+func (r *DiscountRepo) GetDiscountsBySeller(ctx context.Context, sellerId int64) (*[]model.Discount, error) {
+	return r.queryDiscounts(ctx, getDiscountsError, getDiscountsBySellerQuery, sellerId)
+}
+
+// GetActiveDiscounts returns the rules of the given sellers that are in effect at the moment.
+func (r *DiscountRepo) GetActiveDiscounts(ctx context.Context, sellerIds []int64, at time.Time) (*[]model.Discount, error) {
+	return r.queryDiscounts(ctx, activeDiscountsError, getActiveDiscountsQuery, sellerIds, at)
+}
+
+func (r *DiscountRepo) DeleteDiscount(ctx context.Context, id int64) error {
+	cmdTag, err := r.db.Exec(ctx, deleteDiscountQuery, id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", deleteDiscountError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", deleteDiscountError, discountNotFound)
+	}
+
+	return nil
+}
+
+func (r *DiscountRepo) queryDiscounts(ctx context.Context, queryErr error, query string, args ...interface{}) (*[]model.Discount, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", queryErr, err)
+	}
+	defer rows.Close()
+
+	discounts := []model.Discount{}
+	for rows.Next() {
+		var d model.Discount
+		if err = scanDiscount(rows, &d); err != nil {
+			return nil, fmt.Errorf("%w: %w", queryErr, err)
+		}
+		discounts = append(discounts, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", queryErr, rowsIterationError, err)
+	}
+
+	return &discounts, nil
+}
+
+func scanDiscount(row pgx.Row, d *model.Discount) error {
+	return row.Scan(
+		&d.Id,
+		&d.SellerId,
+		&d.Scope,
+		&d.ProductId,
+		&d.CategoryId,
+		&d.Kind,
+		&d.Value,
+		&d.StartsAt,
+		&d.EndsAt,
+		&d.CreatedAt)
+}
@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const importJobColumns = `id, seller_id, format, status, total_rows, processed_rows, created_count, updated_count, failed_count, COALESCE(error, ''), created_at, finished_at`
+
+var (
+	createImportJobQuery = `
+		INSERT INTO product_import_jobs (seller_id, format, status, total_rows, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id;`
+
+	getImportJobByIdQuery = `
+		SELECT ` + importJobColumns + `
+		FROM product_import_jobs
+		WHERE id = $1;`
+
+	updateImportJobQuery = `
+		UPDATE product_import_jobs
+		SET status = $1, processed_rows = $2, created_count = $3, updated_count = $4,
+		    failed_count = $5, error = NULLIF($6, ''), finished_at = $7
+		WHERE id = $8;`
+
+	getImportErrorsQuery = `
+		SELECT job_id, row_number, COALESCE(sku, ''), reason
+		FROM product_import_errors
+		WHERE job_id = $1
+		ORDER BY row_number, id;`
+
+	createImportRowsTableQuery = `
+		CREATE TEMP TABLE product_import_rows (
+			sku TEXT NOT NULL,
+			category_id INT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL,
+			price NUMERIC(10, 2) NOT NULL,
+			stock INT NOT NULL,
+			status TEXT NOT NULL,
+			image_url TEXT NOT NULL,
+			rejection_reason TEXT NOT NULL
+		) ON COMMIT DROP;`
+
+	// The products of the batch are locked before the upsert, so the stock it reads
+	// for the ledger can't change until the products are written.
+	lockImportProductsQuery = `
+		SELECT p.id
+		FROM products p
+		JOIN product_import_rows i ON i.sku = p.sku
+		WHERE p.seller_id = $1
+		ORDER BY p.id
+		FOR UPDATE OF p;`
+
+	// Changing the name, description or category of a moderated product sends it
+	// back to review, the same way a regular update does. The stock of a warehoused
+	// product is kept, it changes per warehouse only, and so is the derived stock of a
+	// bundle. Every change of the stock is recorded in the ledger as an adjustment made
+	// by the seller.
+	upsertImportRowsQuery = `
+		WITH before AS (
+			SELECT p.id, p.stock
+			FROM products p
+			JOIN product_import_rows i ON i.sku = p.sku
+			WHERE p.seller_id = $1
+		), written AS (
+			INSERT INTO products (seller_id, category_id, name, description, price, stock, status, created_at, sku, image_url)
+			SELECT $1, i.category_id, i.name, NULLIF(i.description, ''), i.price, i.stock, i.status, $2, i.sku, NULLIF(i.image_url, '')
+			FROM product_import_rows i
+			JOIN categories c ON c.id = i.category_id
+			ON CONFLICT (seller_id, sku) WHERE sku IS NOT NULL DO UPDATE
+			SET category_id = EXCLUDED.category_id,
+			    name = EXCLUDED.name,
+			    description = EXCLUDED.description,
+			    price = EXCLUDED.price,
+			    stock = CASE
+			        WHEN EXISTS (SELECT 1 FROM inventory_levels il WHERE il.product_id = products.id)
+			          OR EXISTS (SELECT 1 FROM bundle_components bc WHERE bc.bundle_id = products.id)
+			        THEN products.stock
+			        ELSE EXCLUDED.stock
+			    END,
+			    image_url = EXCLUDED.image_url,
+			    status = CASE
+			        WHEN products.status IN ('approved', 'rejected')
+			         AND (products.name, COALESCE(products.description, ''), products.category_id)
+			             IS DISTINCT FROM (EXCLUDED.name, COALESCE(EXCLUDED.description, ''), EXCLUDED.category_id)
+			        THEN 'pending_review'
+			        ELSE products.status
+			    END,
+			    rejection_reason = CASE
+			        WHEN products.status IN ('approved', 'rejected')
+			         AND (products.name, COALESCE(products.description, ''), products.category_id)
+			             IS DISTINCT FROM (EXCLUDED.name, COALESCE(EXCLUDED.description, ''), EXCLUDED.category_id)
+			        THEN NULL
+			        ELSE products.rejection_reason
+			    END
+			WHERE products.deleted_at IS NULL
+			RETURNING id, sku, stock, status, (xmax = 0) AS created
+		), moved AS (
+			INSERT INTO inventory_movements (product_id, quantity, reason, actor_id, reference, created_at)
+			SELECT w.id, w.stock - COALESCE(b.stock, 0), $3, $1, $4, $2
+			FROM written w
+			LEFT JOIN before b ON b.id = w.id
+			WHERE w.stock <> COALESCE(b.stock, 0)
+		)
+		SELECT id, sku, status, created FROM written;`
+
+	// Rows the automated checks found a serious problem in are rejected once they
+	// are written, whether the product was created or updated. Drafts are checked
+	// when they are submitted.
+	rejectImportRowsQuery = `
+		UPDATE products p
+		SET status = 'rejected',
+		    rejection_reason = i.rejection_reason
+		FROM product_import_rows i
+		WHERE p.seller_id = $1
+		  AND p.sku = i.sku
+		  AND p.id = ANY($2)
+		  AND p.status <> 'draft'
+		  AND i.rejection_reason <> ''
+		RETURNING p.id;`
+)
+
+var (
+	createImportJobError  = errors.New("error creating import job")
+	importJobNotFound     = errors.New("import job not found")
+	getImportJobError     = errors.New("error getting import job")
+	updateImportJobError  = errors.New("error updating import job")
+	saveImportErrorsError = errors.New("error saving import errors")
+	getImportErrorsError  = errors.New("error getting import errors")
+	upsertProductsError   = errors.New("error upserting imported products")
+)
+
+type ImportRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewImportRepo(db *pgxpool.Pool) *ImportRepo {
+	return &ImportRepo{db: db}
+}
+
+func (r *ImportRepo) CreateImportJob(ctx context.Context, job *model.ImportJob) error {
+	job.CreatedAt = time.Now()
+	err := r.db.QueryRow(
+		ctx, createImportJobQuery,
+		job.SellerId, job.Format, job.Status, job.TotalRows, job.CreatedAt,
+	).Scan(&job.Id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", createImportJobError, err)
+	}
+
+	return nil
+}
+
+func (r *ImportRepo) GetImportJobById(ctx context.Context, id int64) (*model.ImportJob, error) {
+	var job model.ImportJob
+	err := r.db.QueryRow(ctx, getImportJobByIdQuery, id).Scan(
+		&job.Id, &job.SellerId, &job.Format, &job.Status, &job.TotalRows, &job.ProcessedRows,
+		&job.CreatedCount, &job.UpdatedCount, &job.FailedCount, &job.Error, &job.CreatedAt, &job.FinishedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %w", getImportJobError, importJobNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getImportJobError, err)
+	}
+
+	return &job, nil
+}
+
+// UpdateImportJob stores the progress counters and the status of the job.
+func (r *ImportRepo) UpdateImportJob(ctx context.Context, job *model.ImportJob) error {
+	_, err := r.db.Exec(
+		ctx, updateImportJobQuery,
+		job.Status, job.ProcessedRows, job.CreatedCount, job.UpdatedCount,
+		job.FailedCount, job.Error, job.FinishedAt, job.Id,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", updateImportJobError, err)
+	}
+
+	return nil
+}
+
+func (r *ImportRepo) SaveImportErrors(ctx context.Context, rowErrors []model.ImportRowError) error {
+	if len(rowErrors) == 0 {
+		return nil
+	}
+
+	rows := make([][]interface{}, 0, len(rowErrors))
+	for _, rowErr := range rowErrors {
+		rows = append(rows, []interface{}{rowErr.JobId, rowErr.RowNumber, rowErr.Sku, rowErr.Reason})
+	}
+
+	_, err := r.db.CopyFrom(
+		ctx,
+		pgx.Identifier{"product_import_errors"},
+		[]string{"job_id", "row_number", "sku", "reason"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", saveImportErrorsError, err)
+	}
+
+	return nil
+}
+
+func (r *ImportRepo) GetImportErrors(ctx context.Context, jobId int64) (*[]model.ImportRowError, error) {
+	rows, err := r.db.Query(ctx, getImportErrorsQuery, jobId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getImportErrorsError, err)
+	}
+	defer rows.Close()
+
+	var rowErrors []model.ImportRowError
+	for rows.Next() {
+		var rowErr model.ImportRowError
+		if err = rows.Scan(&rowErr.JobId, &rowErr.RowNumber, &rowErr.Sku, &rowErr.Reason); err != nil {
+			return nil, fmt.Errorf("%w: %w", getImportErrorsError, err)
+		}
+		rowErrors = append(rowErrors, rowErr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getImportErrorsError, rowsIterationError, err)
+	}
+
+	return &rowErrors, nil
+}
+
+// UpsertProductsBySku copies the batch into a temporary table and merges it into
+// products by (seller_id, sku) in one statement, then rejects the written products
+// that carry a rejection reason. The result maps every written sku to the product;
+// skus missing from the result were skipped because of an unknown category or a
+// deleted product.
+func (r *ImportRepo) UpsertProductsBySku(ctx context.Context, sellerId, jobId int64, products []model.Product) (map[string]model.ImportedProduct, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", upsertProductsError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, createImportRowsTableQuery); err != nil {
+		return nil, fmt.Errorf("%w: %w", upsertProductsError, err)
+	}
+
+	rows := make([][]interface{}, 0, len(products))
+	for _, p := range products {
+		rows = append(rows, []interface{}{p.Sku, p.CategoryId, p.Name, p.Description, p.Price, p.Stock, p.Status, p.ImageUrl, p.RejectionReason})
+	}
+
+	_, err = tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"product_import_rows"},
+		[]string{"sku", "category_id", "name", "description", "price", "stock", "status", "image_url", "rejection_reason"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", upsertProductsError, err)
+	}
+
+	if _, err = tx.Exec(ctx, lockImportProductsQuery, sellerId); err != nil {
+		return nil, fmt.Errorf("%w: %w", upsertProductsError, err)
+	}
+
+	reference := fmt.Sprintf("import:%d", jobId)
+	result, err := tx.Query(ctx, upsertImportRowsQuery, sellerId, time.Now(), model.MovementAdjustment, reference)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", upsertProductsError, err)
+	}
+
+	written := make(map[string]model.ImportedProduct, len(products))
+	ids := make([]int64, 0, len(products))
+	for result.Next() {
+		var p model.ImportedProduct
+		if err = result.Scan(&p.Id, &p.Sku, &p.Status, &p.Created); err != nil {
+			result.Close()
+			return nil, fmt.Errorf("%w: %w", upsertProductsError, err)
+		}
+		written[p.Sku] = p
+		ids = append(ids, p.Id)
+	}
+	result.Close()
+
+	if err = result.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", upsertProductsError, rowsIterationError, err)
+	}
+
+	rejected, err := tx.Query(ctx, rejectImportRowsQuery, sellerId, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", upsertProductsError, err)
+	}
+
+	rejectedIds := make(map[int64]bool)
+	for rejected.Next() {
+		var id int64
+		if err = rejected.Scan(&id); err != nil {
+			rejected.Close()
+			return nil, fmt.Errorf("%w: %w", upsertProductsError, err)
+		}
+		rejectedIds[id] = true
+	}
+	rejected.Close()
+
+	if err = rejected.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", upsertProductsError, rowsIterationError, err)
+	}
+
+	for sku, p := range written {
+		if rejectedIds[p.Id] {
+			p.Status = model.ProductStatusRejected
+			written[sku] = p
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %w", upsertProductsError, err)
+	}
+
+	return written, nil
+}
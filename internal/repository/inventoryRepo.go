@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var (
+	createWarehouseQuery = `
+		INSERT INTO warehouses (seller_id, name, priority, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (seller_id, name) DO NOTHING
+		RETURNING id;`
+
+	getWarehouseByIdQuery = `
+		SELECT id, seller_id, name, priority, created_at
+		FROM warehouses
+		WHERE id = $1;`
+
+	getSellerWarehousesQuery = `
+		SELECT id, seller_id, name, priority, created_at
+		FROM warehouses
+		WHERE seller_id = $1
+		ORDER BY priority, id;`
+
+	updateWarehouseQuery = `
+		UPDATE warehouses w
+		SET name = $1, priority = $2
+		WHERE w.id = $3
+		  AND NOT EXISTS (SELECT 1 FROM warehouses o WHERE o.seller_id = w.seller_id AND o.name = $1 AND o.id <> w.id);`
+
+	// Only a warehouse without stock can be deleted, its stock would vanish from the products otherwise.
+	deleteWarehouseQuery = `
+		DELETE FROM warehouses
+		WHERE id = $1
+		  AND NOT EXISTS (SELECT 1 FROM inventory_levels WHERE warehouse_id = $1 AND quantity > 0);`
+
+	getInventoryLevelsQuery = `
+		SELECT il.product_id, il.warehouse_id, w.name, il.quantity, il.updated_at
+		FROM inventory_levels il
+		JOIN warehouses w ON w.id = il.warehouse_id
+		WHERE il.product_id = $1
+		ORDER BY w.priority, w.id;`
+
+	// Every change of the levels locks the product row first, so concurrent changes
+	// of one product are applied one after another and the sum stays exact.
+	lockProductStockQuery = `
+		SELECT stock
+		FROM products
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE;`
+
+	getInventoryLevelQuery = `
+		SELECT quantity
+		FROM inventory_levels
+		WHERE product_id = $1 AND warehouse_id = $2;`
+
+	setInventoryLevelQuery = `
+		INSERT INTO inventory_levels (product_id, warehouse_id, quantity, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, warehouse_id) DO UPDATE
+		SET quantity = EXCLUDED.quantity, updated_at = EXCLUDED.updated_at;`
+
+	syncProductStockQuery = `
+		UPDATE products
+		SET stock = (SELECT COALESCE(SUM(quantity), 0) FROM inventory_levels WHERE product_id = $1)
+		WHERE id = $1
+		RETURNING stock;`
+
+	takeInventoryQuery = `
+		UPDATE inventory_levels
+		SET quantity = quantity - $1, updated_at = $4
+		WHERE product_id = $2 AND warehouse_id = $3 AND quantity >= $1;`
+
+	putInventoryQuery = `
+		INSERT INTO inventory_levels (product_id, warehouse_id, quantity, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, warehouse_id) DO UPDATE
+		SET quantity = inventory_levels.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at;`
+
+	createInventoryTransferQuery = `
+		INSERT INTO inventory_transfers (product_id, from_warehouse_id, to_warehouse_id, quantity, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;`
+)
+
+var (
+	createWarehouseError   = errors.New("error creating warehouse")
+	getWarehouseError      = errors.New("error getting warehouse")
+	updateWarehouseError   = errors.New("error updating warehouse")
+	deleteWarehouseError   = errors.New("error deleting warehouse")
+	getInventoryError      = errors.New("error getting inventory levels")
+	setInventoryLevelError = errors.New("error setting inventory level")
+	transferStockError     = errors.New("error transferring stock")
+)
+
+type InventoryRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewInventoryRepo(db *pgxpool.Pool) *InventoryRepo {
+	return &InventoryRepo{db: db}
+}
+
+// CreateWarehouse returns false when the seller already has a warehouse with this name.
+func (r *InventoryRepo) CreateWarehouse(ctx context.Context, w *model.Warehouse) (bool, error) {
+	w.CreatedAt = time.Now()
+	err := r.db.QueryRow(ctx, createWarehouseQuery, w.SellerId, w.Name, w.Priority, w.CreatedAt).Scan(&w.Id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", createWarehouseError, err)
+	}
+
+	return true, nil
+}
+
+// GetWarehouseById returns nil when the warehouse doesn't exist.
+func (r *InventoryRepo) GetWarehouseById(ctx context.Context, id int64) (*model.Warehouse, error) {
+	w := new(model.Warehouse)
+	err := r.db.QueryRow(ctx, getWarehouseByIdQuery, id).Scan(&w.Id, &w.SellerId, &w.Name, &w.Priority, &w.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getWarehouseError, err)
+	}
+
+	return w, nil
+}
+
+func (r *InventoryRepo) GetSellerWarehouses(ctx context.Context, sellerId int64) (*[]model.Warehouse, error) {
+	rows, err := r.db.Query(ctx, getSellerWarehousesQuery, sellerId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getWarehouseError, err)
+	}
+	defer rows.Close()
+
+	warehouses := []model.Warehouse{}
+	for rows.Next() {
+		var w model.Warehouse
+		if err = rows.Scan(&w.Id, &w.SellerId, &w.Name, &w.Priority, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", getWarehouseError, err)
+		}
+		warehouses = append(warehouses, w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getWarehouseError, rowsIterationError, err)
+	}
+
+	return &warehouses, nil
+}
+
+// UpdateWarehouse returns false when another warehouse of the seller has this name.
+func (r *InventoryRepo) UpdateWarehouse(ctx context.Context, w *model.Warehouse) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, updateWarehouseQuery, w.Name, w.Priority, w.Id)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", updateWarehouseError, err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// DeleteWarehouse returns false when the warehouse still has stock.
+func (r *InventoryRepo) DeleteWarehouse(ctx context.Context, id int64) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, deleteWarehouseQuery, id)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", deleteWarehouseError, err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+func (r *InventoryRepo) GetInventoryLevels(ctx context.Context, productId int64) (*[]model.InventoryLevel, error) {
+	rows, err := r.db.Query(ctx, getInventoryLevelsQuery, productId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getInventoryError, err)
+	}
+	defer rows.Close()
+
+	levels := []model.InventoryLevel{}
+	for rows.Next() {
+		var l model.InventoryLevel
+		if err = rows.Scan(&l.ProductId, &l.WarehouseId, &l.WarehouseName, &l.Quantity, &l.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", getInventoryError, err)
+		}
+		levels = append(levels, l)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getInventoryError, rowsIterationError, err)
+	}
+
+	return &levels, nil
+}
+
+// SetInventoryLevel sets the stock of the product in the warehouse and recomputes the
+// product stock. It returns the product stock before and after the change. The change is
+// recorded as an adjustment of the warehouse; stock set on the product before its first
+// level is written off without a warehouse.
+func (r *InventoryRepo) SetInventoryLevel(ctx context.Context, level *model.InventoryLevel, userId int64) (int, int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", setInventoryLevelError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var before int
+	err = tx.QueryRow(ctx, lockProductStockQuery, level.ProductId).Scan(&before)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, fmt.Errorf("%w: %w", setInventoryLevelError, productNotFound)
+	}
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", setInventoryLevelError, err)
+	}
+
+	var current int
+	err = tx.QueryRow(ctx, getInventoryLevelQuery, level.ProductId, level.WarehouseId).Scan(&current)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, fmt.Errorf("%w: %w", setInventoryLevelError, err)
+	}
+
+	level.UpdatedAt = time.Now()
+	_, err = tx.Exec(ctx, setInventoryLevelQuery, level.ProductId, level.WarehouseId, level.Quantity, level.UpdatedAt)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", setInventoryLevelError, err)
+	}
+
+	var after int
+	if err = tx.QueryRow(ctx, syncProductStockQuery, level.ProductId).Scan(&after); err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", setInventoryLevelError, err)
+	}
+
+	movement := model.InventoryMovement{
+		ProductId: level.ProductId,
+		Reason:    model.MovementAdjustment,
+		ActorId:   userId,
+		CreatedAt: level.UpdatedAt,
+	}
+	changed := []warehouseQuantity{{warehouseId: level.WarehouseId, quantity: level.Quantity - current}}
+	if err = recordMovements(ctx, tx, stockMovements(movement, after-before, changed)...); err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", setInventoryLevelError, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", setInventoryLevelError, err)
+	}
+
+	return before, after, nil
+}
+
+// TransferStock moves stock of the product between two warehouses, the product stock
+// doesn't change and the ledger gets a pair of movements that cancel out. It returns false when the source warehouse doesn't have enough stock.
+func (r *InventoryRepo) TransferStock(ctx context.Context, t *model.InventoryTransfer) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", transferStockError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var stock int
+	err = tx.QueryRow(ctx, lockProductStockQuery, t.ProductId).Scan(&stock)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("%w: %w", transferStockError, productNotFound)
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", transferStockError, err)
+	}
+
+	t.CreatedAt = time.Now()
+	cmdTag, err := tx.Exec(ctx, takeInventoryQuery, t.Quantity, t.ProductId, t.FromWarehouseId, t.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", transferStockError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if _, err = tx.Exec(ctx, putInventoryQuery, t.ProductId, t.ToWarehouseId, t.Quantity, t.CreatedAt); err != nil {
+		return false, fmt.Errorf("%w: %w", transferStockError, err)
+	}
+
+	err = tx.QueryRow(
+		ctx, createInventoryTransferQuery,
+		t.ProductId, t.FromWarehouseId, t.ToWarehouseId, t.Quantity, t.UserId, t.CreatedAt,
+	).Scan(&t.Id)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", transferStockError, err)
+	}
+
+	movement := model.InventoryMovement{
+		ProductId: t.ProductId,
+		Reason:    model.MovementTransfer,
+		ActorId:   t.UserId,
+		Reference: fmt.Sprintf("transfer:%d", t.Id),
+		CreatedAt: t.CreatedAt,
+	}
+	moved := stockMovements(movement, 0, []warehouseQuantity{
+		{warehouseId: t.FromWarehouseId, quantity: -t.Quantity},
+		{warehouseId: t.ToWarehouseId, quantity: t.Quantity},
+	})
+	if err = recordMovements(ctx, tx, moved...); err != nil {
+		return false, fmt.Errorf("%w: %w", transferStockError, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("%w: %w", transferStockError, err)
+	}
+
+	return true, nil
+}
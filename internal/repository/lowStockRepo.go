@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+// lowStockThreshold is the threshold in effect for a product: its own, the seller's
+// default or the default passed as $1.
+const lowStockThreshold = `COALESCE(p.low_stock_threshold, s.low_stock_threshold, $1)`
+
+var (
+	createLowStockAlertsQuery = `
+		INSERT INTO low_stock_alerts (seller_id, product_id, stock, threshold, created_at)
+		SELECT p.seller_id, p.id, p.stock, ` + lowStockThreshold + `, $3
+		FROM products p
+		LEFT JOIN seller_inventory_settings s ON s.seller_id = p.seller_id
+		WHERE p.id = ANY($2) AND p.deleted_at IS NULL AND p.stock <= ` + lowStockThreshold + `
+		ON CONFLICT (product_id) WHERE resolved_at IS NULL DO NOTHING;`
+
+	// An alert is resolved once the stock is back above the threshold or the product is deleted.
+	resolveLowStockAlertsQuery = `
+		UPDATE low_stock_alerts a
+		SET resolved_at = $2
+		FROM products p
+		LEFT JOIN seller_inventory_settings s ON s.seller_id = p.seller_id
+		WHERE a.product_id = p.id AND a.resolved_at IS NULL
+		  AND (p.stock > ` + lowStockThreshold + ` OR p.deleted_at IS NOT NULL);`
+
+	getLowStockAlertsQuery = `
+		SELECT a.id, a.seller_id, a.product_id, COALESCE(p.sku, ''), p.name, p.stock, ` + lowStockThreshold + `, a.created_at
+		FROM low_stock_alerts a
+		JOIN products p ON p.id = a.product_id
+		LEFT JOIN seller_inventory_settings s ON s.seller_id = p.seller_id`
+
+	setSellerLowStockThresholdQuery = `
+		INSERT INTO seller_inventory_settings (seller_id, low_stock_threshold, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (seller_id) DO UPDATE
+		SET low_stock_threshold = EXCLUDED.low_stock_threshold, updated_at = EXCLUDED.updated_at;`
+
+	clearSellerLowStockThresholdQuery = `DELETE FROM seller_inventory_settings WHERE seller_id = $1;`
+
+	setProductLowStockThresholdQuery = `
+		UPDATE products
+		SET low_stock_threshold = $1
+		WHERE id = $2 AND deleted_at IS NULL;`
+)
+
+var (
+	createLowStockAlertsError  = errors.New("error creating low stock alerts")
+	resolveLowStockAlertsError = errors.New("error resolving low stock alerts")
+	getLowStockAlertsError     = errors.New("error getting low stock alerts")
+	setLowStockThresholdError  = errors.New("error setting low stock threshold")
+)
+
+type LowStockRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewLowStockRepo(db *pgxpool.Pool) *LowStockRepo {
+	return &LowStockRepo{db: db}
+}
+
+// CreateLowStockAlerts opens an alert for every given product at or below its threshold
+// that doesn't have an open alert yet, and returns how many were opened.
+func (r *LowStockRepo) CreateLowStockAlerts(ctx context.Context, productIds []int64, defaultThreshold int) (int64, error) {
+	cmdTag, err := r.db.Exec(ctx, createLowStockAlertsQuery, defaultThreshold, productIds, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", createLowStockAlertsError, err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// ResolveLowStockAlerts closes the alerts of restocked and deleted products and returns how many were closed.
+func (r *LowStockRepo) ResolveLowStockAlerts(ctx context.Context, defaultThreshold int) (int64, error) {
+	cmdTag, err := r.db.Exec(ctx, resolveLowStockAlertsQuery, defaultThreshold, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", resolveLowStockAlertsError, err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// GetSellerLowStockAlerts returns a page of the seller's open alerts, newest first.
+// Products restocked since the alert was opened are skipped.
+func (r *LowStockRepo) GetSellerLowStockAlerts(ctx context.Context, sellerId int64, defaultThreshold int, cursor *model.LowStockCursor, limit int) (*[]model.LowStockAlert, error) {
+	where := []string{"a.resolved_at IS NULL", "p.deleted_at IS NULL", "p.stock <= " + lowStockThreshold, "a.seller_id = $2"}
+	args := []interface{}{defaultThreshold, sellerId}
+	if cursor != nil {
+		where = append(where, fmt.Sprintf("a.id < $%d", len(args)+1))
+		args = append(args, cursor.Id)
+	}
+
+	tail := fmt.Sprintf(" ORDER BY a.id DESC LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	return r.queryLowStockAlerts(ctx, getLowStockAlertsQuery+whereClause(where)+tail, args...)
+}
+
+// GetOpenLowStockAlerts returns a page of the open alerts of all sellers ordered by seller,
+// so the alerts of one seller come together.
+func (r *LowStockRepo) GetOpenLowStockAlerts(ctx context.Context, defaultThreshold int, afterSellerId, afterId int64, limit int) (*[]model.LowStockAlert, error) {
+	where := []string{"a.resolved_at IS NULL", "p.deleted_at IS NULL", "p.stock <= " + lowStockThreshold, "(a.seller_id, a.id) > ($2, $3)"}
+	tail := " ORDER BY a.seller_id, a.id LIMIT $4"
+
+	return r.queryLowStockAlerts(ctx, getLowStockAlertsQuery+whereClause(where)+tail, defaultThreshold, afterSellerId, afterId, limit)
+}
+
+func (r *LowStockRepo) queryLowStockAlerts(ctx context.Context, query string, args ...interface{}) (*[]model.LowStockAlert, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getLowStockAlertsError, err)
+	}
+	defer rows.Close()
+
+	alerts := []model.LowStockAlert{}
+	for rows.Next() {
+		var a model.LowStockAlert
+		err = rows.Scan(&a.Id, &a.SellerId, &a.ProductId, &a.Sku, &a.Name, &a.Stock, &a.Threshold, &a.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", getLowStockAlertsError, err)
+		}
+		alerts = append(alerts, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getLowStockAlertsError, rowsIterationError, err)
+	}
+
+	return &alerts, nil
+}
+
+// SetSellerLowStockThreshold sets the seller's default threshold, a nil threshold removes it.
+func (r *LowStockRepo) SetSellerLowStockThreshold(ctx context.Context, sellerId int64, threshold *int) error {
+	var err error
+	if threshold == nil {
+		_, err = r.db.Exec(ctx, clearSellerLowStockThresholdQuery, sellerId)
+	} else {
+		_, err = r.db.Exec(ctx, setSellerLowStockThresholdQuery, sellerId, *threshold, time.Now())
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", setLowStockThresholdError, err)
+	}
+
+	return nil
+}
+
+// SetProductLowStockThreshold sets the product's own threshold, a nil threshold falls back to the seller's.
+// It returns false when the product doesn't exist.
+func (r *LowStockRepo) SetProductLowStockThreshold(ctx context.Context, productId int64, threshold *int) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, setProductLowStockThresholdQuery, threshold, productId)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", setLowStockThresholdError, err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
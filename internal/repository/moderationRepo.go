@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var (
+	createModerationRuleQuery = `
+		INSERT INTO moderation_rules (kind, pattern, severity, description, enabled, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;`
+
+	getModerationRuleByIdQuery = `
+		SELECT id, kind, pattern, severity, COALESCE(description, ''), enabled, created_at
+		FROM moderation_rules
+		WHERE id = $1;`
+
+	getAllModerationRulesQuery = `
+		SELECT id, kind, pattern, severity, COALESCE(description, ''), enabled, created_at
+		FROM moderation_rules
+		ORDER BY id;`
+
+	getEnabledModerationRulesQuery = `
+		SELECT id, kind, pattern, severity, COALESCE(description, ''), enabled, created_at
+		FROM moderation_rules
+		WHERE enabled
+		ORDER BY id;`
+
+	updateModerationRuleQuery = `
+		UPDATE moderation_rules
+		SET pattern = $1, severity = $2, description = $3, enabled = $4
+		WHERE id = $5;`
+
+	deleteModerationRuleQuery = `DELETE FROM moderation_rules WHERE id = $1;`
+
+	categoryMedianPriceQuery = `
+		SELECT COUNT(*), COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY price), 0)
+		FROM products
+		WHERE category_id = $1 AND status = 'approved' AND id <> $2
+		  AND archived_at IS NULL AND deleted_at IS NULL;`
+
+	deleteProductFlagsQuery = `DELETE FROM product_moderation_flags WHERE product_id = $1;`
+
+	getProductFlagsQuery = `
+		SELECT id, product_id, rule_id, code, severity, message, created_at
+		FROM product_moderation_flags
+		WHERE product_id = $1
+		ORDER BY id;`
+)
+
+var (
+	createModerationRuleError = errors.New("error creating moderation rule")
+	moderationRuleNotFound    = errors.New("moderation rule not found")
+	getModerationRulesError   = errors.New("error getting moderation rules")
+	updateModerationRuleError = errors.New("error updating moderation rule")
+	deleteModerationRuleError = errors.New("error deleting moderation rule")
+	categoryMedianPriceError  = errors.New("error getting category median price")
+	saveProductFlagsError     = errors.New("error saving product moderation flags")
+	getProductFlagsError      = errors.New("error getting product moderation flags")
+)
+
+type ModerationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewModerationRepo(db *pgxpool.Pool) *ModerationRepo {
+	return &ModerationRepo{db: db}
+}
+
+func (r *ModerationRepo) CreateRule(ctx context.Context, rule *model.ModerationRule) error {
+	rule.CreatedAt = time.Now()
+	err := r.db.QueryRow(
+		ctx, createModerationRuleQuery,
+		rule.Kind,
+		rule.Pattern,
+		rule.Severity,
+		rule.Description,
+		rule.Enabled,
+		rule.CreatedAt,
+	).Scan(&rule.Id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", createModerationRuleError, err)
+	}
+
+	return nil
+}
+
+func (r *ModerationRepo) GetRuleById(ctx context.Context, id int64) (*model.ModerationRule, error) {
+	rule := new(model.ModerationRule)
+	err := r.db.QueryRow(ctx, getModerationRuleByIdQuery, id).Scan(
+		&rule.Id,
+		&rule.Kind,
+		&rule.Pattern,
+		&rule.Severity,
+		&rule.Description,
+		&rule.Enabled,
+		&rule.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", moderationRuleNotFound, err)
+	}
+
+	return rule, nil
+}
+
+func (r *ModerationRepo) GetAllRules(ctx context.Context) (*[]model.ModerationRule, error) {
+	return r.queryRules(ctx, getAllModerationRulesQuery)
+}
+
+func (r *ModerationRepo) GetEnabledRules(ctx context.Context) (*[]model.ModerationRule, error) {
+	return r.queryRules(ctx, getEnabledModerationRulesQuery)
+}
+
+func (r *ModerationRepo) queryRules(ctx context.Context, query string) (*[]model.ModerationRule, error) {
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getModerationRulesError, err)
+	}
+	defer rows.Close()
+
+	var rules []model.ModerationRule
+	for rows.Next() {
+		var rule model.ModerationRule
+		err = rows.Scan(
+			&rule.Id,
+			&rule.Kind,
+			&rule.Pattern,
+			&rule.Severity,
+			&rule.Description,
+			&rule.Enabled,
+			&rule.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", getModerationRulesError, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getModerationRulesError, rowsIterationError, err)
+	}
+
+	return &rules, nil
+}
+
+func (r *ModerationRepo) UpdateRule(ctx context.Context, rule *model.ModerationRule) error {
+	cmdTag, err := r.db.Exec(
+		ctx, updateModerationRuleQuery,
+		rule.Pattern,
+		rule.Severity,
+		rule.Description,
+		rule.Enabled,
+		rule.Id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", updateModerationRuleError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", updateModerationRuleError, moderationRuleNotFound)
+	}
+
+	return nil
+}
+
+func (r *ModerationRepo) DeleteRule(ctx context.Context, id int64) error {
+	cmdTag, err := r.db.Exec(ctx, deleteModerationRuleQuery, id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", deleteModerationRuleError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", deleteModerationRuleError, moderationRuleNotFound)
+	}
+
+	return nil
+}
+
+// GetCategoryMedianPrice returns the median price of approved products in the category
+// together with the sample size, excluding the product being checked.
+func (r *ModerationRepo) GetCategoryMedianPrice(ctx context.Context, categoryId, excludeProductId int64) (float64, int, error) {
+	var samples int
+	var median float64
+	err := r.db.QueryRow(ctx, categoryMedianPriceQuery, categoryId, excludeProductId).Scan(&samples, &median)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %w", categoryMedianPriceError, err)
+	}
+
+	return median, samples, nil
+}
+
+// SaveFlags replaces the flags of the product with the result of the latest check.
+func (r *ModerationRepo) SaveFlags(ctx context.Context, productId int64, flags []model.ModerationFlag) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", saveProductFlagsError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, deleteProductFlagsQuery, productId); err != nil {
+		return fmt.Errorf("%w: %w", saveProductFlagsError, err)
+	}
+
+	now := time.Now()
+	rows := make([][]interface{}, 0, len(flags))
+	for _, flag := range flags {
+		rows = append(rows, []interface{}{productId, flag.RuleId, flag.Code, flag.Severity, flag.Message, now})
+	}
+
+	_, err = tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"product_moderation_flags"},
+		[]string{"product_id", "rule_id", "code", "severity", "message", "created_at"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", saveProductFlagsError, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: %w", saveProductFlagsError, err)
+	}
+
+	return nil
+}
+
+func (r *ModerationRepo) GetFlagsByProductId(ctx context.Context, productId int64) (*[]model.ModerationFlag, error) {
+	rows, err := r.db.Query(ctx, getProductFlagsQuery, productId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getProductFlagsError, err)
+	}
+	defer rows.Close()
+
+	var flags []model.ModerationFlag
+	for rows.Next() {
+		var flag model.ModerationFlag
+		err = rows.Scan(
+			&flag.Id,
+			&flag.ProductId,
+			&flag.RuleId,
+			&flag.Code,
+			&flag.Severity,
+			&flag.Message,
+			&flag.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", getProductFlagsError, err)
+		}
+
+		flags = append(flags, flag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getProductFlagsError, rowsIterationError, err)
+	}
+
+	return &flags, nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var (
+	getProductMovementsQuery = `
+		SELECT id, product_id, COALESCE(warehouse_id, 0), quantity, reason, COALESCE(actor_id, 0), COALESCE(reference, ''), created_at
+		FROM inventory_movements`
+
+	// The ledger of every product is summed up, products without movements count as zero.
+	// Bundles are left out: their stock is derived from the components, the sales of a
+	// bundle are recorded in the ledger of its components.
+	getStockDriftQuery = `
+		SELECT p.id, p.seller_id, COALESCE(p.sku, ''), p.name, p.stock, COALESCE(m.total, 0)
+		FROM products p
+		LEFT JOIN (
+			SELECT product_id, SUM(quantity) AS total
+			FROM inventory_movements
+			GROUP BY product_id
+		) m ON m.product_id = p.id
+		WHERE p.stock <> COALESCE(m.total, 0) AND p.id > $1
+		  AND NOT EXISTS (SELECT 1 FROM bundle_components bc WHERE bc.bundle_id = p.id)
+		ORDER BY p.id
+		LIMIT $2;`
+)
+
+var (
+	getMovementsError  = errors.New("error getting inventory movements")
+	getStockDriftError = errors.New("error getting stock drift")
+)
+
+// warehouseQuantity is the part of a stock change that fell on one warehouse.
+type warehouseQuantity struct {
+	warehouseId int64
+	quantity    int
+}
+
+// stockMovements splits a change of the product stock into a movement per touched
+// warehouse and a movement without a warehouse for the rest of the change.
+func stockMovements(m model.InventoryMovement, change int, warehouses []warehouseQuantity) []model.InventoryMovement {
+	movements := make([]model.InventoryMovement, 0, len(warehouses)+1)
+	for _, w := range warehouses {
+		wm := m
+		wm.WarehouseId = w.warehouseId
+		wm.Quantity = w.quantity
+		movements = append(movements, wm)
+		change -= w.quantity
+	}
+
+	m.Quantity = change
+	return append(movements, m)
+}
+
+// recordMovements appends the movements to the ledger in the transaction that changes
+// the stock, so the ledger never misses a change. Movements of zero are skipped. The
+// rows are copied in one round trip, a bulk update records thousands of them.
+func recordMovements(ctx context.Context, tx pgx.Tx, movements ...model.InventoryMovement) error {
+	rows := make([][]interface{}, 0, len(movements))
+	for _, m := range movements {
+		if m.Quantity == 0 {
+			continue
+		}
+
+		var warehouseId, actorId, reference interface{}
+		if m.WarehouseId != 0 {
+			warehouseId = m.WarehouseId
+		}
+		if m.ActorId != 0 {
+			actorId = m.ActorId
+		}
+		if m.Reference != "" {
+			reference = m.Reference
+		}
+		rows = append(rows, []interface{}{m.ProductId, warehouseId, m.Quantity, m.Reason, actorId, reference, m.CreatedAt})
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	_, err := tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"inventory_movements"},
+		[]string{"product_id", "warehouse_id", "quantity", "reason", "actor_id", "reference", "created_at"},
+		pgx.CopyFromRows(rows),
+	)
+	return err
+}
+
+type MovementRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewMovementRepo(db *pgxpool.Pool) *MovementRepo {
+	return &MovementRepo{db: db}
+}
+
+// GetProductMovements returns a page of the product's movements, newest first.
+func (r *MovementRepo) GetProductMovements(ctx context.Context, productId int64, cursor *model.MovementCursor, limit int) (*[]model.InventoryMovement, error) {
+	where := []string{"product_id = $1"}
+	args := []interface{}{productId}
+	if cursor != nil {
+		where = append(where, "id < $2")
+		args = append(args, cursor.Id)
+	}
+
+	tail := fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(ctx, getProductMovementsQuery+whereClause(where)+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getMovementsError, err)
+	}
+	defer rows.Close()
+
+	movements := []model.InventoryMovement{}
+	for rows.Next() {
+		var m model.InventoryMovement
+		err = rows.Scan(&m.Id, &m.ProductId, &m.WarehouseId, &m.Quantity, &m.Reason, &m.ActorId, &m.Reference, &m.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", getMovementsError, err)
+		}
+		movements = append(movements, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getMovementsError, rowsIterationError, err)
+	}
+
+	return &movements, nil
+}
+
+// GetStockDrift returns a page of the products whose stock differs from the sum of
+// their movements, ordered by product id.
+func (r *MovementRepo) GetStockDrift(ctx context.Context, afterProductId int64, limit int) (*[]model.StockDrift, error) {
+	rows, err := r.db.Query(ctx, getStockDriftQuery, afterProductId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getStockDriftError, err)
+	}
+	defer rows.Close()
+
+	drifts := []model.StockDrift{}
+	for rows.Next() {
+		var d model.StockDrift
+		if err = rows.Scan(&d.ProductId, &d.SellerId, &d.Sku, &d.Name, &d.Stock, &d.LedgerStock); err != nil {
+			return nil, fmt.Errorf("%w: %w", getStockDriftError, err)
+		}
+		drifts = append(drifts, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getStockDriftError, rowsIterationError, err)
+	}
+
+	return &drifts, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var (
+	getNotificationsQuery = `
+		SELECT id, user_id, kind, title, body, product_id, created_at, read_at
+		FROM notifications`
+
+	markNotificationReadQuery = `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, $1)
+		WHERE id = $2 AND user_id = $3;`
+
+	markAllNotificationsReadQuery = `
+		UPDATE notifications
+		SET read_at = $1
+		WHERE user_id = $2 AND read_at IS NULL;`
+)
+
+var (
+	createNotificationsError  = errors.New("error creating notifications")
+	getNotificationsError     = errors.New("error getting notifications")
+	markNotificationReadError = errors.New("error marking notification as read")
+)
+
+type NotificationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepo(db *pgxpool.Pool) *NotificationRepo {
+	return &NotificationRepo{db: db}
+}
+
+func (r *NotificationRepo) CreateNotifications(ctx context.Context, notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([][]interface{}, 0, len(notifications))
+	for _, n := range notifications {
+		rows = append(rows, []interface{}{n.UserId, n.Kind, n.Title, n.Body, n.ProductId, now})
+	}
+
+	_, err := r.db.CopyFrom(
+		ctx,
+		pgx.Identifier{"notifications"},
+		[]string{"user_id", "kind", "title", "body", "product_id", "created_at"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", createNotificationsError, err)
+	}
+
+	return nil
+}
+
+// GetNotifications returns a keyset page of the user's notifications, newest first.
+func (r *NotificationRepo) GetNotifications(ctx context.Context, userId int64, unread bool, cursor *model.NotificationCursor, limit int) (*[]model.Notification, error) {
+	where := []string{"user_id = $1"}
+	args := []interface{}{userId}
+	if unread {
+		where = append(where, "read_at IS NULL")
+	}
+	if cursor != nil {
+		where = append(where, fmt.Sprintf("id < $%d", len(args)+1))
+		args = append(args, cursor.Id)
+	}
+
+	tail := fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(ctx, getNotificationsQuery+whereClause(where)+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getNotificationsError, err)
+	}
+	defer rows.Close()
+
+	notifications := []model.Notification{}
+	for rows.Next() {
+		var n model.Notification
+		err = rows.Scan(&n.Id, &n.UserId, &n.Kind, &n.Title, &n.Body, &n.ProductId, &n.CreatedAt, &n.ReadAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", getNotificationsError, err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getNotificationsError, rowsIterationError, err)
+	}
+
+	return &notifications, nil
+}
+
+// MarkNotificationRead returns false when the user has no such notification.
+func (r *NotificationRepo) MarkNotificationRead(ctx context.Context, userId, id int64) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, markNotificationReadQuery, time.Now(), id, userId)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", markNotificationReadError, err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+func (r *NotificationRepo) MarkAllNotificationsRead(ctx context.Context, userId int64) error {
+	if _, err := r.db.Exec(ctx, markAllNotificationsReadQuery, time.Now(), userId); err != nil {
+		return fmt.Errorf("%w: %w", markNotificationReadError, err)
+	}
+
+	return nil
+}
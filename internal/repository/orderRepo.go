@@ -4,15 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/niklvrr/myMarketplace/internal/model"
 )
 
 var (
 	createOrderQuery = `
-		INSERT INTO orders (user_id, status, total, create_at)
+		INSERT INTO orders (user_id, status, total, created_at)
 		VALUES ($1, $2, $3, $4)
 		RETURNING id`
 
@@ -22,31 +25,179 @@ var (
 		RETURNING id`
 
 	getOrdersByUserIdQuery = `
-		SELECT order_id, status, total, create_at
+		SELECT id, status, total, created_at
 		FROM orders
 		WHERE user_id = $1`
 
 	getOrderByIdQuery = `
-		SELECT user_id, status, total, create_at
+		SELECT user_id, status, total, created_at
 		FROM orders
-		WHERE order_id = $1`
+		WHERE id = $1`
 
 	getOrderItemsByOrderIdQuery = `
 		SELECT id, product_id, quantity, price
 		FROM order_items
 		WHERE order_id = $1`
 
+	getOrderComponentsQuery = `
+		SELECT oc.order_item_id, oc.product_id, oc.quantity
+		FROM order_item_components oc
+		JOIN order_items oi ON oi.id = oc.order_item_id
+		WHERE oi.order_id = $1
+		ORDER BY oc.order_item_id, oc.product_id;`
+
+	getOrderItemComponentsQuery = `
+		SELECT order_item_id, product_id, quantity
+		FROM order_item_components
+		WHERE order_item_id = $1
+		ORDER BY product_id;`
+
+	createOrderItemComponentQuery = `
+		INSERT INTO order_item_components (order_item_id, product_id, quantity)
+		VALUES ($1, $2, $3);`
+
+	// The components are read without a lock, an order placed while the bundle changes
+	// takes the composition it has read. They are taken in the order of their ids.
+	getBundleCompositionQuery = `
+		SELECT component_id, quantity
+		FROM bundle_components
+		WHERE bundle_id = $1
+		ORDER BY component_id;`
+
 	deleteOrderByIdQuery = `DELETE FROM orders WHERE id = $1`
+
+	updateOrderStatusQuery = `
+		UPDATE orders
+		SET status = $1
+		WHERE id = $2
+		RETURNING total, created_at;`
+
+	// The stock is taken only when there is enough of it and the product is on sale, a
+	// product taken off sale after it was priced isn't sold.
+	takeStockQuery = `
+		UPDATE products
+		SET stock = stock - $1, sold_count = sold_count + $1
+		WHERE id = $2 AND stock >= $1
+		  AND status = 'approved' AND archived_at IS NULL AND deleted_at IS NULL`
+
+	// A component sold within a bundle counts as a sale of the bundle only. Archived and
+	// deleted products can't be sold within a bundle either.
+	takeComponentStockQuery = `
+		UPDATE products
+		SET stock = stock - $1
+		WHERE id = $2 AND stock >= $1 AND archived_at IS NULL AND deleted_at IS NULL`
+
+	// The stock of the bundle follows its components, the trigger updates it. The bundle is
+	// sold only while it is on sale, its row is locked with the products of the order.
+	sellBundleQuery = `
+		UPDATE products
+		SET sold_count = sold_count + $1
+		WHERE id = $2
+		  AND status = 'approved' AND archived_at IS NULL AND deleted_at IS NULL`
+
+	// allocateStockQuery picks the warehouses of a warehoused product in the order of their
+	// priority, the fullest first among equal priorities, and takes the ordered quantity
+	// from them. The product row is already locked by the stock update and its stock is the
+	// sum over the warehouses, so the picked warehouses always have enough.
+	allocateStockQuery = `
+		WITH ranked AS (
+			SELECT il.warehouse_id, il.quantity,
+			       SUM(il.quantity) OVER (ORDER BY w.priority, il.quantity DESC, il.warehouse_id) - il.quantity AS taken_before
+			FROM inventory_levels il
+			JOIN warehouses w ON w.id = il.warehouse_id
+			WHERE il.product_id = $1 AND il.quantity > 0
+		), picked AS (
+			SELECT warehouse_id, LEAST(quantity, $2 - taken_before) AS quantity
+			FROM ranked
+			WHERE taken_before < $2
+		), taken AS (
+			UPDATE inventory_levels il
+			SET quantity = il.quantity - p.quantity, updated_at = $4
+			FROM picked p
+			WHERE il.product_id = $1 AND il.warehouse_id = p.warehouse_id
+			RETURNING il.warehouse_id, p.quantity
+		)
+		INSERT INTO order_item_allocations (order_item_id, product_id, warehouse_id, quantity)
+		SELECT $3, $1, warehouse_id, quantity FROM taken
+		RETURNING warehouse_id, quantity;`
+
+	lockOrderQuery = `
+		SELECT user_id, status
+		FROM orders
+		WHERE id = $1
+		FOR UPDATE;`
+
+	// Items are locked in the order of their products, like concurrent orders lock them.
+	getOrderStockItemsQuery = `
+		SELECT id, product_id, quantity
+		FROM order_items
+		WHERE order_id = $1
+		ORDER BY product_id, id;`
+
+	// The bundles of the products are locked too: the trigger recounts their stock when a
+	// component changes, so they are taken in the same order instead of after the component.
+	lockOrderProductsQuery = `
+		SELECT id
+		FROM products
+		WHERE id = ANY($1)
+		   OR id IN (SELECT bundle_id FROM bundle_components WHERE component_id = ANY($1))
+		ORDER BY id
+		FOR UPDATE;`
+
+	lockOrderProductQuery = `
+		SELECT stock
+		FROM products
+		WHERE id = $1
+		FOR UPDATE;`
+
+	// Stock allocated to a warehouse that was deleted since can't be put back.
+	releaseAllocationsQuery = `
+		UPDATE inventory_levels il
+		SET quantity = il.quantity + a.quantity, updated_at = $3
+		FROM order_item_allocations a
+		WHERE a.order_item_id = $1 AND a.product_id = $2 AND il.product_id = $2 AND il.warehouse_id = a.warehouse_id
+		RETURNING il.warehouse_id, a.quantity;`
+
+	releaseStockQuery = `
+		UPDATE products
+		SET stock = CASE
+		        WHEN EXISTS (SELECT 1 FROM inventory_levels il WHERE il.product_id = products.id)
+		        THEN (SELECT SUM(quantity) FROM inventory_levels il WHERE il.product_id = products.id)
+		        ELSE stock + $1
+		    END,
+		    sold_count = GREATEST(sold_count - $1, 0)
+		WHERE id = $2
+		RETURNING stock;`
+
+	releaseComponentStockQuery = `
+		UPDATE products
+		SET stock = CASE
+		        WHEN EXISTS (SELECT 1 FROM inventory_levels il WHERE il.product_id = products.id)
+		        THEN (SELECT SUM(quantity) FROM inventory_levels il WHERE il.product_id = products.id)
+		        ELSE stock + $1
+		    END
+		WHERE id = $2
+		RETURNING stock;`
+
+	unsellBundleQuery = `
+		UPDATE products
+		SET sold_count = GREATEST(sold_count - $1, 0)
+		WHERE id = $2`
 )
 
 var (
 	createOrderError            = errors.New("error creating order")
 	createOrderItemError        = errors.New("error creating orderItem")
+	takeStockError              = errors.New("error taking stock")
+	allocateStockError          = errors.New("error allocating stock")
+	releaseStockError           = errors.New("error releasing stock")
 	orderNotFound               = errors.New("order not found")
 	getOrdersByUserIdError      = errors.New("error getting orders by user id")
 	getOrderByIdError           = errors.New("error getting order by id")
 	getOrderItemsByOrderIdError = errors.New("error getting order items by order id")
+	getOrderComponentsError     = errors.New("error getting order item components")
 	deleteOrderByIdError        = errors.New("error deleting order by id")
+	updateOrderStatusError      = errors.New("error updating order status")
 )
 
 type OrderRepo struct {
@@ -57,26 +208,43 @@ func NewOrderRepo(db *pgxpool.Pool) *OrderRepo {
 	return &OrderRepo{db: db}
 }
 
-func (r *OrderRepo) CreateOrder(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, error) {
+// CreateOrder saves the order with its items and takes the ordered quantities from the
+// stock in one transaction, warehoused products are allocated to their warehouses. The
+// stock is taken in the order of the product ids. It returns false when a product
+// doesn't have enough stock, nothing is saved then. Every item is recorded in the ledger
+// as a sale. A bundle is taken from the stock of its components, their quantities are
+// kept with the item.
+func (r *OrderRepo) CreateOrder(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error) {
 	var total float64
 	for _, orderItem := range *items {
-		total += orderItem.Price
+		total += orderItem.Price * float64(orderItem.Quantity)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %w", createOrderError, err)
 	}
+	defer tx.Rollback(ctx)
 
 	var orderId int64
-	for _, item := range *items {
-		err := r.db.QueryRow(
-			ctx, createOrderQuery,
-			userId,
-			"pending",
-			total,
-			time.Now()).Scan(&orderId)
+	createdAt := time.Now()
+	err = tx.QueryRow(
+		ctx, createOrderQuery,
+		userId,
+		"pending",
+		total,
+		createdAt).Scan(&orderId)
 
-		if err != nil {
-			return 0, fmt.Errorf("%w: %w", createOrderError, err)
-		}
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %w", createOrderError, err)
+	}
 
-		err = r.db.QueryRow(
+	compositions := make([][]model.BundleComponent, len(*items))
+	productIds := make([]int64, 0, len(*items))
+	for i := range *items {
+		item := &(*items)[i]
+		item.OrderId = orderId
+		err = tx.QueryRow(
 			ctx, createOrderItemQuery,
 			orderId,
 			item.ProductId,
@@ -85,11 +253,162 @@ func (r *OrderRepo) CreateOrder(ctx context.Context, userId int64, items *[]mode
 		).Scan(&item.Id)
 
 		if err != nil {
-			return 0, fmt.Errorf("%w: %w", createOrderItemError, err)
+			return 0, false, fmt.Errorf("%w: %w", createOrderItemError, err)
+		}
+
+		compositions[i], err = r.bundleComposition(ctx, tx, item.ProductId)
+		if err != nil {
+			return 0, false, fmt.Errorf("%w: %w", takeStockError, err)
+		}
+
+		productIds = append(productIds, item.ProductId)
+		for _, c := range compositions[i] {
+			productIds = append(productIds, c.ProductId)
+		}
+	}
+
+	// Every product of the order, components and the bundles they are in included, is
+	// locked in the order of the ids before any stock is taken, so concurrent orders with
+	// the same products wait for each other instead of locking them crosswise.
+	if _, err = tx.Exec(ctx, lockOrderProductsQuery, productIds); err != nil {
+		return 0, false, fmt.Errorf("%w: %w", takeStockError, err)
+	}
+
+	byProduct := make([]int, len(*items))
+	for i := range byProduct {
+		byProduct[i] = i
+	}
+	sort.SliceStable(byProduct, func(a, b int) bool {
+		return (*items)[byProduct[a]].ProductId < (*items)[byProduct[b]].ProductId
+	})
+
+	movement := model.InventoryMovement{
+		Reason:    model.MovementSale,
+		ActorId:   userId,
+		Reference: fmt.Sprintf("order:%d", orderId),
+		CreatedAt: createdAt,
+	}
+	for _, i := range byProduct {
+		item := &(*items)[i]
+
+		var taken bool
+		if len(compositions[i]) > 0 {
+			taken, err = r.takeBundleStock(ctx, tx, item, compositions[i], movement)
+		} else {
+			taken, err = r.takeStock(ctx, tx, takeStockQuery, item.Id, item.ProductId, item.Quantity, movement)
+		}
+		if err != nil || !taken {
+			return 0, false, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, false, fmt.Errorf("%w: %w", createOrderError, err)
+	}
+
+	return orderId, true, nil
+}
+
+// takeStock takes the quantity of the product with the given query and allocates it to
+// the warehouses of the product. It returns false when there isn't enough stock.
+func (r *OrderRepo) takeStock(ctx context.Context, tx pgx.Tx, query string, itemId, productId int64, quantity int, movement model.InventoryMovement) (bool, error) {
+	cmdTag, err := tx.Exec(ctx, query, quantity, productId)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", takeStockError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	allocated, err := r.allocateStock(ctx, tx, itemId, productId, quantity, movement.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", allocateStockError, err)
+	}
+
+	movement.ProductId = productId
+	if err = recordMovements(ctx, tx, stockMovements(movement, -quantity, allocated)...); err != nil {
+		return false, fmt.Errorf("%w: %w", takeStockError, err)
+	}
+
+	return true, nil
+}
+
+// takeBundleStock takes every component of the bundle and keeps the taken quantities
+// as the breakdown of the item. The bundle itself has no stock to take, the sales are
+// recorded in the ledger of the components. It returns false when the bundle is no longer
+// on sale or a component doesn't have enough stock.
+func (r *OrderRepo) takeBundleStock(ctx context.Context, tx pgx.Tx, item *model.OrderItem, composition []model.BundleComponent, movement model.InventoryMovement) (bool, error) {
+	cmdTag, err := tx.Exec(ctx, sellBundleQuery, item.Quantity, item.ProductId)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", takeStockError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	for _, c := range composition {
+		quantity := c.Quantity * item.Quantity
+		taken, err := r.takeStock(ctx, tx, takeComponentStockQuery, item.Id, c.ProductId, quantity, movement)
+		if err != nil || !taken {
+			return false, err
+		}
+
+		if _, err = tx.Exec(ctx, createOrderItemComponentQuery, item.Id, c.ProductId, quantity); err != nil {
+			return false, fmt.Errorf("%w: %w", createOrderItemError, err)
+		}
+
+		item.Components = append(item.Components, model.OrderItemComponent{
+			OrderItemId: item.Id,
+			ProductId:   c.ProductId,
+			Quantity:    quantity,
+		})
+	}
+
+	return true, nil
+}
+
+// bundleComposition returns the components of the product, none when it isn't a bundle.
+func (r *OrderRepo) bundleComposition(ctx context.Context, tx pgx.Tx, productId int64) ([]model.BundleComponent, error) {
+	rows, err := tx.Query(ctx, getBundleCompositionQuery, productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var composition []model.BundleComponent
+	for rows.Next() {
+		c := model.BundleComponent{BundleId: productId}
+		if err = rows.Scan(&c.ProductId, &c.Quantity); err != nil {
+			return nil, err
+		}
+		composition = append(composition, c)
+	}
+
+	return composition, rows.Err()
+}
+
+// allocateStock takes the quantity from the warehouses of the product and returns the
+// taken quantities as negative ones, products without warehouses get none.
+func (r *OrderRepo) allocateStock(ctx context.Context, tx pgx.Tx, itemId, productId int64, quantity int, at time.Time) ([]warehouseQuantity, error) {
+	rows, err := tx.Query(ctx, allocateStockQuery, productId, quantity, itemId, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allocated []warehouseQuantity
+	for rows.Next() {
+		var w warehouseQuantity
+		if err = rows.Scan(&w.warehouseId, &w.quantity); err != nil {
+			return nil, err
 		}
+		w.quantity = -w.quantity
+		allocated = append(allocated, w)
 	}
 
-	return orderId, nil
+	return allocated, rows.Err()
 }
 
 func (r *OrderRepo) GetOrdersByUserId(ctx context.Context, userId int64) (*[]model.Order, error) {
@@ -163,18 +482,255 @@ func (r *OrderRepo) GetOrderItemsByOrderId(ctx context.Context, orderId int64) (
 		return nil, fmt.Errorf("%w(%w): %w", getOrderItemsByOrderIdError, rowsIterationError, err)
 	}
 
+	if err := r.attachComponents(ctx, orderId, orderItems); err != nil {
+		return nil, err
+	}
+
 	return &orderItems, nil
 }
 
+// attachComponents adds the breakdown of the bundles to the items of the order.
+func (r *OrderRepo) attachComponents(ctx context.Context, orderId int64, items []model.OrderItem) error {
+	rows, err := r.db.Query(ctx, getOrderComponentsQuery, orderId)
+	if err != nil {
+		return fmt.Errorf("%w: %w", getOrderComponentsError, err)
+	}
+	defer rows.Close()
+
+	byItem := make(map[int64]int, len(items))
+	for i := range items {
+		byItem[items[i].Id] = i
+	}
+
+	for rows.Next() {
+		var c model.OrderItemComponent
+		if err = rows.Scan(&c.OrderItemId, &c.ProductId, &c.Quantity); err != nil {
+			return fmt.Errorf("%w: %w", getOrderComponentsError, err)
+		}
+
+		if i, ok := byItem[c.OrderItemId]; ok {
+			items[i].Components = append(items[i].Components, c)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%w(%w): %w", getOrderComponentsError, rowsIterationError, err)
+	}
+
+	return nil
+}
+
+// DeleteOrderById removes the order and puts the stock it took back in one transaction,
+// to the warehouses it was allocated from. The returned stock is recorded in the ledger
+// as a release by the buyer, canceled orders have nothing to put back.
 func (r *OrderRepo) DeleteOrderById(ctx context.Context, orderId int64) error {
-	cmdTag, err := r.db.Exec(ctx, deleteOrderByIdQuery, orderId)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("%w: %w", deleteOrderByIdError, err)
 	}
-
-	if cmdTag.RowsAffected() == 0 {
+	defer tx.Rollback(ctx)
+
+	var (
+		userId int64
+		status string
+	)
+	err = tx.QueryRow(ctx, lockOrderQuery, orderId).Scan(&userId, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return fmt.Errorf("%w: %w", deleteOrderByIdError, orderNotFound)
 	}
 
+	if err != nil {
+		return fmt.Errorf("%w: %w", deleteOrderByIdError, err)
+	}
+
+	if status != model.OrderStatusCanceled {
+		if err = r.releaseStock(ctx, tx, orderId, userId); err != nil {
+			return fmt.Errorf("%w: %w", releaseStockError, err)
+		}
+	}
+
+	if _, err = tx.Exec(ctx, deleteOrderByIdQuery, orderId); err != nil {
+		return fmt.Errorf("%w: %w", deleteOrderByIdError, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: %w", deleteOrderByIdError, err)
+	}
+
 	return nil
 }
+
+// UpdateOrderStatus moves the order to the status when its current status is one of
+// from. A canceled order gives its stock back in the same transaction, the release is
+// recorded in the ledger by the actor. It returns nil when the order doesn't exist and
+// false with the order as it is when its status doesn't allow the move.
+func (r *OrderRepo) UpdateOrderStatus(ctx context.Context, orderId, actorId int64, status string, from []string) (*model.Order, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", updateOrderStatusError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	order := &model.Order{Id: orderId}
+	err = tx.QueryRow(ctx, lockOrderQuery, orderId).Scan(&order.UserId, &order.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %w", updateOrderStatusError, err)
+	}
+
+	if !slices.Contains(from, order.Status) {
+		return order, false, nil
+	}
+
+	if status == model.OrderStatusCanceled {
+		if err = r.releaseStock(ctx, tx, orderId, actorId); err != nil {
+			return nil, false, fmt.Errorf("%w: %w", releaseStockError, err)
+		}
+	}
+
+	if err = tx.QueryRow(ctx, updateOrderStatusQuery, status, orderId).Scan(&order.Total, &order.CreateAt); err != nil {
+		return nil, false, fmt.Errorf("%w: %w", updateOrderStatusError, err)
+	}
+	order.Status = status
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, false, fmt.Errorf("%w: %w", updateOrderStatusError, err)
+	}
+
+	return order, true, nil
+}
+
+func (r *OrderRepo) releaseStock(ctx context.Context, tx pgx.Tx, orderId, userId int64) error {
+	items, err := r.orderStockItems(ctx, tx, orderId)
+	if err != nil {
+		return err
+	}
+
+	components := make([][]model.OrderItemComponent, len(items))
+	productIds := make([]int64, 0, len(items))
+	for i := range items {
+		components[i], err = r.itemComponents(ctx, tx, items[i].Id)
+		if err != nil {
+			return err
+		}
+
+		productIds = append(productIds, items[i].ProductId)
+		for _, c := range components[i] {
+			productIds = append(productIds, c.ProductId)
+		}
+	}
+
+	// The products are locked in the order of the ids like in CreateOrder.
+	if _, err = tx.Exec(ctx, lockOrderProductsQuery, productIds); err != nil {
+		return err
+	}
+
+	movement := model.InventoryMovement{
+		Reason:    model.MovementRelease,
+		ActorId:   userId,
+		Reference: fmt.Sprintf("order:%d", orderId),
+		CreatedAt: time.Now(),
+	}
+	for i := range items {
+		item := &items[i]
+		if len(components[i]) == 0 {
+			if err = r.releaseProduct(ctx, tx, releaseStockQuery, item.Id, item.ProductId, item.Quantity, movement); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, c := range components[i] {
+			if err = r.releaseProduct(ctx, tx, releaseComponentStockQuery, item.Id, c.ProductId, c.Quantity, movement); err != nil {
+				return err
+			}
+		}
+
+		if _, err = tx.Exec(ctx, unsellBundleQuery, item.Quantity, item.ProductId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseProduct puts the quantity taken by the item back with the given query, to the
+// warehouses it was allocated from, and records the release in the ledger.
+func (r *OrderRepo) releaseProduct(ctx context.Context, tx pgx.Tx, query string, itemId, productId int64, quantity int, movement model.InventoryMovement) error {
+	var before, after int
+	if err := tx.QueryRow(ctx, lockOrderProductQuery, productId).Scan(&before); err != nil {
+		return err
+	}
+
+	returned, err := r.releaseAllocations(ctx, tx, itemId, productId, movement.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.QueryRow(ctx, query, quantity, productId).Scan(&after); err != nil {
+		return err
+	}
+
+	movement.ProductId = productId
+	return recordMovements(ctx, tx, stockMovements(movement, after-before, returned)...)
+}
+
+func (r *OrderRepo) itemComponents(ctx context.Context, tx pgx.Tx, itemId int64) ([]model.OrderItemComponent, error) {
+	rows, err := tx.Query(ctx, getOrderItemComponentsQuery, itemId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var components []model.OrderItemComponent
+	for rows.Next() {
+		var c model.OrderItemComponent
+		if err = rows.Scan(&c.OrderItemId, &c.ProductId, &c.Quantity); err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+
+	return components, rows.Err()
+}
+
+func (r *OrderRepo) orderStockItems(ctx context.Context, tx pgx.Tx, orderId int64) ([]model.OrderItem, error) {
+	rows, err := tx.Query(ctx, getOrderStockItemsQuery, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.OrderItem
+	for rows.Next() {
+		var item model.OrderItem
+		if err = rows.Scan(&item.Id, &item.ProductId, &item.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *OrderRepo) releaseAllocations(ctx context.Context, tx pgx.Tx, itemId, productId int64, at time.Time) ([]warehouseQuantity, error) {
+	rows, err := tx.Query(ctx, releaseAllocationsQuery, itemId, productId, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var returned []warehouseQuantity
+	for rows.Next() {
+		var w warehouseQuantity
+		if err = rows.Scan(&w.warehouseId, &w.quantity); err != nil {
+			return nil, err
+		}
+		returned = append(returned, w)
+	}
+
+	return returned, rows.Err()
+}
@@ -0,0 +1,380 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+// testDB connects to the postgres from TEST_DATABASE_URL and migrates it to the
+// latest version. Tests that need a real database are skipped without it.
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	mg, err := migrate.New("file://../../migrations", url)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err = mg.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("migrate up: %v", err)
+	}
+	mg.Close()
+
+	db, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	return db
+}
+
+// testUser creates a user with the role, the user and everything it owns is deleted
+// when the test ends.
+func testUser(t *testing.T, db *pgxpool.Pool, role string) int64 {
+	t.Helper()
+	ctx := context.Background()
+
+	var id int64
+	email := fmt.Sprintf("%s-%d@test.local", role, time.Now().UnixNano())
+	err := db.QueryRow(ctx,
+		`INSERT INTO users (name, email, password, role) VALUES ($1, $2, 'x', $3) RETURNING id`,
+		role, email, role).Scan(&id)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, id)
+	})
+
+	return id
+}
+
+func testProduct(t *testing.T, db *pgxpool.Pool, sellerId int64, name string, stock int) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(),
+		`INSERT INTO products (seller_id, name, price, stock, status) VALUES ($1, $2, 10, $3, 'approved') RETURNING id`,
+		sellerId, name, stock).Scan(&id)
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	return id
+}
+
+func testWarehouse(t *testing.T, db *pgxpool.Pool, sellerId, productId int64, name string, priority, quantity int) int64 {
+	t.Helper()
+	ctx := context.Background()
+
+	var id int64
+	err := db.QueryRow(ctx,
+		`INSERT INTO warehouses (seller_id, name, priority) VALUES ($1, $2, $3) RETURNING id`,
+		sellerId, name, priority).Scan(&id)
+	if err != nil {
+		t.Fatalf("create warehouse: %v", err)
+	}
+
+	_, err = db.Exec(ctx,
+		`INSERT INTO inventory_levels (product_id, warehouse_id, quantity) VALUES ($1, $2, $3)`,
+		productId, id, quantity)
+	if err != nil {
+		t.Fatalf("create inventory level: %v", err)
+	}
+
+	return id
+}
+
+func productStock(t *testing.T, db *pgxpool.Pool, productId int64) (int, int) {
+	t.Helper()
+
+	var stock, sold int
+	err := db.QueryRow(context.Background(),
+		`SELECT stock, sold_count FROM products WHERE id = $1`, productId).Scan(&stock, &sold)
+	if err != nil {
+		t.Fatalf("get stock: %v", err)
+	}
+
+	return stock, sold
+}
+
+func TestOrderRepo_CreateOrder(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewOrderRepo(db)
+
+	sellerId := testUser(t, db, "seller")
+	buyerId := testUser(t, db, "user")
+	plain := testProduct(t, db, sellerId, "Cable", 5)
+	warehoused := testProduct(t, db, sellerId, "Phone", 6)
+	first := testWarehouse(t, db, sellerId, warehoused, "Main", 0, 2)
+	second := testWarehouse(t, db, sellerId, warehoused, "Reserve", 1, 4)
+
+	items := []model.OrderItem{
+		{ProductId: warehoused, Quantity: 3, Price: 20},
+		{ProductId: plain, Quantity: 2, Price: 10},
+	}
+	orderId, placed, err := repo.CreateOrder(ctx, buyerId, &items)
+	if err != nil || !placed {
+		t.Fatalf("order not placed: %v %v", placed, err)
+	}
+
+	order, err := repo.GetOrderById(ctx, orderId)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	if order.UserId != buyerId || order.Status != model.OrderStatusPending || order.Total != 80 || order.CreateAt.IsZero() {
+		t.Fatalf("unexpected order: %+v", order)
+	}
+
+	orders, err := repo.GetOrdersByUserId(ctx, buyerId)
+	if err != nil || len(*orders) != 1 || (*orders)[0].Id != orderId {
+		t.Fatalf("unexpected history: %+v %v", orders, err)
+	}
+
+	if stock, sold := productStock(t, db, plain); stock != 3 || sold != 2 {
+		t.Fatalf("plain product: stock %d sold %d", stock, sold)
+	}
+	if stock, sold := productStock(t, db, warehoused); stock != 3 || sold != 3 {
+		t.Fatalf("warehoused product: stock %d sold %d", stock, sold)
+	}
+
+	// The main warehouse is emptied first, the rest comes from the reserve.
+	allocations := make(map[int64]int)
+	rows, err := db.Query(ctx, `
+		SELECT a.warehouse_id, a.quantity
+		FROM order_item_allocations a
+		JOIN order_items oi ON oi.id = a.order_item_id
+		WHERE oi.order_id = $1`, orderId)
+	if err != nil {
+		t.Fatalf("get allocations: %v", err)
+	}
+	for rows.Next() {
+		var warehouseId int64
+		var quantity int
+		if err = rows.Scan(&warehouseId, &quantity); err != nil {
+			t.Fatalf("scan allocation: %v", err)
+		}
+		allocations[warehouseId] = quantity
+	}
+	rows.Close()
+	if len(allocations) != 2 || allocations[first] != 2 || allocations[second] != 1 {
+		t.Fatalf("unexpected allocations: %+v", allocations)
+	}
+
+	ledger := make(map[string]int)
+	rows, err = db.Query(ctx, `
+		SELECT product_id, COALESCE(warehouse_id, 0), quantity
+		FROM inventory_movements
+		WHERE reference = $1 AND reason = $2 AND actor_id = $3`,
+		fmt.Sprintf("order:%d", orderId), model.MovementSale, buyerId)
+	if err != nil {
+		t.Fatalf("get ledger: %v", err)
+	}
+	for rows.Next() {
+		var productId, warehouseId int64
+		var quantity int
+		if err = rows.Scan(&productId, &warehouseId, &quantity); err != nil {
+			t.Fatalf("scan movement: %v", err)
+		}
+		ledger[fmt.Sprintf("%d/%d", productId, warehouseId)] = quantity
+	}
+	rows.Close()
+	want := map[string]int{
+		fmt.Sprintf("%d/0", plain):               -2,
+		fmt.Sprintf("%d/%d", warehoused, first):  -2,
+		fmt.Sprintf("%d/%d", warehoused, second): -1,
+	}
+	if fmt.Sprint(ledger) != fmt.Sprint(want) {
+		t.Fatalf("ledger got %v want %v", ledger, want)
+	}
+}
+
+func TestOrderRepo_CreateOrder_NotEnoughStock(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewOrderRepo(db)
+
+	sellerId := testUser(t, db, "seller")
+	buyerId := testUser(t, db, "user")
+	plenty := testProduct(t, db, sellerId, "Cable", 5)
+	scarce := testProduct(t, db, sellerId, "Phone", 1)
+
+	items := []model.OrderItem{
+		{ProductId: plenty, Quantity: 2, Price: 10},
+		{ProductId: scarce, Quantity: 2, Price: 10},
+	}
+	_, placed, err := repo.CreateOrder(ctx, buyerId, &items)
+	if err != nil || placed {
+		t.Fatalf("expected the order to be refused: %v %v", placed, err)
+	}
+
+	if stock, _ := productStock(t, db, plenty); stock != 5 {
+		t.Fatalf("stock must not change, got %d", stock)
+	}
+	orders, err := repo.GetOrdersByUserId(ctx, buyerId)
+	if err != nil || len(*orders) != 0 {
+		t.Fatalf("order must not be saved: %+v %v", orders, err)
+	}
+}
+
+func TestOrderRepo_CreateOrder_NotListed(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewOrderRepo(db)
+
+	sellerId := testUser(t, db, "seller")
+	buyerId := testUser(t, db, "user")
+	archived := testProduct(t, db, sellerId, "Cable", 5)
+	if _, err := db.Exec(ctx, `UPDATE products SET archived_at = now() WHERE id = $1`, archived); err != nil {
+		t.Fatalf("archive product: %v", err)
+	}
+
+	items := []model.OrderItem{{ProductId: archived, Quantity: 1, Price: 10}}
+	_, placed, err := repo.CreateOrder(ctx, buyerId, &items)
+	if err != nil || placed {
+		t.Fatalf("archived product must not be sold: %v %v", placed, err)
+	}
+	if stock, _ := productStock(t, db, archived); stock != 5 {
+		t.Fatalf("stock must not change, got %d", stock)
+	}
+}
+
+// Orders with the same products in the opposite order must not deadlock.
+func TestOrderRepo_CreateOrder_Concurrent(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewOrderRepo(db)
+
+	sellerId := testUser(t, db, "seller")
+	buyerId := testUser(t, db, "user")
+	first := testProduct(t, db, sellerId, "Cable", 100)
+	second := testProduct(t, db, sellerId, "Phone", 100)
+
+	const rounds = 20
+	errc := make(chan error, 2*rounds)
+	var wg sync.WaitGroup
+	for _, ids := range [][2]int64{{first, second}, {second, first}} {
+		wg.Add(1)
+		go func(ids [2]int64) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				items := []model.OrderItem{
+					{ProductId: ids[0], Quantity: 1, Price: 10},
+					{ProductId: ids[1], Quantity: 1, Price: 10},
+				}
+				_, _, err := repo.CreateOrder(ctx, buyerId, &items)
+				errc <- err
+			}
+		}(ids)
+	}
+	wg.Wait()
+	close(errc)
+
+	for err := range errc {
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	if stock, _ := productStock(t, db, first); stock != 100-2*rounds {
+		t.Fatalf("unexpected stock %d", stock)
+	}
+}
+
+// testBundleComponents makes the product a bundle of the components, one of each.
+func testBundleComponents(t *testing.T, db *pgxpool.Pool, bundleId int64, components ...int64) {
+	t.Helper()
+
+	for _, componentId := range components {
+		_, err := db.Exec(context.Background(),
+			`INSERT INTO bundle_components (bundle_id, component_id, quantity) VALUES ($1, $2, 1)`,
+			bundleId, componentId)
+		if err != nil {
+			t.Fatalf("create bundle component: %v", err)
+		}
+	}
+}
+
+// An order of a component and an order of its bundle must not deadlock on the bundle the
+// trigger recounts, the bundle here is locked before its component.
+func TestOrderRepo_CreateOrder_ConcurrentBundle(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewOrderRepo(db)
+
+	sellerId := testUser(t, db, "seller")
+	buyerId := testUser(t, db, "user")
+	bundle := testProduct(t, db, sellerId, "Starter kit", 0)
+	component := testProduct(t, db, sellerId, "Cable", 100)
+	testBundleComponents(t, db, bundle, component)
+
+	const rounds = 20
+	errc := make(chan error, 2*rounds)
+	var wg sync.WaitGroup
+	for _, productId := range []int64{component, bundle} {
+		wg.Add(1)
+		go func(productId int64) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				items := []model.OrderItem{{ProductId: productId, Quantity: 1, Price: 10}}
+				_, _, err := repo.CreateOrder(ctx, buyerId, &items)
+				errc <- err
+			}
+		}(productId)
+	}
+	wg.Wait()
+	close(errc)
+
+	for err := range errc {
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	if stock, _ := productStock(t, db, component); stock != 100-2*rounds {
+		t.Fatalf("unexpected component stock %d", stock)
+	}
+	if stock, sold := productStock(t, db, bundle); stock != 100-2*rounds || sold != rounds {
+		t.Fatalf("unexpected bundle: stock %d sold %d", stock, sold)
+	}
+}
+
+func TestOrderRepo_CreateOrder_BundleNotListed(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewOrderRepo(db)
+
+	sellerId := testUser(t, db, "seller")
+	buyerId := testUser(t, db, "user")
+	component := testProduct(t, db, sellerId, "Cable", 5)
+	bundle := testProduct(t, db, sellerId, "Starter kit", 0)
+	testBundleComponents(t, db, bundle, component)
+	if _, err := db.Exec(ctx, `UPDATE products SET archived_at = now() WHERE id = $1`, bundle); err != nil {
+		t.Fatalf("archive bundle: %v", err)
+	}
+
+	items := []model.OrderItem{{ProductId: bundle, Quantity: 1, Price: 10}}
+	_, placed, err := repo.CreateOrder(ctx, buyerId, &items)
+	if err != nil || placed {
+		t.Fatalf("archived bundle must not be sold: %v %v", placed, err)
+	}
+	if stock, _ := productStock(t, db, component); stock != 5 {
+		t.Fatalf("stock must not change, got %d", stock)
+	}
+}
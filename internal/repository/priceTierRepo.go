@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var (
+	// The tiers every customer gets and the ones of the given price list, when there is one.
+	getPriceTiersQuery = `
+		SELECT product_id, price_list_id, min_quantity, price
+		FROM price_tiers
+		WHERE product_id = ANY($1)
+		  AND (price_list_id IS NULL OR price_list_id = $2)
+		ORDER BY product_id, price_list_id NULLS FIRST, min_quantity;`
+
+	getProductPriceTiersQuery = `
+		SELECT product_id, price_list_id, min_quantity, price
+		FROM price_tiers
+		WHERE product_id = $1
+		ORDER BY price_list_id NULLS FIRST, min_quantity;`
+
+	// The tiers of one price list of a product change one request at a time, concurrent
+	// replacements would collide on the unique index.
+	lockPriceTiersQuery = `SELECT pg_advisory_xact_lock(hashtext('price_tiers:' || $1::text));`
+
+	deletePriceTiersQuery = `
+		DELETE FROM price_tiers
+		WHERE product_id = $1 AND price_list_id IS NOT DISTINCT FROM $2::int;`
+
+	createPriceTiersQuery = `
+		INSERT INTO price_tiers (product_id, price_list_id, min_quantity, price)
+		SELECT $1, $2::int, t.min_quantity, t.price
+		FROM unnest($3::int[], $4::numeric[]) AS t(min_quantity, price);`
+
+	createPriceListQuery = `
+		INSERT INTO price_lists (name, created_at)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id;`
+
+	getPriceListByIdQuery = `
+		SELECT id, name, created_at
+		FROM price_lists
+		WHERE id = $1;`
+
+	getPriceListsQuery = `
+		SELECT id, name, created_at
+		FROM price_lists
+		ORDER BY name;`
+
+	getUserPriceListQuery = `SELECT price_list_id FROM users WHERE id = $1;`
+
+	setUserPriceListQuery = `UPDATE users SET price_list_id = $2 WHERE id = $1;`
+)
+
+var (
+	getPriceTiersError    = errors.New("error getting price tiers")
+	setPriceTiersError    = errors.New("error setting price tiers")
+	createPriceListError  = errors.New("error creating price list")
+	getPriceListError     = errors.New("error getting price list")
+	getPriceListsError    = errors.New("error getting price lists")
+	getUserPriceListError = errors.New("error getting user price list")
+	setUserPriceListError = errors.New("error setting user price list")
+)
+
+type PriceTierRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPriceTierRepo(db *pgxpool.Pool) *PriceTierRepo {
+	return &PriceTierRepo{db: db}
+}
+
+// GetPriceTiers returns the tiers of the products every customer gets, along with the
+// tiers of the price list when priceListId isn't nil.
+func (r *PriceTierRepo) GetPriceTiers(ctx context.Context, productIds []int64, priceListId *int64) (*[]model.PriceTier, error) {
+	rows, err := r.db.Query(ctx, getPriceTiersQuery, productIds, priceListId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getPriceTiersError, err)
+	}
+
+	return scanPriceTiers(rows)
+}
+
+// GetProductPriceTiers returns the tiers of the product in all its price lists.
+func (r *PriceTierRepo) GetProductPriceTiers(ctx context.Context, productId int64) (*[]model.PriceTier, error) {
+	rows, err := r.db.Query(ctx, getProductPriceTiersQuery, productId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getPriceTiersError, err)
+	}
+
+	return scanPriceTiers(rows)
+}
+
+func scanPriceTiers(rows pgx.Rows) (*[]model.PriceTier, error) {
+	defer rows.Close()
+
+	tiers := []model.PriceTier{}
+	for rows.Next() {
+		var t model.PriceTier
+		if err := rows.Scan(&t.ProductId, &t.PriceListId, &t.MinQuantity, &t.Price); err != nil {
+			return nil, fmt.Errorf("%w: %w", getPriceTiersError, err)
+		}
+		tiers = append(tiers, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getPriceTiersError, rowsIterationError, err)
+	}
+
+	return &tiers, nil
+}
+
+// SetPriceTiers replaces the tiers of the product in one price list, the tiers every
+// customer gets when priceListId is nil.
+func (r *PriceTierRepo) SetPriceTiers(ctx context.Context, productId int64, priceListId *int64, tiers []model.PriceTier) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", setPriceTiersError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, lockPriceTiersQuery, productId); err != nil {
+		return fmt.Errorf("%w: %w", setPriceTiersError, err)
+	}
+
+	if _, err = tx.Exec(ctx, deletePriceTiersQuery, productId, priceListId); err != nil {
+		return fmt.Errorf("%w: %w", setPriceTiersError, err)
+	}
+
+	if len(tiers) > 0 {
+		quantities := make([]int32, 0, len(tiers))
+		prices := make([]float64, 0, len(tiers))
+		for _, t := range tiers {
+			quantities = append(quantities, int32(t.MinQuantity))
+			prices = append(prices, t.Price)
+		}
+
+		if _, err = tx.Exec(ctx, createPriceTiersQuery, productId, priceListId, quantities, prices); err != nil {
+			return fmt.Errorf("%w: %w", setPriceTiersError, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: %w", setPriceTiersError, err)
+	}
+
+	return nil
+}
+
+// CreatePriceList returns false when a price list with this name already exists.
+func (r *PriceTierRepo) CreatePriceList(ctx context.Context, l *model.PriceList) (bool, error) {
+	l.CreatedAt = time.Now()
+	err := r.db.QueryRow(ctx, createPriceListQuery, l.Name, l.CreatedAt).Scan(&l.Id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", createPriceListError, err)
+	}
+
+	return true, nil
+}
+
+// GetPriceListById returns nil when the price list doesn't exist.
+func (r *PriceTierRepo) GetPriceListById(ctx context.Context, id int64) (*model.PriceList, error) {
+	l := new(model.PriceList)
+	err := r.db.QueryRow(ctx, getPriceListByIdQuery, id).Scan(&l.Id, &l.Name, &l.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getPriceListError, err)
+	}
+
+	return l, nil
+}
+
+func (r *PriceTierRepo) GetPriceLists(ctx context.Context) (*[]model.PriceList, error) {
+	rows, err := r.db.Query(ctx, getPriceListsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getPriceListsError, err)
+	}
+	defer rows.Close()
+
+	lists := []model.PriceList{}
+	for rows.Next() {
+		var l model.PriceList
+		if err = rows.Scan(&l.Id, &l.Name, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", getPriceListsError, err)
+		}
+		lists = append(lists, l)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getPriceListsError, rowsIterationError, err)
+	}
+
+	return &lists, nil
+}
+
+// GetUserPriceList returns the price list of the customer's group, nil when the customer
+// isn't in one or doesn't exist.
+func (r *PriceTierRepo) GetUserPriceList(ctx context.Context, userId int64) (*int64, error) {
+	var priceListId *int64
+	err := r.db.QueryRow(ctx, getUserPriceListQuery, userId).Scan(&priceListId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getUserPriceListError, err)
+	}
+
+	return priceListId, nil
+}
+
+// SetUserPriceList returns false when the user doesn't exist.
+func (r *PriceTierRepo) SetUserPriceList(ctx context.Context, userId int64, priceListId *int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, setUserPriceListQuery, userId, priceListId)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", setUserPriceListError, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
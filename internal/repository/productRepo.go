@@ -7,49 +7,198 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/niklvrr/myMarketplace/internal/model"
 )
 
+const productColumns = `id, seller_id, COALESCE(category_id, 0), name, COALESCE(description, ''), price, stock, status, COALESCE(rejection_reason, ''), sold_count, rating, review_count, created_at, archived_at, deleted_at, COALESCE(sku, ''), COALESCE(image_url, ''), slug, ` + lowestPriceColumn + `, ` + warehousedColumn + `, ` + bundleColumn
+
+// warehousedColumn tells whether the product keeps its stock per warehouse.
+const warehousedColumn = `EXISTS (SELECT 1 FROM inventory_levels il WHERE il.product_id = products.id)`
+
+// bundleColumn tells whether the product is a bundle of other products.
+const bundleColumn = `EXISTS (SELECT 1 FROM bundle_components bc WHERE bc.bundle_id = products.id)`
+
+// lowestPriceColumn is the lowest price in effect during the last 30 days: the price set
+// before the window started counts too, since it was still applied at its beginning.
+const lowestPriceColumn = `COALESCE((
+	SELECT MIN(h.price)
+	FROM product_price_history h
+	WHERE h.product_id = products.id
+	  AND h.changed_at >= COALESCE((
+		SELECT MAX(w.changed_at)
+		FROM product_price_history w
+		WHERE w.product_id = products.id AND w.changed_at <= now() - interval '30 days'
+	  ), '-infinity')
+), price)`
+
+// visibleCondition hides archived and soft deleted products from catalog queries.
+const visibleCondition = `archived_at IS NULL AND deleted_at IS NULL`
+
 var (
 	createProductQuery = `
-		INSERT INTO products (seller_id, category_id, name, description, price, stock, is_approved, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id;`
+		INSERT INTO products (seller_id, category_id, name, description, price, stock, status, created_at, sku, image_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''))
+		RETURNING id, slug;`
 
 	getProductByIdQuery = `
-		SELECT seller_id, category_id, name, description, price, stock, is_approved, created_at
+		SELECT ` + productColumns + `
 		FROM products 
 		WHERE id = $1;`
 
 	updateProductByIdQuery = `
 		UPDATE products
-		SET category_id = $1, name = $2, description = $3, price = $4, stock = $5,
-		WHERE id = $6;`
+		SET category_id = $1, name = $2, description = $3, price = $4, stock = COALESCE($5, stock),
+		    status = $6, rejection_reason = NULLIF($7, ''), image_url = NULLIF($8, '')
+		WHERE id = $9 AND deleted_at IS NULL
+		RETURNING slug, stock;`
+
+	updateProductStatusQuery = `
+		UPDATE products
+		SET status = $1, rejection_reason = NULLIF($2, ''), moderated_at = $3
+		WHERE id = $4 AND status = ANY($5);`
+
+	deleteProductByIdQuery = `
+		UPDATE products
+		SET deleted_at = $1
+		WHERE id = $2 AND deleted_at IS NULL;`
 
-	deleteProductByIdQuery = `DELETE FROM products WHERE id = $1;`
+	archiveProductQuery = `
+		UPDATE products
+		SET archived_at = $1
+		WHERE id = $2 AND archived_at IS NULL AND deleted_at IS NULL;`
 
-	countQuery = `SELECT COUNT(*) FROM products;`
+	restoreProductQuery = `
+		UPDATE products
+		SET archived_at = NULL, deleted_at = NULL
+		WHERE id = $1 AND (archived_at IS NOT NULL OR deleted_at IS NOT NULL);`
+
+	// Products referenced by orders are kept forever, order history must not lose them.
+	// Products that are part of a bundle are kept until they are taken out of it.
+	purgeDeletedProductsQuery = `
+		DELETE FROM products
+		WHERE id IN (
+			SELECT p.id
+			FROM products p
+			WHERE p.deleted_at < $1
+			  AND NOT EXISTS (SELECT 1 FROM order_items oi WHERE oi.product_id = p.id)
+			  AND NOT EXISTS (SELECT 1 FROM order_item_components oc WHERE oc.product_id = p.id)
+			  AND NOT EXISTS (SELECT 1 FROM bundle_components bc WHERE bc.component_id = p.id)
+			ORDER BY p.deleted_at
+			LIMIT $2
+		);`
+
+	incrementViewCountsQuery = `
+		UPDATE products p
+		SET view_count = p.view_count + v.views
+		FROM unnest($1::bigint[], $2::bigint[]) AS v(id, views)
+		WHERE p.id = v.id;`
+
+	// Units sold and revenue are counted over all orders except canceled ones.
+	getSellerProductsQuery = `
+		SELECT ` + productColumns + `, view_count, COALESCE(sales.units, 0), COALESCE(sales.revenue, 0)
+		FROM products
+		LEFT JOIN LATERAL (
+			SELECT SUM(oi.quantity) AS units, SUM(oi.quantity * oi.price) AS revenue
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE oi.product_id = products.id AND o.status <> 'canceled'
+		) sales ON TRUE`
+
+	restockProductQuery = `
+		UPDATE products
+		SET stock = stock + $1
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING stock;`
 
 	getAllProductsQuery = `
-		SELECT seller_id, category_id, name, description, price, stock, is_approved, created_at
+		SELECT ` + productColumns + `
+		FROM products`
+
+	getProductsByStatusQuery = `
+		SELECT ` + productColumns + `
+		FROM products`
+
+	listAllProductsQuery = `
+		SELECT ` + productColumns + `
 		FROM products
-		ORDER BY name
-		LIMIT $1 OFFSET $2;`
+		WHERE status = 'approved' AND ` + visibleCondition + `
+		ORDER BY id;`
 
 	searchQuery = `
-		SELECT seller_id, category_id, name, description, price, stock, is_approved, created_at
+		SELECT ` + productColumns + `
+		FROM products`
+
+	// A slug is either the current slug of a product or one it had before, never both.
+	resolveProductSlugQuery = `
+		SELECT id, slug FROM products WHERE slug = $1
+		UNION ALL
+		SELECT p.id, p.slug
+		FROM product_slug_history h
+		JOIN products p ON p.id = h.product_id
+		WHERE h.slug = $1
+		LIMIT 1;`
+
+	getPriceHistoryQuery = `
+		SELECT product_id, price, changed_at
+		FROM product_price_history
+		WHERE product_id = $1
+		ORDER BY changed_at, id;`
+
+	exportProductsQuery = `
+		SELECT ` + productColumns + `
+		FROM products`
+
+	getProductsByIdsQuery = `
+		SELECT ` + productColumns + `
 		FROM products
-		WHERE to_tsvector('simple', name || ' ' || coalesce(description, '')) @@ plainto_tsquery('simple', $1)`
+		WHERE id = ANY($1) AND status = 'approved' AND ` + visibleCondition + `;`
+
+	getCategoryBestsellersQuery = `
+		SELECT ` + productColumns + `
+		FROM products
+		WHERE category_id = $1 AND status = 'approved' AND ` + visibleCondition + `
+		ORDER BY sold_count DESC, id
+		LIMIT $2;`
 )
 
+type productSortOrder struct {
+	column string
+	desc   bool
+}
+
+var productSortOrders = map[string]productSortOrder{
+	model.SortPriceAsc:  {column: "price", desc: false},
+	model.SortPriceDesc: {column: "price", desc: true},
+	model.SortNewest:    {column: "created_at", desc: true},
+	model.SortPopular:   {column: "sold_count", desc: true},
+	model.SortRating:    {column: "rating", desc: true},
+	model.SortOldest:    {column: "created_at", desc: false},
+}
+
 var (
-	createProductError  = errors.New(`error creating product`)
-	productNotFound     = errors.New(`product not found`)
-	updateProductError  = errors.New(`error updating product`)
-	deleteProductError  = errors.New(`error deleting product`)
-	getAllProductsError = errors.New(`error getting all products`)
-	searchProductsError = errors.New(`error searching products`)
+	invalidSortError     = errors.New(`invalid sort`)
+	updateStatusError    = errors.New(`error updating product status`)
+	getByStatusError     = errors.New(`error getting products by status`)
+	createProductError   = errors.New(`error creating product`)
+	productNotFound      = errors.New(`product not found`)
+	updateProductError   = errors.New(`error updating product`)
+	deleteProductError   = errors.New(`error deleting product`)
+	archiveProductError  = errors.New(`error archiving product`)
+	restoreProductError  = errors.New(`error restoring product`)
+	purgeProductsError   = errors.New(`error purging deleted products`)
+	restockProductError  = errors.New(`error restocking product`)
+	viewCountError       = errors.New(`error incrementing product view counts`)
+	sellerProductsError  = errors.New(`error getting seller products`)
+	getAllProductsError  = errors.New(`error getting all products`)
+	listAllProductsError = errors.New(`error listing all products`)
+	searchProductsError  = errors.New(`error searching products`)
+	exportProductsError  = errors.New(`error exporting products`)
+	resolveSlugError     = errors.New(`error resolving product slug`)
+	priceHistoryError    = errors.New(`error getting product price history`)
+	productsByIdsError   = errors.New(`error getting products by ids`)
+	bestsellersError     = errors.New(`error getting category bestsellers`)
 )
 
 type ProductRepo struct {
@@ -60,8 +209,85 @@ func NewProductRepo(db *pgxpool.Pool) *ProductRepo {
 	return &ProductRepo{db: db}
 }
 
+func scanProduct(row pgx.Row, product *model.Product) error {
+	return row.Scan(
+		&product.Id,
+		&product.SellerId,
+		&product.CategoryId,
+		&product.Name,
+		&product.Description,
+		&product.Price,
+		&product.Stock,
+		&product.Status,
+		&product.RejectionReason,
+		&product.SoldCount,
+		&product.Rating,
+		&product.ReviewCount,
+		&product.CreatedAt,
+		&product.ArchivedAt,
+		&product.DeletedAt,
+		&product.Sku,
+		&product.ImageUrl,
+		&product.Slug,
+		&product.LowestPrice30d,
+		&product.Warehoused,
+		&product.Bundle)
+}
+
+// keysetPage appends the cursor condition to where and returns the ORDER BY / LIMIT tail.
+// Ties on the sort column are broken by id, so pages stay stable while products are edited.
+func keysetPage(sort string, cursor *model.ProductCursor, limit int, where []string, args []interface{}) ([]string, []interface{}, string, error) {
+	order, ok := productSortOrders[sort]
+	if !ok {
+		return nil, nil, "", fmt.Errorf("%w: %s", invalidSortError, sort)
+	}
+
+	direction, cmp := "ASC", ">"
+	if order.desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	if cursor != nil {
+		var value interface{}
+		switch order.column {
+		case "price":
+			value = cursor.Price
+		case "sold_count":
+			value = cursor.SoldCount
+		case "rating":
+			value = cursor.Rating
+		default:
+			value = cursor.CreatedAt
+		}
+
+		where = append(where, fmt.Sprintf("(%s, id) %s ($%d, $%d)", order.column, cmp, len(args)+1, len(args)+2))
+		args = append(args, value, cursor.Id)
+	}
+
+	tail := fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d", order.column, direction, direction, len(args)+1)
+	args = append(args, limit)
+
+	return where, args, tail, nil
+}
+
+func whereClause(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(where, " AND ")
+}
+
+// CreateProduct saves the product and records its initial stock in the ledger.
 func (r *ProductRepo) CreateProduct(ctx context.Context, p *model.Product) error {
-	err := r.db.QueryRow(
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", createProductError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	p.CreatedAt = time.Now()
+	err = tx.QueryRow(
 		ctx, createProductQuery,
 		p.SellerId,
 		p.CategoryId,
@@ -69,29 +295,36 @@ func (r *ProductRepo) CreateProduct(ctx context.Context, p *model.Product) error
 		p.Description,
 		p.Price,
 		p.Stock,
-		false,
-		time.Now(),
-	).Scan(&p.Id)
+		p.Status,
+		p.CreatedAt,
+		p.Sku,
+		p.ImageUrl,
+	).Scan(&p.Id, &p.Slug)
+	if err != nil {
+		return fmt.Errorf("%w: %w", createProductError, err)
+	}
+
+	err = recordMovements(ctx, tx, model.InventoryMovement{
+		ProductId: p.Id,
+		Quantity:  p.Stock,
+		Reason:    model.MovementAdjustment,
+		ActorId:   p.SellerId,
+		CreatedAt: p.CreatedAt,
+	})
 	if err != nil {
 		return fmt.Errorf("%w: %w", createProductError, err)
 	}
 
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: %w", createProductError, err)
+	}
+
 	return nil
 }
 
 func (r *ProductRepo) GetProductById(ctx context.Context, id int64) (*model.Product, error) {
 	product := new(model.Product)
-	err := r.db.QueryRow(ctx, getProductByIdQuery, id).
-		Scan(
-			&product.SellerId,
-			&product.CategoryId,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.Stock,
-			&product.IsApproved,
-			&product.CreatedAt)
-
+	err := scanProduct(r.db.QueryRow(ctx, getProductByIdQuery, id), product)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", productNotFound, err)
 	}
@@ -99,31 +332,87 @@ func (r *ProductRepo) GetProductById(ctx context.Context, id int64) (*model.Prod
 	return product, nil
 }
 
-func (r *ProductRepo) UpdateProductById(ctx context.Context, product *model.Product) error {
-	cmdTag, err := r.db.Exec(
+// ResolveProductSlug finds the product by its current or a former slug and returns its
+// id and current slug. An unknown slug returns 0 and no error.
+func (r *ProductRepo) ResolveProductSlug(ctx context.Context, slug string) (int64, string, error) {
+	var (
+		id      int64
+		current string
+	)
+	err := r.db.QueryRow(ctx, resolveProductSlugQuery, slug).Scan(&id, &current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, "", nil
+	}
+
+	if err != nil {
+		return 0, "", fmt.Errorf("%w: %w", resolveSlugError, err)
+	}
+
+	return id, current, nil
+}
+
+// UpdateProductById overwrites the product. The stock is only set when stock is given,
+// otherwise the stored one is kept so orders placed meanwhile are not undone. A change
+// of the stock is recorded in the ledger as an adjustment made by the actor against the
+// stock locked here. product.Stock is set to the stored stock, the stock before the
+// update is returned.
+func (r *ProductRepo) UpdateProductById(ctx context.Context, product *model.Product, stock *int, actorId int64) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", updateProductError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var before int
+	err = tx.QueryRow(ctx, lockProductStockQuery, product.Id).Scan(&before)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %w", updateProductError, productNotFound)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", updateProductError, err)
+	}
+
+	// A new name may give the product a new slug.
+	err = tx.QueryRow(
 		ctx, updateProductByIdQuery,
 		product.CategoryId,
 		product.Name,
 		product.Description,
 		product.Price,
-		product.Stock,
-		product.Id)
+		stock,
+		product.Status,
+		product.RejectionReason,
+		product.ImageUrl,
+		product.Id).Scan(&product.Slug, &product.Stock)
 
 	if err != nil {
-		return fmt.Errorf("%w: %w", updateProductError, err)
+		return 0, fmt.Errorf("%w: %w", updateProductError, err)
 	}
 
-	if cmdTag.RowsAffected() == 0 {
-		return fmt.Errorf("%w: %w", updateProductError, productNotFound)
+	err = recordMovements(ctx, tx, model.InventoryMovement{
+		ProductId: product.Id,
+		Quantity:  product.Stock - before,
+		Reason:    model.MovementAdjustment,
+		ActorId:   actorId,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", updateProductError, err)
 	}
 
-	return nil
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%w: %w", updateProductError, err)
+	}
+
+	return before, nil
 }
 
+// DeleteProductById only marks the product as deleted, the row is removed later by PurgeDeletedProducts.
 func (r *ProductRepo) DeleteProductById(ctx context.Context, id int64) error {
-	cmtTag, err := r.db.Exec(ctx, deleteProductByIdQuery, id)
+	cmtTag, err := r.db.Exec(ctx, deleteProductByIdQuery, time.Now(), id)
 	if err != nil {
-		return deleteProductError
+		return fmt.Errorf("%w: %w", deleteProductError, err)
 	}
 
 	if cmtTag.RowsAffected() == 0 {
@@ -133,44 +422,291 @@ func (r *ProductRepo) DeleteProductById(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (r *ProductRepo) GetAllProducts(ctx context.Context, offset, limit int) (*[]model.Product, int64, error) {
-	rows, err := r.db.Query(ctx, getAllProductsQuery, limit, offset)
+func (r *ProductRepo) ArchiveProduct(ctx context.Context, id int64) error {
+	cmdTag, err := r.db.Exec(ctx, archiveProductQuery, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", archiveProductError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", archiveProductError, productNotFound)
+	}
+
+	return nil
+}
+
+// RestoreProduct brings an archived or soft deleted product back.
+func (r *ProductRepo) RestoreProduct(ctx context.Context, id int64) error {
+	cmdTag, err := r.db.Exec(ctx, restoreProductQuery, id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", restoreProductError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", restoreProductError, productNotFound)
+	}
+
+	return nil
+}
+
+// PurgeDeletedProducts removes up to limit products soft deleted before the given time
+// and returns how many rows were removed.
+func (r *ProductRepo) PurgeDeletedProducts(ctx context.Context, before time.Time, limit int) (int64, error) {
+	cmdTag, err := r.db.Exec(ctx, purgeDeletedProductsQuery, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", purgeProductsError, err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
+// RestockProduct adds quantity to the product stock and returns the new stock.
+func (r *ProductRepo) RestockProduct(ctx context.Context, id int64, quantity int, actorId int64) (int, error) {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return &[]model.Product{}, 0, fmt.Errorf("%w: %w", getAllProductsError, err)
+		return 0, fmt.Errorf("%w: %w", restockProductError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var stock int
+	err = tx.QueryRow(ctx, restockProductQuery, quantity, id).Scan(&stock)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %w", restockProductError, productNotFound)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", restockProductError, err)
+	}
+
+	err = recordMovements(ctx, tx, model.InventoryMovement{
+		ProductId: id,
+		Quantity:  quantity,
+		Reason:    model.MovementRestock,
+		ActorId:   actorId,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", restockProductError, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%w: %w", restockProductError, err)
+	}
+
+	return stock, nil
+}
+
+// IncrementViewCounts adds the aggregated views to the product counters in one statement.
+func (r *ProductRepo) IncrementViewCounts(ctx context.Context, counts map[int64]int64) error {
+	ids := make([]int64, 0, len(counts))
+	views := make([]int64, 0, len(counts))
+	for id, n := range counts {
+		ids = append(ids, id)
+		views = append(views, n)
+	}
+
+	if _, err := r.db.Exec(ctx, incrementViewCountsQuery, ids, views); err != nil {
+		return fmt.Errorf("%w: %w", viewCountError, err)
+	}
+
+	return nil
+}
+
+// GetSellerProducts returns products of one seller in any status together with their counters.
+func (r *ProductRepo) GetSellerProducts(
+	ctx context.Context,
+	sellerId int64,
+	filter *model.SellerProductFilter,
+	sort string,
+	cursor *model.ProductCursor,
+	limit int,
+) (*[]model.SellerProduct, error) {
+	where := []string{"seller_id = $1"}
+	args := []interface{}{sellerId}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where = append(where, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	switch filter.State {
+	case model.ProductStateActive:
+		where = append(where, visibleCondition)
+	case model.ProductStateArchived:
+		where = append(where, "archived_at IS NOT NULL AND deleted_at IS NULL")
+	case model.ProductStateDeleted:
+		where = append(where, "deleted_at IS NOT NULL")
+	default:
+		where = append(where, "deleted_at IS NULL")
+	}
+
+	switch filter.Stock {
+	case model.StockFilterInStock:
+		where = append(where, "stock > 0")
+	case model.StockFilterOutOfStock:
+		where = append(where, "stock = 0")
+	}
+
+	if filter.CategoryId != nil {
+		args = append(args, *filter.CategoryId)
+		where = append(where, fmt.Sprintf("category_id = $%d", len(args)))
+	}
+
+	where, args, tail, err := keysetPage(sort, cursor, limit, where, args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", sellerProductsError, err)
+	}
+
+	rows, err := r.db.Query(ctx, getSellerProductsQuery+whereClause(where)+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", sellerProductsError, err)
+	}
+	defer rows.Close()
+
+	var products []model.SellerProduct
+	for rows.Next() {
+		var p model.SellerProduct
+		err = rows.Scan(
+			&p.Id,
+			&p.SellerId,
+			&p.CategoryId,
+			&p.Name,
+			&p.Description,
+			&p.Price,
+			&p.Stock,
+			&p.Status,
+			&p.RejectionReason,
+			&p.SoldCount,
+			&p.Rating,
+			&p.ReviewCount,
+			&p.CreatedAt,
+			&p.ArchivedAt,
+			&p.DeletedAt,
+			&p.Sku,
+			&p.ImageUrl,
+			&p.Slug,
+			&p.LowestPrice30d,
+			&p.Warehoused,
+			&p.Bundle,
+			&p.ViewCount,
+			&p.UnitsSold,
+			&p.Revenue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", sellerProductsError, err)
+		}
+
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", sellerProductsError, rowsIterationError, err)
+	}
+
+	return &products, nil
+}
+
+// UpdateProductStatus moves the product to status only if its current status is one of from,
+// so concurrent moderation actions can't apply an invalid transition.
+func (r *ProductRepo) UpdateProductStatus(ctx context.Context, id int64, from []string, status, reason string) error {
+	cmdTag, err := r.db.Exec(ctx, updateProductStatusQuery, status, reason, time.Now(), id, from)
+	if err != nil {
+		return fmt.Errorf("%w: %w", updateStatusError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", updateStatusError, productNotFound)
+	}
+
+	return nil
+}
+
+func (r *ProductRepo) GetProductsByStatus(ctx context.Context, status string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
+	where := []string{"status = $1", "deleted_at IS NULL"}
+	args := []interface{}{status}
+
+	where, args, tail, err := keysetPage(model.SortOldest, cursor, limit, where, args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getByStatusError, err)
+	}
+
+	rows, err := r.db.Query(ctx, getProductsByStatusQuery+whereClause(where)+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getByStatusError, err)
 	}
 	defer rows.Close()
 
 	var products []model.Product
 	for rows.Next() {
 		var product model.Product
-		err = rows.Scan(
-			&product.SellerId,
-			&product.CategoryId,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.Stock,
-			&product.IsApproved,
-			&product.CreatedAt)
+		if err = scanProduct(rows, &product); err != nil {
+			return nil, fmt.Errorf("%w: %w", getByStatusError, err)
+		}
 
-		if err != nil {
-			return &[]model.Product{}, 0, fmt.Errorf("%w: %w", getAllProductsError, err)
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getByStatusError, rowsIterationError, err)
+	}
+
+	return &products, nil
+}
+
+func (r *ProductRepo) GetAllProducts(ctx context.Context, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
+	where := []string{"status = $1", visibleCondition}
+	args := []interface{}{model.ProductStatusApproved}
+
+	where, args, tail, err := keysetPage(sort, cursor, limit, where, args)
+	if err != nil {
+		return &[]model.Product{}, fmt.Errorf("%w: %w", getAllProductsError, err)
+	}
+
+	rows, err := r.db.Query(ctx, getAllProductsQuery+whereClause(where)+tail, args...)
+	if err != nil {
+		return &[]model.Product{}, fmt.Errorf("%w: %w", getAllProductsError, err)
+	}
+	defer rows.Close()
+
+	var products []model.Product
+	for rows.Next() {
+		var product model.Product
+		if err = scanProduct(rows, &product); err != nil {
+			return &[]model.Product{}, fmt.Errorf("%w: %w", getAllProductsError, err)
 		}
 
 		products = append(products, product)
 	}
 
 	if err := rows.Err(); err != nil {
-		return &[]model.Product{}, 0, fmt.Errorf("%w(%w): %w", getAllProductsError, rowsIterationError, err)
+		return &[]model.Product{}, fmt.Errorf("%w(%w): %w", getAllProductsError, rowsIterationError, err)
 	}
 
-	var total int64
-	err = r.db.QueryRow(ctx, countQuery).Scan(&total)
+	return &products, nil
+}
+
+// ListAllProducts returns the whole public catalog without pagination, it is used to rebuild search indexes.
+func (r *ProductRepo) ListAllProducts(ctx context.Context) (*[]model.Product, error) {
+	rows, err := r.db.Query(ctx, listAllProductsQuery)
 	if err != nil {
-		return &[]model.Product{}, 0, fmt.Errorf("%w: %w", getAllProductsError, err)
+		return nil, fmt.Errorf("%w: %w", listAllProductsError, err)
+	}
+	defer rows.Close()
+
+	var products []model.Product
+	for rows.Next() {
+		var product model.Product
+		if err = scanProduct(rows, &product); err != nil {
+			return nil, fmt.Errorf("%w: %w", listAllProductsError, err)
+		}
+
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", listAllProductsError, rowsIterationError, err)
 	}
 
-	return &products, total, nil
+	return &products, nil
 }
 
 func (r *ProductRepo) SearchProducts(
@@ -178,95 +714,152 @@ func (r *ProductRepo) SearchProducts(
 	text *string,
 	categoryId *int64,
 	min, max *float64,
-	offset, limit int,
-) (*[]model.Product, int64, error) {
-	sql := searchQuery
+	sort string,
+	cursor *model.ProductCursor,
+	limit int,
+) (*[]model.Product, error) {
+	where := []string{"status = $1", visibleCondition}
+	args := []interface{}{model.ProductStatusApproved}
 
-	args := []interface{}{text}
+	param := func() string { return fmt.Sprintf("$%d", len(args)+1) }
 
-	if categoryId != nil {
-		sql += fmt.Sprintf(" AND category_id = $%d", len(args))
+	if text != nil && strings.TrimSpace(*text) != "" {
+		where = append(where, "to_tsvector('simple', name || ' ' || coalesce(description, '')) @@ plainto_tsquery('simple', "+param()+")")
+		args = append(args, *text)
+	}
+
+	if categoryId != nil && *categoryId > 0 {
+		where = append(where, "category_id = "+param())
+		args = append(args, *categoryId)
 	}
 
 	if min != nil {
-		sql += fmt.Sprintf(" AND min = $%d", len(args))
+		where = append(where, "price >= "+param())
+		args = append(args, *min)
 	}
 
 	if max != nil {
-		sql += fmt.Sprintf(" AND max = $%d", len(args))
+		where = append(where, "price <= "+param())
+		args = append(args, *max)
 	}
 
-	args = append(args, offset, limit)
-	sql += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	where, args, tail, err := keysetPage(sort, cursor, limit, where, args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", searchProductsError, err)
+	}
 
-	rows, err := r.db.Query(ctx, sql, args...)
+	rows, err := r.db.Query(ctx, searchQuery+whereClause(where)+tail, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("%w: %w", searchProductsError, err)
+		return nil, fmt.Errorf("%w: %w", searchProductsError, err)
 	}
 	defer rows.Close()
 
 	var products []model.Product
 	for rows.Next() {
 		var product model.Product
-		err = rows.Scan(
-			&product.SellerId,
-			&product.CategoryId,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.Stock,
-			&product.IsApproved,
-			&product.CreatedAt,
-		)
-
-		if err != nil {
-			return &[]model.Product{}, 0, fmt.Errorf("%w: %w", searchProductsError, err)
+		if err = scanProduct(rows, &product); err != nil {
+			return &[]model.Product{}, fmt.Errorf("%w: %w", searchProductsError, err)
 		}
 
 		products = append(products, product)
 	}
 
 	if err := rows.Err(); err != nil {
-		return &[]model.Product{}, 0, fmt.Errorf("%w(%w): %w", searchProductsError, rowsIterationError, err)
+		return &[]model.Product{}, fmt.Errorf("%w(%w): %w", searchProductsError, rowsIterationError, err)
 	}
 
-	var where []string
-	var countArgs []interface{}
+	return &products, nil
+}
 
-	param := func() string { return fmt.Sprintf("$%d", len(countArgs)+1) }
+// ExportProducts walks the public catalog, optionally limited to one seller, and passes
+// every product to fn as soon as it is read, so the catalog is never held in memory.
+func (r *ProductRepo) ExportProducts(ctx context.Context, sellerId *int64, fn func(p *model.Product) error) error {
+	where := []string{"status = 'approved'", visibleCondition}
+	var args []interface{}
+	if sellerId != nil {
+		args = append(args, *sellerId)
+		where = append(where, fmt.Sprintf("seller_id = $%d", len(args)))
+	}
 
-	if text != nil && strings.TrimSpace(*text) != "" {
-		where = append(where, "title ILIKE '%' || "+param()+" || '%'")
-		countArgs = append(countArgs, *text)
+	rows, err := r.db.Query(ctx, exportProductsQuery+whereClause(where)+" ORDER BY id;", args...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", exportProductsError, err)
 	}
+	defer rows.Close()
 
-	if categoryId != nil && *categoryId > 0 {
-		where = append(where, "category_id = "+param())
-		countArgs = append(countArgs, *categoryId)
+	var product model.Product
+	for rows.Next() {
+		if err = scanProduct(rows, &product); err != nil {
+			return fmt.Errorf("%w: %w", exportProductsError, err)
+		}
+
+		if err = fn(&product); err != nil {
+			return err
+		}
 	}
 
-	if min != nil {
-		where = append(where, "price >= "+param())
-		countArgs = append(countArgs, *min)
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%w(%w): %w", exportProductsError, rowsIterationError, err)
 	}
 
-	if max != nil {
-		where = append(where, "price <= "+param())
-		countArgs = append(countArgs, *max)
+	return nil
+}
+
+// GetPriceHistory returns every price the product ever had, oldest first.
+func (r *ProductRepo) GetPriceHistory(ctx context.Context, productId int64) (*[]model.PriceChange, error) {
+	rows, err := r.db.Query(ctx, getPriceHistoryQuery, productId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", priceHistoryError, err)
 	}
+	defer rows.Close()
+
+	var history []model.PriceChange
+	for rows.Next() {
+		var change model.PriceChange
+		if err = rows.Scan(&change.ProductId, &change.Price, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", priceHistoryError, err)
+		}
 
-	var q string
-	if len(where) > 0 {
-		q = countQuery + " WHERE " + strings.Join(where, " AND ")
-	} else {
-		q = countQuery
+		history = append(history, change)
 	}
 
-	var total int64
-	err = r.db.QueryRow(ctx, q, countArgs...).Scan(&total)
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", priceHistoryError, rowsIterationError, err)
+	}
+
+	return &history, nil
+}
+
+// GetProductsByIds returns the listed products among ids, in no particular order.
+func (r *ProductRepo) GetProductsByIds(ctx context.Context, ids []int64) (*[]model.Product, error) {
+	return r.queryProducts(ctx, productsByIdsError, getProductsByIdsQuery, ids)
+}
+
+// GetCategoryBestsellers returns the listed products of the category, most sold first.
+func (r *ProductRepo) GetCategoryBestsellers(ctx context.Context, categoryId int64, limit int) (*[]model.Product, error) {
+	return r.queryProducts(ctx, bestsellersError, getCategoryBestsellersQuery, categoryId, limit)
+}
+
+func (r *ProductRepo) queryProducts(ctx context.Context, queryErr error, query string, args ...interface{}) (*[]model.Product, error) {
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return &[]model.Product{}, 0, fmt.Errorf("%w: %w", searchProductsError, err)
+		return nil, fmt.Errorf("%w: %w", queryErr, err)
+	}
+	defer rows.Close()
+
+	products := []model.Product{}
+	for rows.Next() {
+		var product model.Product
+		if err = scanProduct(rows, &product); err != nil {
+			return nil, fmt.Errorf("%w: %w", queryErr, err)
+		}
+
+		products = append(products, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", queryErr, rowsIterationError, err)
 	}
 
-	return &products, total, nil
+	return &products, nil
 }
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+// An edit that doesn't touch the stock must not undo orders placed meanwhile.
+func TestProductRepo_UpdateProductById_ConcurrentOrder(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	products := NewProductRepo(db)
+	orders := NewOrderRepo(db)
+
+	sellerId := testUser(t, db, "seller")
+	buyerId := testUser(t, db, "user")
+	productId := testProduct(t, db, sellerId, "Cable", 100)
+
+	// The edits work on the product as it was read before any order.
+	read, err := products.GetProductById(ctx, productId)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+
+	const rounds = 20
+	errc := make(chan error, 2*rounds)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			items := []model.OrderItem{{ProductId: productId, Quantity: 1, Price: 10}}
+			_, _, err := orders.CreateOrder(ctx, buyerId, &items)
+			errc <- err
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < rounds; i++ {
+			p := *read
+			p.Name = fmt.Sprintf("Cable %d", i)
+			_, err := products.UpdateProductById(ctx, &p, nil, sellerId)
+			errc <- err
+		}
+	}()
+	wg.Wait()
+	close(errc)
+
+	for err := range errc {
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	if stock, sold := productStock(t, db, productId); stock != 100-rounds || sold != rounds {
+		t.Fatalf("orders were undone: stock %d sold %d", stock, sold)
+	}
+
+	var adjustments int
+	err = db.QueryRow(ctx,
+		`SELECT count(*) FROM inventory_movements WHERE product_id = $1 AND reason = $2`,
+		productId, model.MovementAdjustment).Scan(&adjustments)
+	if err != nil {
+		t.Fatalf("get ledger: %v", err)
+	}
+	if adjustments != 0 {
+		t.Fatalf("an edit without stock must not be booked, got %d adjustments", adjustments)
+	}
+
+	// A stock set by the seller is booked against the stock at the time of the update.
+	stock := 50
+	p := *read
+	before, err := products.UpdateProductById(ctx, &p, &stock, sellerId)
+	if err != nil {
+		t.Fatalf("update stock: %v", err)
+	}
+	if before != 100-rounds || p.Stock != 50 {
+		t.Fatalf("unexpected stock: before %d after %d", before, p.Stock)
+	}
+
+	var quantity int
+	err = db.QueryRow(ctx,
+		`SELECT quantity FROM inventory_movements WHERE product_id = $1 AND reason = $2`,
+		productId, model.MovementAdjustment).Scan(&quantity)
+	if err != nil || quantity != 50-(100-rounds) {
+		t.Fatalf("unexpected adjustment %d: %v", quantity, err)
+	}
+}
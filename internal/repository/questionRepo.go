@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const questionColumns = `q.id, q.product_id, q.user_id, q.text, q.answer, q.answered_by, q.answered_at, q.upvotes, q.created_at`
+
+var (
+	createQuestionQuery = `
+		INSERT INTO product_questions (product_id, user_id, text, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id;`
+
+	getQuestionByIdQuery = `
+		SELECT ` + questionColumns + `
+		FROM product_questions q
+		WHERE q.id = $1;`
+
+	getQuestionsQuery = `
+		SELECT ` + questionColumns + `
+		FROM product_questions q`
+
+	getUnansweredQuestionsQuery = `
+		SELECT ` + questionColumns + `
+		FROM product_questions q
+		JOIN products p ON p.id = q.product_id`
+
+	answerQuestionQuery = `
+		UPDATE product_questions
+		SET answer = $1, answered_by = $2, answered_at = $3
+		WHERE id = $4;`
+
+	deleteQuestionQuery = `DELETE FROM product_questions WHERE id = $1;`
+
+	addQuestionVoteQuery = `
+		WITH vote AS (
+			INSERT INTO question_votes (question_id, user_id, created_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (question_id, user_id) DO NOTHING
+			RETURNING question_id
+		)
+		UPDATE product_questions SET upvotes = upvotes + 1
+		WHERE id IN (SELECT question_id FROM vote);`
+
+	removeQuestionVoteQuery = `
+		WITH vote AS (
+			DELETE FROM question_votes
+			WHERE question_id = $1 AND user_id = $2
+			RETURNING question_id
+		)
+		UPDATE product_questions SET upvotes = upvotes - 1
+		WHERE id IN (SELECT question_id FROM vote);`
+)
+
+var (
+	createQuestionError = errors.New("error creating question")
+	questionNotFound    = errors.New("question not found")
+	getQuestionError    = errors.New("error getting question")
+	getQuestionsError   = errors.New("error getting questions")
+	questionInboxError  = errors.New("error getting question inbox")
+	answerQuestionError = errors.New("error answering question")
+	deleteQuestionError = errors.New("error deleting question")
+	questionVoteError   = errors.New("error saving question vote")
+	invalidQuestionSort = errors.New("invalid question sort")
+)
+
+type QuestionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewQuestionRepo(db *pgxpool.Pool) *QuestionRepo {
+	return &QuestionRepo{db: db}
+}
+
+func (r *QuestionRepo) CreateQuestion(ctx context.Context, q *model.Question) error {
+	q.CreatedAt = time.Now()
+	err := r.db.QueryRow(ctx, createQuestionQuery, q.ProductId, q.UserId, q.Text, q.CreatedAt).Scan(&q.Id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", createQuestionError, err)
+	}
+
+	return nil
+}
+
+func (r *QuestionRepo) GetQuestionById(ctx context.Context, id int64) (*model.Question, error) {
+	q := new(model.Question)
+	err := scanQuestion(r.db.QueryRow(ctx, getQuestionByIdQuery, id), q)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %w", getQuestionError, questionNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getQuestionError, err)
+	}
+
+	return q, nil
+}
+
+// GetQuestionsByProduct returns a keyset page of the product questions, newest or most upvoted first.
+func (r *QuestionRepo) GetQuestionsByProduct(ctx context.Context, productId int64, sort string, cursor *model.QuestionCursor, limit int) (*[]model.Question, error) {
+	where := []string{"q.product_id = $1"}
+	args := []interface{}{productId}
+
+	where, args, tail, err := questionPage(sort, cursor, limit, where, args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getQuestionsError, err)
+	}
+
+	return r.queryQuestions(ctx, getQuestionsError, getQuestionsQuery+whereClause(where)+tail, args...)
+}
+
+// GetUnansweredQuestions returns the seller inbox: unanswered questions about the
+// seller's products that are not deleted, oldest first.
+func (r *QuestionRepo) GetUnansweredQuestions(ctx context.Context, sellerId int64, cursor *model.QuestionCursor, limit int) (*[]model.Question, error) {
+	where := []string{"p.seller_id = $1", "p.deleted_at IS NULL", "q.answer IS NULL"}
+	args := []interface{}{sellerId}
+
+	where, args, tail, err := questionPage(model.QuestionSortOldest, cursor, limit, where, args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", questionInboxError, err)
+	}
+
+	return r.queryQuestions(ctx, questionInboxError, getUnansweredQuestionsQuery+whereClause(where)+tail, args...)
+}
+
+func (r *QuestionRepo) AnswerQuestion(ctx context.Context, id int64, answer string, answeredBy int64, at time.Time) error {
+	cmdTag, err := r.db.Exec(ctx, answerQuestionQuery, answer, answeredBy, at, id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", answerQuestionError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", answerQuestionError, questionNotFound)
+	}
+
+	return nil
+}
+
+func (r *QuestionRepo) DeleteQuestion(ctx context.Context, id int64) error {
+	cmdTag, err := r.db.Exec(ctx, deleteQuestionQuery, id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", deleteQuestionError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", deleteQuestionError, questionNotFound)
+	}
+
+	return nil
+}
+
+func (r *QuestionRepo) AddQuestionVote(ctx context.Context, questionId, userId int64) error {
+	if _, err := r.db.Exec(ctx, addQuestionVoteQuery, questionId, userId, time.Now()); err != nil {
+		return fmt.Errorf("%w: %w", questionVoteError, err)
+	}
+
+	return nil
+}
+
+func (r *QuestionRepo) RemoveQuestionVote(ctx context.Context, questionId, userId int64) error {
+	if _, err := r.db.Exec(ctx, removeQuestionVoteQuery, questionId, userId); err != nil {
+		return fmt.Errorf("%w: %w", questionVoteError, err)
+	}
+
+	return nil
+}
+
+func (r *QuestionRepo) queryQuestions(ctx context.Context, queryErr error, query string, args ...interface{}) (*[]model.Question, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", queryErr, err)
+	}
+	defer rows.Close()
+
+	questions := []model.Question{}
+	for rows.Next() {
+		var q model.Question
+		if err = scanQuestion(rows, &q); err != nil {
+			return nil, fmt.Errorf("%w: %w", queryErr, err)
+		}
+		questions = append(questions, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", queryErr, rowsIterationError, err)
+	}
+
+	return &questions, nil
+}
+
+// questionPage appends the cursor condition to where and returns the ORDER BY / LIMIT tail.
+func questionPage(sort string, cursor *model.QuestionCursor, limit int, where []string, args []interface{}) ([]string, []interface{}, string, error) {
+	column, direction, cmp := "q.created_at", "DESC", "<"
+	switch sort {
+	case model.QuestionSortNewest:
+	case model.QuestionSortTop:
+		column = "q.upvotes"
+	case model.QuestionSortOldest:
+		direction, cmp = "ASC", ">"
+	default:
+		return nil, nil, "", fmt.Errorf("%w: %s", invalidQuestionSort, sort)
+	}
+
+	if cursor != nil {
+		var value interface{} = cursor.CreatedAt
+		if sort == model.QuestionSortTop {
+			value = cursor.Upvotes
+		}
+
+		where = append(where, fmt.Sprintf("(%s, q.id) %s ($%d, $%d)", column, cmp, len(args)+1, len(args)+2))
+		args = append(args, value, cursor.Id)
+	}
+
+	tail := fmt.Sprintf(" ORDER BY %s %s, q.id %s LIMIT $%d", column, direction, direction, len(args)+1)
+	args = append(args, limit)
+
+	return where, args, tail, nil
+}
+
+func scanQuestion(row pgx.Row, q *model.Question) error {
+	return row.Scan(
+		&q.Id,
+		&q.ProductId,
+		&q.UserId,
+		&q.Text,
+		&q.Answer,
+		&q.AnsweredBy,
+		&q.AnsweredAt,
+		&q.Upvotes,
+		&q.CreatedAt)
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var (
+	// Every pair is returned in both directions, canceled orders are not counted.
+	coPurchasesQuery = `
+		SELECT a.product_id, b.product_id, COUNT(DISTINCT a.order_id) AS orders
+		FROM order_items a
+		JOIN order_items b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+		JOIN orders o ON o.id = a.order_id
+		WHERE o.status <> $1
+		GROUP BY a.product_id, b.product_id
+		HAVING COUNT(DISTINCT a.order_id) >= $2;`
+)
+
+var (
+	coPurchasesError = errors.New("error getting co-purchases")
+)
+
+type RecommendationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRecommendationRepo(db *pgxpool.Pool) *RecommendationRepo {
+	return &RecommendationRepo{db: db}
+}
+
+// EachCoPurchase passes to fn every pair of products bought together in at least minOrders orders.
+func (r *RecommendationRepo) EachCoPurchase(ctx context.Context, minOrders int, fn func(c *model.CoPurchase) error) error {
+	rows, err := r.db.Query(ctx, coPurchasesQuery, model.OrderStatusCanceled, minOrders)
+	if err != nil {
+		return fmt.Errorf("%w: %w", coPurchasesError, err)
+	}
+	defer rows.Close()
+
+	var c model.CoPurchase
+	for rows.Next() {
+		if err = rows.Scan(&c.ProductId, &c.RelatedId, &c.Orders); err != nil {
+			return fmt.Errorf("%w: %w", coPurchasesError, err)
+		}
+
+		if err = fn(&c); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%w(%w): %w", coPurchasesError, rowsIterationError, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const reviewColumns = `id, product_id, user_id, rating, text, photos, seller_reply, replied_at, helpful_count, created_at, updated_at`
+
+var (
+	hasCompletedPurchaseQuery = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM orders o
+			JOIN order_items oi ON oi.order_id = o.id
+			WHERE o.user_id = $1 AND oi.product_id = $2 AND o.status = $3
+		);`
+
+	createReviewQuery = `
+		INSERT INTO reviews (product_id, user_id, rating, text, photos, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (product_id, user_id) DO NOTHING
+		RETURNING id;`
+
+	getReviewByIdQuery = `
+		SELECT ` + reviewColumns + `
+		FROM reviews
+		WHERE id = $1;`
+
+	getReviewsByProductQuery = `
+		SELECT ` + reviewColumns + `
+		FROM reviews`
+
+	updateReviewQuery = `
+		UPDATE reviews
+		SET rating = $1, text = $2, photos = $3, updated_at = $4
+		WHERE id = $5;`
+
+	deleteReviewQuery = `DELETE FROM reviews WHERE id = $1;`
+
+	replyReviewQuery = `
+		UPDATE reviews
+		SET seller_reply = $1, replied_at = $2
+		WHERE id = $3;`
+
+	// The counter only moves when the vote row was actually inserted or removed,
+	// so repeated votes of the same user are no-ops.
+	addReviewVoteQuery = `
+		WITH vote AS (
+			INSERT INTO review_votes (review_id, user_id, created_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (review_id, user_id) DO NOTHING
+			RETURNING review_id
+		)
+		UPDATE reviews SET helpful_count = helpful_count + 1
+		WHERE id IN (SELECT review_id FROM vote);`
+
+	removeReviewVoteQuery = `
+		WITH vote AS (
+			DELETE FROM review_votes
+			WHERE review_id = $1 AND user_id = $2
+			RETURNING review_id
+		)
+		UPDATE reviews SET helpful_count = helpful_count - 1
+		WHERE id IN (SELECT review_id FROM vote);`
+
+	refreshProductRatingQuery = `
+		UPDATE products
+		SET rating = COALESCE(s.avg_rating, 0), review_count = s.total
+		FROM (
+			SELECT ROUND(AVG(rating), 2) AS avg_rating, COUNT(*) AS total
+			FROM reviews
+			WHERE product_id = $1
+		) s
+		WHERE products.id = $1;`
+)
+
+var (
+	purchaseCheckError = errors.New("error checking purchase")
+	createReviewError  = errors.New("error creating review")
+	reviewNotFound     = errors.New("review not found")
+	getReviewError     = errors.New("error getting review")
+	getReviewsError    = errors.New("error getting reviews")
+	updateReviewError  = errors.New("error updating review")
+	deleteReviewError  = errors.New("error deleting review")
+	replyReviewError   = errors.New("error replying to review")
+	reviewVoteError    = errors.New("error saving review vote")
+	invalidReviewSort  = errors.New("invalid review sort")
+	refreshRatingError = errors.New("error refreshing product rating")
+)
+
+type ReviewRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewReviewRepo(db *pgxpool.Pool) *ReviewRepo {
+	return &ReviewRepo{db: db}
+}
+
+// HasCompletedPurchase reports whether the user has a completed order containing the product.
+func (r *ReviewRepo) HasCompletedPurchase(ctx context.Context, userId, productId int64) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, hasCompletedPurchaseQuery, userId, productId, model.OrderStatusCompleted).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", purchaseCheckError, err)
+	}
+
+	return exists, nil
+}
+
+// CreateReview saves the review and refreshes the product rating. It returns false
+// when the user has already reviewed the product.
+func (r *ReviewRepo) CreateReview(ctx context.Context, review *model.Review) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", createReviewError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	review.CreatedAt = time.Now()
+	review.UpdatedAt = review.CreatedAt
+	err = tx.QueryRow(
+		ctx, createReviewQuery,
+		review.ProductId, review.UserId, review.Rating, review.Text, review.Photos, review.CreatedAt,
+	).Scan(&review.Id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", createReviewError, err)
+	}
+
+	if _, err = tx.Exec(ctx, refreshProductRatingQuery, review.ProductId); err != nil {
+		return false, fmt.Errorf("%w(%w): %w", createReviewError, refreshRatingError, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("%w: %w", createReviewError, err)
+	}
+
+	return true, nil
+}
+
+func (r *ReviewRepo) GetReviewById(ctx context.Context, id int64) (*model.Review, error) {
+	review := new(model.Review)
+	err := scanReview(r.db.QueryRow(ctx, getReviewByIdQuery, id), review)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %w", getReviewError, reviewNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getReviewError, err)
+	}
+
+	return review, nil
+}
+
+// GetReviewsByProduct returns a keyset page of the product reviews, newest or most helpful first.
+func (r *ReviewRepo) GetReviewsByProduct(ctx context.Context, productId int64, sort string, cursor *model.ReviewCursor, limit int) (*[]model.Review, error) {
+	var column string
+	switch sort {
+	case model.ReviewSortNewest:
+		column = "created_at"
+	case model.ReviewSortHelpful:
+		column = "helpful_count"
+	default:
+		return nil, fmt.Errorf("%w: %w: %s", getReviewsError, invalidReviewSort, sort)
+	}
+
+	where := []string{"product_id = $1"}
+	args := []interface{}{productId}
+	if cursor != nil {
+		var value interface{} = cursor.CreatedAt
+		if sort == model.ReviewSortHelpful {
+			value = cursor.HelpfulCount
+		}
+
+		where = append(where, fmt.Sprintf("(%s, id) < ($%d, $%d)", column, len(args)+1, len(args)+2))
+		args = append(args, value, cursor.Id)
+	}
+
+	tail := fmt.Sprintf(" ORDER BY %s DESC, id DESC LIMIT $%d", column, len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(ctx, getReviewsByProductQuery+whereClause(where)+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getReviewsError, err)
+	}
+	defer rows.Close()
+
+	reviews := []model.Review{}
+	for rows.Next() {
+		var review model.Review
+		if err = scanReview(rows, &review); err != nil {
+			return nil, fmt.Errorf("%w: %w", getReviewsError, err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getReviewsError, rowsIterationError, err)
+	}
+
+	return &reviews, nil
+}
+
+func (r *ReviewRepo) UpdateReview(ctx context.Context, review *model.Review) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", updateReviewError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	review.UpdatedAt = time.Now()
+	cmdTag, err := tx.Exec(ctx, updateReviewQuery, review.Rating, review.Text, review.Photos, review.UpdatedAt, review.Id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", updateReviewError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", updateReviewError, reviewNotFound)
+	}
+
+	if _, err = tx.Exec(ctx, refreshProductRatingQuery, review.ProductId); err != nil {
+		return fmt.Errorf("%w(%w): %w", updateReviewError, refreshRatingError, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: %w", updateReviewError, err)
+	}
+
+	return nil
+}
+
+func (r *ReviewRepo) DeleteReview(ctx context.Context, review *model.Review) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", deleteReviewError, err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx, deleteReviewQuery, review.Id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", deleteReviewError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", deleteReviewError, reviewNotFound)
+	}
+
+	if _, err = tx.Exec(ctx, refreshProductRatingQuery, review.ProductId); err != nil {
+		return fmt.Errorf("%w(%w): %w", deleteReviewError, refreshRatingError, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: %w", deleteReviewError, err)
+	}
+
+	return nil
+}
+
+func (r *ReviewRepo) ReplyToReview(ctx context.Context, id int64, reply string, at time.Time) error {
+	cmdTag, err := r.db.Exec(ctx, replyReviewQuery, reply, at, id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", replyReviewError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", replyReviewError, reviewNotFound)
+	}
+
+	return nil
+}
+
+func (r *ReviewRepo) AddReviewVote(ctx context.Context, reviewId, userId int64) error {
+	if _, err := r.db.Exec(ctx, addReviewVoteQuery, reviewId, userId, time.Now()); err != nil {
+		return fmt.Errorf("%w: %w", reviewVoteError, err)
+	}
+
+	return nil
+}
+
+func (r *ReviewRepo) RemoveReviewVote(ctx context.Context, reviewId, userId int64) error {
+	if _, err := r.db.Exec(ctx, removeReviewVoteQuery, reviewId, userId); err != nil {
+		return fmt.Errorf("%w: %w", reviewVoteError, err)
+	}
+
+	return nil
+}
+
+func scanReview(row pgx.Row, review *model.Review) error {
+	return row.Scan(
+		&review.Id,
+		&review.ProductId,
+		&review.UserId,
+		&review.Rating,
+		&review.Text,
+		&review.Photos,
+		&review.SellerReply,
+		&review.RepliedAt,
+		&review.HelpfulCount,
+		&review.CreatedAt,
+		&review.UpdatedAt)
+}
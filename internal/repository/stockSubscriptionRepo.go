@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var (
+	// Subscribing again keeps a single row and extends its expiry.
+	subscribeStockQuery = `
+		INSERT INTO stock_subscriptions (product_id, user_id, created_at, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id, user_id) DO UPDATE
+		SET expires_at = EXCLUDED.expires_at
+		RETURNING created_at;`
+
+	unsubscribeStockQuery = `DELETE FROM stock_subscriptions WHERE product_id = $1 AND user_id = $2;`
+
+	getStockSubscribersQuery = `
+		SELECT user_id
+		FROM stock_subscriptions
+		WHERE product_id = $1 AND expires_at > $2 AND user_id > $3
+		ORDER BY user_id
+		LIMIT $4;`
+
+	deleteStockSubscriptionsQuery = `
+		DELETE FROM stock_subscriptions
+		WHERE product_id = $1 AND user_id = ANY($2);`
+
+	purgeStockSubscriptionsQuery = `DELETE FROM stock_subscriptions WHERE expires_at <= $1;`
+)
+
+var (
+	subscribeStockError           = errors.New("error saving stock subscription")
+	unsubscribeStockError         = errors.New("error removing stock subscription")
+	getStockSubscribersError      = errors.New("error getting stock subscribers")
+	deleteStockSubscriptionsError = errors.New("error deleting stock subscriptions")
+	purgeStockSubscriptionsError  = errors.New("error purging stock subscriptions")
+)
+
+type StockSubscriptionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewStockSubscriptionRepo(db *pgxpool.Pool) *StockSubscriptionRepo {
+	return &StockSubscriptionRepo{db: db}
+}
+
+func (r *StockSubscriptionRepo) SubscribeStock(ctx context.Context, sub *model.StockSubscription) error {
+	err := r.db.QueryRow(ctx, subscribeStockQuery, sub.ProductId, sub.UserId, sub.CreatedAt, sub.ExpiresAt).Scan(&sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", subscribeStockError, err)
+	}
+
+	return nil
+}
+
+// UnsubscribeStock returns false when the user is not subscribed to the product.
+func (r *StockSubscriptionRepo) UnsubscribeStock(ctx context.Context, productId, userId int64) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, unsubscribeStockQuery, productId, userId)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", unsubscribeStockError, err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// GetStockSubscribers returns a page of users with an active subscription to the product, ordered by id.
+func (r *StockSubscriptionRepo) GetStockSubscribers(ctx context.Context, productId int64, now time.Time, afterUserId int64, limit int) ([]int64, error) {
+	rows, err := r.db.Query(ctx, getStockSubscribersQuery, productId, now, afterUserId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getStockSubscribersError, err)
+	}
+	defer rows.Close()
+
+	var userIds []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%w: %w", getStockSubscribersError, err)
+		}
+		userIds = append(userIds, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getStockSubscribersError, rowsIterationError, err)
+	}
+
+	return userIds, nil
+}
+
+func (r *StockSubscriptionRepo) DeleteStockSubscriptions(ctx context.Context, productId int64, userIds []int64) error {
+	if _, err := r.db.Exec(ctx, deleteStockSubscriptionsQuery, productId, userIds); err != nil {
+		return fmt.Errorf("%w: %w", deleteStockSubscriptionsError, err)
+	}
+
+	return nil
+}
+
+// PurgeExpiredSubscriptions removes subscriptions expired before the given time and returns how many were removed.
+func (r *StockSubscriptionRepo) PurgeExpiredSubscriptions(ctx context.Context, before time.Time) (int64, error) {
+	cmdTag, err := r.db.Exec(ctx, purgeStockSubscriptionsQuery, before)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", purgeStockSubscriptionsError, err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
@@ -40,7 +40,9 @@ var (
 
 	updateUserRoleQuery = `UPDATE users SET role=$1 WHERE id=$2`
 
-	approveProductQuery = `UPDATE products SET is_approve=TRUE WHERE product_id=$2`
+	approveProductQuery = `
+		UPDATE products SET status='approved', rejection_reason=NULL, moderated_at=now()
+		WHERE id=$1 AND status IN ('pending_review', 'suspended')`
 )
 
 var (
@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const wishlistColumns = `w.id, w.user_id, w.name, w.share_token, w.created_at,
+	(SELECT COUNT(*) FROM wishlist_items wi WHERE wi.wishlist_id = w.id)`
+
+const wishlistItemColumns = `wi.wishlist_id, wi.product_id, wi.notify_price_drop, wi.notify_back_in_stock,
+	wi.last_notified_price, wi.last_in_stock, wi.added_at`
+
+var (
+	createWishlistQuery = `
+		INSERT INTO wishlists (user_id, name, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, name) DO NOTHING
+		RETURNING id;`
+
+	getWishlistByIdQuery = `
+		SELECT ` + wishlistColumns + `
+		FROM wishlists w
+		WHERE w.id = $1;`
+
+	getWishlistByTokenQuery = `
+		SELECT ` + wishlistColumns + `
+		FROM wishlists w
+		WHERE w.share_token = $1;`
+
+	getUserWishlistsQuery = `
+		SELECT ` + wishlistColumns + `
+		FROM wishlists w
+		WHERE w.user_id = $1
+		ORDER BY w.created_at, w.id;`
+
+	// The name check keeps the rename from violating the unique (user_id, name) constraint.
+	renameWishlistQuery = `
+		UPDATE wishlists
+		SET name = $1
+		WHERE id = $2 AND NOT EXISTS (
+			SELECT 1 FROM wishlists o
+			WHERE o.user_id = wishlists.user_id AND o.name = $1 AND o.id <> $2
+		);`
+
+	deleteWishlistQuery = `DELETE FROM wishlists WHERE id = $1;`
+
+	setShareTokenQuery = `UPDATE wishlists SET share_token = $1 WHERE id = $2;`
+
+	getWishlistItemsQuery = `
+		SELECT ` + wishlistItemColumns + `
+		FROM wishlist_items wi
+		WHERE wi.wishlist_id = $1
+		ORDER BY wi.added_at DESC, wi.product_id DESC;`
+
+	// Adding a product again updates its alert settings and restarts them from the current state.
+	upsertWishlistItemQuery = `
+		INSERT INTO wishlist_items (wishlist_id, product_id, notify_price_drop, notify_back_in_stock, last_notified_price, last_in_stock, added_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (wishlist_id, product_id) DO UPDATE
+		SET notify_price_drop = EXCLUDED.notify_price_drop,
+			notify_back_in_stock = EXCLUDED.notify_back_in_stock,
+			last_notified_price = EXCLUDED.last_notified_price,
+			last_in_stock = EXCLUDED.last_in_stock
+		RETURNING added_at;`
+
+	removeWishlistItemQuery = `DELETE FROM wishlist_items WHERE wishlist_id = $1 AND product_id = $2;`
+
+	// Only items of listed products are checked, alerts resume once a product is back in the catalog.
+	getWishlistAlertsQuery = `
+		SELECT ` + wishlistItemColumns + `, w.user_id, p.name, p.seller_id, p.category_id, p.price, p.stock
+		FROM wishlist_items wi
+		JOIN wishlists w ON w.id = wi.wishlist_id
+		JOIN products p ON p.id = wi.product_id
+		WHERE (wi.notify_price_drop OR wi.notify_back_in_stock)
+			AND p.status = 'approved' AND p.archived_at IS NULL AND p.deleted_at IS NULL
+			AND (wi.wishlist_id, wi.product_id) > ($1, $2)
+		ORDER BY wi.wishlist_id, wi.product_id
+		LIMIT $3;`
+
+	updateWishlistAlertsQuery = `
+		UPDATE wishlist_items wi
+		SET last_notified_price = s.price, last_in_stock = s.in_stock
+		FROM unnest($1::bigint[], $2::bigint[], $3::numeric[], $4::boolean[]) AS s(wishlist_id, product_id, price, in_stock)
+		WHERE wi.wishlist_id = s.wishlist_id AND wi.product_id = s.product_id;`
+)
+
+var (
+	createWishlistError     = errors.New("error creating wishlist")
+	wishlistNotFound        = errors.New("wishlist not found")
+	getWishlistError        = errors.New("error getting wishlist")
+	getWishlistsError       = errors.New("error getting wishlists")
+	renameWishlistError     = errors.New("error renaming wishlist")
+	deleteWishlistError     = errors.New("error deleting wishlist")
+	shareWishlistError      = errors.New("error sharing wishlist")
+	getWishlistItemsError   = errors.New("error getting wishlist items")
+	saveWishlistItemError   = errors.New("error saving wishlist item")
+	removeWishlistItemError = errors.New("error removing wishlist item")
+	getWishlistAlertsError  = errors.New("error getting wishlist alerts")
+	updateAlertsError       = errors.New("error updating wishlist alerts")
+)
+
+type WishlistRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewWishlistRepo(db *pgxpool.Pool) *WishlistRepo {
+	return &WishlistRepo{db: db}
+}
+
+// CreateWishlist returns false when the user already has a wishlist with the same name.
+func (r *WishlistRepo) CreateWishlist(ctx context.Context, w *model.Wishlist) (bool, error) {
+	w.CreatedAt = time.Now()
+	err := r.db.QueryRow(ctx, createWishlistQuery, w.UserId, w.Name, w.CreatedAt).Scan(&w.Id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", createWishlistError, err)
+	}
+
+	return true, nil
+}
+
+func (r *WishlistRepo) GetWishlistById(ctx context.Context, id int64) (*model.Wishlist, error) {
+	w := new(model.Wishlist)
+	err := scanWishlist(r.db.QueryRow(ctx, getWishlistByIdQuery, id), w)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %w", getWishlistError, wishlistNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getWishlistError, err)
+	}
+
+	return w, nil
+}
+
+// GetWishlistByToken returns nil when no wishlist is shared with the token.
+func (r *WishlistRepo) GetWishlistByToken(ctx context.Context, token string) (*model.Wishlist, error) {
+	w := new(model.Wishlist)
+	err := scanWishlist(r.db.QueryRow(ctx, getWishlistByTokenQuery, token), w)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getWishlistError, err)
+	}
+
+	return w, nil
+}
+
+func (r *WishlistRepo) GetUserWishlists(ctx context.Context, userId int64) (*[]model.Wishlist, error) {
+	rows, err := r.db.Query(ctx, getUserWishlistsQuery, userId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getWishlistsError, err)
+	}
+	defer rows.Close()
+
+	wishlists := []model.Wishlist{}
+	for rows.Next() {
+		var w model.Wishlist
+		if err = scanWishlist(rows, &w); err != nil {
+			return nil, fmt.Errorf("%w: %w", getWishlistsError, err)
+		}
+		wishlists = append(wishlists, w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getWishlistsError, rowsIterationError, err)
+	}
+
+	return &wishlists, nil
+}
+
+// RenameWishlist returns false when another wishlist of the user already has the name.
+func (r *WishlistRepo) RenameWishlist(ctx context.Context, id int64, name string) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, renameWishlistQuery, name, id)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", renameWishlistError, err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+func (r *WishlistRepo) DeleteWishlist(ctx context.Context, id int64) error {
+	cmdTag, err := r.db.Exec(ctx, deleteWishlistQuery, id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", deleteWishlistError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", deleteWishlistError, wishlistNotFound)
+	}
+
+	return nil
+}
+
+// SetShareToken sets or, with a nil token, clears the share token of the wishlist.
+func (r *WishlistRepo) SetShareToken(ctx context.Context, id int64, token *string) error {
+	cmdTag, err := r.db.Exec(ctx, setShareTokenQuery, token, id)
+	if err != nil {
+		return fmt.Errorf("%w: %w", shareWishlistError, err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %w", shareWishlistError, wishlistNotFound)
+	}
+
+	return nil
+}
+
+func (r *WishlistRepo) GetWishlistItems(ctx context.Context, wishlistId int64) (*[]model.WishlistItem, error) {
+	rows, err := r.db.Query(ctx, getWishlistItemsQuery, wishlistId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getWishlistItemsError, err)
+	}
+	defer rows.Close()
+
+	items := []model.WishlistItem{}
+	for rows.Next() {
+		var item model.WishlistItem
+		if err = scanWishlistItem(rows, &item); err != nil {
+			return nil, fmt.Errorf("%w: %w", getWishlistItemsError, err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getWishlistItemsError, rowsIterationError, err)
+	}
+
+	return &items, nil
+}
+
+func (r *WishlistRepo) SaveWishlistItem(ctx context.Context, item *model.WishlistItem) error {
+	err := r.db.QueryRow(
+		ctx, upsertWishlistItemQuery,
+		item.WishlistId, item.ProductId, item.NotifyPriceDrop, item.NotifyBackInStock,
+		item.LastNotifiedPrice, item.LastInStock, time.Now(),
+	).Scan(&item.AddedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", saveWishlistItemError, err)
+	}
+
+	return nil
+}
+
+// RemoveWishlistItem returns false when the product is not in the wishlist.
+func (r *WishlistRepo) RemoveWishlistItem(ctx context.Context, wishlistId, productId int64) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, removeWishlistItemQuery, wishlistId, productId)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", removeWishlistItemError, err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// GetWishlistAlerts returns a page of items with opted in alerts ordered by
+// (wishlist_id, product_id), starting after the given item.
+func (r *WishlistRepo) GetWishlistAlerts(ctx context.Context, afterWishlistId, afterProductId int64, limit int) (*[]model.WishlistAlert, error) {
+	rows, err := r.db.Query(ctx, getWishlistAlertsQuery, afterWishlistId, afterProductId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", getWishlistAlertsError, err)
+	}
+	defer rows.Close()
+
+	alerts := []model.WishlistAlert{}
+	for rows.Next() {
+		var a model.WishlistAlert
+		err = rows.Scan(
+			&a.WishlistId,
+			&a.ProductId,
+			&a.NotifyPriceDrop,
+			&a.NotifyBackInStock,
+			&a.LastNotifiedPrice,
+			&a.LastInStock,
+			&a.AddedAt,
+			&a.UserId,
+			&a.Name,
+			&a.SellerId,
+			&a.CategoryId,
+			&a.Price,
+			&a.Stock)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", getWishlistAlertsError, err)
+		}
+		alerts = append(alerts, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w(%w): %w", getWishlistAlertsError, rowsIterationError, err)
+	}
+
+	return &alerts, nil
+}
+
+// UpdateWishlistAlerts stores the last notified price and stock state of the items in one statement.
+func (r *WishlistRepo) UpdateWishlistAlerts(ctx context.Context, items []model.WishlistItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	wishlistIds := make([]int64, 0, len(items))
+	productIds := make([]int64, 0, len(items))
+	prices := make([]float64, 0, len(items))
+	inStock := make([]bool, 0, len(items))
+	for _, item := range items {
+		wishlistIds = append(wishlistIds, item.WishlistId)
+		productIds = append(productIds, item.ProductId)
+		prices = append(prices, item.LastNotifiedPrice)
+		inStock = append(inStock, item.LastInStock)
+	}
+
+	if _, err := r.db.Exec(ctx, updateWishlistAlertsQuery, wishlistIds, productIds, prices, inStock); err != nil {
+		return fmt.Errorf("%w: %w", updateAlertsError, err)
+	}
+
+	return nil
+}
+
+func scanWishlist(row pgx.Row, w *model.Wishlist) error {
+	return row.Scan(&w.Id, &w.UserId, &w.Name, &w.ShareToken, &w.CreatedAt, &w.ItemCount)
+}
+
+func scanWishlistItem(row pgx.Row, item *model.WishlistItem) error {
+	return row.Scan(
+		&item.WishlistId,
+		&item.ProductId,
+		&item.NotifyPriceDrop,
+		&item.NotifyBackInStock,
+		&item.LastNotifiedPrice,
+		&item.LastInStock,
+		&item.AddedAt)
+}
@@ -0,0 +1,218 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+// MemoryIndex is an embedded inverted index kept in the application memory.
+// Searches never touch the database, the index is filled by Reindex and kept
+// current through Index and Delete calls.
+//
+// Every process has its own copy and only sees the changes made through it, the
+// engine is meant for a single application instance. Several instances behind a
+// balancer should use the postgres engine.
+type MemoryIndex struct {
+	source IProductSource
+
+	mu       sync.RWMutex
+	docs     map[int64]model.Product
+	postings map[string]map[int64]struct{}
+}
+
+func NewMemoryIndex(source IProductSource) *MemoryIndex {
+	return &MemoryIndex{
+		source:   source,
+		docs:     make(map[int64]model.Product),
+		postings: make(map[string]map[int64]struct{}),
+	}
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func (i *MemoryIndex) Search(ctx context.Context, q Query) (*[]model.Product, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var candidates map[int64]struct{}
+	if q.Text != nil {
+		tokens := tokenize(*q.Text)
+
+		// Text made of punctuation only matches nothing, the same as an empty
+		// tsquery in postgres, instead of dropping the filter.
+		if len(tokens) == 0 && *q.Text != "" {
+			return &[]model.Product{}, nil
+		}
+
+		for _, token := range tokens {
+			ids := i.postings[token]
+			if candidates == nil {
+				candidates = make(map[int64]struct{}, len(ids))
+				for id := range ids {
+					candidates[id] = struct{}{}
+				}
+				continue
+			}
+
+			for id := range candidates {
+				if _, ok := ids[id]; !ok {
+					delete(candidates, id)
+				}
+			}
+		}
+	}
+
+	var matched []model.Product
+	for id, doc := range i.docs {
+		if candidates != nil {
+			if _, ok := candidates[id]; !ok {
+				continue
+			}
+		}
+
+		if q.CategoryId != nil && *q.CategoryId > 0 && doc.CategoryId != *q.CategoryId {
+			continue
+		}
+
+		if q.Min != nil && doc.Price < *q.Min {
+			continue
+		}
+
+		if q.Max != nil && doc.Price > *q.Max {
+			continue
+		}
+
+		matched = append(matched, doc)
+	}
+
+	sort.Slice(matched, func(a, b int) bool {
+		return before(q.Sort, &matched[a], &matched[b])
+	})
+
+	if q.Cursor != nil {
+		last := model.Product{
+			Id:        q.Cursor.Id,
+			Price:     q.Cursor.Price,
+			CreatedAt: q.Cursor.CreatedAt,
+			SoldCount: q.Cursor.SoldCount,
+			Rating:    q.Cursor.Rating,
+		}
+		start := sort.Search(len(matched), func(n int) bool {
+			return before(q.Sort, &last, &matched[n])
+		})
+		matched = matched[start:]
+	}
+
+	if q.Limit > 0 && q.Limit < len(matched) {
+		matched = matched[:q.Limit]
+	}
+
+	return &matched, nil
+}
+
+// before reports whether a goes before b in the given sort order, ties are broken by id
+// the same way the postgres keyset queries do.
+func before(order string, a, b *model.Product) bool {
+	switch order {
+	case model.SortPriceAsc:
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.Id < b.Id
+	case model.SortPriceDesc:
+		if a.Price != b.Price {
+			return a.Price > b.Price
+		}
+	case model.SortPopular:
+		if a.SoldCount != b.SoldCount {
+			return a.SoldCount > b.SoldCount
+		}
+	case model.SortRating:
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+	default:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+	}
+
+	return a.Id > b.Id
+}
+
+func (i *MemoryIndex) Index(ctx context.Context, product *model.Product) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(product.Id)
+	i.add(*product)
+	return nil
+}
+
+func (i *MemoryIndex) Delete(ctx context.Context, productId int64) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(productId)
+	return nil
+}
+
+func (i *MemoryIndex) Reindex(ctx context.Context) error {
+	products, err := i.source.ListAllProducts(ctx)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.docs = make(map[int64]model.Product, len(*products))
+	i.postings = make(map[string]map[int64]struct{})
+	for _, product := range *products {
+		i.add(product)
+	}
+
+	return nil
+}
+
+// add indexes only listed products, the index mirrors the public catalog.
+func (i *MemoryIndex) add(product model.Product) {
+	if !product.Listed() {
+		return
+	}
+
+	i.docs[product.Id] = product
+	for _, token := range tokenize(product.Name + " " + product.Description) {
+		ids, ok := i.postings[token]
+		if !ok {
+			ids = make(map[int64]struct{})
+			i.postings[token] = ids
+		}
+		ids[product.Id] = struct{}{}
+	}
+}
+
+func (i *MemoryIndex) remove(productId int64) {
+	doc, ok := i.docs[productId]
+	if !ok {
+		return
+	}
+
+	for _, token := range tokenize(doc.Name + " " + doc.Description) {
+		ids := i.postings[token]
+		delete(ids, productId)
+		if len(ids) == 0 {
+			delete(i.postings, token)
+		}
+	}
+	delete(i.docs, productId)
+}
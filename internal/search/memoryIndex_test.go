@@ -0,0 +1,98 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+func TestMemoryIndex_Search(t *testing.T) {
+	now := time.Now()
+	idx := NewMemoryIndex(nil)
+	products := []model.Product{
+		{Id: 1, CategoryId: 1, Name: "Игровая консоль", Description: "Black edition", Price: 500, Status: model.ProductStatusApproved, CreatedAt: now.Add(-2 * time.Hour)},
+		{Id: 2, CategoryId: 1, Name: "Геймпад", Description: "для игровая консоль", Price: 60, Status: model.ProductStatusApproved, CreatedAt: now.Add(-time.Hour)},
+		{Id: 3, CategoryId: 2, Name: "Black kettle", Price: 30, Status: model.ProductStatusApproved, CreatedAt: now},
+	}
+	for i := range products {
+		if err := idx.Index(context.Background(), &products[i]); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	text := "консоль"
+	got, err := idx.Search(context.Background(), Query{Text: &text, Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(*got) != 2 || (*got)[0].Id != 2 || (*got)[1].Id != 1 {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+
+	text = "BLACK"
+	min := 100.0
+	got, err = idx.Search(context.Background(), Query{Text: &text, Min: &min, Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(*got) != 1 || (*got)[0].Id != 1 {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+
+	text = "!!!"
+	got, err = idx.Search(context.Background(), Query{Text: &text, Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(*got) != 0 {
+		t.Fatalf("text without tokens must match nothing, got %+v", got)
+	}
+
+	category := int64(1)
+	cursor := model.NewProductCursor(model.SortPriceAsc, &products[1])
+	got, err = idx.Search(context.Background(), Query{CategoryId: &category, Sort: model.SortPriceAsc, Cursor: &cursor, Limit: 10})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(*got) != 1 || (*got)[0].Id != 1 {
+		t.Fatalf("unexpected page: %+v", got)
+	}
+}
+
+func TestMemoryIndex_UpdateAndDelete(t *testing.T) {
+	idx := NewMemoryIndex(nil)
+	p := model.Product{Id: 1, Name: "Old name", Status: model.ProductStatusApproved}
+	_ = idx.Index(context.Background(), &p)
+
+	p.Name = "New name"
+	_ = idx.Index(context.Background(), &p)
+
+	text := "old"
+	got, _ := idx.Search(context.Background(), Query{Text: &text})
+	if len(*got) != 0 {
+		t.Fatalf("stale token still indexed")
+	}
+
+	text = "new"
+	got, _ = idx.Search(context.Background(), Query{Text: &text})
+	if len(*got) != 1 {
+		t.Fatalf("updated token not indexed")
+	}
+
+	p.Status = model.ProductStatusSuspended
+	_ = idx.Index(context.Background(), &p)
+	got, _ = idx.Search(context.Background(), Query{Text: &text})
+	if len(*got) != 0 {
+		t.Fatalf("suspended product still indexed")
+	}
+
+	p.Status = model.ProductStatusApproved
+	_ = idx.Index(context.Background(), &p)
+	_ = idx.Delete(context.Background(), 1)
+	got, _ = idx.Search(context.Background(), Query{Text: &text})
+	if len(*got) != 0 {
+		t.Fatalf("deleted product still indexed")
+	}
+}
@@ -0,0 +1,33 @@
+package search
+
+import (
+	"context"
+
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+// PostgresIndex delegates search to the products table. The source may be
+// backed by a read replica so search traffic stays off the primary database.
+type PostgresIndex struct {
+	source IProductSource
+}
+
+func NewPostgresIndex(source IProductSource) *PostgresIndex {
+	return &PostgresIndex{source: source}
+}
+
+func (i *PostgresIndex) Search(ctx context.Context, q Query) (*[]model.Product, error) {
+	return i.source.SearchProducts(ctx, q.Text, q.CategoryId, q.Min, q.Max, q.Sort, q.Cursor, q.Limit)
+}
+
+func (i *PostgresIndex) Index(ctx context.Context, product *model.Product) error {
+	return nil
+}
+
+func (i *PostgresIndex) Delete(ctx context.Context, productId int64) error {
+	return nil
+}
+
+func (i *PostgresIndex) Reindex(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,45 @@
+package search
+
+import (
+	"context"
+
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const (
+	EnginePostgres = "postgres"
+	EngineMemory   = "memory"
+)
+
+type Query struct {
+	Text       *string
+	CategoryId *int64
+	Min        *float64
+	Max        *float64
+	Sort       string
+	Cursor     *model.ProductCursor
+	Limit      int
+}
+
+// SearchIndex is the full-text search backend used by the product service.
+// Index and Delete are called on product create, update and delete events,
+// Reindex rebuilds the whole index from the products table.
+type SearchIndex interface {
+	Search(ctx context.Context, q Query) (*[]model.Product, error)
+	Index(ctx context.Context, product *model.Product) error
+	Delete(ctx context.Context, productId int64) error
+	Reindex(ctx context.Context) error
+}
+
+type IProductSource interface {
+	SearchProducts(
+		ctx context.Context,
+		text *string,
+		categoryId *int64,
+		min, max *float64,
+		sort string,
+		cursor *model.ProductCursor,
+		limit int,
+	) (*[]model.Product, error)
+	ListAllProducts(ctx context.Context) (*[]model.Product, error)
+}
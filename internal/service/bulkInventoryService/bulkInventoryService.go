@@ -0,0 +1,252 @@
+package bulkInventoryService
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/search"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultMaxItems      = 5000
+	maxIdempotencyKeyLen = 255
+	maxPrice             = 1e8
+)
+
+type IBulkInventoryRepository interface {
+	ApplyBulkInventoryUpdate(ctx context.Context, u *model.BulkInventoryUpdate) (bool, error)
+}
+
+type IProductReader interface {
+	GetProductsByIds(ctx context.Context, ids []int64) (*[]model.Product, error)
+}
+
+// IRestockListener is told when a listed product goes from sold out to in stock.
+type IRestockListener interface {
+	Restocked(productId int64, name string)
+}
+
+// ILowStockChecker is told about products whose stock went down.
+type ILowStockChecker interface {
+	StockDecreased(productIds []int64)
+}
+
+// BulkInventoryService applies batches of stock and price changes sent by the sellers'
+// integrations, every batch in one transaction.
+type BulkInventoryService struct {
+	repo     IBulkInventoryRepository
+	products IProductReader
+	index    search.SearchIndex
+	cache    *redis.Client
+	restocks IRestockListener
+	lowStock ILowStockChecker
+	cfg      config.BulkInventoryConfig
+}
+
+func NewBulkInventoryService(
+	repo IBulkInventoryRepository,
+	products IProductReader,
+	index search.SearchIndex,
+	cache *redis.Client,
+	restocks IRestockListener,
+	lowStock ILowStockChecker,
+	cfg config.BulkInventoryConfig,
+) *BulkInventoryService {
+	if cfg.MaxItems <= 0 {
+		cfg.MaxItems = defaultMaxItems
+	}
+
+	return &BulkInventoryService{
+		repo:     repo,
+		products: products,
+		index:    index,
+		cache:    cache,
+		restocks: restocks,
+		lowStock: lowStock,
+		cfg:      cfg,
+	}
+}
+
+// Update validates the items one by one and applies the valid ones. The response has
+// an outcome for every item in the order of the request. A request repeated with the
+// same idempotency key gets the stored outcome and changes nothing.
+func (s *BulkInventoryService) Update(ctx context.Context, sellerId int64, req *model.BulkInventoryRequest) (model.BulkInventoryResponse, error) {
+	if len(req.Items) > s.cfg.MaxItems {
+		return model.BulkInventoryResponse{}, fmt.Errorf("%w: a batch can have at most %d items", errs.ValidationError, s.cfg.MaxItems)
+	}
+
+	if len(req.IdempotencyKey) > maxIdempotencyKeyLen {
+		return model.BulkInventoryResponse{}, fmt.Errorf("%w: idempotency key is longer than %d characters", errs.ValidationError, maxIdempotencyKeyLen)
+	}
+
+	var (
+		invalid []model.BulkInventoryResult
+		valid   []model.BulkInventoryItem
+	)
+	for i, item := range req.Items {
+		if reason := validateItem(&item); reason != "" {
+			invalid = append(invalid, model.BulkInventoryResult{
+				Index:     i,
+				ProductId: item.ProductId,
+				Sku:       item.Sku,
+				Status:    model.BulkItemInvalid,
+				Error:     reason,
+			})
+			continue
+		}
+
+		valid = append(valid, model.BulkInventoryItem{
+			Index:     i,
+			ProductId: item.ProductId,
+			Sku:       item.Sku,
+			Stock:     item.Stock,
+			Price:     item.Price,
+		})
+	}
+
+	hash, err := requestHash(req.Items)
+	if err != nil {
+		return model.BulkInventoryResponse{}, err
+	}
+
+	u := model.BulkInventoryUpdate{
+		SellerId:       sellerId,
+		IdempotencyKey: req.IdempotencyKey,
+		RequestHash:    hash,
+		Items:          valid,
+	}
+	if len(valid) > 0 {
+		ok, err := s.repo.ApplyBulkInventoryUpdate(ctx, &u)
+		if err != nil {
+			return model.BulkInventoryResponse{}, err
+		}
+
+		if !ok {
+			return model.BulkInventoryResponse{}, fmt.Errorf("%w: idempotency key was already used for a different request", errs.ConflictError)
+		}
+
+		if !u.Replayed {
+			s.applied(ctx, u.Results)
+		}
+	}
+
+	results := append(invalid, u.Results...)
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+
+	resp := model.BulkInventoryResponse{
+		Replayed: u.Replayed,
+		Items:    make([]model.BulkInventoryItemResponse, 0, len(results)),
+	}
+	for _, res := range results {
+		switch res.Status {
+		case model.BulkItemUpdated:
+			resp.Updated++
+		case model.BulkItemUnchanged:
+			resp.Unchanged++
+		default:
+			resp.Failed++
+		}
+
+		resp.Items = append(resp.Items, model.BulkInventoryItemResponse{
+			Index:     res.Index,
+			ProductId: res.ProductId,
+			Sku:       res.Sku,
+			Status:    res.Status,
+			Error:     res.Error,
+		})
+	}
+
+	return resp, nil
+}
+
+// applied invalidates the catalog cache once for the whole batch and reindexes the
+// updated products. The batch is committed already, so failures are only logged.
+func (s *BulkInventoryService) applied(ctx context.Context, results []model.BulkInventoryResult) {
+	var (
+		ids       []int64
+		decreased []int64
+		restocked = make(map[int64]bool)
+	)
+	for _, res := range results {
+		if res.Status != model.BulkItemUpdated {
+			continue
+		}
+
+		ids = append(ids, res.ProductId)
+		if res.PreviousStock == 0 && res.Stock > 0 {
+			restocked[res.ProductId] = true
+		}
+		if res.Stock < res.PreviousStock {
+			decreased = append(decreased, res.ProductId)
+		}
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	s.cache.Del(ctx, "products:all")
+
+	// Only listed products are returned, the others are neither searchable nor awaited.
+	products, err := s.products.GetProductsByIds(ctx, ids)
+	if err != nil {
+		slog.Error("bulk inventory reindex failed", "product_ids", ids, "error", err)
+	} else {
+		for i := range *products {
+			p := &(*products)[i]
+			if err = s.index.Index(ctx, p); err != nil {
+				slog.Error("bulk inventory reindex failed", "product_id", p.Id, "error", err)
+			}
+
+			if restocked[p.Id] {
+				s.restocks.Restocked(p.Id, p.Name)
+			}
+		}
+	}
+
+	if len(decreased) > 0 {
+		s.lowStock.StockDecreased(decreased)
+	}
+}
+
+func validateItem(item *model.BulkInventoryItemRequest) string {
+	switch {
+	case item.ProductId == 0 && item.Sku == "":
+		return "product_id or sku is required"
+	case item.ProductId != 0 && item.Sku != "":
+		return "only one of product_id and sku can be set"
+	case item.ProductId < 0:
+		return "product_id must be positive"
+	case item.Stock == nil && item.Price == nil:
+		return "stock or price is required"
+	case item.Stock != nil && *item.Stock < 0:
+		return "stock must not be negative"
+	case item.Price != nil && *item.Price <= 0:
+		return "price must be positive"
+	case item.Price != nil && *item.Price >= maxPrice:
+		return "price is too large"
+	}
+
+	return ""
+}
+
+// requestHash identifies the content of the batch, so a reused idempotency key with
+// different items is told apart from a retry.
+func requestHash(items []model.BulkInventoryItemRequest) (string, error) {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,218 @@
+package bulkInventoryService
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/search"
+)
+
+type mockRepo struct {
+	results  []model.BulkInventoryResult
+	replayed bool
+	ok       bool
+	applied  *model.BulkInventoryUpdate
+}
+
+func (m *mockRepo) ApplyBulkInventoryUpdate(ctx context.Context, u *model.BulkInventoryUpdate) (bool, error) {
+	m.applied = u
+	u.Results = m.results
+	u.Replayed = m.replayed
+	return m.ok, nil
+}
+
+type mockProducts struct{}
+
+func (m *mockProducts) GetProductsByIds(ctx context.Context, ids []int64) (*[]model.Product, error) {
+	products := make([]model.Product, 0, len(ids))
+	for _, id := range ids {
+		products = append(products, model.Product{Id: id, Name: "Kettle"})
+	}
+	return &products, nil
+}
+
+type mockIndex struct {
+	indexed []int64
+}
+
+func (m *mockIndex) Search(ctx context.Context, q search.Query) (*[]model.Product, error) {
+	return &[]model.Product{}, nil
+}
+func (m *mockIndex) Index(ctx context.Context, product *model.Product) error {
+	m.indexed = append(m.indexed, product.Id)
+	return nil
+}
+func (m *mockIndex) Delete(ctx context.Context, productId int64) error { return nil }
+func (m *mockIndex) Reindex(ctx context.Context) error                 { return nil }
+
+type mockRestocks struct {
+	restocked []int64
+}
+
+func (m *mockRestocks) Restocked(productId int64, name string) {
+	m.restocked = append(m.restocked, productId)
+}
+
+type mockLowStock struct {
+	decreased []int64
+}
+
+func (m *mockLowStock) StockDecreased(productIds []int64) {
+	m.decreased = append(m.decreased, productIds...)
+}
+
+func intPtr(v int) *int           { return &v }
+func floatPtr(v float64) *float64 { return &v }
+
+func TestBulkInventoryService_Update(t *testing.T) {
+	repo := &mockRepo{
+		ok: true,
+		results: []model.BulkInventoryResult{
+			{Index: 0, ProductId: 7, Status: model.BulkItemUpdated, PreviousStock: 0, Stock: 5},
+			{Index: 2, ProductId: 8, Sku: "KT-1", Status: model.BulkItemUpdated, PreviousStock: 9, Stock: 3},
+			{Index: 3, ProductId: 9, Status: model.BulkItemUnchanged, PreviousStock: 4, Stock: 4},
+			{Index: 4, ProductId: 10, Status: model.BulkItemNotFound, Error: "product not found"},
+		},
+	}
+	index := &mockIndex{}
+	restocks := &mockRestocks{}
+	lowStock := &mockLowStock{}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	s := NewBulkInventoryService(repo, &mockProducts{}, index, client, restocks, lowStock, config.BulkInventoryConfig{})
+
+	resp, err := s.Update(context.Background(), 2, &model.BulkInventoryRequest{
+		IdempotencyKey: "sync-1",
+		Items: []model.BulkInventoryItemRequest{
+			{ProductId: 7, Stock: intPtr(5)},
+			{ProductId: 8, Sku: "KT-1", Stock: intPtr(1)},
+			{Sku: "KT-1", Stock: intPtr(3)},
+			{ProductId: 9, Price: floatPtr(100)},
+			{ProductId: 10, Stock: intPtr(1)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo.applied.SellerId != 2 || repo.applied.IdempotencyKey != "sync-1" || repo.applied.RequestHash == "" {
+		t.Fatalf("unexpected update: %+v", repo.applied)
+	}
+	if len(repo.applied.Items) != 4 || repo.applied.Items[1].Index != 2 {
+		t.Fatalf("only the valid items must be applied: %+v", repo.applied.Items)
+	}
+	if resp.Updated != 2 || resp.Unchanged != 1 || resp.Failed != 2 || len(resp.Items) != 5 {
+		t.Fatalf("unexpected summary: %+v", resp)
+	}
+	for i, item := range resp.Items {
+		if item.Index != i {
+			t.Fatalf("items must follow the request order: %+v", resp.Items)
+		}
+	}
+	if resp.Items[1].Status != model.BulkItemInvalid {
+		t.Fatalf("an item with both product_id and sku is invalid: %+v", resp.Items[1])
+	}
+	if len(index.indexed) != 2 {
+		t.Fatalf("the updated products must be reindexed: %v", index.indexed)
+	}
+	if len(restocks.restocked) != 1 || restocks.restocked[0] != 7 {
+		t.Fatalf("unexpected restocks: %v", restocks.restocked)
+	}
+	if len(lowStock.decreased) != 1 || lowStock.decreased[0] != 8 {
+		t.Fatalf("unexpected low stock check: %v", lowStock.decreased)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestBulkInventoryService_UpdateReplayed(t *testing.T) {
+	repo := &mockRepo{
+		ok:       true,
+		replayed: true,
+		results:  []model.BulkInventoryResult{{Index: 0, ProductId: 7, Status: model.BulkItemUpdated}},
+	}
+	index := &mockIndex{}
+	restocks := &mockRestocks{}
+	lowStock := &mockLowStock{}
+	client, mock := redismock.NewClientMock()
+	s := NewBulkInventoryService(repo, &mockProducts{}, index, client, restocks, lowStock, config.BulkInventoryConfig{})
+
+	resp, err := s.Update(context.Background(), 2, &model.BulkInventoryRequest{
+		IdempotencyKey: "sync-1",
+		Items:          []model.BulkInventoryItemRequest{{ProductId: 7, Stock: intPtr(5)}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !resp.Replayed || resp.Updated != 1 {
+		t.Fatalf("the stored outcome must be returned: %+v", resp)
+	}
+	if len(index.indexed) != 0 || len(restocks.restocked) != 0 || len(lowStock.decreased) != 0 {
+		t.Fatalf("a replayed batch must have no side effects")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestBulkInventoryService_UpdateErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		items       int
+		ok          bool
+		expectedErr error
+	}{
+		{name: "too many items", items: 3, ok: true, expectedErr: errs.ValidationError},
+		{name: "key too long", key: string(make([]byte, 256)), items: 1, ok: true, expectedErr: errs.ValidationError},
+		{name: "key reused for another batch", key: "sync-1", items: 1, ok: false, expectedErr: errs.ConflictError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := redismock.NewClientMock()
+			s := NewBulkInventoryService(&mockRepo{ok: tt.ok}, &mockProducts{}, &mockIndex{}, client, &mockRestocks{}, &mockLowStock{}, config.BulkInventoryConfig{MaxItems: 2})
+
+			items := make([]model.BulkInventoryItemRequest, tt.items)
+			for i := range items {
+				items[i] = model.BulkInventoryItemRequest{ProductId: int64(i + 1), Stock: intPtr(1)}
+			}
+
+			_, err := s.Update(context.Background(), 2, &model.BulkInventoryRequest{IdempotencyKey: tt.key, Items: items})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateItem(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  model.BulkInventoryItemRequest
+		valid bool
+	}{
+		{"stock by id", model.BulkInventoryItemRequest{ProductId: 1, Stock: intPtr(0)}, true},
+		{"price by sku", model.BulkInventoryItemRequest{Sku: "KT-1", Price: floatPtr(99.9)}, true},
+		{"no product", model.BulkInventoryItemRequest{Stock: intPtr(1)}, false},
+		{"nothing to change", model.BulkInventoryItemRequest{ProductId: 1}, false},
+		{"negative stock", model.BulkInventoryItemRequest{ProductId: 1, Stock: intPtr(-1)}, false},
+		{"zero price", model.BulkInventoryItemRequest{ProductId: 1, Price: floatPtr(0)}, false},
+		{"huge price", model.BulkInventoryItemRequest{ProductId: 1, Price: floatPtr(1e9)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if reason := validateItem(&tt.item); (reason == "") != tt.valid {
+				t.Fatalf("unexpected validation result: %q", reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package bundleService
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/policy"
+	"github.com/niklvrr/myMarketplace/internal/search"
+	"github.com/redis/go-redis/v9"
+)
+
+type IBundleRepository interface {
+	GetBundleComponents(ctx context.Context, bundleId int64) (*[]model.BundleComponent, error)
+	GetBundleCandidates(ctx context.Context, ids []int64) (*[]model.Product, error)
+	SetBundleComponents(ctx context.Context, sellerId, bundleId int64, components []model.BundleComponent) (int, bool, error)
+}
+
+type IProductReader interface {
+	GetProductById(ctx context.Context, productId int64) (*model.Product, error)
+}
+
+// IRestockListener is told when a listed product goes from sold out to in stock.
+type IRestockListener interface {
+	Restocked(productId int64, name string)
+}
+
+// ILowStockChecker is told about products whose stock went down.
+type ILowStockChecker interface {
+	StockDecreased(productIds []int64)
+}
+
+// BundleService manages bundles: products sold at their own price and taken from the
+// stock of the products they are made of.
+type BundleService struct {
+	repo     IBundleRepository
+	products IProductReader
+	index    search.SearchIndex
+	cache    *redis.Client
+	restocks IRestockListener
+	lowStock ILowStockChecker
+}
+
+func NewBundleService(
+	repo IBundleRepository,
+	products IProductReader,
+	index search.SearchIndex,
+	cache *redis.Client,
+	restocks IRestockListener,
+	lowStock ILowStockChecker,
+) *BundleService {
+	return &BundleService{
+		repo:     repo,
+		products: products,
+		index:    index,
+		cache:    cache,
+		restocks: restocks,
+		lowStock: lowStock,
+	}
+}
+
+// Components returns the composition of the bundle. Bundles that aren't listed are
+// visible only to their seller and to admins, like the products themselves.
+func (s *BundleService) Components(ctx context.Context, userId int64, role string, req *model.BundleRequest) (model.BundleResponse, error) {
+	p, err := s.products.GetProductById(ctx, req.ProductId)
+	if err != nil {
+		return model.BundleResponse{}, err
+	}
+
+	if !p.Listed() && p.SellerId != userId && role != policy.RoleAdmin {
+		return model.BundleResponse{}, fmt.Errorf("%w: product not found", errs.NotFoundError)
+	}
+
+	if !p.Bundle {
+		return model.BundleResponse{}, fmt.Errorf("%w: product %d is not a bundle", errs.NotFoundError, p.Id)
+	}
+
+	return s.bundleResponse(ctx, p)
+}
+
+// SetComponents replaces the composition of the product, the first components turn it
+// into a bundle and an empty list turns it back into a regular product. The components
+// are products of the same seller, a bundle can't contain bundles.
+func (s *BundleService) SetComponents(ctx context.Context, userId int64, role string, req *model.SetBundleComponentsRequest) (model.BundleResponse, error) {
+	p, err := s.products.GetProductById(ctx, req.ProductId)
+	if err != nil {
+		return model.BundleResponse{}, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return model.BundleResponse{}, err
+	}
+
+	if p.DeletedAt != nil {
+		return model.BundleResponse{}, fmt.Errorf("%w: product is deleted, restore it first", errs.ConflictError)
+	}
+
+	if p.Warehoused && len(req.Components) > 0 {
+		return model.BundleResponse{}, fmt.Errorf("%w: product %d keeps its stock per warehouse and can't become a bundle", errs.ConflictError, p.Id)
+	}
+
+	components, err := s.components(ctx, p, req.Components)
+	if err != nil {
+		return model.BundleResponse{}, err
+	}
+
+	stock, ok, err := s.repo.SetBundleComponents(ctx, p.SellerId, p.Id, components)
+	if err != nil {
+		return model.BundleResponse{}, err
+	}
+
+	if !ok {
+		return model.BundleResponse{}, fmt.Errorf("%w: a bundle can't contain bundles or be part of one", errs.ConflictError)
+	}
+
+	before := p.Stock
+	p.Stock = stock
+	p.Bundle = len(components) > 0
+	s.cache.Del(ctx, "products:all")
+	if err = s.index.Index(ctx, p); err != nil {
+		return model.BundleResponse{}, err
+	}
+
+	if before == 0 && stock > 0 && p.Listed() {
+		s.restocks.Restocked(p.Id, p.Name)
+	}
+	if stock < before {
+		s.lowStock.StockDecreased([]int64{p.Id})
+	}
+
+	return s.bundleResponse(ctx, p)
+}
+
+// components checks the requested components against the products they refer to.
+func (s *BundleService) components(ctx context.Context, bundle *model.Product, req []model.BundleComponentRequest) ([]model.BundleComponent, error) {
+	if len(req) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, 0, len(req))
+	seen := make(map[int64]bool, len(req))
+	for _, c := range req {
+		if c.ProductId == bundle.Id {
+			return nil, fmt.Errorf("%w: a bundle can't contain itself", errs.ValidationError)
+		}
+
+		if seen[c.ProductId] {
+			return nil, fmt.Errorf("%w: product %d is listed twice", errs.ValidationError, c.ProductId)
+		}
+		seen[c.ProductId] = true
+		ids = append(ids, c.ProductId)
+	}
+
+	candidates, err := s.repo.GetBundleCandidates(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int64]*model.Product, len(*candidates))
+	for i := range *candidates {
+		found[(*candidates)[i].Id] = &(*candidates)[i]
+	}
+
+	components := make([]model.BundleComponent, 0, len(req))
+	for _, c := range req {
+		p, ok := found[c.ProductId]
+		if !ok || p.SellerId != bundle.SellerId {
+			return nil, fmt.Errorf("%w: product %d", errs.NotFoundError, c.ProductId)
+		}
+
+		if p.DeletedAt != nil {
+			return nil, fmt.Errorf("%w: product %d is deleted", errs.ConflictError, p.Id)
+		}
+
+		if p.Bundle {
+			return nil, fmt.Errorf("%w: product %d is a bundle itself", errs.ConflictError, p.Id)
+		}
+
+		components = append(components, model.BundleComponent{
+			BundleId:  bundle.Id,
+			ProductId: p.Id,
+			Quantity:  c.Quantity,
+		})
+	}
+
+	return components, nil
+}
+
+func (s *BundleService) bundleResponse(ctx context.Context, p *model.Product) (model.BundleResponse, error) {
+	components, err := s.repo.GetBundleComponents(ctx, p.Id)
+	if err != nil {
+		return model.BundleResponse{}, err
+	}
+
+	resp := model.BundleResponse{
+		ProductId:  p.Id,
+		Price:      p.Price,
+		Stock:      p.Stock,
+		Components: make([]model.BundleComponentResponse, 0, len(*components)),
+	}
+	for _, c := range *components {
+		resp.Components = append(resp.Components, model.BundleComponentResponse{
+			ProductId: c.ProductId,
+			Name:      c.Name,
+			Slug:      c.Slug,
+			Quantity:  c.Quantity,
+			Price:     c.Price,
+			Stock:     c.Stock,
+		})
+	}
+
+	return resp, nil
+}
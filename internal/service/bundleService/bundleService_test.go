@@ -0,0 +1,239 @@
+package bundleService
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/search"
+)
+
+type mockRepo struct {
+	products   map[int64]model.Product
+	components []model.BundleComponent
+	stock      int
+	nested     bool
+	set        []model.BundleComponent
+	setCalled  bool
+}
+
+func (m *mockRepo) GetBundleComponents(ctx context.Context, bundleId int64) (*[]model.BundleComponent, error) {
+	return &m.components, nil
+}
+func (m *mockRepo) GetBundleCandidates(ctx context.Context, ids []int64) (*[]model.Product, error) {
+	result := []model.Product{}
+	for _, id := range ids {
+		if p, ok := m.products[id]; ok {
+			result = append(result, p)
+		}
+	}
+	return &result, nil
+}
+func (m *mockRepo) SetBundleComponents(ctx context.Context, sellerId, bundleId int64, components []model.BundleComponent) (int, bool, error) {
+	m.setCalled = true
+	if m.nested {
+		return 0, false, nil
+	}
+	m.set = components
+	return m.stock, true, nil
+}
+
+type mockProducts struct {
+	products map[int64]model.Product
+}
+
+func (m *mockProducts) GetProductById(ctx context.Context, productId int64) (*model.Product, error) {
+	p, ok := m.products[productId]
+	if !ok {
+		return nil, errors.New("product not found")
+	}
+	return &p, nil
+}
+
+type mockIndex struct {
+	indexed []model.Product
+}
+
+func (m *mockIndex) Search(ctx context.Context, q search.Query) (*[]model.Product, error) {
+	return &[]model.Product{}, nil
+}
+func (m *mockIndex) Index(ctx context.Context, product *model.Product) error {
+	m.indexed = append(m.indexed, *product)
+	return nil
+}
+func (m *mockIndex) Delete(ctx context.Context, productId int64) error { return nil }
+func (m *mockIndex) Reindex(ctx context.Context) error                 { return nil }
+
+type mockRestocks struct {
+	restocked []int64
+}
+
+func (m *mockRestocks) Restocked(productId int64, name string) {
+	m.restocked = append(m.restocked, productId)
+}
+
+type mockLowStock struct {
+	decreased []int64
+}
+
+func (m *mockLowStock) StockDecreased(productIds []int64) {
+	m.decreased = append(m.decreased, productIds...)
+}
+
+var deletedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var testProducts = map[int64]model.Product{
+	1:  {Id: 1, SellerId: 2, Name: "Console set", Price: 500, Status: model.ProductStatusApproved},
+	2:  {Id: 2, SellerId: 2, Name: "Console", Price: 400, Stock: 3},
+	3:  {Id: 3, SellerId: 2, Name: "Controller", Price: 60, Stock: 10},
+	4:  {Id: 4, SellerId: 3, Name: "Foreign", Stock: 5},
+	5:  {Id: 5, SellerId: 2, Name: "Old", Stock: 5, DeletedAt: &deletedAt},
+	6:  {Id: 6, SellerId: 2, Name: "Other set", Bundle: true},
+	7:  {Id: 7, SellerId: 2, Name: "Stored", Stock: 4, Warehoused: true},
+	8:  {Id: 8, SellerId: 2, Name: "Listed set", Stock: 3, Bundle: true, Status: model.ProductStatusApproved},
+	9:  {Id: 9, SellerId: 2, Name: "Draft set", Stock: 3, Bundle: true, Status: model.ProductStatusDraft},
+	10: {Id: 10, SellerId: 2, Name: "Plain", Status: model.ProductStatusApproved},
+}
+
+func TestBundleService_SetComponents(t *testing.T) {
+	tests := []struct {
+		name        string
+		userId      int64
+		productId   int64
+		components  []model.BundleComponentRequest
+		nested      bool
+		stock       int
+		expectedErr error
+		restocked   bool
+		decreased   bool
+	}{
+		{
+			name: "bundle", userId: 2, productId: 1, stock: 3, restocked: true,
+			components: []model.BundleComponentRequest{{ProductId: 2, Quantity: 1}, {ProductId: 3, Quantity: 2}},
+		},
+		{name: "unbundle", userId: 2, productId: 8, stock: 0, decreased: true, components: []model.BundleComponentRequest{}},
+		{
+			name: "another seller's bundle", userId: 3, productId: 1, expectedErr: errs.NotOwnerError,
+			components: []model.BundleComponentRequest{{ProductId: 2, Quantity: 1}},
+		},
+		{
+			name: "itself", userId: 2, productId: 1, expectedErr: errs.ValidationError,
+			components: []model.BundleComponentRequest{{ProductId: 1, Quantity: 1}},
+		},
+		{
+			name: "duplicate component", userId: 2, productId: 1, expectedErr: errs.ValidationError,
+			components: []model.BundleComponentRequest{{ProductId: 2, Quantity: 1}, {ProductId: 2, Quantity: 2}},
+		},
+		{
+			name: "another seller's component", userId: 2, productId: 1, expectedErr: errs.NotFoundError,
+			components: []model.BundleComponentRequest{{ProductId: 4, Quantity: 1}},
+		},
+		{
+			name: "deleted component", userId: 2, productId: 1, expectedErr: errs.ConflictError,
+			components: []model.BundleComponentRequest{{ProductId: 5, Quantity: 1}},
+		},
+		{
+			name: "bundle component", userId: 2, productId: 1, expectedErr: errs.ConflictError,
+			components: []model.BundleComponentRequest{{ProductId: 6, Quantity: 1}},
+		},
+		{
+			name: "warehoused bundle", userId: 2, productId: 7, expectedErr: errs.ConflictError,
+			components: []model.BundleComponentRequest{{ProductId: 2, Quantity: 1}},
+		},
+		{
+			name: "part of another bundle", userId: 2, productId: 1, nested: true, expectedErr: errs.ConflictError,
+			components: []model.BundleComponentRequest{{ProductId: 2, Quantity: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{products: testProducts, nested: tt.nested, stock: tt.stock}
+			index := &mockIndex{}
+			restocks := &mockRestocks{}
+			lowStock := &mockLowStock{}
+			client, mock := redismock.NewClientMock()
+			if tt.expectedErr == nil {
+				mock.ExpectDel("products:all").SetVal(1)
+			}
+			s := NewBundleService(repo, &mockProducts{products: testProducts}, index, client, restocks, lowStock)
+
+			req := &model.SetBundleComponentsRequest{ProductId: tt.productId, Components: tt.components}
+			resp, err := s.SetComponents(context.Background(), tt.userId, "seller", req)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr != nil {
+				if repo.setCalled && !tt.nested {
+					t.Fatalf("the composition must not be changed")
+				}
+				return
+			}
+
+			if len(repo.set) != len(tt.components) {
+				t.Fatalf("unexpected composition: %+v", repo.set)
+			}
+			for i, c := range repo.set {
+				if c.BundleId != tt.productId || c.ProductId != tt.components[i].ProductId || c.Quantity != tt.components[i].Quantity {
+					t.Fatalf("unexpected component: %+v", c)
+				}
+			}
+			if resp.ProductId != tt.productId || resp.Stock != tt.stock {
+				t.Fatalf("unexpected response: %+v", resp)
+			}
+			if len(index.indexed) != 1 || index.indexed[0].Stock != tt.stock || index.indexed[0].Bundle != (len(tt.components) > 0) {
+				t.Fatalf("the product must be reindexed with the new stock: %+v", index.indexed)
+			}
+			if (len(restocks.restocked) == 1) != tt.restocked {
+				t.Fatalf("unexpected restock: %v", restocks.restocked)
+			}
+			if (len(lowStock.decreased) == 1) != tt.decreased {
+				t.Fatalf("unexpected low stock check: %v", lowStock.decreased)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("redis expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestBundleService_Components(t *testing.T) {
+	tests := []struct {
+		name        string
+		userId      int64
+		role        string
+		productId   int64
+		expectedErr error
+	}{
+		{"listed bundle", 5, "user", 8, nil},
+		{"own draft bundle", 2, "seller", 9, nil},
+		{"admin", 1, "admin", 9, nil},
+		{"someone else's draft bundle", 5, "user", 9, errs.NotFoundError},
+		{"not a bundle", 5, "user", 10, errs.NotFoundError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{components: []model.BundleComponent{{BundleId: tt.productId, ProductId: 2, Quantity: 1, Name: "Console", Price: 400}}}
+			client, _ := redismock.NewClientMock()
+			s := NewBundleService(repo, &mockProducts{products: testProducts}, &mockIndex{}, client, &mockRestocks{}, &mockLowStock{})
+
+			resp, err := s.Components(context.Background(), tt.userId, tt.role, &model.BundleRequest{ProductId: tt.productId})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+
+			p := testProducts[tt.productId]
+			if resp.Price != p.Price || resp.Stock != p.Stock || len(resp.Components) != 1 || resp.Components[0].Name != "Console" {
+				t.Fatalf("unexpected response: %+v", resp)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package cartService
 
 import (
 	"context"
+	"errors"
+	"math"
 
+	"github.com/niklvrr/myMarketplace/internal/errs"
 	"github.com/niklvrr/myMarketplace/internal/model"
 )
 
@@ -14,12 +17,22 @@ type ICartRepository interface {
 	ClearCart(ctx context.Context, cartId int64) error
 }
 
+// IPriceResolver knows the unit price a customer buys a quantity of a product at,
+// including active discounts and quantity breaks.
+type IPriceResolver interface {
+	EffectivePrice(ctx context.Context, userId, productId int64, quantity int) (float64, error)
+}
+
 type CartService struct {
-	repo ICartRepository
+	repo   ICartRepository
+	prices IPriceResolver
 }
 
-func NewCartService(repo ICartRepository) *CartService {
-	return &CartService{repo: repo}
+func NewCartService(repo ICartRepository, prices IPriceResolver) *CartService {
+	return &CartService{
+		repo:   repo,
+		prices: prices,
+	}
 }
 
 func (s *CartService) GetCartByUserId(ctx context.Context, req *model.GetCartByUserIdRequest) (*model.CartResponse, error) {
@@ -33,6 +46,9 @@ func (s *CartService) GetCartByUserId(ctx context.Context, req *model.GetCartByU
 	}, nil
 }
 
+// GetCartItemsByCartId prices every item for the customer at its quantity, the same
+// way the order will be charged when it is placed now. Products taken off sale are
+// left out, they can't be ordered.
 func (s *CartService) GetCartItemsByCartId(ctx context.Context, req *model.GetCartItemsByCartIdRequest) (*[]model.CartItemResponse, error) {
 	cartId := req.CartId
 	cartItems, err := s.repo.GetCartItemsByCartId(ctx, cartId)
@@ -42,11 +58,22 @@ func (s *CartService) GetCartItemsByCartId(ctx context.Context, req *model.GetCa
 
 	var items []model.CartItemResponse
 	for _, item := range *cartItems {
+		price, err := s.prices.EffectivePrice(ctx, req.UserId, item.ProductId, int(item.Quantity))
+		if errors.Is(err, errs.NotFoundError) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
 		items = append(items, model.CartItemResponse{
 			Id:        item.Id,
 			CartId:    cartId,
 			ProductId: item.ProductId,
 			Quantity:  item.Quantity,
+			UnitPrice: price,
+			Total:     math.Round(price*float64(item.Quantity)*100) / 100,
 		})
 	}
 
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/niklvrr/myMarketplace/internal/errs"
 	"github.com/niklvrr/myMarketplace/internal/model"
 )
 
@@ -33,6 +34,24 @@ func (m *mockRepo) ClearCart(ctx context.Context, cartId int64) error {
 	return m.ClearCartFn(ctx, cartId)
 }
 
+// mockPricer sells products at their wholesale price from 3 units on.
+type mockPricer struct {
+	prices    map[int64]float64
+	wholesale map[int64]float64
+	userIds   []int64
+}
+
+func (m *mockPricer) EffectivePrice(ctx context.Context, userId, productId int64, quantity int) (float64, error) {
+	m.userIds = append(m.userIds, userId)
+	if _, ok := m.prices[productId]; !ok {
+		return 0, errs.NotFoundError
+	}
+	if wholesale, ok := m.wholesale[productId]; ok && quantity >= 3 {
+		return wholesale, nil
+	}
+	return m.prices[productId], nil
+}
+
 func TestCartService_GetCartByUserId(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -56,7 +75,7 @@ func TestCartService_GetCartByUserId(t *testing.T) {
 					return tt.repoCart, tt.repoErr
 				},
 			}
-			s := NewCartService(repo)
+			s := NewCartService(repo, &mockPricer{})
 			resp, err := s.GetCartByUserId(context.Background(), &model.GetCartByUserIdRequest{UserId: tt.userId})
 			if tt.wantErr {
 				if err == nil {
@@ -89,12 +108,13 @@ func TestCartService_GetCartItemsByCartId(t *testing.T) {
 			&[]model.CartItem{
 				{Id: 1, ProductId: 11, Quantity: 2},
 				{Id: 2, ProductId: 22, Quantity: 3},
+				{Id: 3, ProductId: 33, Quantity: 1},
 			},
 			nil,
 			false,
 			[]model.CartItemResponse{
-				{Id: 1, CartId: 5, ProductId: 11, Quantity: 2},
-				{Id: 2, CartId: 5, ProductId: 22, Quantity: 3},
+				{Id: 1, CartId: 5, ProductId: 11, Quantity: 2, UnitPrice: 9.99, Total: 19.98},
+				{Id: 2, CartId: 5, ProductId: 22, Quantity: 3, UnitPrice: 80, Total: 240},
 			},
 		},
 		{"repo error", 6, nil, errors.New("repo err"), true, nil},
@@ -110,8 +130,9 @@ func TestCartService_GetCartItemsByCartId(t *testing.T) {
 					return tt.repoItems, tt.repoErr
 				},
 			}
-			s := NewCartService(repo)
-			resp, err := s.GetCartItemsByCartId(context.Background(), &model.GetCartItemsByCartIdRequest{CartId: tt.cartId})
+			pricer := &mockPricer{prices: map[int64]float64{11: 9.99, 22: 100}, wholesale: map[int64]float64{22: 80}}
+			s := NewCartService(repo, pricer)
+			resp, err := s.GetCartItemsByCartId(context.Background(), &model.GetCartItemsByCartIdRequest{CartId: tt.cartId, UserId: 4})
 			if tt.wantErr {
 				if err == nil {
 					t.Fatalf("expected error")
@@ -124,6 +145,11 @@ func TestCartService_GetCartItemsByCartId(t *testing.T) {
 			if !reflect.DeepEqual(*resp, tt.wantItems) {
 				t.Fatalf("got items %+v want %+v", *resp, tt.wantItems)
 			}
+			for _, userId := range pricer.userIds {
+				if userId != 4 {
+					t.Fatalf("items must be priced for the customer, got user %d", userId)
+				}
+			}
 		})
 	}
 }
@@ -151,7 +177,7 @@ func TestCartService_AddItem(t *testing.T) {
 					return tt.repoID, tt.repoErr
 				},
 			}
-			s := NewCartService(repo)
+			s := NewCartService(repo, &mockPricer{})
 			id, err := s.AddItem(context.Background(), tt.req)
 			if tt.wantErr {
 				if err == nil {
@@ -190,7 +216,7 @@ func TestCartService_RemoveItem(t *testing.T) {
 					return tt.repoErr
 				},
 			}
-			s := NewCartService(repo)
+			s := NewCartService(repo, &mockPricer{})
 			err := s.RemoveItem(context.Background(), tt.req)
 			if tt.wantErr {
 				if err == nil {
@@ -226,7 +252,7 @@ func TestCartService_ClearCart(t *testing.T) {
 					return tt.repoErr
 				},
 			}
-			s := NewCartService(repo)
+			s := NewCartService(repo, &mockPricer{})
 			err := s.ClearCart(context.Background(), tt.req)
 			if tt.wantErr {
 				if err == nil {
@@ -0,0 +1,144 @@
+package catalogChangeService
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
+)
+
+type ICatalogChangeRepository interface {
+	GetCatalogChanges(ctx context.Context, after *model.CatalogChangeCursor, limit int) (*[]model.CatalogChange, int64, error)
+	CatalogSnapshot(ctx context.Context, begin func(horizon int64) error, fn func(entity string, data []byte) error) error
+}
+
+// CatalogChangeService serves the catalog change feed that partners use to keep a
+// mirror of the catalog: a snapshot to start from and the changes after it.
+type CatalogChangeService struct {
+	repo ICatalogChangeRepository
+	cfg  config.CatalogChangesConfig
+	now  func() time.Time
+}
+
+func NewCatalogChangeService(repo ICatalogChangeRepository, cfg config.CatalogChangesConfig) *CatalogChangeService {
+	return &CatalogChangeService{
+		repo: repo,
+		cfg:  cfg,
+		now:  time.Now,
+	}
+}
+
+// Changes returns a page of the changes after the since cursor. The next cursor is
+// always returned: when the feed is caught up it points at the current end of the feed,
+// so polling with it doesn't repeat changes. A cursor older than the retention window
+// is gone, the mirror has to start over from a snapshot.
+func (s *CatalogChangeService) Changes(ctx context.Context, limit int, req *model.CatalogChangesRequest) ([]model.CatalogChangeResponse, string, error) {
+	if req.Since == "" {
+		return []model.CatalogChangeResponse{}, "", fmt.Errorf("%w: since is required, start from the catalog snapshot", errs.ValidationError)
+	}
+
+	var cursor model.CatalogChangeCursor
+	if err := utils.DecodeCursor(req.Since, &cursor); err != nil {
+		return []model.CatalogChangeResponse{}, "", fmt.Errorf("%w: invalid cursor", errs.ValidationError)
+	}
+
+	now := s.now()
+	if s.cfg.Retention > 0 && cursor.At.Before(now.Add(-s.cfg.Retention)) {
+		return []model.CatalogChangeResponse{}, "", fmt.Errorf("%w: the cursor is older than the change retention, start from the catalog snapshot", errs.GoneError)
+	}
+
+	changes, horizon, err := s.repo.GetCatalogChanges(ctx, &cursor, limit)
+	if err != nil {
+		return []model.CatalogChangeResponse{}, "", err
+	}
+
+	resp := make([]model.CatalogChangeResponse, 0, len(*changes))
+	for _, c := range *changes {
+		resp = append(resp, model.CatalogChangeResponse{
+			Entity:    c.Entity,
+			Id:        c.EntityId,
+			Op:        c.Op,
+			Data:      c.Data,
+			ChangedAt: c.CreatedAt,
+		})
+	}
+
+	// Everything below the horizon has been read unless the page is full.
+	next := model.CatalogChangeCursor{TxId: horizon, At: now}
+	if len(*changes) == limit {
+		last := (*changes)[len(*changes)-1]
+		next = model.CatalogChangeCursor{TxId: last.TxId, Id: last.Id, At: last.CreatedAt}
+	}
+
+	nextCursor, err := utils.EncodeCursor(next)
+	if err != nil {
+		return []model.CatalogChangeResponse{}, "", err
+	}
+
+	return resp, nextCursor, nil
+}
+
+// Snapshot streams the whole public catalog as one JSON document together with the
+// cursor to follow the changes from. The changes right after that cursor may already be
+// in the snapshot, applying them again doesn't change the mirror.
+func (s *CatalogChangeService) Snapshot(ctx context.Context, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	products, first := false, true
+
+	begin := func(horizon int64) error {
+		cursor, err := utils.EncodeCursor(model.CatalogChangeCursor{TxId: horizon, At: s.now()})
+		if err != nil {
+			return err
+		}
+
+		quoted, err := json.Marshal(cursor)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(bw, `{"data":{"cursor":%s,"categories":[`, quoted)
+		return err
+	}
+
+	fn := func(entity string, data []byte) error {
+		if entity == model.CatalogEntityProduct && !products {
+			if _, err := bw.WriteString(`],"products":[`); err != nil {
+				return err
+			}
+			products, first = true, true
+		}
+
+		if !first {
+			if err := bw.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		_, err := bw.Write(data)
+		return err
+	}
+
+	if err := s.repo.CatalogSnapshot(ctx, begin, fn); err != nil {
+		return err
+	}
+
+	if !products {
+		if _, err := bw.WriteString(`],"products":[`); err != nil {
+			return err
+		}
+	}
+
+	if _, err := bw.WriteString(`]}}`); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
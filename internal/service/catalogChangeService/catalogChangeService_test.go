@@ -0,0 +1,171 @@
+package catalogChangeService
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
+)
+
+type mockRepo struct {
+	changes    []model.CatalogChange
+	horizon    int64
+	after      *model.CatalogChangeCursor
+	categories []string
+	products   []string
+}
+
+func (m *mockRepo) GetCatalogChanges(ctx context.Context, after *model.CatalogChangeCursor, limit int) (*[]model.CatalogChange, int64, error) {
+	m.after = after
+	result := []model.CatalogChange{}
+	for _, c := range m.changes {
+		if (c.TxId > after.TxId || c.TxId == after.TxId && c.Id > after.Id) && c.TxId < m.horizon && len(result) < limit {
+			result = append(result, c)
+		}
+	}
+	return &result, m.horizon, nil
+}
+
+func (m *mockRepo) CatalogSnapshot(ctx context.Context, begin func(horizon int64) error, fn func(entity string, data []byte) error) error {
+	if err := begin(m.horizon); err != nil {
+		return err
+	}
+	for _, c := range m.categories {
+		if err := fn(model.CatalogEntityCategory, []byte(c)); err != nil {
+			return err
+		}
+	}
+	for _, p := range m.products {
+		if err := fn(model.CatalogEntityProduct, []byte(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var testNow = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func newService(repo *mockRepo) *CatalogChangeService {
+	s := NewCatalogChangeService(repo, config.CatalogChangesConfig{Retention: 7 * 24 * time.Hour})
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+func cursor(t *testing.T, c model.CatalogChangeCursor) string {
+	encoded, err := utils.EncodeCursor(c)
+	if err != nil {
+		t.Fatalf("encode cursor: %v", err)
+	}
+	return encoded
+}
+
+func TestCatalogChangeService_Changes(t *testing.T) {
+	at := testNow.Add(-time.Hour)
+	repo := &mockRepo{
+		horizon: 103,
+		changes: []model.CatalogChange{
+			{Id: 5, TxId: 100, Entity: model.CatalogEntityProduct, EntityId: 7, Op: model.CatalogOpCreate, Data: []byte(`{"id":7}`), CreatedAt: at},
+			{Id: 4, TxId: 101, Entity: model.CatalogEntityProduct, EntityId: 8, Op: model.CatalogOpDelete, CreatedAt: at},
+			{Id: 6, TxId: 101, Entity: model.CatalogEntityCategory, EntityId: 2, Op: model.CatalogOpUpdate, Data: []byte(`{"id":2}`), CreatedAt: at},
+		},
+	}
+	s := newService(repo)
+
+	changes, next, err := s.Changes(context.Background(), 2, &model.CatalogChangesRequest{Since: cursor(t, model.CatalogChangeCursor{TxId: 99, At: at})})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Id != 7 || changes[1].Op != model.CatalogOpDelete {
+		t.Fatalf("changes must follow the transaction order: %+v", changes)
+	}
+
+	changes, next, err = s.Changes(context.Background(), 2, &model.CatalogChangesRequest{Since: next})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Entity != model.CatalogEntityCategory {
+		t.Fatalf("the page must resume after the cursor: %+v", changes)
+	}
+
+	var caughtUp model.CatalogChangeCursor
+	if err = utils.DecodeCursor(next, &caughtUp); err != nil {
+		t.Fatalf("decode cursor: %v", err)
+	}
+	if caughtUp.TxId != 103 || caughtUp.Id != 0 || !caughtUp.At.Equal(testNow) {
+		t.Fatalf("a caught up feed must point at the horizon: %+v", caughtUp)
+	}
+}
+
+func TestCatalogChangeService_ChangesErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		since       string
+		expectedErr error
+	}{
+		{"no cursor", "", errs.ValidationError},
+		{"malformed cursor", "%%%", errs.ValidationError},
+		{"expired cursor", "expired", errs.GoneError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since := tt.since
+			if since == "expired" {
+				since = cursor(t, model.CatalogChangeCursor{TxId: 1, At: testNow.Add(-8 * 24 * time.Hour)})
+			}
+
+			_, _, err := newService(&mockRepo{}).Changes(context.Background(), 10, &model.CatalogChangesRequest{Since: since})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCatalogChangeService_Snapshot(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []string
+		products   []string
+	}{
+		{"catalog", []string{`{"id":1}`, `{"id":2}`}, []string{`{"id":7}`}},
+		{"no products", []string{`{"id":1}`}, nil},
+		{"empty", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			s := newService(&mockRepo{horizon: 42, categories: tt.categories, products: tt.products})
+			if err := s.Snapshot(context.Background(), &buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var doc struct {
+				Data struct {
+					Cursor     string            `json:"cursor"`
+					Categories []json.RawMessage `json:"categories"`
+					Products   []json.RawMessage `json:"products"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("the snapshot must be valid JSON: %v: %s", err, buf.String())
+			}
+			if len(doc.Data.Categories) != len(tt.categories) || len(doc.Data.Products) != len(tt.products) {
+				t.Fatalf("unexpected snapshot: %s", buf.String())
+			}
+
+			var c model.CatalogChangeCursor
+			if err := utils.DecodeCursor(doc.Data.Cursor, &c); err != nil || c.TxId != 42 {
+				t.Fatalf("the cursor must point at the snapshot horizon: %+v, %v", c, err)
+			}
+		})
+	}
+}
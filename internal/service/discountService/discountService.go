@@ -0,0 +1,269 @@
+package discountService
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/policy"
+	"github.com/niklvrr/myMarketplace/internal/pricing"
+)
+
+type IDiscountRepository interface {
+	CreateDiscount(ctx context.Context, d *model.Discount) error
+	GetDiscountById(ctx context.Context, id int64) (*model.Discount, error)
+	GetDiscountsBySeller(ctx context.Context, sellerId int64) (*[]model.Discount, error)
+	GetActiveDiscounts(ctx context.Context, sellerIds []int64, at time.Time) (*[]model.Discount, error)
+	DeleteDiscount(ctx context.Context, id int64) error
+}
+
+type IProductReader interface {
+	GetProductById(ctx context.Context, productId int64) (*model.Product, error)
+}
+
+// IPriceTierReader knows the quantity breaks of products and the price lists of customers.
+type IPriceTierReader interface {
+	GetPriceTiers(ctx context.Context, productIds []int64, priceListId *int64) (*[]model.PriceTier, error)
+	GetUserPriceList(ctx context.Context, userId int64) (*int64, error)
+}
+
+type DiscountService struct {
+	repo     IDiscountRepository
+	products IProductReader
+	tiers    IPriceTierReader
+	now      func() time.Time
+}
+
+func NewDiscountService(repo IDiscountRepository, products IProductReader, tiers IPriceTierReader) *DiscountService {
+	return &DiscountService{
+		repo:     repo,
+		products: products,
+		tiers:    tiers,
+		now:      time.Now,
+	}
+}
+
+// Create registers a discount rule. Sellers create rules for their own catalog,
+// admins may create them for any seller.
+func (s *DiscountService) Create(ctx context.Context, userId int64, role string, req *model.CreateDiscountRequest) (model.DiscountResponse, error) {
+	if err := validateDiscount(req, s.now()); err != nil {
+		return model.DiscountResponse{}, err
+	}
+
+	d := model.Discount{
+		SellerId: userId,
+		Scope:    req.Scope,
+		Kind:     req.Kind,
+		Value:    req.Value,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+	}
+	if role == policy.RoleAdmin && req.SellerId != nil {
+		d.SellerId = *req.SellerId
+	}
+
+	switch req.Scope {
+	case model.DiscountScopeProduct:
+		p, err := s.products.GetProductById(ctx, *req.ProductId)
+		if err != nil {
+			return model.DiscountResponse{}, err
+		}
+
+		if err = policy.CanManageProduct(userId, role, p); err != nil {
+			return model.DiscountResponse{}, err
+		}
+
+		d.SellerId = p.SellerId
+		d.ProductId = req.ProductId
+	case model.DiscountScopeCategory:
+		d.CategoryId = req.CategoryId
+	}
+
+	if err := s.repo.CreateDiscount(ctx, &d); err != nil {
+		return model.DiscountResponse{}, err
+	}
+
+	return s.toDiscountResponse(&d), nil
+}
+
+func (s *DiscountService) GetBySeller(ctx context.Context, sellerId int64) ([]model.DiscountResponse, error) {
+	discounts, err := s.repo.GetDiscountsBySeller(ctx, sellerId)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]model.DiscountResponse, 0, len(*discounts))
+	for i := range *discounts {
+		res = append(res, s.toDiscountResponse(&(*discounts)[i]))
+	}
+
+	return res, nil
+}
+
+func (s *DiscountService) Delete(ctx context.Context, userId int64, role string, req *model.DeleteDiscountRequest) error {
+	d, err := s.repo.GetDiscountById(ctx, req.Id)
+	if err != nil {
+		return err
+	}
+
+	if role != policy.RoleAdmin && d.SellerId != userId {
+		return fmt.Errorf("%w: discount %d belongs to another seller", errs.NotOwnerError, d.Id)
+	}
+
+	return s.repo.DeleteDiscount(ctx, d.Id)
+}
+
+// ApplyDiscounts sets the current price of every product to the price under the
+// best active rule and lists the quantity breaks every customer gets. Products keep
+// their base price in Price.
+func (s *DiscountService) ApplyDiscounts(ctx context.Context, products []*model.ProductResponse) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(products))
+	sellers := make([]int64, 0, len(products))
+	seen := make(map[int64]struct{}, len(products))
+	for _, p := range products {
+		ids = append(ids, p.Id)
+		if _, ok := seen[p.SellerId]; !ok {
+			seen[p.SellerId] = struct{}{}
+			sellers = append(sellers, p.SellerId)
+		}
+	}
+
+	now := s.now()
+	rules, err := s.repo.GetActiveDiscounts(ctx, sellers, now)
+	if err != nil {
+		return err
+	}
+
+	tiers, err := s.tiers.GetPriceTiers(ctx, ids, nil)
+	if err != nil {
+		return err
+	}
+
+	byProduct := make(map[int64][]model.PriceTierResponse, len(products))
+	for _, t := range *tiers {
+		byProduct[t.ProductId] = append(byProduct[t.ProductId], model.PriceTierResponse{
+			MinQuantity: t.MinQuantity,
+			Price:       t.Price,
+		})
+	}
+
+	for _, p := range products {
+		price, applied := pricing.Resolve(pricing.Target{
+			ProductId:  p.Id,
+			SellerId:   p.SellerId,
+			CategoryId: p.CategoryId,
+			Price:      p.Price,
+		}, *rules, now)
+
+		p.CurrentPrice = price
+		p.PriceTiers = byProduct[p.Id]
+		p.DiscountId = nil
+		p.DiscountEndsAt = nil
+		if applied != nil {
+			id, endsAt := applied.Id, applied.EndsAt
+			p.DiscountId = &id
+			p.DiscountEndsAt = &endsAt
+		}
+	}
+
+	return nil
+}
+
+// EffectivePrice is the unit price the customer buys the quantity of a product at right
+// now, it is used for carts and when an order is placed. The tiers every customer gets
+// and the ones of the customer's price list compete with the discounts, the lowest
+// price wins.
+func (s *DiscountService) EffectivePrice(ctx context.Context, userId, productId int64, quantity int) (float64, error) {
+	p, err := s.products.GetProductById(ctx, productId)
+	if err != nil {
+		return 0, err
+	}
+
+	// Drafts, products under moderation, archived and deleted ones are not on sale.
+	if !p.Listed() {
+		return 0, fmt.Errorf("%w: product is not on sale", errs.NotFoundError)
+	}
+
+	now := s.now()
+	rules, err := s.repo.GetActiveDiscounts(ctx, []int64{p.SellerId}, now)
+	if err != nil {
+		return 0, err
+	}
+
+	price, _ := pricing.Resolve(pricing.Target{
+		ProductId:  p.Id,
+		SellerId:   p.SellerId,
+		CategoryId: p.CategoryId,
+		Price:      p.Price,
+	}, *rules, now)
+
+	priceListId, err := s.tiers.GetUserPriceList(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	tiers, err := s.tiers.GetPriceTiers(ctx, []int64{p.Id}, priceListId)
+	if err != nil {
+		return 0, err
+	}
+
+	if tierPrice, ok := pricing.TierPrice(*tiers, p.Id, quantity); ok {
+		price = math.Min(price, tierPrice)
+	}
+
+	return price, nil
+}
+
+func validateDiscount(req *model.CreateDiscountRequest, now time.Time) error {
+	switch req.Scope {
+	case model.DiscountScopeProduct:
+		if req.ProductId == nil || req.CategoryId != nil {
+			return fmt.Errorf("%w: product discount needs product_id only", errs.ValidationError)
+		}
+	case model.DiscountScopeCategory:
+		if req.CategoryId == nil || req.ProductId != nil {
+			return fmt.Errorf("%w: category discount needs category_id only", errs.ValidationError)
+		}
+	case model.DiscountScopeSeller:
+		if req.ProductId != nil || req.CategoryId != nil {
+			return fmt.Errorf("%w: seller discount takes neither product_id nor category_id", errs.ValidationError)
+		}
+	}
+
+	if req.Kind == model.DiscountKindPercent && req.Value >= 100 {
+		return fmt.Errorf("%w: percent discount must be below 100", errs.ValidationError)
+	}
+
+	if !req.EndsAt.After(req.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", errs.ValidationError)
+	}
+
+	if !req.EndsAt.After(now) {
+		return fmt.Errorf("%w: discount has already ended", errs.ValidationError)
+	}
+
+	return nil
+}
+
+func (s *DiscountService) toDiscountResponse(d *model.Discount) model.DiscountResponse {
+	now := s.now()
+	return model.DiscountResponse{
+		Id:         d.Id,
+		SellerId:   d.SellerId,
+		Scope:      d.Scope,
+		ProductId:  d.ProductId,
+		CategoryId: d.CategoryId,
+		Kind:       d.Kind,
+		Value:      d.Value,
+		StartsAt:   d.StartsAt,
+		EndsAt:     d.EndsAt,
+		Active:     !now.Before(d.StartsAt) && now.Before(d.EndsAt),
+	}
+}
@@ -0,0 +1,364 @@
+package discountService
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockRepo struct {
+	CreateDiscountFn       func(ctx context.Context, d *model.Discount) error
+	GetDiscountByIdFn      func(ctx context.Context, id int64) (*model.Discount, error)
+	GetDiscountsBySellerFn func(ctx context.Context, sellerId int64) (*[]model.Discount, error)
+	GetActiveDiscountsFn   func(ctx context.Context, sellerIds []int64, at time.Time) (*[]model.Discount, error)
+	DeleteDiscountFn       func(ctx context.Context, id int64) error
+}
+
+func (m *mockRepo) CreateDiscount(ctx context.Context, d *model.Discount) error {
+	return m.CreateDiscountFn(ctx, d)
+}
+func (m *mockRepo) GetDiscountById(ctx context.Context, id int64) (*model.Discount, error) {
+	return m.GetDiscountByIdFn(ctx, id)
+}
+func (m *mockRepo) GetDiscountsBySeller(ctx context.Context, sellerId int64) (*[]model.Discount, error) {
+	return m.GetDiscountsBySellerFn(ctx, sellerId)
+}
+func (m *mockRepo) GetActiveDiscounts(ctx context.Context, sellerIds []int64, at time.Time) (*[]model.Discount, error) {
+	return m.GetActiveDiscountsFn(ctx, sellerIds, at)
+}
+func (m *mockRepo) DeleteDiscount(ctx context.Context, id int64) error {
+	return m.DeleteDiscountFn(ctx, id)
+}
+
+type mockProducts struct {
+	GetProductByIdFn func(ctx context.Context, productId int64) (*model.Product, error)
+}
+
+func (m *mockProducts) GetProductById(ctx context.Context, productId int64) (*model.Product, error) {
+	return m.GetProductByIdFn(ctx, productId)
+}
+
+type mockTiers struct {
+	tiers      []model.PriceTier
+	priceLists map[int64]int64
+}
+
+func (m *mockTiers) GetPriceTiers(ctx context.Context, productIds []int64, priceListId *int64) (*[]model.PriceTier, error) {
+	result := []model.PriceTier{}
+	for _, t := range m.tiers {
+		if t.PriceListId == nil || (priceListId != nil && *t.PriceListId == *priceListId) {
+			result = append(result, t)
+		}
+	}
+	return &result, nil
+}
+func (m *mockTiers) GetUserPriceList(ctx context.Context, userId int64) (*int64, error) {
+	if id, ok := m.priceLists[userId]; ok {
+		return &id, nil
+	}
+	return nil, nil
+}
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService(repo *mockRepo, products *mockProducts) *DiscountService {
+	s := NewDiscountService(repo, products, &mockTiers{})
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+func TestDiscountService_Create(t *testing.T) {
+	productId, categoryId, otherSeller := int64(5), int64(3), int64(42)
+	products := &mockProducts{
+		GetProductByIdFn: func(ctx context.Context, id int64) (*model.Product, error) {
+			return &model.Product{Id: id, SellerId: 7}, nil
+		},
+	}
+	base := func(scope string) model.CreateDiscountRequest {
+		return model.CreateDiscountRequest{
+			Scope:    scope,
+			Kind:     model.DiscountKindPercent,
+			Value:    10,
+			StartsAt: testNow,
+			EndsAt:   testNow.Add(24 * time.Hour),
+		}
+	}
+
+	tests := []struct {
+		name           string
+		userId         int64
+		role           string
+		req            func() model.CreateDiscountRequest
+		expectedSeller int64
+		expectedErr    error
+	}{
+		{"seller wide", 7, "seller", func() model.CreateDiscountRequest { return base(model.DiscountScopeSeller) }, 7, nil},
+		{"own product", 7, "seller", func() model.CreateDiscountRequest {
+			req := base(model.DiscountScopeProduct)
+			req.ProductId = &productId
+			return req
+		}, 7, nil},
+		{"foreign product", 8, "seller", func() model.CreateDiscountRequest {
+			req := base(model.DiscountScopeProduct)
+			req.ProductId = &productId
+			return req
+		}, 0, errs.NotOwnerError},
+		{"admin on product uses owner", 1, "admin", func() model.CreateDiscountRequest {
+			req := base(model.DiscountScopeProduct)
+			req.ProductId = &productId
+			return req
+		}, 7, nil},
+		{"admin for seller", 1, "admin", func() model.CreateDiscountRequest {
+			req := base(model.DiscountScopeCategory)
+			req.CategoryId = &categoryId
+			req.SellerId = &otherSeller
+			return req
+		}, 42, nil},
+		{"seller cannot act for others", 7, "seller", func() model.CreateDiscountRequest {
+			req := base(model.DiscountScopeSeller)
+			req.SellerId = &otherSeller
+			return req
+		}, 7, nil},
+		{"category without id", 7, "seller", func() model.CreateDiscountRequest { return base(model.DiscountScopeCategory) }, 0, errs.ValidationError},
+		{"percent too high", 7, "seller", func() model.CreateDiscountRequest {
+			req := base(model.DiscountScopeSeller)
+			req.Value = 100
+			return req
+		}, 0, errs.ValidationError},
+		{"already ended", 7, "seller", func() model.CreateDiscountRequest {
+			req := base(model.DiscountScopeSeller)
+			req.StartsAt = testNow.Add(-48 * time.Hour)
+			req.EndsAt = testNow.Add(-time.Hour)
+			return req
+		}, 0, errs.ValidationError},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *model.Discount
+			repo := &mockRepo{
+				CreateDiscountFn: func(ctx context.Context, d *model.Discount) error {
+					d.Id = 1
+					saved = d
+					return nil
+				},
+			}
+			s := newTestService(repo, products)
+			req := tt.req()
+			got, err := s.Create(context.Background(), tt.userId, tt.role, &req)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+				}
+				if saved != nil {
+					t.Fatalf("discount must not be saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if saved.SellerId != tt.expectedSeller || got.SellerId != tt.expectedSeller {
+				t.Fatalf("expected seller %d, got %d", tt.expectedSeller, saved.SellerId)
+			}
+			if !got.Active {
+				t.Fatalf("discount starting now must be active")
+			}
+		})
+	}
+}
+
+func TestDiscountService_Delete(t *testing.T) {
+	deleted := false
+	repo := &mockRepo{
+		GetDiscountByIdFn: func(ctx context.Context, id int64) (*model.Discount, error) {
+			return &model.Discount{Id: id, SellerId: 7}, nil
+		},
+		DeleteDiscountFn: func(ctx context.Context, id int64) error {
+			deleted = true
+			return nil
+		},
+	}
+	s := newTestService(repo, nil)
+
+	err := s.Delete(context.Background(), 8, "seller", &model.DeleteDiscountRequest{Id: 1})
+	if !errors.Is(err, errs.NotOwnerError) || deleted {
+		t.Fatalf("expected not owner, got %v", err)
+	}
+	if err = s.Delete(context.Background(), 7, "seller", &model.DeleteDiscountRequest{Id: 1}); err != nil || !deleted {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestDiscountService_ApplyDiscounts(t *testing.T) {
+	categoryId := int64(3)
+	repo := &mockRepo{
+		GetActiveDiscountsFn: func(ctx context.Context, sellerIds []int64, at time.Time) (*[]model.Discount, error) {
+			if len(sellerIds) != 2 || !at.Equal(testNow) {
+				t.Fatalf("unexpected args: %v %v", sellerIds, at)
+			}
+			rules := []model.Discount{{
+				Id:         4,
+				SellerId:   1,
+				Scope:      model.DiscountScopeCategory,
+				CategoryId: &categoryId,
+				Kind:       model.DiscountKindPercent,
+				Value:      25,
+				StartsAt:   testNow.Add(-time.Hour),
+				EndsAt:     testNow.Add(time.Hour),
+			}}
+			return &rules, nil
+		},
+	}
+	s := newTestService(repo, nil)
+	s.tiers = &mockTiers{tiers: []model.PriceTier{
+		{ProductId: 2, MinQuantity: 10, Price: 90},
+		{ProductId: 2, MinQuantity: 50, Price: 80},
+	}}
+	products := []*model.ProductResponse{
+		{Id: 1, SellerId: 1, CategoryId: 3, Price: 200, CurrentPrice: 200},
+		{Id: 2, SellerId: 1, CategoryId: 4, Price: 100, CurrentPrice: 100},
+		{Id: 3, SellerId: 2, CategoryId: 3, Price: 50, CurrentPrice: 50},
+	}
+	if err := s.ApplyDiscounts(context.Background(), products); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if products[0].CurrentPrice != 150 || products[0].DiscountId == nil || *products[0].DiscountId != 4 {
+		t.Fatalf("discount not applied: %+v", products[0])
+	}
+	if !products[0].DiscountEndsAt.Equal(testNow.Add(time.Hour)) {
+		t.Fatalf("unexpected end: %v", products[0].DiscountEndsAt)
+	}
+	for _, p := range products[1:] {
+		if p.CurrentPrice != p.Price || p.DiscountId != nil {
+			t.Fatalf("unexpected discount on %+v", p)
+		}
+	}
+	if len(products[1].PriceTiers) != 2 || products[1].PriceTiers[1].MinQuantity != 50 || products[1].PriceTiers[1].Price != 80 {
+		t.Fatalf("unexpected tiers: %+v", products[1].PriceTiers)
+	}
+	if products[0].PriceTiers != nil || products[2].PriceTiers != nil {
+		t.Fatalf("tiers of another product: %+v %+v", products[0].PriceTiers, products[2].PriceTiers)
+	}
+}
+
+func TestDiscountService_EffectivePrice(t *testing.T) {
+	repo := &mockRepo{
+		GetActiveDiscountsFn: func(ctx context.Context, sellerIds []int64, at time.Time) (*[]model.Discount, error) {
+			rules := []model.Discount{{
+				Id:       1,
+				SellerId: 7,
+				Scope:    model.DiscountScopeSeller,
+				Kind:     model.DiscountKindFixed,
+				Value:    90,
+				StartsAt: testNow.Add(-time.Hour),
+				EndsAt:   testNow.Add(time.Hour),
+			}}
+			return &rules, nil
+		},
+	}
+	products := &mockProducts{
+		GetProductByIdFn: func(ctx context.Context, id int64) (*model.Product, error) {
+			if id != 5 {
+				return nil, errors.New("not found")
+			}
+			return &model.Product{Id: 5, SellerId: 7, Price: 120, Status: model.ProductStatusApproved}, nil
+		},
+	}
+	s := newTestService(repo, products)
+
+	price, err := s.EffectivePrice(context.Background(), 1, 5, 1)
+	if err != nil || price != 90 {
+		t.Fatalf("expected 90, got %v %v", price, err)
+	}
+	if _, err = s.EffectivePrice(context.Background(), 1, 6, 1); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestDiscountService_EffectivePrice_NotListed(t *testing.T) {
+	archived := time.Now()
+	tests := []struct {
+		name    string
+		product model.Product
+	}{
+		{"draft", model.Product{Id: 5, Status: model.ProductStatusDraft}},
+		{"pending review", model.Product{Id: 5, Status: model.ProductStatusPendingReview}},
+		{"archived", model.Product{Id: 5, Status: model.ProductStatusApproved, ArchivedAt: &archived}},
+		{"deleted", model.Product{Id: 5, Status: model.ProductStatusApproved, DeletedAt: &archived}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			products := &mockProducts{
+				GetProductByIdFn: func(ctx context.Context, id int64) (*model.Product, error) {
+					p := tt.product
+					return &p, nil
+				},
+			}
+			s := newTestService(&mockRepo{}, products)
+
+			if _, err := s.EffectivePrice(context.Background(), 1, 5, 1); !errors.Is(err, errs.NotFoundError) {
+				t.Fatalf("expected not found, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDiscountService_EffectivePrice_Tiers(t *testing.T) {
+	wholesale := int64(3)
+	repo := &mockRepo{
+		GetActiveDiscountsFn: func(ctx context.Context, sellerIds []int64, at time.Time) (*[]model.Discount, error) {
+			rules := []model.Discount{{
+				Id:       1,
+				SellerId: 7,
+				Scope:    model.DiscountScopeSeller,
+				Kind:     model.DiscountKindPercent,
+				Value:    5,
+				StartsAt: testNow.Add(-time.Hour),
+				EndsAt:   testNow.Add(time.Hour),
+			}}
+			return &rules, nil
+		},
+	}
+	products := &mockProducts{
+		GetProductByIdFn: func(ctx context.Context, id int64) (*model.Product, error) {
+			return &model.Product{Id: id, SellerId: 7, Price: 100, Status: model.ProductStatusApproved}, nil
+		},
+	}
+	s := newTestService(repo, products)
+	s.tiers = &mockTiers{
+		tiers: []model.PriceTier{
+			{ProductId: 5, MinQuantity: 10, Price: 90},
+			{ProductId: 5, MinQuantity: 50, Price: 80},
+			{ProductId: 5, PriceListId: &wholesale, MinQuantity: 1, Price: 85},
+		},
+		priceLists: map[int64]int64{2: wholesale},
+	}
+
+	tests := []struct {
+		name     string
+		userId   int64
+		quantity int
+		expected float64
+	}{
+		{"discount below the first tier", 1, 9, 95},
+		{"first tier", 1, 10, 90},
+		{"highest tier", 1, 50, 80},
+		{"wholesale from one unit", 2, 1, 85},
+		{"public tier beats the price list", 2, 50, 80},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := s.EffectivePrice(context.Background(), tt.userId, 5, tt.quantity)
+			if err != nil || price != tt.expected {
+				t.Fatalf("expected %v, got %v %v", tt.expected, price, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package exportService
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultCurrency = "RUB"
+	feedKeyPrefix   = "export:feed:"
+)
+
+type IExportRepository interface {
+	ExportProducts(ctx context.Context, sellerId *int64, fn func(p *model.Product) error) error
+}
+
+type ICategoryRepository interface {
+	GetAllCategories(ctx context.Context) (*[]model.Category, error)
+}
+
+type ExportService struct {
+	repo       IExportRepository
+	categories ICategoryRepository
+	cache      *redis.Client
+	cfg        config.ExportConfig
+}
+
+func NewExportService(repo IExportRepository, categories ICategoryRepository, cache *redis.Client, cfg config.ExportConfig) *ExportService {
+	if cfg.Currency == "" {
+		cfg.Currency = defaultCurrency
+	}
+
+	return &ExportService{
+		repo:       repo,
+		categories: categories,
+		cache:      cache,
+		cfg:        cfg,
+	}
+}
+
+// Export streams the public catalog in the given format into w. A nil sellerId
+// exports the products of all sellers.
+func (s *ExportService) Export(ctx context.Context, w io.Writer, format string, sellerId *int64) error {
+	categories, err := s.categories.GetAllCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	meta := &feedMeta{
+		cfg:        s.cfg,
+		categories: make(map[int64]string, len(*categories)),
+		order:      *categories,
+	}
+	for _, category := range *categories {
+		meta.categories[category.Id] = category.Name
+	}
+
+	var writer feedWriter
+	switch format {
+	case model.ExportFormatCSV:
+		writer, err = newCSVFeedWriter(w, meta)
+	case model.ExportFormatYML:
+		writer, err = newYMLFeedWriter(w, meta, time.Now())
+	case model.ExportFormatSitemap:
+		writer, err = newSitemapFeedWriter(w, meta)
+	default:
+		return fmt.Errorf("%w: unsupported export format %q", errs.ValidationError, format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = s.repo.ExportProducts(ctx, sellerId, writer.WriteProduct); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// Feed returns the cached public feed, generating it on the spot when the
+// background job hasn't stored it yet.
+func (s *ExportService) Feed(ctx context.Context, format string) ([]byte, error) {
+	feed, err := s.cache.Get(ctx, feedKeyPrefix+format).Bytes()
+	if err == nil {
+		return feed, nil
+	}
+
+	if !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	return s.regenerate(ctx, format)
+}
+
+// RegenerateFeeds rebuilds the cached public feeds in every format and the sitemap,
+// when the base url for its links is configured.
+func (s *ExportService) RegenerateFeeds(ctx context.Context) error {
+	formats := []string{model.ExportFormatYML, model.ExportFormatCSV}
+	if s.cfg.BaseUrl != "" {
+		formats = append(formats, model.ExportFormatSitemap)
+	}
+
+	for _, format := range formats {
+		if _, err := s.regenerate(ctx, format); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ExportService) regenerate(ctx context.Context, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.Export(ctx, &buf, format, nil); err != nil {
+		return nil, err
+	}
+
+	// The feed outlives a couple of job runs, so a stuck job serves a stale
+	// feed for a while instead of regenerating it on every request.
+	var ttl time.Duration
+	if s.cfg.FeedInterval > 0 {
+		ttl = 3 * s.cfg.FeedInterval
+	}
+	if err := s.cache.Set(ctx, feedKeyPrefix+format, buf.Bytes(), ttl).Err(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
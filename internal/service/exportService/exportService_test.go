@@ -0,0 +1,208 @@
+package exportService
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockRepo struct {
+	ExportProductsFn func(ctx context.Context, sellerId *int64, fn func(p *model.Product) error) error
+}
+
+func (m *mockRepo) ExportProducts(ctx context.Context, sellerId *int64, fn func(p *model.Product) error) error {
+	return m.ExportProductsFn(ctx, sellerId, fn)
+}
+
+type mockCategories struct {
+	GetAllCategoriesFn func(ctx context.Context) (*[]model.Category, error)
+}
+
+func (m *mockCategories) GetAllCategories(ctx context.Context) (*[]model.Category, error) {
+	return m.GetAllCategoriesFn(ctx)
+}
+
+func newTestService(products []model.Product, sellerCheck func(sellerId *int64)) (*ExportService, redismock.ClientMock) {
+	repo := &mockRepo{
+		ExportProductsFn: func(ctx context.Context, sellerId *int64, fn func(p *model.Product) error) error {
+			if sellerCheck != nil {
+				sellerCheck(sellerId)
+			}
+			for i := range products {
+				if err := fn(&products[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	categories := &mockCategories{
+		GetAllCategoriesFn: func(ctx context.Context) (*[]model.Category, error) {
+			return &[]model.Category{{Id: 1, Name: "Phones & Tablets"}, {Id: 2, Name: "Cables"}}, nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	cfg := config.ExportConfig{ShopName: "Azon", Company: "Azon LLC", BaseUrl: "https://azon.example", FeedInterval: time.Hour}
+	return NewExportService(repo, categories, client, cfg), mock
+}
+
+var testProducts = []model.Product{
+	{Id: 10, Sku: "PH-1", Name: "Phone", Description: "Fast, \"new\"", CategoryId: 1, Price: 19999.9, Stock: 3, ImageUrl: "https://cdn.example/1.jpg"},
+	{Id: 11, Name: "Cable <USB>", CategoryId: 2, Price: 5, Stock: 0},
+}
+
+func TestExportService_ExportCSV(t *testing.T) {
+	var gotSeller *int64
+	s, _ := newTestService(testProducts, func(sellerId *int64) { gotSeller = sellerId })
+	sellerId := int64(7)
+
+	var buf bytes.Buffer
+	if err := s.Export(context.Background(), &buf, model.ExportFormatCSV, &sellerId); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if gotSeller == nil || *gotSeller != 7 {
+		t.Fatalf("seller filter was not passed: %v", gotSeller)
+	}
+
+	expected := "id,sku,name,description,category_id,category,price,currency,stock,available,image_url,url\n" +
+		"10,PH-1,Phone,\"Fast, \"\"new\"\"\",1,Phones & Tablets,19999.90,RUB,3,true,https://cdn.example/1.jpg,https://azon.example/products/10\n" +
+		"11,,Cable <USB>,,2,Cables,5.00,RUB,0,false,,https://azon.example/products/11\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected csv:\n%s", buf.String())
+	}
+}
+
+func TestExportService_ExportYML(t *testing.T) {
+	s, _ := newTestService(testProducts, nil)
+
+	var buf bytes.Buffer
+	if err := s.Export(context.Background(), &buf, model.ExportFormatYML, nil); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var catalog struct {
+		Date string `xml:"date,attr"`
+		Shop struct {
+			Name       string `xml:"name"`
+			Currencies []struct {
+				Id string `xml:"id,attr"`
+			} `xml:"currencies>currency"`
+			Categories []ymlCategory `xml:"categories>category"`
+			Offers     []ymlOffer    `xml:"offers>offer"`
+		} `xml:"shop"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &catalog); err != nil {
+		t.Fatalf("invalid xml: %v\n%s", err, buf.String())
+	}
+	if catalog.Date == "" || catalog.Shop.Name != "Azon" || catalog.Shop.Currencies[0].Id != "RUB" {
+		t.Fatalf("unexpected shop: %+v", catalog)
+	}
+	if len(catalog.Shop.Categories) != 2 || catalog.Shop.Categories[0].Name != "Phones & Tablets" {
+		t.Fatalf("unexpected categories: %+v", catalog.Shop.Categories)
+	}
+	offers := catalog.Shop.Offers
+	if len(offers) != 2 {
+		t.Fatalf("expected 2 offers, got %d", len(offers))
+	}
+	if !offers[0].Available || offers[0].Price != "19999.90" || offers[0].Picture != "https://cdn.example/1.jpg" ||
+		offers[0].VendorCode != "PH-1" || offers[0].Url != "https://azon.example/products/10" {
+		t.Fatalf("unexpected first offer: %+v", offers[0])
+	}
+	if offers[1].Available || offers[1].Name != "Cable <USB>" || offers[1].Picture != "" {
+		t.Fatalf("unexpected second offer: %+v", offers[1])
+	}
+}
+
+func TestExportService_ExportSitemap(t *testing.T) {
+	products := []model.Product{{Id: 10, Slug: "telefon", Name: "Телефон"}, {Id: 11, Name: "Cable"}}
+	s, _ := newTestService(products, nil)
+
+	var buf bytes.Buffer
+	if err := s.Export(context.Background(), &buf, model.ExportFormatSitemap, nil); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var urlset struct {
+		XMLName xml.Name
+		Urls    []sitemapUrl `xml:"url"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &urlset); err != nil {
+		t.Fatalf("invalid xml: %v\n%s", err, buf.String())
+	}
+	if urlset.XMLName.Space != sitemapNamespace {
+		t.Fatalf("unexpected namespace: %q", urlset.XMLName.Space)
+	}
+
+	expected := []string{
+		"https://azon.example/categories/1",
+		"https://azon.example/categories/2",
+		"https://azon.example/products/telefon",
+		"https://azon.example/products/11",
+	}
+	if len(urlset.Urls) != len(expected) {
+		t.Fatalf("unexpected urls: %+v", urlset.Urls)
+	}
+	for i, u := range urlset.Urls {
+		if u.Loc != expected[i] {
+			t.Fatalf("url %d: expected %q, got %q", i, expected[i], u.Loc)
+		}
+	}
+
+	s.cfg.BaseUrl = ""
+	if err := s.Export(context.Background(), &bytes.Buffer{}, model.ExportFormatSitemap, nil); !errors.Is(err, errs.NotFoundError) {
+		t.Fatalf("the sitemap needs the base url, got %v", err)
+	}
+}
+
+func TestExportService_ExportErrors(t *testing.T) {
+	s, _ := newTestService(testProducts, nil)
+
+	err := s.Export(context.Background(), &bytes.Buffer{}, "xlsx", nil)
+	if !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	dbErr := errors.New("db")
+	s.repo = &mockRepo{
+		ExportProductsFn: func(ctx context.Context, sellerId *int64, fn func(p *model.Product) error) error {
+			return dbErr
+		},
+	}
+	if err = s.Export(context.Background(), &bytes.Buffer{}, model.ExportFormatCSV, nil); !errors.Is(err, dbErr) {
+		t.Fatalf("expected repo error, got %v", err)
+	}
+}
+
+func TestExportService_Feed(t *testing.T) {
+	s, mock := newTestService(testProducts, nil)
+
+	mock.ExpectGet("export:feed:csv").SetVal("cached")
+	feed, err := s.Feed(context.Background(), model.ExportFormatCSV)
+	if err != nil || string(feed) != "cached" {
+		t.Fatalf("unexpected feed: %q %v", feed, err)
+	}
+
+	var expected bytes.Buffer
+	if err = s.Export(context.Background(), &expected, model.ExportFormatCSV, nil); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	mock.ExpectGet("export:feed:csv").RedisNil()
+	mock.ExpectSet("export:feed:csv", expected.Bytes(), 3*time.Hour).SetVal("OK")
+	feed, err = s.Feed(context.Background(), model.ExportFormatCSV)
+	if err != nil || !strings.HasPrefix(string(feed), "id,sku,") {
+		t.Fatalf("unexpected feed: %q %v", feed, err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package exportService
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+// feedWriter writes one product at a time, so the feed can be streamed to the client.
+type feedWriter interface {
+	WriteProduct(p *model.Product) error
+	Close() error
+}
+
+type feedMeta struct {
+	cfg        config.ExportConfig
+	categories map[int64]string
+	order      []model.Category
+}
+
+// productUrl links the product by its slug, products saved before slugs existed by id.
+func (m *feedMeta) productUrl(p *model.Product) string {
+	if m.cfg.BaseUrl == "" {
+		return ""
+	}
+	if p.Slug != "" {
+		return fmt.Sprintf("%s/products/%s", m.cfg.BaseUrl, p.Slug)
+	}
+	return fmt.Sprintf("%s/products/%d", m.cfg.BaseUrl, p.Id)
+}
+
+func (m *feedMeta) categoryUrl(c *model.Category) string {
+	return fmt.Sprintf("%s/categories/%d", m.cfg.BaseUrl, c.Id)
+}
+
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 2, 64)
+}
+
+var csvHeader = []string{
+	"id", "sku", "name", "description", "category_id", "category",
+	"price", "currency", "stock", "available", "image_url", "url",
+}
+
+type csvFeedWriter struct {
+	meta   *feedMeta
+	writer *csv.Writer
+}
+
+func newCSVFeedWriter(w io.Writer, meta *feedMeta) (*csvFeedWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	return &csvFeedWriter{meta: meta, writer: writer}, nil
+}
+
+func (w *csvFeedWriter) WriteProduct(p *model.Product) error {
+	return w.writer.Write([]string{
+		strconv.FormatInt(p.Id, 10),
+		p.Sku,
+		p.Name,
+		p.Description,
+		strconv.FormatInt(p.CategoryId, 10),
+		w.meta.categories[p.CategoryId],
+		formatPrice(p.Price),
+		w.meta.cfg.Currency,
+		strconv.Itoa(p.Stock),
+		strconv.FormatBool(p.Stock > 0),
+		p.ImageUrl,
+		w.meta.productUrl(p),
+	})
+}
+
+func (w *csvFeedWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type ymlCurrency struct {
+	Id   string `xml:"id,attr"`
+	Rate string `xml:"rate,attr"`
+}
+
+type ymlCurrencies struct {
+	XMLName    xml.Name      `xml:"currencies"`
+	Currencies []ymlCurrency `xml:"currency"`
+}
+
+type ymlCategory struct {
+	Id   int64  `xml:"id,attr"`
+	Name string `xml:",chardata"`
+}
+
+type ymlCategories struct {
+	XMLName    xml.Name      `xml:"categories"`
+	Categories []ymlCategory `xml:"category"`
+}
+
+type ymlOffer struct {
+	XMLName     xml.Name `xml:"offer"`
+	Id          int64    `xml:"id,attr"`
+	Available   bool     `xml:"available,attr"`
+	Name        string   `xml:"name"`
+	Url         string   `xml:"url,omitempty"`
+	Price       string   `xml:"price"`
+	CurrencyId  string   `xml:"currencyId"`
+	CategoryId  int64    `xml:"categoryId"`
+	Picture     string   `xml:"picture,omitempty"`
+	VendorCode  string   `xml:"vendorCode,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	Count       int      `xml:"count"`
+}
+
+// ymlFeedWriter writes the Yandex Market YML format: shop info and categories
+// first, then offers one by one.
+type ymlFeedWriter struct {
+	meta *feedMeta
+	enc  *xml.Encoder
+}
+
+func newYMLFeedWriter(w io.Writer, meta *feedMeta, now time.Time) (*ymlFeedWriter, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	catalog := xml.StartElement{
+		Name: xml.Name{Local: "yml_catalog"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "date"}, Value: now.Format(time.RFC3339)}},
+	}
+	if err := enc.EncodeToken(catalog); err != nil {
+		return nil, err
+	}
+
+	if err := enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "shop"}}); err != nil {
+		return nil, err
+	}
+
+	for _, field := range []struct{ name, value string }{
+		{"name", meta.cfg.ShopName},
+		{"company", meta.cfg.Company},
+		{"url", meta.cfg.BaseUrl},
+	} {
+		if err := enc.EncodeElement(field.value, xml.StartElement{Name: xml.Name{Local: field.name}}); err != nil {
+			return nil, err
+		}
+	}
+
+	currencies := ymlCurrencies{Currencies: []ymlCurrency{{Id: meta.cfg.Currency, Rate: "1"}}}
+	if err := enc.Encode(currencies); err != nil {
+		return nil, err
+	}
+
+	categories := ymlCategories{Categories: make([]ymlCategory, 0, len(meta.order))}
+	for _, category := range meta.order {
+		categories.Categories = append(categories.Categories, ymlCategory{Id: category.Id, Name: category.Name})
+	}
+	if err := enc.Encode(categories); err != nil {
+		return nil, err
+	}
+
+	if err := enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "offers"}}); err != nil {
+		return nil, err
+	}
+
+	return &ymlFeedWriter{meta: meta, enc: enc}, nil
+}
+
+func (w *ymlFeedWriter) WriteProduct(p *model.Product) error {
+	return w.enc.Encode(ymlOffer{
+		Id:          p.Id,
+		Available:   p.Stock > 0,
+		Name:        p.Name,
+		Url:         w.meta.productUrl(p),
+		Price:       formatPrice(p.Price),
+		CurrencyId:  w.meta.cfg.Currency,
+		CategoryId:  p.CategoryId,
+		Picture:     p.ImageUrl,
+		VendorCode:  p.Sku,
+		Description: p.Description,
+		Count:       p.Stock,
+	})
+}
+
+func (w *ymlFeedWriter) Close() error {
+	for _, name := range []string{"offers", "shop", "yml_catalog"} {
+		if err := w.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+
+	return w.enc.Close()
+}
+
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type sitemapUrl struct {
+	XMLName xml.Name `xml:"url"`
+	Loc     string   `xml:"loc"`
+}
+
+// sitemapFeedWriter writes the sitemap protocol: the categories first, then the
+// products one by one. The links must be absolute, so the base url is required.
+type sitemapFeedWriter struct {
+	meta *feedMeta
+	enc  *xml.Encoder
+}
+
+func newSitemapFeedWriter(w io.Writer, meta *feedMeta) (*sitemapFeedWriter, error) {
+	if meta.cfg.BaseUrl == "" {
+		return nil, fmt.Errorf("%w: the sitemap requires export.base_url", errs.NotFoundError)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	urlset := xml.StartElement{
+		Name: xml.Name{Local: "urlset"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: sitemapNamespace}},
+	}
+	if err := enc.EncodeToken(urlset); err != nil {
+		return nil, err
+	}
+
+	for i := range meta.order {
+		if err := enc.Encode(sitemapUrl{Loc: meta.categoryUrl(&meta.order[i])}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &sitemapFeedWriter{meta: meta, enc: enc}, nil
+}
+
+func (w *sitemapFeedWriter) WriteProduct(p *model.Product) error {
+	return w.enc.Encode(sitemapUrl{Loc: w.meta.productUrl(p)})
+}
+
+func (w *sitemapFeedWriter) Close() error {
+	if err := w.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "urlset"}}); err != nil {
+		return err
+	}
+
+	return w.enc.Close()
+}
@@ -0,0 +1,302 @@
+package importService
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/policy"
+	"github.com/niklvrr/myMarketplace/internal/search"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	MaxFileSize = 20 << 20
+	maxRows     = 50000
+	batchSize   = 500
+)
+
+type IImportRepository interface {
+	CreateImportJob(ctx context.Context, job *model.ImportJob) error
+	GetImportJobById(ctx context.Context, id int64) (*model.ImportJob, error)
+	UpdateImportJob(ctx context.Context, job *model.ImportJob) error
+	SaveImportErrors(ctx context.Context, rowErrors []model.ImportRowError) error
+	GetImportErrors(ctx context.Context, jobId int64) (*[]model.ImportRowError, error)
+	UpsertProductsBySku(ctx context.Context, sellerId, jobId int64, products []model.Product) (map[string]model.ImportedProduct, error)
+}
+
+// IContentModerator runs the same automated checks on imported rows as on
+// products created by hand.
+type IContentModerator interface {
+	Review(ctx context.Context, p *model.Product) ([]model.ModerationFlag, error)
+	SaveFlags(ctx context.Context, productId int64, flags []model.ModerationFlag) error
+}
+
+type ImportService struct {
+	repo      IImportRepository
+	moderator IContentModerator
+	cache     *redis.Client
+	index     search.SearchIndex
+}
+
+func NewImportService(repo IImportRepository, moderator IContentModerator, cache *redis.Client, index search.SearchIndex) *ImportService {
+	return &ImportService{
+		repo:      repo,
+		moderator: moderator,
+		cache:     cache,
+		index:     index,
+	}
+}
+
+// StartImport validates the file, registers the job and processes the rows in
+// the background. The returned job can be polled for progress.
+func (s *ImportService) StartImport(ctx context.Context, sellerId int64, format string, file io.Reader) (model.ImportJobResponse, error) {
+	data, err := io.ReadAll(io.LimitReader(file, MaxFileSize+1))
+	if err != nil {
+		return model.ImportJobResponse{}, fmt.Errorf("%w: cannot read import file: %w", errs.ValidationError, err)
+	}
+
+	if len(data) > MaxFileSize {
+		return model.ImportJobResponse{}, fmt.Errorf("%w: import file is larger than %d bytes", errs.ValidationError, MaxFileSize)
+	}
+
+	rows, err := parseRows(format, data, maxRows)
+	if err != nil {
+		return model.ImportJobResponse{}, err
+	}
+
+	job := model.ImportJob{
+		SellerId:  sellerId,
+		Format:    format,
+		Status:    model.ImportStatusPending,
+		TotalRows: len(rows),
+	}
+	if err = s.repo.CreateImportJob(ctx, &job); err != nil {
+		return model.ImportJobResponse{}, err
+	}
+
+	// The request context ends with the response, the job has to outlive it.
+	go s.run(context.Background(), job, rows)
+
+	return toImportJobResponse(&job), nil
+}
+
+func (s *ImportService) GetJob(ctx context.Context, userId int64, role string, req *model.GetImportJobRequest) (model.ImportJobResponse, error) {
+	job, err := s.repo.GetImportJobById(ctx, req.Id)
+	if err != nil {
+		return model.ImportJobResponse{}, err
+	}
+
+	if err = policy.CanViewImportJob(userId, role, job); err != nil {
+		return model.ImportJobResponse{}, err
+	}
+
+	return toImportJobResponse(job), nil
+}
+
+func (s *ImportService) GetErrors(ctx context.Context, userId int64, role string, req *model.GetImportJobRequest) ([]model.ImportRowErrorResponse, error) {
+	job, err := s.repo.GetImportJobById(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.CanViewImportJob(userId, role, job); err != nil {
+		return nil, err
+	}
+
+	rowErrors, err := s.repo.GetImportErrors(ctx, job.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]model.ImportRowErrorResponse, 0, len(*rowErrors))
+	for _, rowErr := range *rowErrors {
+		res = append(res, model.ImportRowErrorResponse{
+			RowNumber: rowErr.RowNumber,
+			Sku:       rowErr.Sku,
+			Reason:    rowErr.Reason,
+		})
+	}
+
+	return res, nil
+}
+
+// run upserts the rows batch by batch, storing progress and row errors after
+// every batch. A failed batch marks its rows as failed and the job goes on.
+func (s *ImportService) run(ctx context.Context, job model.ImportJob, rows []importRow) {
+	job.Status = model.ImportStatusRunning
+	if err := s.repo.UpdateImportJob(ctx, &job); err != nil {
+		slog.Error("import job update failed", "job_id", job.Id, "error", err)
+	}
+
+	markDuplicates(rows)
+
+	batchFailed := false
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+		rowErrors, failed := s.processBatch(ctx, &job, rows[start:end])
+		batchFailed = batchFailed || failed
+
+		if err := s.repo.SaveImportErrors(ctx, rowErrors); err != nil {
+			slog.Error("import errors save failed", "job_id", job.Id, "error", err)
+		}
+
+		job.ProcessedRows = end
+		job.FailedCount += len(rowErrors)
+		if err := s.repo.UpdateImportJob(ctx, &job); err != nil {
+			slog.Error("import job update failed", "job_id", job.Id, "error", err)
+		}
+	}
+
+	if job.CreatedCount+job.UpdatedCount > 0 {
+		s.cache.Del(ctx, "products:all")
+		if err := s.index.Reindex(ctx); err != nil {
+			slog.Error("search index rebuild after import failed", "job_id", job.Id, "error", err)
+		}
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = model.ImportStatusCompleted
+	if batchFailed {
+		job.Status = model.ImportStatusFailed
+		job.Error = "some rows could not be saved, see the error report"
+	}
+	if err := s.repo.UpdateImportJob(ctx, &job); err != nil {
+		slog.Error("import job update failed", "job_id", job.Id, "error", err)
+	}
+}
+
+func (s *ImportService) processBatch(ctx context.Context, job *model.ImportJob, rows []importRow) ([]model.ImportRowError, bool) {
+	var (
+		rowErrors []model.ImportRowError
+		valid     []importRow
+		products  []model.Product
+		failed    bool
+	)
+	flags := make(map[string][]model.ModerationFlag)
+	for _, row := range rows {
+		if row.err != "" {
+			rowErrors = append(rowErrors, toRowError(job.Id, row, row.err))
+			continue
+		}
+
+		// Every row is checked, a draft flag in the file doesn't keep an already
+		// listed product from the review. Whether a draft is rejected is decided
+		// when the row is written.
+		rowFlags, reason, err := s.review(ctx, &row.product)
+		if err != nil {
+			slog.Error("import row moderation failed", "job_id", job.Id, "sku", row.product.Sku, "error", err)
+			rowErrors = append(rowErrors, toRowError(job.Id, row, "row could not be moderated"))
+			failed = true
+			continue
+		}
+		row.product.RejectionReason = reason
+		flags[row.product.Sku] = rowFlags
+
+		valid = append(valid, row)
+		products = append(products, row.product)
+	}
+
+	if len(products) == 0 {
+		return rowErrors, failed
+	}
+
+	written, err := s.repo.UpsertProductsBySku(ctx, job.SellerId, job.Id, products)
+	if err != nil {
+		slog.Error("import batch failed", "job_id", job.Id, "error", err)
+		for _, row := range valid {
+			rowErrors = append(rowErrors, toRowError(job.Id, row, "row could not be saved"))
+		}
+		return rowErrors, true
+	}
+
+	for _, row := range valid {
+		p, ok := written[row.product.Sku]
+		switch {
+		case !ok:
+			rowErrors = append(rowErrors, toRowError(job.Id, row, "category does not exist or product with this sku is deleted"))
+			continue
+		case p.Created:
+			job.CreatedCount++
+		default:
+			job.UpdatedCount++
+		}
+
+		if rowFlags := flags[p.Sku]; rowFlags != nil && p.Status != model.ProductStatusDraft {
+			if err = s.moderator.SaveFlags(ctx, p.Id, rowFlags); err != nil {
+				slog.Error("import moderation flags save failed", "job_id", job.Id, "product_id", p.Id, "error", err)
+			}
+		}
+	}
+
+	return rowErrors, failed
+}
+
+// review returns the flags of the automated checks and the rejection reason made
+// of the high severity ones, the same way a product created by hand is reviewed.
+func (s *ImportService) review(ctx context.Context, p *model.Product) ([]model.ModerationFlag, string, error) {
+	flags, err := s.moderator.Review(ctx, p)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var messages []string
+	for _, flag := range flags {
+		if flag.Severity == model.SeverityHigh {
+			messages = append(messages, flag.Message)
+		}
+	}
+
+	return flags, strings.Join(messages, "; "), nil
+}
+
+// markDuplicates keeps the last occurrence of every sku, the same product
+// cannot be written twice in one upsert.
+func markDuplicates(rows []importRow) {
+	last := make(map[string]int, len(rows))
+	for i, row := range rows {
+		if row.err == "" {
+			last[row.product.Sku] = i
+		}
+	}
+
+	for i := range rows {
+		if rows[i].err != "" {
+			continue
+		}
+		if j := last[rows[i].product.Sku]; j != i {
+			rows[i].err = fmt.Sprintf("duplicate sku, overridden by row %d", rows[j].line)
+		}
+	}
+}
+
+func toRowError(jobId int64, row importRow, reason string) model.ImportRowError {
+	return model.ImportRowError{
+		JobId:     jobId,
+		RowNumber: row.line,
+		Sku:       row.product.Sku,
+		Reason:    reason,
+	}
+}
+
+func toImportJobResponse(job *model.ImportJob) model.ImportJobResponse {
+	return model.ImportJobResponse{
+		Id:            job.Id,
+		Format:        job.Format,
+		Status:        job.Status,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		CreatedCount:  job.CreatedCount,
+		UpdatedCount:  job.UpdatedCount,
+		FailedCount:   job.FailedCount,
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt,
+		FinishedAt:    job.FinishedAt,
+	}
+}
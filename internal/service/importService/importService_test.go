@@ -0,0 +1,345 @@
+package importService
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/search"
+)
+
+type mockRepo struct {
+	CreateJobFn   func(ctx context.Context, job *model.ImportJob) error
+	GetJobFn      func(ctx context.Context, id int64) (*model.ImportJob, error)
+	UpdateJobFn   func(ctx context.Context, job *model.ImportJob) error
+	SaveErrorsFn  func(ctx context.Context, rowErrors []model.ImportRowError) error
+	GetErrorsFn   func(ctx context.Context, jobId int64) (*[]model.ImportRowError, error)
+	UpsertBySkuFn func(ctx context.Context, sellerId int64, products []model.Product) (map[string]model.ImportedProduct, error)
+}
+
+func (m *mockRepo) CreateImportJob(ctx context.Context, job *model.ImportJob) error {
+	return m.CreateJobFn(ctx, job)
+}
+func (m *mockRepo) GetImportJobById(ctx context.Context, id int64) (*model.ImportJob, error) {
+	return m.GetJobFn(ctx, id)
+}
+func (m *mockRepo) UpdateImportJob(ctx context.Context, job *model.ImportJob) error {
+	return m.UpdateJobFn(ctx, job)
+}
+func (m *mockRepo) SaveImportErrors(ctx context.Context, rowErrors []model.ImportRowError) error {
+	return m.SaveErrorsFn(ctx, rowErrors)
+}
+func (m *mockRepo) GetImportErrors(ctx context.Context, jobId int64) (*[]model.ImportRowError, error) {
+	return m.GetErrorsFn(ctx, jobId)
+}
+func (m *mockRepo) UpsertProductsBySku(ctx context.Context, sellerId, jobId int64, products []model.Product) (map[string]model.ImportedProduct, error) {
+	return m.UpsertBySkuFn(ctx, sellerId, products)
+}
+
+type mockModerator struct {
+	ReviewFn    func(ctx context.Context, p *model.Product) ([]model.ModerationFlag, error)
+	SaveFlagsFn func(ctx context.Context, productId int64, flags []model.ModerationFlag) error
+}
+
+func (m *mockModerator) Review(ctx context.Context, p *model.Product) ([]model.ModerationFlag, error) {
+	if m.ReviewFn == nil {
+		return nil, nil
+	}
+	return m.ReviewFn(ctx, p)
+}
+func (m *mockModerator) SaveFlags(ctx context.Context, productId int64, flags []model.ModerationFlag) error {
+	return m.SaveFlagsFn(ctx, productId, flags)
+}
+
+type mockIndex struct {
+	reindexed int
+}
+
+func (m *mockIndex) Search(ctx context.Context, q search.Query) (*[]model.Product, error) {
+	return nil, nil
+}
+func (m *mockIndex) Index(ctx context.Context, product *model.Product) error { return nil }
+func (m *mockIndex) Delete(ctx context.Context, productId int64) error       { return nil }
+func (m *mockIndex) Reindex(ctx context.Context) error {
+	m.reindexed++
+	return nil
+}
+
+func TestParseCSV(t *testing.T) {
+	data := "\ufeffSKU,name,description,price,stock,category_id,draft\n" +
+		"A-1,Phone,\"Good, new\",100.5,3,2,\n" +
+		"A-2,Case,,abc,1,2,\n" +
+		"A-3,Cable,,10,1,2,true\n" +
+		",Nameless,,10,1,2,\n"
+	rows, err := parseRows(model.ImportFormatCSV, []byte(data), 10)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(rows))
+	}
+	first := rows[0]
+	if first.err != "" || first.line != 2 || first.product.Description != "Good, new" || first.product.Price != 100.5 ||
+		first.product.Status != model.ProductStatusPendingReview {
+		t.Fatalf("unexpected first row: %+v", first)
+	}
+	if rows[1].err != "price is not a number" || rows[1].line != 3 {
+		t.Fatalf("unexpected second row: %+v", rows[1])
+	}
+	if rows[2].product.Status != model.ProductStatusDraft {
+		t.Fatalf("expected draft, got %+v", rows[2])
+	}
+	if rows[3].err != "sku is required" {
+		t.Fatalf("unexpected last row: %+v", rows[3])
+	}
+}
+
+func TestParseRows_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{"missing column", model.ImportFormatCSV, "sku,name,price,stock\nA,B,1,1\n"},
+		{"header only", model.ImportFormatCSV, "sku,name,price,stock,category_id\n"},
+		{"empty jsonl", model.ImportFormatJSONL, "\n\n"},
+		{"too many rows", model.ImportFormatJSONL, "{}\n{}\n{}\n"},
+		{"unknown format", "xml", "<a/>"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRows(tt.format, []byte(tt.data), 2)
+			if !errors.Is(err, errs.ValidationError) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseJSONL(t *testing.T) {
+	data := `{"sku":"A-1","name":"Phone","price":100,"stock":0,"category_id":2}
+
+not json
+{"sku":"A-2","name":"P","price":100,"stock":1,"category_id":2}
+{"sku":"A-3","name":"Cable","price":5,"stock":1,"category_id":2,"draft":true}
+`
+	rows, err := parseRows(model.ImportFormatJSONL, []byte(data), 10)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(rows))
+	}
+	if rows[0].err != "" || rows[0].product.Stock != 0 {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].line != 3 || rows[1].err != "invalid json" {
+		t.Fatalf("unexpected second row: %+v", rows[1])
+	}
+	if rows[2].line != 4 || !strings.Contains(rows[2].err, "name") {
+		t.Fatalf("unexpected third row: %+v", rows[2])
+	}
+	if rows[3].product.Status != model.ProductStatusDraft {
+		t.Fatalf("expected draft, got %+v", rows[3])
+	}
+}
+
+func TestImportService_Run(t *testing.T) {
+	rows := []importRow{
+		{line: 2, product: model.Product{Sku: "A", Name: "First", Price: 1, CategoryId: 1}},
+		{line: 3, err: "price is not a number", product: model.Product{Sku: "B"}},
+		{line: 4, product: model.Product{Sku: "C", Name: "Old", Price: 1, CategoryId: 1}},
+		{line: 5, product: model.Product{Sku: "A", Name: "Second", Price: 2, CategoryId: 1}},
+		{line: 6, product: model.Product{Sku: "D", Name: "Unknown category", Price: 1, CategoryId: 99}},
+	}
+
+	var (
+		saved   []model.ImportRowError
+		updates []model.ImportJob
+	)
+	repo := &mockRepo{
+		UpdateJobFn: func(ctx context.Context, job *model.ImportJob) error {
+			updates = append(updates, *job)
+			return nil
+		},
+		SaveErrorsFn: func(ctx context.Context, rowErrors []model.ImportRowError) error {
+			saved = append(saved, rowErrors...)
+			return nil
+		},
+		UpsertBySkuFn: func(ctx context.Context, sellerId int64, products []model.Product) (map[string]model.ImportedProduct, error) {
+			if sellerId != 7 || len(products) != 3 || products[0].Sku != "C" || products[1].Name != "Second" {
+				t.Fatalf("unexpected upsert: %d %+v", sellerId, products)
+			}
+			return map[string]model.ImportedProduct{
+				"A": {Id: 1, Sku: "A", Status: model.ProductStatusPendingReview, Created: true},
+				"C": {Id: 2, Sku: "C", Status: model.ProductStatusApproved},
+			}, nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	index := &mockIndex{}
+	s := NewImportService(repo, &mockModerator{}, client, index)
+
+	s.run(context.Background(), model.ImportJob{Id: 1, SellerId: 7, TotalRows: len(rows)}, rows)
+
+	last := updates[len(updates)-1]
+	if last.Status != model.ImportStatusCompleted || last.FinishedAt == nil || last.ProcessedRows != 5 ||
+		last.CreatedCount != 1 || last.UpdatedCount != 1 || last.FailedCount != 3 {
+		t.Fatalf("unexpected job: %+v", last)
+	}
+	if updates[0].Status != model.ImportStatusRunning {
+		t.Fatalf("expected running status first, got %+v", updates[0])
+	}
+	if len(saved) != 3 || saved[0].RowNumber != 2 || !strings.Contains(saved[0].Reason, "row 5") ||
+		saved[1].RowNumber != 3 || saved[2].Sku != "D" {
+		t.Fatalf("unexpected row errors: %+v", saved)
+	}
+	if index.reindexed != 1 {
+		t.Fatalf("expected one reindex, got %d", index.reindexed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestImportService_RunBatchError(t *testing.T) {
+	var last model.ImportJob
+	repo := &mockRepo{
+		UpdateJobFn: func(ctx context.Context, job *model.ImportJob) error {
+			last = *job
+			return nil
+		},
+		SaveErrorsFn: func(ctx context.Context, rowErrors []model.ImportRowError) error { return nil },
+		UpsertBySkuFn: func(ctx context.Context, sellerId int64, products []model.Product) (map[string]model.ImportedProduct, error) {
+			return nil, errors.New("db down")
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	index := &mockIndex{}
+	s := NewImportService(repo, &mockModerator{}, client, index)
+
+	s.run(context.Background(), model.ImportJob{Id: 1, SellerId: 7}, []importRow{
+		{line: 2, product: model.Product{Sku: "A", Name: "First", Price: 1, CategoryId: 1}},
+	})
+
+	if last.Status != model.ImportStatusFailed || last.FailedCount != 1 || last.Error == "" {
+		t.Fatalf("unexpected job: %+v", last)
+	}
+	if index.reindexed != 0 {
+		t.Fatalf("index must not be rebuilt when nothing was written")
+	}
+}
+
+func TestImportService_RunModeration(t *testing.T) {
+	var (
+		last     model.ImportJob
+		upserted []model.Product
+		saved    = make(map[int64][]model.ModerationFlag)
+	)
+	repo := &mockRepo{
+		UpdateJobFn: func(ctx context.Context, job *model.ImportJob) error {
+			last = *job
+			return nil
+		},
+		SaveErrorsFn: func(ctx context.Context, rowErrors []model.ImportRowError) error { return nil },
+		UpsertBySkuFn: func(ctx context.Context, sellerId int64, products []model.Product) (map[string]model.ImportedProduct, error) {
+			upserted = products
+			return map[string]model.ImportedProduct{
+				"A": {Id: 1, Sku: "A", Status: model.ProductStatusRejected, Created: true},
+				"B": {Id: 2, Sku: "B", Status: model.ProductStatusDraft, Created: true},
+				"C": {Id: 3, Sku: "C", Status: model.ProductStatusPendingReview, Created: true},
+			}, nil
+		},
+	}
+	moderator := &mockModerator{
+		ReviewFn: func(ctx context.Context, p *model.Product) ([]model.ModerationFlag, error) {
+			switch p.Sku {
+			case "C":
+				return nil, nil
+			case "D":
+				return nil, errors.New("rules unavailable")
+			}
+			return []model.ModerationFlag{
+				{Code: "contacts", Severity: model.SeverityHigh, Message: "contacts in text"},
+				{Code: "price", Severity: model.SeverityLow, Message: "price is low"},
+			}, nil
+		},
+		SaveFlagsFn: func(ctx context.Context, productId int64, flags []model.ModerationFlag) error {
+			saved[productId] = flags
+			return nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	s := NewImportService(repo, moderator, client, &mockIndex{})
+
+	s.run(context.Background(), model.ImportJob{Id: 1, SellerId: 7}, []importRow{
+		{line: 2, product: model.Product{Sku: "A", Name: "Call me", Price: 1, CategoryId: 1, Status: model.ProductStatusPendingReview}},
+		{line: 3, product: model.Product{Sku: "B", Name: "Call me", Price: 1, CategoryId: 1, Status: model.ProductStatusDraft}},
+		{line: 4, product: model.Product{Sku: "C", Name: "Clean", Price: 1, CategoryId: 1, Status: model.ProductStatusPendingReview}},
+		{line: 5, product: model.Product{Sku: "D", Name: "Unchecked", Price: 1, CategoryId: 1, Status: model.ProductStatusPendingReview}},
+	})
+
+	if len(upserted) != 3 || upserted[0].RejectionReason != "contacts in text" ||
+		upserted[1].RejectionReason != "contacts in text" || upserted[2].RejectionReason != "" {
+		t.Fatalf("unexpected upsert: %+v", upserted)
+	}
+	if len(saved) != 1 || len(saved[1]) != 2 {
+		t.Fatalf("expected flags saved for the rejected product only, got %+v", saved)
+	}
+	if last.Status != model.ImportStatusFailed || last.CreatedCount != 3 || last.FailedCount != 1 {
+		t.Fatalf("unexpected job: %+v", last)
+	}
+}
+
+func TestImportService_StartImportInvalidFile(t *testing.T) {
+	repo := &mockRepo{
+		CreateJobFn: func(ctx context.Context, job *model.ImportJob) error {
+			t.Fatalf("job must not be created for an invalid file")
+			return nil
+		},
+	}
+	s := NewImportService(repo, &mockModerator{}, nil, &mockIndex{})
+
+	_, err := s.StartImport(context.Background(), 7, model.ImportFormatCSV, strings.NewReader("name,price\n"))
+	if !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestImportService_GetJob(t *testing.T) {
+	repo := &mockRepo{
+		GetJobFn: func(ctx context.Context, id int64) (*model.ImportJob, error) {
+			return &model.ImportJob{Id: id, SellerId: 7, Status: model.ImportStatusRunning, ProcessedRows: 500}, nil
+		},
+		GetErrorsFn: func(ctx context.Context, jobId int64) (*[]model.ImportRowError, error) {
+			return &[]model.ImportRowError{{JobId: jobId, RowNumber: 4, Sku: "A", Reason: "sku is required"}}, nil
+		},
+	}
+	s := NewImportService(repo, &mockModerator{}, nil, &mockIndex{})
+	req := &model.GetImportJobRequest{Id: 3}
+
+	out, err := s.GetJob(context.Background(), 7, "seller", req)
+	if err != nil || out.Id != 3 || out.ProcessedRows != 500 {
+		t.Fatalf("unexpected result: %+v %v", out, err)
+	}
+
+	if _, err = s.GetJob(context.Background(), 8, "seller", req); !errors.Is(err, errs.NotOwnerError) {
+		t.Fatalf("expected not owner error, got %v", err)
+	}
+
+	rowErrors, err := s.GetErrors(context.Background(), 1, "admin", req)
+	if err != nil || len(rowErrors) != 1 || rowErrors[0].RowNumber != 4 {
+		t.Fatalf("unexpected errors: %+v %v", rowErrors, err)
+	}
+
+	if _, err = s.GetErrors(context.Background(), 8, "seller", req); !errors.Is(err, errs.NotOwnerError) {
+		t.Fatalf("expected not owner error, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package importService
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const (
+	maxLineSize = 1 << 20
+	// maxPrice is the first value that does not fit into NUMERIC(10, 2).
+	maxPrice = 1e8
+)
+
+var requiredColumns = []string{"sku", "name", "price", "stock", "category_id"}
+
+// importRow is one product line of the file. Line is the line number in the
+// file, so the seller can find the row in the error report.
+type importRow struct {
+	line    int
+	product model.Product
+	err     string
+}
+
+type jsonRecord struct {
+	Sku         string  `json:"sku"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Stock       int     `json:"stock"`
+	CategoryId  int64   `json:"category_id"`
+	ImageUrl    string  `json:"image_url"`
+	Draft       bool    `json:"draft"`
+}
+
+func parseRows(format string, data []byte, maxRows int) ([]importRow, error) {
+	var (
+		rows []importRow
+		err  error
+	)
+	switch format {
+	case model.ImportFormatCSV:
+		rows, err = parseCSV(data, maxRows)
+	case model.ImportFormatJSONL:
+		rows, err = parseJSONL(data, maxRows)
+	default:
+		return nil, fmt.Errorf("%w: unsupported import format %q", errs.ValidationError, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: import file has no rows", errs.ValidationError)
+	}
+
+	return rows, nil
+}
+
+func parseCSV(data []byte, maxRows int) ([]importRow, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: import file is empty", errs.ValidationError)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid csv: %w", errs.ValidationError, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: csv header has no %q column", errs.ValidationError, name)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid csv: %w", errs.ValidationError, err)
+		}
+
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("%w: import file has more than %d rows", errs.ValidationError, maxRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, csvRow(line, columns, record))
+	}
+
+	return rows, nil
+}
+
+func csvRow(line int, columns map[string]int, record []string) importRow {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := importRow{line: line}
+	row.product.Sku = field("sku")
+
+	price, err := strconv.ParseFloat(field("price"), 64)
+	if err != nil {
+		row.err = "price is not a number"
+		return row
+	}
+
+	stock, err := strconv.Atoi(field("stock"))
+	if err != nil {
+		row.err = "stock is not an integer"
+		return row
+	}
+
+	categoryId, err := strconv.ParseInt(field("category_id"), 10, 64)
+	if err != nil {
+		row.err = "category_id is not an integer"
+		return row
+	}
+
+	draft := false
+	if raw := field("draft"); raw != "" {
+		if draft, err = strconv.ParseBool(raw); err != nil {
+			row.err = "draft must be true or false"
+			return row
+		}
+	}
+
+	row.product.Name = field("name")
+	row.product.Description = field("description")
+	row.product.ImageUrl = field("image_url")
+	row.product.Price = price
+	row.product.Stock = stock
+	row.product.CategoryId = categoryId
+	row.product.Status = statusFor(draft)
+	row.err = validateProduct(&row.product)
+
+	return row
+}
+
+func parseJSONL(data []byte, maxRows int) ([]importRow, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var rows []importRow
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("%w: import file has more than %d rows", errs.ValidationError, maxRows)
+		}
+
+		var record jsonRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			rows = append(rows, importRow{line: line, err: "invalid json"})
+			continue
+		}
+
+		row := importRow{line: line}
+		row.product = model.Product{
+			Sku:         strings.TrimSpace(record.Sku),
+			Name:        strings.TrimSpace(record.Name),
+			Description: strings.TrimSpace(record.Description),
+			Price:       record.Price,
+			Stock:       record.Stock,
+			CategoryId:  record.CategoryId,
+			ImageUrl:    strings.TrimSpace(record.ImageUrl),
+			Status:      statusFor(record.Draft),
+		}
+		row.err = validateProduct(&row.product)
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: invalid jsonl: %w", errs.ValidationError, err)
+	}
+
+	return rows, nil
+}
+
+// validateProduct applies the same limits as CreateProductRequest and returns
+// the reason the row is rejected, or an empty string.
+func validateProduct(p *model.Product) string {
+	switch {
+	case p.Sku == "":
+		return "sku is required"
+	case utf8.RuneCountInString(p.Sku) > 64:
+		return "sku is longer than 64 characters"
+	case utf8.RuneCountInString(p.Name) < 2 || utf8.RuneCountInString(p.Name) > 100:
+		return "name must be from 2 to 100 characters"
+	case utf8.RuneCountInString(p.Description) > 5000:
+		return "description is longer than 5000 characters"
+	case p.Price <= 0:
+		return "price must be greater than 0"
+	case p.Price >= maxPrice:
+		return "price is too large"
+	case p.Stock < 0:
+		return "stock must not be negative"
+	case p.CategoryId <= 0:
+		return "category_id is required"
+	case len(p.ImageUrl) > 2048:
+		return "image_url is longer than 2048 characters"
+	case p.ImageUrl != "" && !isHttpUrl(p.ImageUrl):
+		return "image_url is not a valid url"
+	}
+
+	return ""
+}
+
+func isHttpUrl(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func statusFor(draft bool) string {
+	if draft {
+		return model.ProductStatusDraft
+	}
+	return model.ProductStatusPendingReview
+}
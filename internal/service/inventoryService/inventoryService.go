@@ -0,0 +1,412 @@
+package inventoryService
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/policy"
+	"github.com/niklvrr/myMarketplace/internal/search"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+type IInventoryRepository interface {
+	CreateWarehouse(ctx context.Context, w *model.Warehouse) (bool, error)
+	GetWarehouseById(ctx context.Context, id int64) (*model.Warehouse, error)
+	GetSellerWarehouses(ctx context.Context, sellerId int64) (*[]model.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, w *model.Warehouse) (bool, error)
+	DeleteWarehouse(ctx context.Context, id int64) (bool, error)
+	GetInventoryLevels(ctx context.Context, productId int64) (*[]model.InventoryLevel, error)
+	SetInventoryLevel(ctx context.Context, level *model.InventoryLevel, userId int64) (int, int, error)
+	TransferStock(ctx context.Context, t *model.InventoryTransfer) (bool, error)
+}
+
+// IInventoryLedger reads the movements recorded for every change of the stock.
+type IInventoryLedger interface {
+	GetProductMovements(ctx context.Context, productId int64, cursor *model.MovementCursor, limit int) (*[]model.InventoryMovement, error)
+	GetStockDrift(ctx context.Context, afterProductId int64, limit int) (*[]model.StockDrift, error)
+}
+
+type IProductReader interface {
+	GetProductById(ctx context.Context, productId int64) (*model.Product, error)
+}
+
+// IRestockListener is told when a listed product goes from sold out to in stock.
+type IRestockListener interface {
+	Restocked(productId int64, name string)
+}
+
+// ILowStockChecker is told about products whose stock went down.
+type ILowStockChecker interface {
+	StockDecreased(productIds []int64)
+}
+
+// InventoryService manages the sellers' warehouses and the stock of products per warehouse.
+type InventoryService struct {
+	repo     IInventoryRepository
+	ledger   IInventoryLedger
+	products IProductReader
+	index    search.SearchIndex
+	cache    *redis.Client
+	restocks IRestockListener
+	lowStock ILowStockChecker
+}
+
+func NewInventoryService(
+	repo IInventoryRepository,
+	ledger IInventoryLedger,
+	products IProductReader,
+	index search.SearchIndex,
+	cache *redis.Client,
+	restocks IRestockListener,
+	lowStock ILowStockChecker,
+) *InventoryService {
+	return &InventoryService{
+		repo:     repo,
+		ledger:   ledger,
+		products: products,
+		index:    index,
+		cache:    cache,
+		restocks: restocks,
+		lowStock: lowStock,
+	}
+}
+
+func (s *InventoryService) CreateWarehouse(ctx context.Context, userId int64, req *model.CreateWarehouseRequest) (model.WarehouseResponse, error) {
+	w := model.Warehouse{
+		SellerId: userId,
+		Name:     req.Name,
+		Priority: req.Priority,
+	}
+
+	created, err := s.repo.CreateWarehouse(ctx, &w)
+	if err != nil {
+		return model.WarehouseResponse{}, err
+	}
+
+	if !created {
+		return model.WarehouseResponse{}, fmt.Errorf("%w: warehouse %q already exists", errs.ConflictError, req.Name)
+	}
+
+	return toWarehouseResponse(&w), nil
+}
+
+func (s *InventoryService) ListWarehouses(ctx context.Context, userId int64) ([]model.WarehouseResponse, error) {
+	warehouses, err := s.repo.GetSellerWarehouses(ctx, userId)
+	if err != nil {
+		return []model.WarehouseResponse{}, err
+	}
+
+	result := make([]model.WarehouseResponse, 0, len(*warehouses))
+	for i := range *warehouses {
+		result = append(result, toWarehouseResponse(&(*warehouses)[i]))
+	}
+
+	return result, nil
+}
+
+func (s *InventoryService) UpdateWarehouse(ctx context.Context, userId int64, role string, req *model.UpdateWarehouseRequest) (model.WarehouseResponse, error) {
+	w, err := s.ownWarehouse(ctx, userId, role, req.Id)
+	if err != nil {
+		return model.WarehouseResponse{}, err
+	}
+
+	w.Name = req.Name
+	w.Priority = req.Priority
+	updated, err := s.repo.UpdateWarehouse(ctx, w)
+	if err != nil {
+		return model.WarehouseResponse{}, err
+	}
+
+	if !updated {
+		return model.WarehouseResponse{}, fmt.Errorf("%w: warehouse %q already exists", errs.ConflictError, req.Name)
+	}
+
+	return toWarehouseResponse(w), nil
+}
+
+func (s *InventoryService) DeleteWarehouse(ctx context.Context, userId int64, role string, req *model.WarehouseRequest) error {
+	if _, err := s.ownWarehouse(ctx, userId, role, req.Id); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeleteWarehouse(ctx, req.Id)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return fmt.Errorf("%w: warehouse %d still has stock, transfer it first", errs.ConflictError, req.Id)
+	}
+
+	return nil
+}
+
+func (s *InventoryService) ProductInventory(ctx context.Context, userId int64, role string, req *model.ProductInventoryRequest) (model.ProductInventoryResponse, error) {
+	p, err := s.manageableProduct(ctx, userId, role, req.ProductId)
+	if err != nil {
+		return model.ProductInventoryResponse{}, err
+	}
+
+	return s.productInventory(ctx, p.Id, p.Stock)
+}
+
+// SetLevel sets the stock of the product in one of the seller's warehouses. The product
+// stock becomes the sum over its warehouses, so the first level replaces the stock set
+// on the product before. Bundles are kept in the warehouses as their components.
+func (s *InventoryService) SetLevel(ctx context.Context, userId int64, role string, req *model.SetInventoryLevelRequest) (model.ProductInventoryResponse, error) {
+	p, err := s.manageableProduct(ctx, userId, role, req.ProductId)
+	if err != nil {
+		return model.ProductInventoryResponse{}, err
+	}
+
+	if p.Bundle {
+		return model.ProductInventoryResponse{}, fmt.Errorf("%w: stock of bundle %d comes from its components", errs.ConflictError, p.Id)
+	}
+
+	if err = s.sellerWarehouse(ctx, p, req.WarehouseId); err != nil {
+		return model.ProductInventoryResponse{}, err
+	}
+
+	level := model.InventoryLevel{
+		ProductId:   p.Id,
+		WarehouseId: req.WarehouseId,
+		Quantity:    *req.Quantity,
+	}
+	before, after, err := s.repo.SetInventoryLevel(ctx, &level, userId)
+	if err != nil {
+		return model.ProductInventoryResponse{}, err
+	}
+
+	p.Stock = after
+	p.Warehoused = true
+	s.cache.Del(ctx, "products:all")
+	if err = s.index.Index(ctx, p); err != nil {
+		return model.ProductInventoryResponse{}, err
+	}
+
+	if before == 0 && after > 0 && p.Listed() {
+		s.restocks.Restocked(p.Id, p.Name)
+	}
+	if after < before {
+		s.lowStock.StockDecreased([]int64{p.Id})
+	}
+
+	return s.productInventory(ctx, p.Id, after)
+}
+
+// Transfer moves stock between two warehouses of the seller, the product stock stays the same.
+func (s *InventoryService) Transfer(ctx context.Context, userId int64, role string, req *model.TransferStockRequest) (model.InventoryTransferResponse, error) {
+	if req.FromWarehouseId == req.ToWarehouseId {
+		return model.InventoryTransferResponse{}, fmt.Errorf("%w: source and destination warehouses are the same", errs.ValidationError)
+	}
+
+	p, err := s.manageableProduct(ctx, userId, role, req.ProductId)
+	if err != nil {
+		return model.InventoryTransferResponse{}, err
+	}
+
+	for _, id := range []int64{req.FromWarehouseId, req.ToWarehouseId} {
+		if err = s.sellerWarehouse(ctx, p, id); err != nil {
+			return model.InventoryTransferResponse{}, err
+		}
+	}
+
+	t := model.InventoryTransfer{
+		ProductId:       p.Id,
+		FromWarehouseId: req.FromWarehouseId,
+		ToWarehouseId:   req.ToWarehouseId,
+		Quantity:        req.Quantity,
+		UserId:          userId,
+	}
+	moved, err := s.repo.TransferStock(ctx, &t)
+	if err != nil {
+		return model.InventoryTransferResponse{}, err
+	}
+
+	if !moved {
+		return model.InventoryTransferResponse{}, fmt.Errorf("%w: not enough stock in warehouse %d", errs.ConflictError, req.FromWarehouseId)
+	}
+
+	return model.InventoryTransferResponse{
+		Id:              t.Id,
+		ProductId:       t.ProductId,
+		FromWarehouseId: t.FromWarehouseId,
+		ToWarehouseId:   t.ToWarehouseId,
+		Quantity:        t.Quantity,
+		CreatedAt:       t.CreatedAt,
+	}, nil
+}
+
+// ProductMovements returns a page of the product's stock movements, newest first. The
+// history stays available after the product is deleted.
+func (s *InventoryService) ProductMovements(ctx context.Context, userId int64, role string, limit int, req *model.ProductMovementsRequest) ([]model.InventoryMovementResponse, string, error) {
+	var cursor *model.MovementCursor
+	if req.Cursor != "" {
+		cursor = new(model.MovementCursor)
+		if err := utils.DecodeCursor(req.Cursor, cursor); err != nil {
+			return []model.InventoryMovementResponse{}, "", fmt.Errorf("%w: invalid cursor", errs.ValidationError)
+		}
+	}
+
+	p, err := s.products.GetProductById(ctx, req.ProductId)
+	if err != nil {
+		return []model.InventoryMovementResponse{}, "", err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return []model.InventoryMovementResponse{}, "", err
+	}
+
+	movements, err := s.ledger.GetProductMovements(ctx, p.Id, cursor, limit+1)
+	if err != nil {
+		return []model.InventoryMovementResponse{}, "", err
+	}
+
+	page := *movements
+	var nextCursor string
+	if len(page) > limit {
+		page = page[:limit]
+		nextCursor, err = utils.EncodeCursor(model.MovementCursor{Id: page[limit-1].Id})
+		if err != nil {
+			return []model.InventoryMovementResponse{}, "", err
+		}
+	}
+
+	result := make([]model.InventoryMovementResponse, 0, len(page))
+	for _, m := range page {
+		result = append(result, model.InventoryMovementResponse{
+			Id:          m.Id,
+			WarehouseId: m.WarehouseId,
+			Quantity:    m.Quantity,
+			Reason:      m.Reason,
+			ActorId:     m.ActorId,
+			Reference:   m.Reference,
+			CreatedAt:   m.CreatedAt,
+		})
+	}
+
+	return result, nextCursor, nil
+}
+
+// Reconcile returns a page of the products whose stock doesn't match the sum of their
+// movements. An empty report means the ledger explains every stock.
+func (s *InventoryService) Reconcile(ctx context.Context, limit int, req *model.ReconciliationRequest) ([]model.StockDriftResponse, string, error) {
+	var cursor model.StockDriftCursor
+	if req.Cursor != "" {
+		if err := utils.DecodeCursor(req.Cursor, &cursor); err != nil {
+			return []model.StockDriftResponse{}, "", fmt.Errorf("%w: invalid cursor", errs.ValidationError)
+		}
+	}
+
+	drifts, err := s.ledger.GetStockDrift(ctx, cursor.ProductId, limit+1)
+	if err != nil {
+		return []model.StockDriftResponse{}, "", err
+	}
+
+	page := *drifts
+	var nextCursor string
+	if len(page) > limit {
+		page = page[:limit]
+		nextCursor, err = utils.EncodeCursor(model.StockDriftCursor{ProductId: page[limit-1].ProductId})
+		if err != nil {
+			return []model.StockDriftResponse{}, "", err
+		}
+	}
+
+	result := make([]model.StockDriftResponse, 0, len(page))
+	for _, d := range page {
+		result = append(result, model.StockDriftResponse{
+			ProductId:   d.ProductId,
+			SellerId:    d.SellerId,
+			Sku:         d.Sku,
+			Name:        d.Name,
+			Stock:       d.Stock,
+			LedgerStock: d.LedgerStock,
+			Drift:       d.Stock - d.LedgerStock,
+		})
+	}
+
+	return result, nextCursor, nil
+}
+
+func (s *InventoryService) productInventory(ctx context.Context, productId int64, stock int) (model.ProductInventoryResponse, error) {
+	levels, err := s.repo.GetInventoryLevels(ctx, productId)
+	if err != nil {
+		return model.ProductInventoryResponse{}, err
+	}
+
+	resp := model.ProductInventoryResponse{
+		ProductId: productId,
+		Stock:     stock,
+		Levels:    make([]model.InventoryLevelResponse, 0, len(*levels)),
+	}
+	for _, l := range *levels {
+		resp.Levels = append(resp.Levels, model.InventoryLevelResponse{
+			WarehouseId:   l.WarehouseId,
+			WarehouseName: l.WarehouseName,
+			Quantity:      l.Quantity,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *InventoryService) manageableProduct(ctx context.Context, userId int64, role string, productId int64) (*model.Product, error) {
+	p, err := s.products.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return nil, err
+	}
+
+	if p.DeletedAt != nil {
+		return nil, fmt.Errorf("%w: product is deleted, restore it first", errs.ConflictError)
+	}
+
+	return p, nil
+}
+
+func (s *InventoryService) ownWarehouse(ctx context.Context, userId int64, role string, id int64) (*model.Warehouse, error) {
+	w, err := s.repo.GetWarehouseById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if w == nil {
+		return nil, fmt.Errorf("%w: warehouse %d", errs.NotFoundError, id)
+	}
+
+	if w.SellerId != userId && role != policy.RoleAdmin {
+		return nil, fmt.Errorf("%w: warehouse %d belongs to another seller", errs.NotOwnerError, id)
+	}
+
+	return w, nil
+}
+
+// sellerWarehouse checks that the warehouse belongs to the seller of the product.
+func (s *InventoryService) sellerWarehouse(ctx context.Context, p *model.Product, id int64) error {
+	w, err := s.repo.GetWarehouseById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if w == nil || w.SellerId != p.SellerId {
+		return fmt.Errorf("%w: warehouse %d", errs.NotFoundError, id)
+	}
+
+	return nil
+}
+
+func toWarehouseResponse(w *model.Warehouse) model.WarehouseResponse {
+	return model.WarehouseResponse{
+		Id:        w.Id,
+		Name:      w.Name,
+		Priority:  w.Priority,
+		CreatedAt: w.CreatedAt,
+	}
+}
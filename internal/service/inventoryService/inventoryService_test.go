@@ -0,0 +1,314 @@
+package inventoryService
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/search"
+)
+
+type mockRepo struct {
+	warehouses map[int64]model.Warehouse
+	created    bool
+	deleted    bool
+	before     int
+	after      int
+	moved      bool
+	transfer   *model.InventoryTransfer
+	setBy      int64
+}
+
+func (m *mockRepo) CreateWarehouse(ctx context.Context, w *model.Warehouse) (bool, error) {
+	w.Id = 1
+	return m.created, nil
+}
+func (m *mockRepo) GetWarehouseById(ctx context.Context, id int64) (*model.Warehouse, error) {
+	w, ok := m.warehouses[id]
+	if !ok {
+		return nil, nil
+	}
+	return &w, nil
+}
+func (m *mockRepo) GetSellerWarehouses(ctx context.Context, sellerId int64) (*[]model.Warehouse, error) {
+	return &[]model.Warehouse{}, nil
+}
+func (m *mockRepo) UpdateWarehouse(ctx context.Context, w *model.Warehouse) (bool, error) {
+	return true, nil
+}
+func (m *mockRepo) DeleteWarehouse(ctx context.Context, id int64) (bool, error) {
+	return m.deleted, nil
+}
+func (m *mockRepo) GetInventoryLevels(ctx context.Context, productId int64) (*[]model.InventoryLevel, error) {
+	return &[]model.InventoryLevel{{ProductId: productId, WarehouseId: 10, WarehouseName: "Moscow", Quantity: m.after}}, nil
+}
+func (m *mockRepo) SetInventoryLevel(ctx context.Context, level *model.InventoryLevel, userId int64) (int, int, error) {
+	m.setBy = userId
+	return m.before, m.after, nil
+}
+func (m *mockRepo) TransferStock(ctx context.Context, t *model.InventoryTransfer) (bool, error) {
+	m.transfer = t
+	return m.moved, nil
+}
+
+type mockLedger struct {
+	movements []model.InventoryMovement
+	drifts    []model.StockDrift
+	afterId   int64
+}
+
+func (m *mockLedger) GetProductMovements(ctx context.Context, productId int64, cursor *model.MovementCursor, limit int) (*[]model.InventoryMovement, error) {
+	result := []model.InventoryMovement{}
+	for _, mv := range m.movements {
+		if (cursor == nil || mv.Id < cursor.Id) && len(result) < limit {
+			result = append(result, mv)
+		}
+	}
+	return &result, nil
+}
+func (m *mockLedger) GetStockDrift(ctx context.Context, afterProductId int64, limit int) (*[]model.StockDrift, error) {
+	m.afterId = afterProductId
+	result := []model.StockDrift{}
+	for _, d := range m.drifts {
+		if d.ProductId > afterProductId && len(result) < limit {
+			result = append(result, d)
+		}
+	}
+	return &result, nil
+}
+
+type mockProducts struct{}
+
+func (m *mockProducts) GetProductById(ctx context.Context, productId int64) (*model.Product, error) {
+	return &model.Product{Id: productId, SellerId: 2, Name: "Kettle", Status: model.ProductStatusApproved}, nil
+}
+
+type mockIndex struct {
+	indexed []model.Product
+}
+
+func (m *mockIndex) Search(ctx context.Context, q search.Query) (*[]model.Product, error) {
+	return &[]model.Product{}, nil
+}
+func (m *mockIndex) Index(ctx context.Context, product *model.Product) error {
+	m.indexed = append(m.indexed, *product)
+	return nil
+}
+func (m *mockIndex) Delete(ctx context.Context, productId int64) error { return nil }
+func (m *mockIndex) Reindex(ctx context.Context) error                 { return nil }
+
+type mockRestocks struct {
+	restocked []int64
+}
+
+func (m *mockRestocks) Restocked(productId int64, name string) {
+	m.restocked = append(m.restocked, productId)
+}
+
+type mockLowStock struct {
+	decreased []int64
+}
+
+func (m *mockLowStock) StockDecreased(productIds []int64) {
+	m.decreased = append(m.decreased, productIds...)
+}
+
+var testWarehouses = map[int64]model.Warehouse{
+	10: {Id: 10, SellerId: 2, Name: "Moscow"},
+	11: {Id: 11, SellerId: 2, Name: "Kazan"},
+	20: {Id: 20, SellerId: 3, Name: "Other"},
+}
+
+func TestInventoryService_SetLevel(t *testing.T) {
+	quantity := 5
+	tests := []struct {
+		name          string
+		userId        int64
+		role          string
+		warehouseId   int64
+		before, after int
+		expectedErr   error
+		restocked     bool
+		decreased     bool
+	}{
+		{name: "restock from zero", userId: 2, role: "seller", warehouseId: 10, before: 0, after: 5, restocked: true},
+		{name: "decrease", userId: 2, role: "seller", warehouseId: 10, before: 8, after: 5, decreased: true},
+		{name: "another seller's product", userId: 3, role: "seller", warehouseId: 20, expectedErr: errs.NotOwnerError},
+		{name: "another seller's warehouse", userId: 2, role: "seller", warehouseId: 20, expectedErr: errs.NotFoundError},
+		{name: "admin", userId: 1, role: "admin", warehouseId: 11, before: 5, after: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{warehouses: testWarehouses, before: tt.before, after: tt.after}
+			index := &mockIndex{}
+			restocks := &mockRestocks{}
+			lowStock := &mockLowStock{}
+			client, mock := redismock.NewClientMock()
+			if tt.expectedErr == nil {
+				mock.ExpectDel("products:all").SetVal(1)
+			}
+			s := NewInventoryService(repo, &mockLedger{}, &mockProducts{}, index, client, restocks, lowStock)
+
+			resp, err := s.SetLevel(context.Background(), tt.userId, tt.role, &model.SetInventoryLevelRequest{ProductId: 7, WarehouseId: tt.warehouseId, Quantity: &quantity})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+
+			if repo.setBy != tt.userId {
+				t.Fatalf("the change must be recorded for user %d, got %d", tt.userId, repo.setBy)
+			}
+			if resp.Stock != tt.after || len(resp.Levels) != 1 {
+				t.Fatalf("unexpected inventory: %+v", resp)
+			}
+			if len(index.indexed) != 1 || index.indexed[0].Stock != tt.after || !index.indexed[0].Warehoused {
+				t.Fatalf("the product must be reindexed with the new stock: %+v", index.indexed)
+			}
+			if (len(restocks.restocked) == 1) != tt.restocked {
+				t.Fatalf("unexpected restock: %v", restocks.restocked)
+			}
+			if (len(lowStock.decreased) == 1) != tt.decreased {
+				t.Fatalf("unexpected low stock check: %v", lowStock.decreased)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("redis expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestInventoryService_Transfer(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         model.TransferStockRequest
+		moved       bool
+		expectedErr error
+	}{
+		{"success", model.TransferStockRequest{ProductId: 7, FromWarehouseId: 10, ToWarehouseId: 11, Quantity: 2}, true, nil},
+		{"same warehouse", model.TransferStockRequest{ProductId: 7, FromWarehouseId: 10, ToWarehouseId: 10, Quantity: 2}, true, errs.ValidationError},
+		{"foreign destination", model.TransferStockRequest{ProductId: 7, FromWarehouseId: 10, ToWarehouseId: 20, Quantity: 2}, true, errs.NotFoundError},
+		{"not enough stock", model.TransferStockRequest{ProductId: 7, FromWarehouseId: 10, ToWarehouseId: 11, Quantity: 2}, false, errs.ConflictError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{warehouses: testWarehouses, moved: tt.moved}
+			client, _ := redismock.NewClientMock()
+			s := NewInventoryService(repo, &mockLedger{}, &mockProducts{}, &mockIndex{}, client, &mockRestocks{}, &mockLowStock{})
+
+			resp, err := s.Transfer(context.Background(), 2, "seller", &tt.req)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+
+			if resp.Quantity != 2 || repo.transfer.UserId != 2 {
+				t.Fatalf("unexpected transfer: %+v %+v", resp, repo.transfer)
+			}
+		})
+	}
+}
+
+func TestInventoryService_DeleteWarehouse(t *testing.T) {
+	tests := []struct {
+		name        string
+		userId      int64
+		id          int64
+		deleted     bool
+		expectedErr error
+	}{
+		{"empty warehouse", 2, 10, true, nil},
+		{"warehouse with stock", 2, 10, false, errs.ConflictError},
+		{"another seller's warehouse", 2, 20, true, errs.NotOwnerError},
+		{"unknown warehouse", 2, 99, true, errs.NotFoundError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{warehouses: testWarehouses, deleted: tt.deleted}
+			client, _ := redismock.NewClientMock()
+			s := NewInventoryService(repo, &mockLedger{}, &mockProducts{}, &mockIndex{}, client, &mockRestocks{}, &mockLowStock{})
+
+			err := s.DeleteWarehouse(context.Background(), tt.userId, "seller", &model.WarehouseRequest{Id: tt.id})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestInventoryService_CreateWarehouse(t *testing.T) {
+	s := NewInventoryService(&mockRepo{}, &mockLedger{}, &mockProducts{}, &mockIndex{}, nil, &mockRestocks{}, &mockLowStock{})
+
+	_, err := s.CreateWarehouse(context.Background(), 2, &model.CreateWarehouseRequest{Name: "Moscow"})
+	if !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("expected conflict for a duplicate name, got %v", err)
+	}
+}
+
+func TestInventoryService_ProductMovements(t *testing.T) {
+	ledger := &mockLedger{movements: []model.InventoryMovement{
+		{Id: 3, ProductId: 7, Quantity: -2, Reason: model.MovementSale, ActorId: 5, Reference: "order:1"},
+		{Id: 2, ProductId: 7, WarehouseId: 10, Quantity: 4, Reason: model.MovementAdjustment, ActorId: 2},
+		{Id: 1, ProductId: 7, Quantity: 3, Reason: model.MovementRestock, ActorId: 2},
+	}}
+	s := NewInventoryService(&mockRepo{}, ledger, &mockProducts{}, &mockIndex{}, nil, &mockRestocks{}, &mockLowStock{})
+
+	page, next, err := s.ProductMovements(context.Background(), 2, "seller", 2, &model.ProductMovementsRequest{ProductId: 7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 2 || page[0].Id != 3 || page[0].Reason != model.MovementSale || page[1].WarehouseId != 10 || next == "" {
+		t.Fatalf("unexpected first page: %+v %q", page, next)
+	}
+
+	page, next, err = s.ProductMovements(context.Background(), 2, "seller", 2, &model.ProductMovementsRequest{ProductId: 7, Cursor: next})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].Id != 1 || next != "" {
+		t.Fatalf("unexpected last page: %+v %q", page, next)
+	}
+
+	_, _, err = s.ProductMovements(context.Background(), 3, "seller", 2, &model.ProductMovementsRequest{ProductId: 7})
+	if !errors.Is(err, errs.NotOwnerError) {
+		t.Fatalf("expected not owner, got %v", err)
+	}
+
+	_, _, err = s.ProductMovements(context.Background(), 2, "seller", 2, &model.ProductMovementsRequest{ProductId: 7, Cursor: "broken"})
+	if !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error for a broken cursor, got %v", err)
+	}
+}
+
+func TestInventoryService_Reconcile(t *testing.T) {
+	ledger := &mockLedger{drifts: []model.StockDrift{
+		{ProductId: 4, SellerId: 2, Name: "Kettle", Stock: 10, LedgerStock: 7},
+		{ProductId: 9, SellerId: 3, Name: "Mug", Stock: 0, LedgerStock: 2},
+	}}
+	s := NewInventoryService(&mockRepo{}, ledger, &mockProducts{}, &mockIndex{}, nil, &mockRestocks{}, &mockLowStock{})
+
+	page, next, err := s.Reconcile(context.Background(), 1, &model.ReconciliationRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].ProductId != 4 || page[0].Drift != 3 || next == "" {
+		t.Fatalf("unexpected first page: %+v %q", page, next)
+	}
+
+	page, next, err = s.Reconcile(context.Background(), 1, &model.ReconciliationRequest{Cursor: next})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ledger.afterId != 4 || len(page) != 1 || page[0].Drift != -2 || next != "" {
+		t.Fatalf("unexpected last page: %+v %q", page, next)
+	}
+}
@@ -0,0 +1,221 @@
+package lowStockService
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
+)
+
+const defaultDigestBatchSize = 500
+
+type ILowStockRepository interface {
+	CreateLowStockAlerts(ctx context.Context, productIds []int64, defaultThreshold int) (int64, error)
+	ResolveLowStockAlerts(ctx context.Context, defaultThreshold int) (int64, error)
+	GetSellerLowStockAlerts(ctx context.Context, sellerId int64, defaultThreshold int, cursor *model.LowStockCursor, limit int) (*[]model.LowStockAlert, error)
+	GetOpenLowStockAlerts(ctx context.Context, defaultThreshold int, afterSellerId, afterId int64, limit int) (*[]model.LowStockAlert, error)
+	SetSellerLowStockThreshold(ctx context.Context, sellerId int64, threshold *int) error
+	SetProductLowStockThreshold(ctx context.Context, productId int64, threshold *int) (bool, error)
+}
+
+type IProductReader interface {
+	GetProductById(ctx context.Context, productId int64) (*model.Product, error)
+}
+
+type INotifier interface {
+	Notify(ctx context.Context, notifications []model.Notification) error
+}
+
+// LowStockService opens alerts for products that run low on stock and sends the
+// sellers a digest of them.
+type LowStockService struct {
+	repo     ILowStockRepository
+	products IProductReader
+	notifier INotifier
+	cfg      config.LowStockConfig
+}
+
+func NewLowStockService(repo ILowStockRepository, products IProductReader, notifier INotifier, cfg config.LowStockConfig) *LowStockService {
+	if cfg.DigestBatchSize <= 0 {
+		cfg.DigestBatchSize = defaultDigestBatchSize
+	}
+
+	return &LowStockService{
+		repo:     repo,
+		products: products,
+		notifier: notifier,
+		cfg:      cfg,
+	}
+}
+
+// StockDecreased checks the products in the background, so the order or the
+// seller's update doesn't wait for it.
+func (s *LowStockService) StockDecreased(productIds []int64) {
+	go func() {
+		if err := s.CheckProducts(context.Background(), productIds); err != nil {
+			slog.Error("low stock check failed", "product_ids", productIds, "error", err)
+		}
+	}()
+}
+
+// CheckProducts opens an alert for every product at or below its threshold. A product
+// keeps a single open alert until it's restocked.
+func (s *LowStockService) CheckProducts(ctx context.Context, productIds []int64) error {
+	if len(productIds) == 0 {
+		return nil
+	}
+
+	_, err := s.repo.CreateLowStockAlerts(ctx, productIds, s.cfg.DefaultThreshold)
+	return err
+}
+
+func (s *LowStockService) List(ctx context.Context, sellerId int64, limit int, req *model.ListLowStockAlertsRequest) ([]model.LowStockAlertResponse, string, error) {
+	var cursor *model.LowStockCursor
+	if req.Cursor != "" {
+		cursor = new(model.LowStockCursor)
+		if err := utils.DecodeCursor(req.Cursor, cursor); err != nil {
+			return []model.LowStockAlertResponse{}, "", fmt.Errorf("%w: invalid cursor", errs.ValidationError)
+		}
+	}
+
+	alerts, err := s.repo.GetSellerLowStockAlerts(ctx, sellerId, s.cfg.DefaultThreshold, cursor, limit+1)
+	if err != nil {
+		return []model.LowStockAlertResponse{}, "", err
+	}
+
+	page := *alerts
+	var nextCursor string
+	if len(page) > limit {
+		page = page[:limit]
+		nextCursor, err = utils.EncodeCursor(model.LowStockCursor{Id: page[limit-1].Id})
+		if err != nil {
+			return []model.LowStockAlertResponse{}, "", err
+		}
+	}
+
+	result := make([]model.LowStockAlertResponse, 0, len(page))
+	for _, a := range page {
+		result = append(result, model.LowStockAlertResponse{
+			Id:        a.Id,
+			ProductId: a.ProductId,
+			Sku:       a.Sku,
+			Name:      a.Name,
+			Stock:     a.Stock,
+			Threshold: a.Threshold,
+			CreatedAt: a.CreatedAt,
+		})
+	}
+
+	return result, nextCursor, nil
+}
+
+// SetThreshold sets the threshold of the product or, without a product, the seller's
+// default. A product's new threshold is checked right away.
+func (s *LowStockService) SetThreshold(ctx context.Context, userId int64, role string, req *model.LowStockThresholdRequest) (model.LowStockThresholdResponse, error) {
+	if req.ProductId == 0 {
+		if err := s.repo.SetSellerLowStockThreshold(ctx, userId, req.Threshold); err != nil {
+			return model.LowStockThresholdResponse{}, err
+		}
+
+		return model.LowStockThresholdResponse{Threshold: req.Threshold}, nil
+	}
+
+	p, err := s.products.GetProductById(ctx, req.ProductId)
+	if err != nil {
+		return model.LowStockThresholdResponse{}, err
+	}
+
+	if p.SellerId != userId && role != "admin" {
+		return model.LowStockThresholdResponse{}, errs.NotOwnerError
+	}
+
+	found, err := s.repo.SetProductLowStockThreshold(ctx, p.Id, req.Threshold)
+	if err != nil {
+		return model.LowStockThresholdResponse{}, err
+	}
+
+	if !found {
+		return model.LowStockThresholdResponse{}, fmt.Errorf("%w: product %d", errs.NotFoundError, p.Id)
+	}
+
+	if err = s.CheckProducts(ctx, []int64{p.Id}); err != nil {
+		return model.LowStockThresholdResponse{}, err
+	}
+
+	return model.LowStockThresholdResponse{ProductId: p.Id, Threshold: req.Threshold}, nil
+}
+
+// SendDigest closes the alerts of restocked products and sends every seller with open
+// alerts one notification listing the low and out of stock products.
+func (s *LowStockService) SendDigest(ctx context.Context) error {
+	if _, err := s.repo.ResolveLowStockAlerts(ctx, s.cfg.DefaultThreshold); err != nil {
+		return err
+	}
+
+	var (
+		afterSellerId, afterId int64
+		sellerAlerts           []model.LowStockAlert
+	)
+	for {
+		alerts, err := s.repo.GetOpenLowStockAlerts(ctx, s.cfg.DefaultThreshold, afterSellerId, afterId, s.cfg.DigestBatchSize)
+		if err != nil {
+			return err
+		}
+
+		page := *alerts
+		var notifications []model.Notification
+		for _, a := range page {
+			if len(sellerAlerts) > 0 && sellerAlerts[0].SellerId != a.SellerId {
+				notifications = append(notifications, digestNotification(sellerAlerts))
+				sellerAlerts = nil
+			}
+			sellerAlerts = append(sellerAlerts, a)
+		}
+
+		// The last seller of a full page may continue on the next one.
+		if len(page) < s.cfg.DigestBatchSize && len(sellerAlerts) > 0 {
+			notifications = append(notifications, digestNotification(sellerAlerts))
+		}
+
+		if len(notifications) > 0 {
+			if err = s.notifier.Notify(ctx, notifications); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < s.cfg.DigestBatchSize {
+			return nil
+		}
+		afterSellerId, afterId = page[len(page)-1].SellerId, page[len(page)-1].Id
+	}
+}
+
+func digestNotification(alerts []model.LowStockAlert) model.Notification {
+	var outOfStock int
+	lines := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		name := a.Name
+		if a.Sku != "" {
+			name = a.Sku + " " + a.Name
+		}
+
+		if a.Stock == 0 {
+			outOfStock++
+			lines = append(lines, name+": out of stock")
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: %d left", name, a.Stock))
+		}
+	}
+
+	return model.Notification{
+		UserId: alerts[0].SellerId,
+		Kind:   model.NotificationLowStock,
+		Title:  fmt.Sprintf("Low stock: %d products, %d out of stock", len(alerts), outOfStock),
+		Body:   strings.Join(lines, "\n"),
+	}
+}
@@ -0,0 +1,210 @@
+package lowStockService
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockRepo struct {
+	alerts           []model.LowStockAlert
+	pages            int
+	resolved         bool
+	checked          []int64
+	productThreshold *int
+	sellerThreshold  *int
+	productFound     bool
+}
+
+func (m *mockRepo) CreateLowStockAlerts(ctx context.Context, productIds []int64, defaultThreshold int) (int64, error) {
+	m.checked = append(m.checked, productIds...)
+	return int64(len(productIds)), nil
+}
+
+func (m *mockRepo) ResolveLowStockAlerts(ctx context.Context, defaultThreshold int) (int64, error) {
+	m.resolved = true
+	return 0, nil
+}
+
+func (m *mockRepo) GetSellerLowStockAlerts(ctx context.Context, sellerId int64, defaultThreshold int, cursor *model.LowStockCursor, limit int) (*[]model.LowStockAlert, error) {
+	page := []model.LowStockAlert{}
+	for _, a := range m.alerts {
+		if a.SellerId == sellerId && (cursor == nil || a.Id < cursor.Id) {
+			page = append(page, a)
+		}
+		if len(page) == limit {
+			break
+		}
+	}
+	return &page, nil
+}
+
+func (m *mockRepo) GetOpenLowStockAlerts(ctx context.Context, defaultThreshold int, afterSellerId, afterId int64, limit int) (*[]model.LowStockAlert, error) {
+	m.pages++
+	page := []model.LowStockAlert{}
+	for _, a := range m.alerts {
+		if a.SellerId > afterSellerId || (a.SellerId == afterSellerId && a.Id > afterId) {
+			page = append(page, a)
+		}
+		if len(page) == limit {
+			break
+		}
+	}
+	return &page, nil
+}
+
+func (m *mockRepo) SetSellerLowStockThreshold(ctx context.Context, sellerId int64, threshold *int) error {
+	m.sellerThreshold = threshold
+	return nil
+}
+
+func (m *mockRepo) SetProductLowStockThreshold(ctx context.Context, productId int64, threshold *int) (bool, error) {
+	m.productThreshold = threshold
+	return m.productFound, nil
+}
+
+type mockProducts struct{}
+
+func (m *mockProducts) GetProductById(ctx context.Context, productId int64) (*model.Product, error) {
+	return &model.Product{Id: productId, SellerId: 2}, nil
+}
+
+type mockNotifier struct {
+	sent []model.Notification
+	err  error
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, notifications []model.Notification) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, notifications...)
+	return nil
+}
+
+func TestLowStockService_SendDigest(t *testing.T) {
+	repo := &mockRepo{alerts: []model.LowStockAlert{
+		{Id: 1, SellerId: 2, Sku: "KT-1", Name: "Kettle", Stock: 0},
+		{Id: 4, SellerId: 2, Name: "Mug", Stock: 3},
+		{Id: 2, SellerId: 3, Sku: "TP-9", Name: "Teapot", Stock: 1},
+	}}
+	notifier := &mockNotifier{}
+	s := NewLowStockService(repo, &mockProducts{}, notifier, config.LowStockConfig{DigestBatchSize: 1})
+
+	if err := s.SendDigest(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !repo.resolved {
+		t.Fatalf("restocked products must be resolved before the digest")
+	}
+	if repo.pages != 4 {
+		t.Fatalf("expected 4 pages, got %d", repo.pages)
+	}
+	if len(notifier.sent) != 2 {
+		t.Fatalf("expected one digest per seller, got %+v", notifier.sent)
+	}
+
+	first := notifier.sent[0]
+	if first.UserId != 2 || first.Kind != model.NotificationLowStock || first.ProductId != nil {
+		t.Fatalf("unexpected digest: %+v", first)
+	}
+	if first.Title != "Low stock: 2 products, 1 out of stock" {
+		t.Fatalf("unexpected title: %q", first.Title)
+	}
+	if !strings.Contains(first.Body, "KT-1 Kettle: out of stock") || !strings.Contains(first.Body, "Mug: 3 left") {
+		t.Fatalf("unexpected body: %q", first.Body)
+	}
+	if notifier.sent[1].UserId != 3 {
+		t.Fatalf("unexpected second digest: %+v", notifier.sent[1])
+	}
+}
+
+func TestLowStockService_SendDigest_NotifyFailure(t *testing.T) {
+	repo := &mockRepo{alerts: []model.LowStockAlert{{Id: 1, SellerId: 2, Name: "Kettle"}}}
+	s := NewLowStockService(repo, &mockProducts{}, &mockNotifier{err: errors.New("db is down")}, config.LowStockConfig{})
+
+	if err := s.SendDigest(context.Background()); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestLowStockService_SetThreshold(t *testing.T) {
+	threshold := 3
+	tests := []struct {
+		name         string
+		userId       int64
+		role         string
+		productId    int64
+		productFound bool
+		expectedErr  error
+	}{
+		{"seller default", 2, "seller", 0, false, nil},
+		{"own product", 2, "seller", 7, true, nil},
+		{"another seller's product", 5, "seller", 7, true, errs.NotOwnerError},
+		{"admin", 1, "admin", 7, true, nil},
+		{"deleted product", 2, "seller", 7, false, errs.NotFoundError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{productFound: tt.productFound}
+			s := NewLowStockService(repo, &mockProducts{}, &mockNotifier{}, config.LowStockConfig{})
+
+			resp, err := s.SetThreshold(context.Background(), tt.userId, tt.role, &model.LowStockThresholdRequest{ProductId: tt.productId, Threshold: &threshold})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr != nil {
+				return
+			}
+
+			if *resp.Threshold != 3 || resp.ProductId != tt.productId {
+				t.Fatalf("unexpected response: %+v", resp)
+			}
+			if tt.productId == 0 {
+				if repo.sellerThreshold == nil || repo.productThreshold != nil {
+					t.Fatalf("only the seller default must change")
+				}
+				return
+			}
+			if len(repo.checked) != 1 || repo.checked[0] != 7 {
+				t.Fatalf("the product must be checked against the new threshold, got %v", repo.checked)
+			}
+		})
+	}
+}
+
+func TestLowStockService_List(t *testing.T) {
+	repo := &mockRepo{alerts: []model.LowStockAlert{
+		{Id: 9, SellerId: 2, Name: "Kettle"},
+		{Id: 8, SellerId: 3, Name: "Teapot"},
+		{Id: 7, SellerId: 2, Name: "Mug"},
+	}}
+	s := NewLowStockService(repo, &mockProducts{}, &mockNotifier{}, config.LowStockConfig{})
+
+	alerts, next, err := s.List(context.Background(), 2, 1, &model.ListLowStockAlertsRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Id != 9 || next == "" {
+		t.Fatalf("unexpected first page: %+v %q", alerts, next)
+	}
+
+	alerts, next, err = s.List(context.Background(), 2, 1, &model.ListLowStockAlertsRequest{Cursor: next})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Id != 7 || next != "" {
+		t.Fatalf("unexpected second page: %+v %q", alerts, next)
+	}
+
+	if _, _, err = s.List(context.Background(), 2, 1, &model.ListLowStockAlertsRequest{Cursor: "!"}); !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package moderationService
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type IModerationRepository interface {
+	CreateRule(ctx context.Context, rule *model.ModerationRule) error
+	GetRuleById(ctx context.Context, id int64) (*model.ModerationRule, error)
+	GetAllRules(ctx context.Context) (*[]model.ModerationRule, error)
+	GetEnabledRules(ctx context.Context) (*[]model.ModerationRule, error)
+	UpdateRule(ctx context.Context, rule *model.ModerationRule) error
+	DeleteRule(ctx context.Context, id int64) error
+	GetCategoryMedianPrice(ctx context.Context, categoryId, excludeProductId int64) (float64, int, error)
+	SaveFlags(ctx context.Context, productId int64, flags []model.ModerationFlag) error
+	GetFlagsByProductId(ctx context.Context, productId int64) (*[]model.ModerationFlag, error)
+}
+
+type ModerationService struct {
+	repo  IModerationRepository
+	cfg   config.ModerationConfig
+	rules ruleCache
+}
+
+func NewModerationService(repo IModerationRepository, cfg config.ModerationConfig) *ModerationService {
+	return &ModerationService{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+// Review runs the automated checks against the product and returns the raised flags.
+func (s *ModerationService) Review(ctx context.Context, p *model.Product) ([]model.ModerationFlag, error) {
+	rules, err := s.repo.GetEnabledRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := checkRules(p.Name+" "+p.Description, s.rules.compile(*rules))
+	flags = append(flags, checkContacts(s.cfg.ContactSeverity, p.Name, p.Description)...)
+
+	if p.CategoryId > 0 {
+		median, samples, err := s.repo.GetCategoryMedianPrice(ctx, p.CategoryId, p.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		flags = append(flags, checkPrice(
+			p, median, samples,
+			s.cfg.PriceMinSamples, s.cfg.PriceLowRatio, s.cfg.PriceHighRatio, s.cfg.PriceSeverity)...)
+	}
+
+	return flags, nil
+}
+
+// ReviewText runs the rule and contact checks used for product content against
+// user generated text such as questions and answers.
+func (s *ModerationService) ReviewText(ctx context.Context, text string) ([]model.ModerationFlag, error) {
+	rules, err := s.repo.GetEnabledRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := checkRules(text, s.rules.compile(*rules))
+	flags = append(flags, checkContacts(s.cfg.ContactSeverity, text)...)
+
+	return flags, nil
+}
+
+func (s *ModerationService) SaveFlags(ctx context.Context, productId int64, flags []model.ModerationFlag) error {
+	return s.repo.SaveFlags(ctx, productId, flags)
+}
+
+func (s *ModerationService) CreateRule(ctx context.Context, req *model.CreateModerationRuleRequest) (*model.ModerationRuleResponse, error) {
+	if req.Kind == model.RuleKindRegex {
+		if _, err := regexp.Compile(req.Pattern); err != nil {
+			return nil, fmt.Errorf("%w: invalid pattern: %w", errs.ValidationError, err)
+		}
+	}
+
+	rule := model.ModerationRule{
+		Kind:        req.Kind,
+		Pattern:     req.Pattern,
+		Severity:    req.Severity,
+		Description: req.Description,
+		Enabled:     true,
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := s.repo.CreateRule(ctx, &rule); err != nil {
+		return nil, err
+	}
+
+	return toRuleResponse(&rule), nil
+}
+
+func (s *ModerationService) GetAllRules(ctx context.Context) (*[]model.ModerationRuleResponse, error) {
+	rules, err := s.repo.GetAllRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []model.ModerationRuleResponse
+	for _, rule := range *rules {
+		resp = append(resp, *toRuleResponse(&rule))
+	}
+
+	return &resp, nil
+}
+
+func (s *ModerationService) UpdateRule(ctx context.Context, req *model.UpdateModerationRuleRequest) (*model.ModerationRuleResponse, error) {
+	rule, err := s.repo.GetRuleById(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Pattern != nil {
+		rule.Pattern = *req.Pattern
+	}
+
+	if req.Severity != nil {
+		rule.Severity = *req.Severity
+	}
+
+	if req.Description != nil {
+		rule.Description = *req.Description
+	}
+
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if rule.Kind == model.RuleKindRegex {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("%w: invalid pattern: %w", errs.ValidationError, err)
+		}
+	}
+
+	if err = s.repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return toRuleResponse(rule), nil
+}
+
+func (s *ModerationService) DeleteRule(ctx context.Context, req *model.DeleteModerationRuleRequest) error {
+	return s.repo.DeleteRule(ctx, req.Id)
+}
+
+func (s *ModerationService) GetProductFlags(ctx context.Context, req *model.GetProductFlagsRequest) (*[]model.ModerationFlagResponse, error) {
+	flags, err := s.repo.GetFlagsByProductId(ctx, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []model.ModerationFlagResponse
+	for _, flag := range *flags {
+		resp = append(resp, model.ModerationFlagResponse{
+			Id:        flag.Id,
+			ProductId: flag.ProductId,
+			RuleId:    flag.RuleId,
+			Code:      flag.Code,
+			Severity:  flag.Severity,
+			Message:   flag.Message,
+		})
+	}
+
+	return &resp, nil
+}
+
+func toRuleResponse(rule *model.ModerationRule) *model.ModerationRuleResponse {
+	return &model.ModerationRuleResponse{
+		Id:          rule.Id,
+		Kind:        rule.Kind,
+		Pattern:     rule.Pattern,
+		Severity:    rule.Severity,
+		Description: rule.Description,
+		Enabled:     rule.Enabled,
+	}
+}
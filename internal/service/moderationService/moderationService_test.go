@@ -0,0 +1,278 @@
+package moderationService
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockRepo struct {
+	CreateRuleFn      func(ctx context.Context, rule *model.ModerationRule) error
+	GetRuleByIdFn     func(ctx context.Context, id int64) (*model.ModerationRule, error)
+	GetAllRulesFn     func(ctx context.Context) (*[]model.ModerationRule, error)
+	GetEnabledRulesFn func(ctx context.Context) (*[]model.ModerationRule, error)
+	UpdateRuleFn      func(ctx context.Context, rule *model.ModerationRule) error
+	DeleteRuleFn      func(ctx context.Context, id int64) error
+	GetMedianPriceFn  func(ctx context.Context, categoryId, excludeProductId int64) (float64, int, error)
+	SaveFlagsFn       func(ctx context.Context, productId int64, flags []model.ModerationFlag) error
+	GetFlagsFn        func(ctx context.Context, productId int64) (*[]model.ModerationFlag, error)
+}
+
+func (m *mockRepo) CreateRule(ctx context.Context, rule *model.ModerationRule) error {
+	return m.CreateRuleFn(ctx, rule)
+}
+func (m *mockRepo) GetRuleById(ctx context.Context, id int64) (*model.ModerationRule, error) {
+	return m.GetRuleByIdFn(ctx, id)
+}
+func (m *mockRepo) GetAllRules(ctx context.Context) (*[]model.ModerationRule, error) {
+	return m.GetAllRulesFn(ctx)
+}
+func (m *mockRepo) GetEnabledRules(ctx context.Context) (*[]model.ModerationRule, error) {
+	return m.GetEnabledRulesFn(ctx)
+}
+func (m *mockRepo) UpdateRule(ctx context.Context, rule *model.ModerationRule) error {
+	return m.UpdateRuleFn(ctx, rule)
+}
+func (m *mockRepo) DeleteRule(ctx context.Context, id int64) error {
+	return m.DeleteRuleFn(ctx, id)
+}
+func (m *mockRepo) GetCategoryMedianPrice(ctx context.Context, categoryId, excludeProductId int64) (float64, int, error) {
+	return m.GetMedianPriceFn(ctx, categoryId, excludeProductId)
+}
+func (m *mockRepo) SaveFlags(ctx context.Context, productId int64, flags []model.ModerationFlag) error {
+	return m.SaveFlagsFn(ctx, productId, flags)
+}
+func (m *mockRepo) GetFlagsByProductId(ctx context.Context, productId int64) (*[]model.ModerationFlag, error) {
+	return m.GetFlagsFn(ctx, productId)
+}
+
+var testConfig = config.ModerationConfig{
+	PriceLowRatio:   0.2,
+	PriceHighRatio:  5,
+	PriceMinSamples: 5,
+	PriceSeverity:   model.SeverityMedium,
+	ContactSeverity: model.SeverityHigh,
+}
+
+func codes(flags []model.ModerationFlag) map[string]string {
+	out := make(map[string]string)
+	for _, flag := range flags {
+		out[flag.Code] = flag.Severity
+	}
+	return out
+}
+
+func TestModerationService_Review(t *testing.T) {
+	repo := &mockRepo{
+		GetEnabledRulesFn: func(ctx context.Context) (*[]model.ModerationRule, error) {
+			return &[]model.ModerationRule{
+				{Id: 1, Kind: model.RuleKindBannedWord, Pattern: "replica", Severity: model.SeverityHigh},
+				{Id: 2, Kind: model.RuleKindRegex, Pattern: `опт\w*`, Severity: model.SeverityLow},
+			}, nil
+		},
+		GetMedianPriceFn: func(ctx context.Context, categoryId, excludeProductId int64) (float64, int, error) {
+			return 1000, 10, nil
+		},
+	}
+	s := NewModerationService(repo, testConfig)
+
+	tests := []struct {
+		name string
+		p    model.Product
+		want map[string]string
+	}{
+		{
+			"clean",
+			model.Product{CategoryId: 1, Name: "Кроссовки", Description: "Удобные кроссовки", Price: 900},
+			map[string]string{},
+		},
+		{
+			"banned word is matched as a whole word",
+			model.Product{CategoryId: 1, Name: "Replica watch", Price: 900},
+			map[string]string{model.FlagCodeBannedWord: model.SeverityHigh},
+		},
+		{
+			"banned word inside another word",
+			model.Product{CategoryId: 1, Name: "Replicator", Price: 900},
+			map[string]string{},
+		},
+		{
+			"regex and contacts",
+			model.Product{CategoryId: 1, Name: "Чехол", Description: "Продаю ОПТОМ, пишите на shop@mail.ru", Price: 900},
+			map[string]string{model.FlagCodeRegex: model.SeverityLow, model.FlagCodeContactDetails: model.SeverityHigh},
+		},
+		{
+			"phone number",
+			model.Product{CategoryId: 1, Name: "Чехол", Description: "Звоните +7 (912) 345-67-89", Price: 900},
+			map[string]string{model.FlagCodeContactDetails: model.SeverityHigh},
+		},
+		{
+			"price too low",
+			model.Product{CategoryId: 1, Name: "Чехол", Price: 10},
+			map[string]string{model.FlagCodeSuspiciousPrice: model.SeverityMedium},
+		},
+		{
+			"no category skips price check",
+			model.Product{Name: "Чехол", Price: 10},
+			map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			flags, err := s.Review(context.Background(), &tt.p)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			got := codes(flags)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v want %v", got, tt.want)
+			}
+			for code, severity := range tt.want {
+				if got[code] != severity {
+					t.Fatalf("got %v want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestModerationService_Review_SmallCategory(t *testing.T) {
+	repo := &mockRepo{
+		GetEnabledRulesFn: func(ctx context.Context) (*[]model.ModerationRule, error) {
+			return &[]model.ModerationRule{}, nil
+		},
+		GetMedianPriceFn: func(ctx context.Context, categoryId, excludeProductId int64) (float64, int, error) {
+			return 1000, 2, nil
+		},
+	}
+	s := NewModerationService(repo, testConfig)
+	flags, err := s.Review(context.Background(), &model.Product{CategoryId: 1, Name: "X", Price: 1})
+	if err != nil || len(flags) != 0 {
+		t.Fatalf("unexpected result: %+v %v", flags, err)
+	}
+
+	repo.GetEnabledRulesFn = func(ctx context.Context) (*[]model.ModerationRule, error) {
+		return nil, errors.New("db")
+	}
+	if _, err = s.Review(context.Background(), &model.Product{}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestModerationService_Review_CompiledRules(t *testing.T) {
+	rules := []model.ModerationRule{
+		{Id: 1, Kind: model.RuleKindRegex, Pattern: `опт\w*`, Severity: model.SeverityLow},
+		{Id: 2, Kind: model.RuleKindRegex, Pattern: `(`, Severity: model.SeverityHigh},
+	}
+	repo := &mockRepo{
+		GetEnabledRulesFn: func(ctx context.Context) (*[]model.ModerationRule, error) {
+			return &rules, nil
+		},
+	}
+	s := NewModerationService(repo, testConfig)
+
+	p := &model.Product{Name: "Чехол", Description: "Продаю оптом"}
+	flags, err := s.Review(context.Background(), p)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if got := codes(flags); len(got) != 1 || got[model.FlagCodeRegex] != model.SeverityLow {
+		t.Fatalf("an invalid pattern must be skipped, got %v", got)
+	}
+
+	re := s.rules.patterns[`опт\w*`]
+	if _, err = s.Review(context.Background(), p); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if re == nil || s.rules.patterns[`опт\w*`] != re {
+		t.Fatalf("the pattern must be compiled once")
+	}
+
+	// A pattern of a removed rule is dropped from the cache.
+	rules = rules[1:]
+	if _, err = s.Review(context.Background(), p); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if _, ok := s.rules.patterns[`опт\w*`]; ok || len(s.rules.patterns) != 1 {
+		t.Fatalf("unexpected cache: %v", s.rules.patterns)
+	}
+}
+
+func TestModerationService_ReviewText(t *testing.T) {
+	repo := &mockRepo{
+		GetEnabledRulesFn: func(ctx context.Context) (*[]model.ModerationRule, error) {
+			return &[]model.ModerationRule{
+				{Id: 1, Kind: model.RuleKindBannedWord, Pattern: "replica", Severity: model.SeverityHigh},
+			}, nil
+		},
+	}
+	s := NewModerationService(repo, testConfig)
+
+	flags, err := s.ReviewText(context.Background(), "Подойдет ли размер 42?")
+	if err != nil || len(flags) != 0 {
+		t.Fatalf("unexpected result: %+v %v", flags, err)
+	}
+
+	flags, err = s.ReviewText(context.Background(), "Это replica? Напишите в t.me/shop")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	got := codes(flags)
+	if got[model.FlagCodeBannedWord] != model.SeverityHigh || got[model.FlagCodeContactDetails] != model.SeverityHigh {
+		t.Fatalf("unexpected flags: %v", got)
+	}
+}
+
+func TestModerationService_CreateRule(t *testing.T) {
+	repo := &mockRepo{
+		CreateRuleFn: func(ctx context.Context, rule *model.ModerationRule) error {
+			rule.Id = 4
+			return nil
+		},
+	}
+	s := NewModerationService(repo, testConfig)
+
+	got, err := s.CreateRule(context.Background(), &model.CreateModerationRuleRequest{
+		Kind: model.RuleKindBannedWord, Pattern: "fake", Severity: model.SeverityHigh,
+	})
+	if err != nil || got.Id != 4 || !got.Enabled {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+
+	_, err = s.CreateRule(context.Background(), &model.CreateModerationRuleRequest{
+		Kind: model.RuleKindRegex, Pattern: "(", Severity: model.SeverityLow,
+	})
+	if !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestModerationService_UpdateRule(t *testing.T) {
+	repo := &mockRepo{
+		GetRuleByIdFn: func(ctx context.Context, id int64) (*model.ModerationRule, error) {
+			return &model.ModerationRule{Id: id, Kind: model.RuleKindRegex, Pattern: "a+", Severity: model.SeverityLow, Enabled: true}, nil
+		},
+		UpdateRuleFn: func(ctx context.Context, rule *model.ModerationRule) error {
+			return nil
+		},
+	}
+	s := NewModerationService(repo, testConfig)
+
+	enabled := false
+	severity := model.SeverityHigh
+	got, err := s.UpdateRule(context.Background(), &model.UpdateModerationRuleRequest{Id: 2, Severity: &severity, Enabled: &enabled})
+	if err != nil || got.Pattern != "a+" || got.Severity != model.SeverityHigh || got.Enabled {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+
+	pattern := "[a"
+	_, err = s.UpdateRule(context.Background(), &model.UpdateModerationRuleRequest{Id: 2, Pattern: &pattern})
+	if !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package moderationService
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+var contactPatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"email", regexp.MustCompile(`(?i)[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}`)},
+	{"phone", regexp.MustCompile(`(?:\+7|8|\+\d{1,3})[\s\-(]*\d{3}[\s\-)]*\d{3}[\s\-]*\d{2}[\s\-]*\d{2}`)},
+	{"link", regexp.MustCompile(`(?i)(?:https?://|www\.)\S+`)},
+	{"messenger", regexp.MustCompile(`(?i)(?:t\.me/|wa\.me/|telegram|whatsapp|viber)`)},
+}
+
+// compiledRule is an enabled rule together with the compiled pattern of a regex rule.
+type compiledRule struct {
+	model.ModerationRule
+	re *regexp.Regexp
+}
+
+// ruleCache keeps the compiled regex rules by pattern, so a pattern is compiled once
+// and not for every checked text. Patterns that don't compile are kept as nil and
+// logged once, when they are first seen.
+type ruleCache struct {
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+// compile pairs the rules with their compiled patterns. Patterns of rules that are
+// gone are dropped from the cache.
+func (c *ruleCache) compile(rules []model.ModerationRule) []compiledRule {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	patterns := make(map[string]*regexp.Regexp, len(c.patterns))
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		cr := compiledRule{ModerationRule: rule}
+		if rule.Kind == model.RuleKindRegex {
+			re, ok := c.patterns[rule.Pattern]
+			if !ok {
+				var err error
+				re, err = regexp.Compile("(?i)" + rule.Pattern)
+				if err != nil {
+					slog.Error("moderation rule pattern is invalid", "rule_id", rule.Id, "pattern", rule.Pattern, "error", err)
+				}
+			}
+			patterns[rule.Pattern] = re
+			cr.re = re
+		}
+		compiled = append(compiled, cr)
+	}
+	c.patterns = patterns
+
+	return compiled
+}
+
+// checkRules matches banned words as whole words and regex rules as case-insensitive
+// patterns against the text. Regex rules with an invalid pattern are skipped.
+func checkRules(text string, rules []compiledRule) []model.ModerationFlag {
+	words := make(map[string]struct{})
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = struct{}{}
+	}
+
+	var flags []model.ModerationFlag
+	for _, rule := range rules {
+		ruleId := rule.Id
+		switch rule.Kind {
+		case model.RuleKindBannedWord:
+			if _, ok := words[strings.ToLower(strings.TrimSpace(rule.Pattern))]; ok {
+				flags = append(flags, model.ModerationFlag{
+					RuleId:   &ruleId,
+					Code:     model.FlagCodeBannedWord,
+					Severity: rule.Severity,
+					Message:  fmt.Sprintf("banned word %q", rule.Pattern),
+				})
+			}
+		case model.RuleKindRegex:
+			if rule.re == nil {
+				continue
+			}
+
+			if match := rule.re.FindString(text); match != "" {
+				message := rule.Description
+				if message == "" {
+					message = fmt.Sprintf("forbidden content %q", match)
+				}
+				flags = append(flags, model.ModerationFlag{
+					RuleId:   &ruleId,
+					Code:     model.FlagCodeRegex,
+					Severity: rule.Severity,
+					Message:  message,
+				})
+			}
+		}
+	}
+
+	return flags
+}
+
+func checkContacts(severity string, texts ...string) []model.ModerationFlag {
+	var flags []model.ModerationFlag
+	for _, pattern := range contactPatterns {
+		if slices.ContainsFunc(texts, pattern.re.MatchString) {
+			flags = append(flags, model.ModerationFlag{
+				Code:     model.FlagCodeContactDetails,
+				Severity: severity,
+				Message:  fmt.Sprintf("contact details (%s) in text", pattern.name),
+			})
+		}
+	}
+
+	return flags
+}
+
+// checkPrice flags prices far below or above the category median. Small categories
+// are skipped because their median is not representative.
+func checkPrice(p *model.Product, median float64, samples, minSamples int, lowRatio, highRatio float64, severity string) []model.ModerationFlag {
+	if samples < minSamples || median <= 0 {
+		return nil
+	}
+
+	ratio := p.Price / median
+	if (lowRatio > 0 && ratio < lowRatio) || (highRatio > 0 && ratio > highRatio) {
+		return []model.ModerationFlag{{
+			Code:     model.FlagCodeSuspiciousPrice,
+			Severity: severity,
+			Message:  fmt.Sprintf("price %.2f differs from category median %.2f", p.Price, median),
+		}}
+	}
+
+	return nil
+}
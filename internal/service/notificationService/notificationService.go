@@ -0,0 +1,92 @@
+package notificationService
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
+)
+
+type INotificationRepository interface {
+	CreateNotifications(ctx context.Context, notifications []model.Notification) error
+	GetNotifications(ctx context.Context, userId int64, unread bool, cursor *model.NotificationCursor, limit int) (*[]model.Notification, error)
+	MarkNotificationRead(ctx context.Context, userId, id int64) (bool, error)
+	MarkAllNotificationsRead(ctx context.Context, userId int64) error
+}
+
+// NotificationService delivers notifications to the users' in-app inbox.
+type NotificationService struct {
+	repo INotificationRepository
+}
+
+func NewNotificationService(repo INotificationRepository) *NotificationService {
+	return &NotificationService{repo: repo}
+}
+
+// Notify delivers a batch of notifications.
+func (s *NotificationService) Notify(ctx context.Context, notifications []model.Notification) error {
+	return s.repo.CreateNotifications(ctx, notifications)
+}
+
+func (s *NotificationService) List(ctx context.Context, userId int64, limit int, req *model.ListNotificationsRequest) ([]model.NotificationResponse, string, error) {
+	var cursor *model.NotificationCursor
+	if req.Cursor != "" {
+		cursor = new(model.NotificationCursor)
+		if err := utils.DecodeCursor(req.Cursor, cursor); err != nil {
+			return []model.NotificationResponse{}, "", fmt.Errorf("%w: invalid cursor", errs.ValidationError)
+		}
+	}
+
+	notifications, err := s.repo.GetNotifications(ctx, userId, req.Unread, cursor, limit+1)
+	if err != nil {
+		return []model.NotificationResponse{}, "", err
+	}
+
+	page := *notifications
+	var nextCursor string
+	if len(page) > limit {
+		page = page[:limit]
+		nextCursor, err = utils.EncodeCursor(model.NotificationCursor{Id: page[limit-1].Id})
+		if err != nil {
+			return []model.NotificationResponse{}, "", err
+		}
+	}
+
+	result := make([]model.NotificationResponse, 0, len(page))
+	for i := range page {
+		result = append(result, toNotificationResponse(&page[i]))
+	}
+
+	return result, nextCursor, nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userId int64, req *model.ReadNotificationRequest) error {
+	found, err := s.repo.MarkNotificationRead(ctx, userId, req.Id)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("%w: notification %d", errs.NotFoundError, req.Id)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userId int64) error {
+	return s.repo.MarkAllNotificationsRead(ctx, userId)
+}
+
+func toNotificationResponse(n *model.Notification) model.NotificationResponse {
+	return model.NotificationResponse{
+		Id:        n.Id,
+		Kind:      n.Kind,
+		Title:     n.Title,
+		Body:      n.Body,
+		ProductId: n.ProductId,
+		CreatedAt: n.CreatedAt,
+		ReadAt:    n.ReadAt,
+	}
+}
@@ -0,0 +1,81 @@
+package notificationService
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockRepo struct {
+	CreateNotificationsFn      func(ctx context.Context, notifications []model.Notification) error
+	GetNotificationsFn         func(ctx context.Context, userId int64, unread bool, cursor *model.NotificationCursor, limit int) (*[]model.Notification, error)
+	MarkNotificationReadFn     func(ctx context.Context, userId, id int64) (bool, error)
+	MarkAllNotificationsReadFn func(ctx context.Context, userId int64) error
+}
+
+func (m *mockRepo) CreateNotifications(ctx context.Context, notifications []model.Notification) error {
+	return m.CreateNotificationsFn(ctx, notifications)
+}
+func (m *mockRepo) GetNotifications(ctx context.Context, userId int64, unread bool, cursor *model.NotificationCursor, limit int) (*[]model.Notification, error) {
+	return m.GetNotificationsFn(ctx, userId, unread, cursor, limit)
+}
+func (m *mockRepo) MarkNotificationRead(ctx context.Context, userId, id int64) (bool, error) {
+	return m.MarkNotificationReadFn(ctx, userId, id)
+}
+func (m *mockRepo) MarkAllNotificationsRead(ctx context.Context, userId int64) error {
+	return m.MarkAllNotificationsReadFn(ctx, userId)
+}
+
+func TestNotificationService_List(t *testing.T) {
+	repo := &mockRepo{
+		GetNotificationsFn: func(ctx context.Context, userId int64, unread bool, cursor *model.NotificationCursor, limit int) (*[]model.Notification, error) {
+			if userId != 9 || !unread || limit != 3 {
+				t.Fatalf("unexpected query: %d %v %d", userId, unread, limit)
+			}
+			start := int64(10)
+			if cursor != nil {
+				start = cursor.Id - 1
+			}
+			page := []model.Notification{}
+			for id := start; id > start-int64(limit) && id > 0; id-- {
+				page = append(page, model.Notification{Id: id, UserId: userId, Kind: model.NotificationPriceDrop})
+			}
+			return &page, nil
+		},
+	}
+	s := NewNotificationService(repo)
+
+	first, next, err := s.List(context.Background(), 9, 2, &model.ListNotificationsRequest{Unread: true})
+	if err != nil || len(first) != 2 || first[1].Id != 9 || next == "" {
+		t.Fatalf("unexpected first page: %+v %q %v", first, next, err)
+	}
+
+	second, _, err := s.List(context.Background(), 9, 2, &model.ListNotificationsRequest{Unread: true, Cursor: next})
+	if err != nil || second[0].Id != 8 {
+		t.Fatalf("unexpected second page: %+v %v", second, err)
+	}
+
+	if _, _, err = s.List(context.Background(), 9, 2, &model.ListNotificationsRequest{Cursor: "???"}); !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestNotificationService_MarkRead(t *testing.T) {
+	repo := &mockRepo{
+		MarkNotificationReadFn: func(ctx context.Context, userId, id int64) (bool, error) {
+			return userId == 9 && id == 1, nil
+		},
+	}
+	s := NewNotificationService(repo)
+
+	if err := s.MarkRead(context.Background(), 9, &model.ReadNotificationRequest{Id: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.MarkRead(context.Background(), 10, &model.ReadNotificationRequest{Id: 1}); !errors.Is(err, errs.NotFoundError) {
+		t.Fatalf("expected not found for another user's notification, got %v", err)
+	}
+}
@@ -2,44 +2,141 @@ package orderService
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 
+	"github.com/niklvrr/myMarketplace/internal/errs"
 	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/search"
 )
 
 type IOrderRepository interface {
-	CreateOrder(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, error)
+	CreateOrder(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error)
 	GetOrdersByUserId(ctx context.Context, userId int64) (*[]model.Order, error)
 	GetOrderById(ctx context.Context, orderId int64) (*model.Order, error)
 	GetOrderItemsByOrderId(ctx context.Context, orderId int64) (*[]model.OrderItem, error)
 	DeleteOrderById(ctx context.Context, orderId int64) error
+	UpdateOrderStatus(ctx context.Context, orderId, actorId int64, status string, from []string) (*model.Order, bool, error)
+}
+
+// orderTransitions lists for every status the statuses an order can be moved to it
+// from. A completed order is final, it lets the buyer review the ordered products.
+var orderTransitions = map[string][]string{
+	model.OrderStatusShipped:   {model.OrderStatusPending},
+	model.OrderStatusCompleted: {model.OrderStatusShipped},
+	model.OrderStatusCanceled:  {model.OrderStatusPending, model.OrderStatusShipped},
+}
+
+// IPriceResolver knows the unit price a customer buys a quantity of a product at,
+// including active discounts and quantity breaks.
+type IPriceResolver interface {
+	EffectivePrice(ctx context.Context, userId, productId int64, quantity int) (float64, error)
+}
+
+// ILowStockChecker is told about products whose stock went down.
+type ILowStockChecker interface {
+	StockDecreased(productIds []int64)
+}
+
+// IProductReader reads the products whose stock an order changed.
+type IProductReader interface {
+	GetProductsByIds(ctx context.Context, ids []int64) (*[]model.Product, error)
 }
 
 type OrderService struct {
-	repo IOrderRepository
+	repo     IOrderRepository
+	prices   IPriceResolver
+	lowStock ILowStockChecker
+	products IProductReader
+	index    search.SearchIndex
 }
 
-func NewOrderService(repo IOrderRepository) *OrderService {
-	return &OrderService{repo: repo}
+func NewOrderService(
+	repo IOrderRepository,
+	prices IPriceResolver,
+	lowStock ILowStockChecker,
+	products IProductReader,
+	index search.SearchIndex,
+) *OrderService {
+	return &OrderService{
+		repo:     repo,
+		prices:   prices,
+		lowStock: lowStock,
+		products: products,
+		index:    index,
+	}
 }
 
+// CreateOrder charges the current price of every product, the price sent by the
+// client is ignored so an ended or not yet started sale can't be applied. The quantity
+// break is picked by the total quantity of the product in the order. A bundle is
+// charged its own price, not the sum of its components.
 func (s *OrderService) CreateOrder(ctx context.Context, req *model.CreateOrderRequest) (int64, error) {
+	quantities := make(map[int64]int, len(req.OrderItems))
+	for _, r := range req.OrderItems {
+		quantities[r.ProductId] += r.Quantity
+	}
+
 	var items []model.OrderItem
 	for _, r := range req.OrderItems {
+		price, err := s.prices.EffectivePrice(ctx, req.UserId, r.ProductId, quantities[r.ProductId])
+		if err != nil {
+			return 0, err
+		}
+
 		items = append(items, model.OrderItem{
 			ProductId: r.ProductId,
 			Quantity:  r.Quantity,
-			Price:     r.Price,
+			Price:     price,
 		})
 	}
 
-	orderId, err := s.repo.CreateOrder(ctx, req.UserId, &items)
+	orderId, placed, err := s.repo.CreateOrder(ctx, req.UserId, &items)
 	if err != nil {
 		return 0, err
 	}
 
+	if !placed {
+		return 0, fmt.Errorf("%w: not enough stock or a product is no longer on sale", errs.ConflictError)
+	}
+
+	// A bundle takes the stock of its components, they are the ones that may run low.
+	productIds := orderedProductIds(&items)
+	s.lowStock.StockDecreased(productIds)
+	s.reindex(ctx, productIds)
+
 	return orderId, nil
 }
 
+// orderedProductIds lists the products of the order items together with the
+// components of the bundles among them.
+func orderedProductIds(items *[]model.OrderItem) []int64 {
+	productIds := make([]int64, 0, len(*items))
+	for _, item := range *items {
+		productIds = append(productIds, item.ProductId)
+		for _, c := range item.Components {
+			productIds = append(productIds, c.ProductId)
+		}
+	}
+	return productIds
+}
+
+// reindex puts the stock and sold counts an order changed into the search index.
+// The order is already committed, a failure is only logged.
+func (s *OrderService) reindex(ctx context.Context, productIds []int64) {
+	products, err := s.products.GetProductsByIds(ctx, productIds)
+	if err != nil {
+		slog.Error("order reindex failed", "product_ids", productIds, "error", err)
+		return
+	}
+
+	for i := range *products {
+		if err = s.index.Index(ctx, &(*products)[i]); err != nil {
+			slog.Error("order reindex failed", "product_id", (*products)[i].Id, "error", err)
+		}
+	}
+}
+
 func (s *OrderService) GetOrdersByUserId(ctx context.Context, req *model.GetOrdersByUserIdRequest) (*[]model.OrderResponse, error) {
 	orders, err := s.repo.GetOrdersByUserId(ctx, req.UserId)
 	if err != nil {
@@ -80,13 +177,20 @@ func (s *OrderService) GetOrderItemsByOrderId(ctx context.Context, req *model.Ge
 
 	var resp []model.OrderItemResponse
 	for _, o := range *items {
-		resp = append(resp, model.OrderItemResponse{
+		item := model.OrderItemResponse{
 			Id:        o.Id,
 			OrderId:   o.OrderId,
 			ProductId: o.ProductId,
 			Quantity:  o.Quantity,
 			Price:     o.Price,
-		})
+		}
+		for _, c := range o.Components {
+			item.Components = append(item.Components, model.OrderItemComponentResponse{
+				ProductId: c.ProductId,
+				Quantity:  c.Quantity,
+			})
+		}
+		resp = append(resp, item)
 	}
 
 	return &resp, nil
@@ -100,3 +204,41 @@ func (s *OrderService) DeleteOrderById(ctx context.Context, req *model.DeleteOrd
 
 	return nil
 }
+
+// UpdateStatus moves the order along its lifecycle: pending, shipped, completed, or
+// canceled before it is completed. Canceling gives the stock of the order back.
+func (s *OrderService) UpdateStatus(ctx context.Context, actorId int64, req *model.UpdateOrderStatusRequest) (*model.OrderResponse, error) {
+	from, ok := orderTransitions[req.Status]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown order status %q", errs.ValidationError, req.Status)
+	}
+
+	order, updated, err := s.repo.UpdateOrderStatus(ctx, req.Id, actorId, req.Status, from)
+	if err != nil {
+		return nil, err
+	}
+
+	if order == nil {
+		return nil, fmt.Errorf("%w: order not found", errs.NotFoundError)
+	}
+
+	if !updated {
+		return nil, fmt.Errorf("%w: order can't be moved from %s to %s", errs.ConflictError, order.Status, req.Status)
+	}
+
+	if order.Status == model.OrderStatusCanceled {
+		items, err := s.repo.GetOrderItemsByOrderId(ctx, order.Id)
+		if err != nil {
+			slog.Error("order reindex failed", "order_id", order.Id, "error", err)
+		} else {
+			s.reindex(ctx, orderedProductIds(items))
+		}
+	}
+
+	return &model.OrderResponse{
+		Id:     order.Id,
+		UserId: order.UserId,
+		Status: order.Status,
+		Total:  order.Total,
+	}, nil
+}
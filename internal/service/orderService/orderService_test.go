@@ -6,18 +6,21 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/niklvrr/myMarketplace/internal/errs"
 	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/search"
 )
 
 type mockRepo struct {
-	CreateOrderFn            func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, error)
+	CreateOrderFn            func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error)
 	GetOrdersByUserIdFn      func(ctx context.Context, userId int64) (*[]model.Order, error)
 	GetOrderByIdFn           func(ctx context.Context, orderId int64) (*model.Order, error)
 	GetOrderItemsByOrderIdFn func(ctx context.Context, orderId int64) (*[]model.OrderItem, error)
 	DeleteOrderByIdFn        func(ctx context.Context, orderId int64) error
+	UpdateOrderStatusFn      func(ctx context.Context, orderId, actorId int64, status string, from []string) (*model.Order, bool, error)
 }
 
-func (m *mockRepo) CreateOrder(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, error) {
+func (m *mockRepo) CreateOrder(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error) {
 	return m.CreateOrderFn(ctx, userId, items)
 }
 func (m *mockRepo) GetOrdersByUserId(ctx context.Context, userId int64) (*[]model.Order, error) {
@@ -32,12 +35,66 @@ func (m *mockRepo) GetOrderItemsByOrderId(ctx context.Context, orderId int64) (*
 func (m *mockRepo) DeleteOrderById(ctx context.Context, orderId int64) error {
 	return m.DeleteOrderByIdFn(ctx, orderId)
 }
+func (m *mockRepo) UpdateOrderStatus(ctx context.Context, orderId, actorId int64, status string, from []string) (*model.Order, bool, error) {
+	return m.UpdateOrderStatusFn(ctx, orderId, actorId, status, from)
+}
+
+// mockPricer sells products at their wholesale price from 10 units on.
+type mockPricer struct {
+	prices    map[int64]float64
+	wholesale map[int64]float64
+}
+
+func (m *mockPricer) EffectivePrice(ctx context.Context, userId, productId int64, quantity int) (float64, error) {
+	price, ok := m.prices[productId]
+	if !ok {
+		return 0, errors.New("product not found")
+	}
+	if wholesale, ok := m.wholesale[productId]; ok && quantity >= 10 {
+		return wholesale, nil
+	}
+	return price, nil
+}
+
+type mockLowStock struct {
+	decreased []int64
+}
+
+func (m *mockLowStock) StockDecreased(productIds []int64) {
+	m.decreased = append(m.decreased, productIds...)
+}
+
+type mockProducts struct{}
+
+func (m *mockProducts) GetProductsByIds(ctx context.Context, ids []int64) (*[]model.Product, error) {
+	products := make([]model.Product, 0, len(ids))
+	for _, id := range ids {
+		products = append(products, model.Product{Id: id})
+	}
+	return &products, nil
+}
+
+type mockIndex struct {
+	indexed []int64
+}
+
+func (m *mockIndex) Search(ctx context.Context, q search.Query) (*[]model.Product, error) {
+	return &[]model.Product{}, nil
+}
+func (m *mockIndex) Index(ctx context.Context, product *model.Product) error {
+	m.indexed = append(m.indexed, product.Id)
+	return nil
+}
+func (m *mockIndex) Delete(ctx context.Context, productId int64) error { return nil }
+func (m *mockIndex) Reindex(ctx context.Context) error                 { return nil }
+
+var testPrices = &mockPricer{prices: map[int64]float64{1: 10, 10: 100, 11: 40}}
 
 func TestOrderService_CreateOrder(t *testing.T) {
 	tests := []struct {
 		name    string
 		req     *model.CreateOrderRequest
-		repoFn  func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, error)
+		repoFn  func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error)
 		wantID  int64
 		wantErr bool
 	}{
@@ -50,7 +107,7 @@ func TestOrderService_CreateOrder(t *testing.T) {
 					{ProductId: 11, Quantity: 2, Price: 50},
 				},
 			},
-			func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, error) {
+			func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error) {
 				if userId != 2 {
 					t.Fatalf("unexpected userId: got %d want %d", userId, 2)
 				}
@@ -60,7 +117,10 @@ func TestOrderService_CreateOrder(t *testing.T) {
 				if (*items)[0].ProductId != 10 || (*items)[0].Quantity != 1 || (*items)[0].Price != 100 {
 					t.Fatalf("unexpected first item: %+v", (*items)[0])
 				}
-				return 77, nil
+				if (*items)[1].Price != 40 {
+					t.Fatalf("item must be charged at the current price, got %+v", (*items)[1])
+				}
+				return 77, true, nil
 			},
 			77,
 			false,
@@ -68,8 +128,18 @@ func TestOrderService_CreateOrder(t *testing.T) {
 		{
 			"repo error",
 			&model.CreateOrderRequest{UserId: 3, OrderItems: []model.OrderItemRequest{{ProductId: 1, Quantity: 1, Price: 10}}},
-			func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, error) {
-				return 0, errors.New("db")
+			func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error) {
+				return 0, false, errors.New("db")
+			},
+			0,
+			true,
+		},
+		{
+			"unknown product",
+			&model.CreateOrderRequest{UserId: 3, OrderItems: []model.OrderItemRequest{{ProductId: 404, Quantity: 1, Price: 10}}},
+			func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error) {
+				t.Fatalf("order must not be created")
+				return 0, false, nil
 			},
 			0,
 			true,
@@ -79,7 +149,7 @@ func TestOrderService_CreateOrder(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mockRepo{CreateOrderFn: tt.repoFn}
-			s := NewOrderService(repo)
+			s := NewOrderService(repo, testPrices, &mockLowStock{}, &mockProducts{}, &mockIndex{})
 			id, err := s.CreateOrder(context.Background(), tt.req)
 			if tt.wantErr {
 				if err == nil {
@@ -97,6 +167,90 @@ func TestOrderService_CreateOrder(t *testing.T) {
 	}
 }
 
+func TestOrderService_CreateOrder_Stock(t *testing.T) {
+	req := &model.CreateOrderRequest{UserId: 2, OrderItems: []model.OrderItemRequest{{ProductId: 10, Quantity: 3}, {ProductId: 11, Quantity: 1}}}
+	lowStock := &mockLowStock{}
+	placed := false
+	repo := &mockRepo{
+		CreateOrderFn: func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error) {
+			return 77, placed, nil
+		},
+	}
+	index := &mockIndex{}
+	s := NewOrderService(repo, testPrices, lowStock, &mockProducts{}, index)
+
+	if _, err := s.CreateOrder(context.Background(), req); !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if len(lowStock.decreased) != 0 || len(index.indexed) != 0 {
+		t.Fatalf("stock must not be checked or reindexed for a rejected order")
+	}
+
+	placed = true
+	if _, err := s.CreateOrder(context.Background(), req); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !reflect.DeepEqual(lowStock.decreased, []int64{10, 11}) {
+		t.Fatalf("expected the stock check of the ordered products, got %v", lowStock.decreased)
+	}
+	if !reflect.DeepEqual(index.indexed, []int64{10, 11}) {
+		t.Fatalf("expected the ordered products to be reindexed, got %v", index.indexed)
+	}
+}
+
+func TestOrderService_CreateOrder_Bundle(t *testing.T) {
+	req := &model.CreateOrderRequest{UserId: 2, OrderItems: []model.OrderItemRequest{{ProductId: 10, Quantity: 2}}}
+	lowStock := &mockLowStock{}
+	repo := &mockRepo{
+		CreateOrderFn: func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error) {
+			item := &(*items)[0]
+			if item.Price != 100 {
+				t.Fatalf("a bundle must be charged its own price, got %v", item.Price)
+			}
+			item.Components = []model.OrderItemComponent{{ProductId: 20, Quantity: 2}, {ProductId: 21, Quantity: 4}}
+			return 77, true, nil
+		},
+	}
+	index := &mockIndex{}
+	s := NewOrderService(repo, testPrices, lowStock, &mockProducts{}, index)
+
+	if _, err := s.CreateOrder(context.Background(), req); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !reflect.DeepEqual(lowStock.decreased, []int64{10, 20, 21}) {
+		t.Fatalf("expected the stock check of the bundle and its components, got %v", lowStock.decreased)
+	}
+	if !reflect.DeepEqual(index.indexed, []int64{10, 20, 21}) {
+		t.Fatalf("expected the bundle and its components to be reindexed, got %v", index.indexed)
+	}
+}
+
+func TestOrderService_CreateOrder_QuantityBreaks(t *testing.T) {
+	pricer := &mockPricer{prices: map[int64]float64{1: 10, 10: 100}, wholesale: map[int64]float64{1: 8, 10: 90}}
+	req := &model.CreateOrderRequest{UserId: 2, OrderItems: []model.OrderItemRequest{
+		{ProductId: 1, Quantity: 6},
+		{ProductId: 10, Quantity: 9},
+		{ProductId: 1, Quantity: 4},
+	}}
+	var got []float64
+	repo := &mockRepo{
+		CreateOrderFn: func(ctx context.Context, userId int64, items *[]model.OrderItem) (int64, bool, error) {
+			for _, item := range *items {
+				got = append(got, item.Price)
+			}
+			return 77, true, nil
+		},
+	}
+	s := NewOrderService(repo, pricer, &mockLowStock{}, &mockProducts{}, &mockIndex{})
+
+	if _, err := s.CreateOrder(context.Background(), req); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !reflect.DeepEqual(got, []float64{8, 100, 8}) {
+		t.Fatalf("the break must be picked by the total quantity of the product, got %v", got)
+	}
+}
+
 func TestOrderService_GetOrdersByUserId(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -132,7 +286,7 @@ func TestOrderService_GetOrdersByUserId(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mockRepo{GetOrdersByUserIdFn: tt.repoFn}
-			s := NewOrderService(repo)
+			s := NewOrderService(repo, testPrices, &mockLowStock{}, &mockProducts{}, &mockIndex{})
 			got, err := s.GetOrdersByUserId(context.Background(), tt.req)
 			if tt.wantErr {
 				if err == nil {
@@ -181,7 +335,7 @@ func TestOrderService_GetOrderById(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mockRepo{GetOrderByIdFn: tt.repoFn}
-			s := NewOrderService(repo)
+			s := NewOrderService(repo, testPrices, &mockLowStock{}, &mockProducts{}, &mockIndex{})
 			got, err := s.GetOrderById(context.Background(), tt.req)
 			if tt.wantErr {
 				if err == nil {
@@ -223,6 +377,22 @@ func TestOrderService_GetOrderItemsByOrderId(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"bundle breakdown",
+			&model.GetOrderItemsByOrderIdRequest{OrderId: 4},
+			func(ctx context.Context, orderId int64) (*[]model.OrderItem, error) {
+				items := []model.OrderItem{{
+					Id: 3, OrderId: 4, ProductId: 9, Quantity: 2, Price: 300,
+					Components: []model.OrderItemComponent{{OrderItemId: 3, ProductId: 20, Quantity: 2}, {OrderItemId: 3, ProductId: 21, Quantity: 4}},
+				}}
+				return &items, nil
+			},
+			&[]model.OrderItemResponse{{
+				Id: 3, OrderId: 4, ProductId: 9, Quantity: 2, Price: 300,
+				Components: []model.OrderItemComponentResponse{{ProductId: 20, Quantity: 2}, {ProductId: 21, Quantity: 4}},
+			}},
+			false,
+		},
 		{
 			"repo error",
 			&model.GetOrderItemsByOrderIdRequest{OrderId: 5},
@@ -237,7 +407,7 @@ func TestOrderService_GetOrderItemsByOrderId(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mockRepo{GetOrderItemsByOrderIdFn: tt.repoFn}
-			s := NewOrderService(repo)
+			s := NewOrderService(repo, testPrices, &mockLowStock{}, &mockProducts{}, &mockIndex{})
 			got, err := s.GetOrderItemsByOrderId(context.Background(), tt.req)
 			if tt.wantErr {
 				if err == nil {
@@ -269,7 +439,7 @@ func TestOrderService_DeleteOrderById(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mockRepo{DeleteOrderByIdFn: tt.repoFn}
-			s := NewOrderService(repo)
+			s := NewOrderService(repo, testPrices, &mockLowStock{}, &mockProducts{}, &mockIndex{})
 			err := s.DeleteOrderById(context.Background(), tt.req)
 			if tt.wantErr {
 				if err == nil {
@@ -283,3 +453,68 @@ func TestOrderService_DeleteOrderById(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderService_UpdateStatus(t *testing.T) {
+	// The mock moves an order from its current status the way the repository does.
+	current := map[int64]string{1: model.OrderStatusPending, 2: model.OrderStatusShipped, 3: model.OrderStatusCompleted}
+	tests := []struct {
+		name    string
+		req     *model.UpdateOrderStatusRequest
+		wantErr error
+	}{
+		{"ship pending", &model.UpdateOrderStatusRequest{Id: 1, Status: model.OrderStatusShipped}, nil},
+		{"complete shipped", &model.UpdateOrderStatusRequest{Id: 2, Status: model.OrderStatusCompleted}, nil},
+		{"cancel shipped", &model.UpdateOrderStatusRequest{Id: 2, Status: model.OrderStatusCanceled}, nil},
+		{"complete pending", &model.UpdateOrderStatusRequest{Id: 1, Status: model.OrderStatusCompleted}, errs.ConflictError},
+		{"cancel completed", &model.UpdateOrderStatusRequest{Id: 3, Status: model.OrderStatusCanceled}, errs.ConflictError},
+		{"back to pending", &model.UpdateOrderStatusRequest{Id: 2, Status: model.OrderStatusPending}, errs.ValidationError},
+		{"unknown status", &model.UpdateOrderStatusRequest{Id: 1, Status: "lost"}, errs.ValidationError},
+		{"not found", &model.UpdateOrderStatusRequest{Id: 9, Status: model.OrderStatusShipped}, errs.NotFoundError},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				UpdateOrderStatusFn: func(ctx context.Context, orderId, actorId int64, status string, from []string) (*model.Order, bool, error) {
+					if actorId != 5 {
+						t.Fatalf("unexpected actor %d", actorId)
+					}
+					st, ok := current[orderId]
+					if !ok {
+						return nil, false, nil
+					}
+					order := &model.Order{Id: orderId, UserId: 2, Status: st, Total: 10}
+					for _, f := range from {
+						if f == st {
+							order.Status = status
+							return order, true, nil
+						}
+					}
+					return order, false, nil
+				},
+				GetOrderItemsByOrderIdFn: func(ctx context.Context, orderId int64) (*[]model.OrderItem, error) {
+					return &[]model.OrderItem{{OrderId: orderId, ProductId: 10, Quantity: 1}}, nil
+				},
+			}
+			index := &mockIndex{}
+			s := NewOrderService(repo, testPrices, &mockLowStock{}, &mockProducts{}, index)
+			resp, err := s.UpdateStatus(context.Background(), 5, tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if resp.Id != tt.req.Id || resp.Status != tt.req.Status {
+				t.Fatalf("unexpected order: %+v", resp)
+			}
+			// Canceling gives the stock back, the index has to see it.
+			if reindexed := len(index.indexed) > 0; reindexed != (tt.req.Status == model.OrderStatusCanceled) {
+				t.Fatalf("unexpected reindex %v for %s", index.indexed, tt.req.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package priceTierService
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/policy"
+)
+
+type IPriceTierRepository interface {
+	GetProductPriceTiers(ctx context.Context, productId int64) (*[]model.PriceTier, error)
+	SetPriceTiers(ctx context.Context, productId int64, priceListId *int64, tiers []model.PriceTier) error
+	CreatePriceList(ctx context.Context, l *model.PriceList) (bool, error)
+	GetPriceListById(ctx context.Context, id int64) (*model.PriceList, error)
+	GetPriceLists(ctx context.Context) (*[]model.PriceList, error)
+	SetUserPriceList(ctx context.Context, userId int64, priceListId *int64) (bool, error)
+}
+
+type IProductReader interface {
+	GetProductById(ctx context.Context, productId int64) (*model.Product, error)
+}
+
+// PriceTierService manages quantity breaks of products and the price lists of customer
+// groups. The prices themselves are resolved by the discount service.
+type PriceTierService struct {
+	repo     IPriceTierRepository
+	products IProductReader
+}
+
+func NewPriceTierService(repo IPriceTierRepository, products IProductReader) *PriceTierService {
+	return &PriceTierService{
+		repo:     repo,
+		products: products,
+	}
+}
+
+// Tiers returns the tiers of the product in all its price lists, only its seller and
+// admins see the prices of customer groups.
+func (s *PriceTierService) Tiers(ctx context.Context, userId int64, role string, req *model.PriceTiersRequest) (model.ProductPriceTiersResponse, error) {
+	p, err := s.products.GetProductById(ctx, req.ProductId)
+	if err != nil {
+		return model.ProductPriceTiersResponse{}, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return model.ProductPriceTiersResponse{}, err
+	}
+
+	return s.tiersResponse(ctx, p)
+}
+
+// SetTiers replaces the tiers of the product in one price list. The price of one unit
+// for every customer is the price of the product itself, so common tiers start from
+// two units, a price list may set its own price from one unit on.
+func (s *PriceTierService) SetTiers(ctx context.Context, userId int64, role string, req *model.SetPriceTiersRequest) (model.ProductPriceTiersResponse, error) {
+	p, err := s.products.GetProductById(ctx, req.ProductId)
+	if err != nil {
+		return model.ProductPriceTiersResponse{}, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return model.ProductPriceTiersResponse{}, err
+	}
+
+	if p.DeletedAt != nil {
+		return model.ProductPriceTiersResponse{}, fmt.Errorf("%w: product is deleted, restore it first", errs.ConflictError)
+	}
+
+	if req.PriceListId != nil {
+		l, err := s.repo.GetPriceListById(ctx, *req.PriceListId)
+		if err != nil {
+			return model.ProductPriceTiersResponse{}, err
+		}
+
+		if l == nil {
+			return model.ProductPriceTiersResponse{}, fmt.Errorf("%w: price list %d", errs.NotFoundError, *req.PriceListId)
+		}
+	}
+
+	tiers, err := validateTiers(p.Id, req.PriceListId, req.Tiers)
+	if err != nil {
+		return model.ProductPriceTiersResponse{}, err
+	}
+
+	if err = s.repo.SetPriceTiers(ctx, p.Id, req.PriceListId, tiers); err != nil {
+		return model.ProductPriceTiersResponse{}, err
+	}
+
+	return s.tiersResponse(ctx, p)
+}
+
+// CreatePriceList registers a customer group with its own prices.
+func (s *PriceTierService) CreatePriceList(ctx context.Context, req *model.CreatePriceListRequest) (model.PriceListResponse, error) {
+	l := model.PriceList{Name: req.Name}
+	created, err := s.repo.CreatePriceList(ctx, &l)
+	if err != nil {
+		return model.PriceListResponse{}, err
+	}
+
+	if !created {
+		return model.PriceListResponse{}, fmt.Errorf("%w: price list %q already exists", errs.ConflictError, req.Name)
+	}
+
+	return toPriceListResponse(&l), nil
+}
+
+func (s *PriceTierService) PriceLists(ctx context.Context) ([]model.PriceListResponse, error) {
+	lists, err := s.repo.GetPriceLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]model.PriceListResponse, 0, len(*lists))
+	for i := range *lists {
+		res = append(res, toPriceListResponse(&(*lists)[i]))
+	}
+
+	return res, nil
+}
+
+// AssignPriceList puts the customer into the group of the price list or takes them out
+// of their group. A customer is in one group at most.
+func (s *PriceTierService) AssignPriceList(ctx context.Context, req *model.AssignPriceListRequest) error {
+	if req.PriceListId != nil {
+		l, err := s.repo.GetPriceListById(ctx, *req.PriceListId)
+		if err != nil {
+			return err
+		}
+
+		if l == nil {
+			return fmt.Errorf("%w: price list %d", errs.NotFoundError, *req.PriceListId)
+		}
+	}
+
+	assigned, err := s.repo.SetUserPriceList(ctx, req.UserId, req.PriceListId)
+	if err != nil {
+		return err
+	}
+
+	if !assigned {
+		return fmt.Errorf("%w: user %d", errs.NotFoundError, req.UserId)
+	}
+
+	return nil
+}
+
+// validateTiers orders the tiers by quantity and checks that a tier for more units is
+// cheaper than the one before it.
+func validateTiers(productId int64, priceListId *int64, req []model.PriceTierRequest) ([]model.PriceTier, error) {
+	tiers := make([]model.PriceTier, 0, len(req))
+	for _, t := range req {
+		if priceListId == nil && t.MinQuantity < 2 {
+			return nil, fmt.Errorf("%w: a single unit is sold at the product price, tiers start from 2 units", errs.ValidationError)
+		}
+
+		tiers = append(tiers, model.PriceTier{
+			ProductId:   productId,
+			PriceListId: priceListId,
+			MinQuantity: t.MinQuantity,
+			Price:       t.Price,
+		})
+	}
+
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].MinQuantity < tiers[j].MinQuantity
+	})
+
+	for i := 1; i < len(tiers); i++ {
+		if tiers[i].MinQuantity == tiers[i-1].MinQuantity {
+			return nil, fmt.Errorf("%w: more than one tier from %d units", errs.ValidationError, tiers[i].MinQuantity)
+		}
+
+		if tiers[i].Price >= tiers[i-1].Price {
+			return nil, fmt.Errorf("%w: the tier from %d units must be cheaper than the one from %d", errs.ValidationError, tiers[i].MinQuantity, tiers[i-1].MinQuantity)
+		}
+	}
+
+	return tiers, nil
+}
+
+func (s *PriceTierService) tiersResponse(ctx context.Context, p *model.Product) (model.ProductPriceTiersResponse, error) {
+	tiers, err := s.repo.GetProductPriceTiers(ctx, p.Id)
+	if err != nil {
+		return model.ProductPriceTiersResponse{}, err
+	}
+
+	resp := model.ProductPriceTiersResponse{
+		ProductId:  p.Id,
+		Price:      p.Price,
+		PriceLists: []model.PriceListTiersResponse{},
+	}
+
+	// The tiers come ordered by price list, the common ones first.
+	for _, t := range *tiers {
+		last := len(resp.PriceLists) - 1
+		if last < 0 || !samePriceList(resp.PriceLists[last].PriceListId, t.PriceListId) {
+			resp.PriceLists = append(resp.PriceLists, model.PriceListTiersResponse{PriceListId: t.PriceListId})
+			last++
+		}
+
+		resp.PriceLists[last].Tiers = append(resp.PriceLists[last].Tiers, model.PriceTierResponse{
+			MinQuantity: t.MinQuantity,
+			Price:       t.Price,
+		})
+	}
+
+	return resp, nil
+}
+
+func samePriceList(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return *a == *b
+}
+
+func toPriceListResponse(l *model.PriceList) model.PriceListResponse {
+	return model.PriceListResponse{
+		Id:        l.Id,
+		Name:      l.Name,
+		CreatedAt: l.CreatedAt,
+	}
+}
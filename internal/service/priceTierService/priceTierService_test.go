@@ -0,0 +1,245 @@
+package priceTierService
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockRepo struct {
+	tiers      []model.PriceTier
+	lists      map[int64]model.PriceList
+	users      map[int64]*int64
+	set        []model.PriceTier
+	setCalled  bool
+	setListId  *int64
+	assignedTo map[int64]*int64
+}
+
+func (m *mockRepo) GetProductPriceTiers(ctx context.Context, productId int64) (*[]model.PriceTier, error) {
+	return &m.tiers, nil
+}
+func (m *mockRepo) SetPriceTiers(ctx context.Context, productId int64, priceListId *int64, tiers []model.PriceTier) error {
+	m.setCalled = true
+	m.set = tiers
+	m.setListId = priceListId
+	return nil
+}
+func (m *mockRepo) CreatePriceList(ctx context.Context, l *model.PriceList) (bool, error) {
+	for _, existing := range m.lists {
+		if existing.Name == l.Name {
+			return false, nil
+		}
+	}
+	l.Id = 9
+	l.CreatedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return true, nil
+}
+func (m *mockRepo) GetPriceListById(ctx context.Context, id int64) (*model.PriceList, error) {
+	if l, ok := m.lists[id]; ok {
+		return &l, nil
+	}
+	return nil, nil
+}
+func (m *mockRepo) GetPriceLists(ctx context.Context) (*[]model.PriceList, error) {
+	result := []model.PriceList{}
+	for _, l := range m.lists {
+		result = append(result, l)
+	}
+	return &result, nil
+}
+func (m *mockRepo) SetUserPriceList(ctx context.Context, userId int64, priceListId *int64) (bool, error) {
+	if _, ok := m.users[userId]; !ok {
+		return false, nil
+	}
+	m.assignedTo[userId] = priceListId
+	return true, nil
+}
+
+type mockProducts struct {
+	products map[int64]model.Product
+}
+
+func (m *mockProducts) GetProductById(ctx context.Context, productId int64) (*model.Product, error) {
+	p, ok := m.products[productId]
+	if !ok {
+		return nil, errors.New("product not found")
+	}
+	return &p, nil
+}
+
+var (
+	wholesale = int64(3)
+	deletedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+var testProducts = map[int64]model.Product{
+	1: {Id: 1, SellerId: 2, Name: "Paper", Price: 100},
+	2: {Id: 2, SellerId: 2, Name: "Old paper", Price: 100, DeletedAt: &deletedAt},
+}
+
+func newTestRepo() *mockRepo {
+	return &mockRepo{
+		lists:      map[int64]model.PriceList{wholesale: {Id: wholesale, Name: "Wholesale"}},
+		users:      map[int64]*int64{5: nil},
+		assignedTo: map[int64]*int64{},
+	}
+}
+
+func TestPriceTierService_SetTiers(t *testing.T) {
+	unknown := int64(42)
+	tests := []struct {
+		name        string
+		userId      int64
+		productId   int64
+		priceListId *int64
+		tiers       []model.PriceTierRequest
+		expected    []model.PriceTier
+		expectedErr error
+	}{
+		{
+			name: "quantity breaks", userId: 2, productId: 1,
+			tiers: []model.PriceTierRequest{{MinQuantity: 50, Price: 80}, {MinQuantity: 10, Price: 90}},
+			expected: []model.PriceTier{
+				{ProductId: 1, MinQuantity: 10, Price: 90},
+				{ProductId: 1, MinQuantity: 50, Price: 80},
+			},
+		},
+		{
+			name: "price list from one unit", userId: 2, productId: 1, priceListId: &wholesale,
+			tiers:    []model.PriceTierRequest{{MinQuantity: 1, Price: 85}},
+			expected: []model.PriceTier{{ProductId: 1, PriceListId: &wholesale, MinQuantity: 1, Price: 85}},
+		},
+		{name: "remove", userId: 2, productId: 1, tiers: []model.PriceTierRequest{}, expected: []model.PriceTier{}},
+		{
+			name: "another seller's product", userId: 3, productId: 1, expectedErr: errs.NotOwnerError,
+			tiers: []model.PriceTierRequest{{MinQuantity: 10, Price: 90}},
+		},
+		{
+			name: "deleted product", userId: 2, productId: 2, expectedErr: errs.ConflictError,
+			tiers: []model.PriceTierRequest{{MinQuantity: 10, Price: 90}},
+		},
+		{
+			name: "unknown price list", userId: 2, productId: 1, priceListId: &unknown, expectedErr: errs.NotFoundError,
+			tiers: []model.PriceTierRequest{{MinQuantity: 10, Price: 90}},
+		},
+		{
+			name: "common tier from one unit", userId: 2, productId: 1, expectedErr: errs.ValidationError,
+			tiers: []model.PriceTierRequest{{MinQuantity: 1, Price: 90}},
+		},
+		{
+			name: "same quantity twice", userId: 2, productId: 1, expectedErr: errs.ValidationError,
+			tiers: []model.PriceTierRequest{{MinQuantity: 10, Price: 90}, {MinQuantity: 10, Price: 85}},
+		},
+		{
+			name: "more units cost more", userId: 2, productId: 1, expectedErr: errs.ValidationError,
+			tiers: []model.PriceTierRequest{{MinQuantity: 10, Price: 80}, {MinQuantity: 50, Price: 90}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepo()
+			s := NewPriceTierService(repo, &mockProducts{products: testProducts})
+
+			req := &model.SetPriceTiersRequest{ProductId: tt.productId, PriceListId: tt.priceListId, Tiers: tt.tiers}
+			resp, err := s.SetTiers(context.Background(), tt.userId, "seller", req)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr != nil {
+				if repo.setCalled {
+					t.Fatalf("the tiers must not be changed")
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(repo.set, tt.expected) || repo.setListId != tt.priceListId {
+				t.Fatalf("unexpected tiers: %+v in %v", repo.set, repo.setListId)
+			}
+			if resp.ProductId != tt.productId || resp.Price != 100 {
+				t.Fatalf("unexpected response: %+v", resp)
+			}
+		})
+	}
+}
+
+func TestPriceTierService_Tiers(t *testing.T) {
+	repo := newTestRepo()
+	repo.tiers = []model.PriceTier{
+		{ProductId: 1, MinQuantity: 10, Price: 90},
+		{ProductId: 1, MinQuantity: 50, Price: 80},
+		{ProductId: 1, PriceListId: &wholesale, MinQuantity: 1, Price: 85},
+	}
+	s := NewPriceTierService(repo, &mockProducts{products: testProducts})
+
+	if _, err := s.Tiers(context.Background(), 5, "user", &model.PriceTiersRequest{ProductId: 1}); !errors.Is(err, errs.ForbiddenError) {
+		t.Fatalf("customers must not see the prices of groups, got %v", err)
+	}
+
+	resp, err := s.Tiers(context.Background(), 2, "seller", &model.PriceTiersRequest{ProductId: 1})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(resp.PriceLists) != 2 || resp.PriceLists[0].PriceListId != nil || len(resp.PriceLists[0].Tiers) != 2 {
+		t.Fatalf("unexpected common tiers: %+v", resp.PriceLists)
+	}
+	if *resp.PriceLists[1].PriceListId != wholesale || len(resp.PriceLists[1].Tiers) != 1 || resp.PriceLists[1].Tiers[0].Price != 85 {
+		t.Fatalf("unexpected price list tiers: %+v", resp.PriceLists[1])
+	}
+}
+
+func TestPriceTierService_CreatePriceList(t *testing.T) {
+	repo := newTestRepo()
+	s := NewPriceTierService(repo, &mockProducts{})
+
+	resp, err := s.CreatePriceList(context.Background(), &model.CreatePriceListRequest{Name: "Resellers"})
+	if err != nil || resp.Id != 9 || resp.Name != "Resellers" {
+		t.Fatalf("unexpected result: %+v %v", resp, err)
+	}
+	if _, err = s.CreatePriceList(context.Background(), &model.CreatePriceListRequest{Name: "Wholesale"}); !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+}
+
+func TestPriceTierService_AssignPriceList(t *testing.T) {
+	unknown := int64(42)
+	tests := []struct {
+		name        string
+		userId      int64
+		priceListId *int64
+		expectedErr error
+	}{
+		{"join", 5, &wholesale, nil},
+		{"leave", 5, nil, nil},
+		{"unknown price list", 5, &unknown, errs.NotFoundError},
+		{"unknown user", 6, &wholesale, errs.NotFoundError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepo()
+			s := NewPriceTierService(repo, &mockProducts{})
+
+			err := s.AssignPriceList(context.Background(), &model.AssignPriceListRequest{UserId: tt.userId, PriceListId: tt.priceListId})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr != nil {
+				if len(repo.assignedTo) != 0 {
+					t.Fatalf("the group must not be changed")
+				}
+				return
+			}
+
+			if got, ok := repo.assignedTo[tt.userId]; !ok || got != tt.priceListId {
+				t.Fatalf("unexpected group: %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package productService
+
+import "github.com/niklvrr/myMarketplace/internal/model"
+
+const (
+	// feedHistorySize is how many recently viewed products are used to pick feed categories.
+	feedHistorySize = 20
+	// feedCategories is how many recently viewed categories the feed is personalized with.
+	feedCategories = 3
+)
+
+type feedSource struct {
+	name     string
+	products []model.Product
+}
+
+// recentCategories returns up to n distinct categories of the products, in their order.
+func recentCategories(products []model.Product, n int) []int64 {
+	seen := make(map[int64]struct{}, n)
+	var categories []int64
+	for _, p := range products {
+		if len(categories) == n {
+			break
+		}
+
+		if _, ok := seen[p.CategoryId]; ok || p.CategoryId == 0 {
+			continue
+		}
+		seen[p.CategoryId] = struct{}{}
+		categories = append(categories, p.CategoryId)
+	}
+
+	return categories
+}
+
+// blendFeed takes products from the sources in turn, skipping the ones already in seen,
+// until limit products are picked or every source is exhausted.
+func blendFeed(sources []feedSource, seen map[int64]struct{}, limit int) []model.FeedItemResponse {
+	items := make([]model.FeedItemResponse, 0, limit)
+	next := make([]int, len(sources))
+
+	for len(items) < limit {
+		picked := false
+		for i := range sources {
+			if len(items) == limit {
+				break
+			}
+
+			for next[i] < len(sources[i].products) {
+				p := &sources[i].products[next[i]]
+				next[i]++
+
+				if _, ok := seen[p.Id]; ok {
+					continue
+				}
+				seen[p.Id] = struct{}{}
+
+				items = append(items, model.FeedItemResponse{
+					ProductResponse: toProductResponse(p),
+					Source:          sources[i].name,
+				})
+				picked = true
+				break
+			}
+		}
+
+		if !picked {
+			break
+		}
+	}
+
+	return items
+}
@@ -3,36 +3,128 @@ package productService
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/niklvrr/myMarketplace/internal/errs"
 	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/policy"
+	"github.com/niklvrr/myMarketplace/internal/search"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
 	"github.com/redis/go-redis/v9"
 )
 
 type IProductRepository interface {
 	CreateProduct(ctx context.Context, product *model.Product) error
 	GetProductById(ctx context.Context, productId int64) (*model.Product, error)
-	UpdateProductById(ctx context.Context, product *model.Product) error
+	ResolveProductSlug(ctx context.Context, slug string) (int64, string, error)
+	UpdateProductById(ctx context.Context, product *model.Product, stock *int, actorId int64) (int, error)
 	DeleteProductById(ctx context.Context, productId int64) error
-	GetAllProducts(ctx context.Context, offset, limit int) (*[]model.Product, int64, error)
-	SearchProducts(
-		ctx context.Context,
-		text *string,
-		categoryId *int64,
-		min, max *float64,
-		offset, limit int,
-	) (*[]model.Product, int64, error)
+	ArchiveProduct(ctx context.Context, productId int64) error
+	RestoreProduct(ctx context.Context, productId int64) error
+	RestockProduct(ctx context.Context, productId int64, quantity int, actorId int64) (int, error)
+	GetPriceHistory(ctx context.Context, productId int64) (*[]model.PriceChange, error)
+	GetSellerProducts(ctx context.Context, sellerId int64, filter *model.SellerProductFilter, sort string, cursor *model.ProductCursor, limit int) (*[]model.SellerProduct, error)
+	GetAllProducts(ctx context.Context, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error)
+	UpdateProductStatus(ctx context.Context, productId int64, from []string, status, reason string) error
+	GetProductsByStatus(ctx context.Context, status string, cursor *model.ProductCursor, limit int) (*[]model.Product, error)
+	GetProductsByIds(ctx context.Context, ids []int64) (*[]model.Product, error)
+	GetCategoryBestsellers(ctx context.Context, categoryId int64, limit int) (*[]model.Product, error)
+}
+
+// IContentModerator runs automated checks before a product gets to a human moderator.
+type IContentModerator interface {
+	Review(ctx context.Context, p *model.Product) ([]model.ModerationFlag, error)
+	SaveFlags(ctx context.Context, productId int64, flags []model.ModerationFlag) error
+}
+
+// IPriceResolver sets the current price of products according to active discounts.
+type IPriceResolver interface {
+	ApplyDiscounts(ctx context.Context, products []*model.ProductResponse) error
+}
+
+// IRecommender picks the ids of the products recommended next to a product.
+type IRecommender interface {
+	Related(ctx context.Context, p *model.Product, limit int) ([]int64, error)
+	BoughtTogether(ctx context.Context, p *model.Product, limit int) ([]int64, error)
+}
+
+// IViewTracker records product views without blocking and keeps the per-user view history.
+type IViewTracker interface {
+	Record(userId, productId int64)
+	Recent(ctx context.Context, userId int64, limit int) ([]int64, error)
+}
+
+// IRestockListener is told when a listed product goes from sold out to in stock.
+type IRestockListener interface {
+	Restocked(productId int64, name string)
+}
+
+// ILowStockChecker is told about products whose stock went down.
+type ILowStockChecker interface {
+	StockDecreased(productIds []int64)
+}
+
+type statusTransition struct {
+	from []string
+	to   string
+}
+
+var moderationTransitions = map[string]statusTransition{
+	model.ModerationActionApprove: {
+		from: []string{model.ProductStatusPendingReview, model.ProductStatusSuspended},
+		to:   model.ProductStatusApproved,
+	},
+	model.ModerationActionReject: {
+		from: []string{model.ProductStatusPendingReview},
+		to:   model.ProductStatusRejected,
+	},
+	model.ModerationActionSuspend: {
+		from: []string{model.ProductStatusApproved},
+		to:   model.ProductStatusSuspended,
+	},
+}
+
+var submitTransition = statusTransition{
+	from: []string{model.ProductStatusDraft, model.ProductStatusRejected},
+	to:   model.ProductStatusPendingReview,
 }
 
 type ProductService struct {
-	repo  IProductRepository
-	cache *redis.Client
+	repo        IProductRepository
+	cache       *redis.Client
+	index       search.SearchIndex
+	moderator   IContentModerator
+	pricer      IPriceResolver
+	recommender IRecommender
+	views       IViewTracker
+	restocks    IRestockListener
+	lowStock    ILowStockChecker
 }
 
-func NewProductService(repo IProductRepository, cache *redis.Client) *ProductService {
+func NewProductService(
+	repo IProductRepository,
+	cache *redis.Client,
+	index search.SearchIndex,
+	moderator IContentModerator,
+	pricer IPriceResolver,
+	recommender IRecommender,
+	views IViewTracker,
+	restocks IRestockListener,
+	lowStock ILowStockChecker,
+) *ProductService {
 	return &ProductService{
-		repo:  repo,
-		cache: cache,
+		repo:        repo,
+		cache:       cache,
+		index:       index,
+		moderator:   moderator,
+		pricer:      pricer,
+		recommender: recommender,
+		views:       views,
+		restocks:    restocks,
+		lowStock:    lowStock,
 	}
 }
 
@@ -44,6 +136,28 @@ func (s *ProductService) Create(ctx context.Context, sellerId int64, req *model.
 		Description: req.Description,
 		Price:       req.Price,
 		Stock:       req.Stock,
+		Status:      model.ProductStatusPendingReview,
+		Sku:         strings.TrimSpace(req.Sku),
+		ImageUrl:    req.ImageUrl,
+	}
+	p.LowestPrice30d = p.Price
+	if req.Draft {
+		p.Status = model.ProductStatusDraft
+	}
+
+	var flags []model.ModerationFlag
+	if !req.Draft {
+		var reason string
+		var err error
+		flags, reason, err = s.review(ctx, &p)
+		if err != nil {
+			return model.ProductResponse{}, err
+		}
+
+		if reason != "" {
+			p.Status = model.ProductStatusRejected
+			p.RejectionReason = reason
+		}
 	}
 
 	err := s.repo.CreateProduct(ctx, &p)
@@ -51,144 +165,833 @@ func (s *ProductService) Create(ctx context.Context, sellerId int64, req *model.
 		return model.ProductResponse{}, err
 	}
 
+	if flags != nil {
+		if err = s.moderator.SaveFlags(ctx, p.Id, flags); err != nil {
+			return model.ProductResponse{}, err
+		}
+	}
+
 	s.cache.Del(ctx, "products:all")
+	if err = s.index.Index(ctx, &p); err != nil {
+		return model.ProductResponse{}, err
+	}
 
-	return model.ProductResponse{
-		Id:          p.Id,
-		SellerId:    p.SellerId,
-		CategoryId:  p.CategoryId,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-		Stock:       p.Stock,
-	}, nil
+	return toProductResponse(&p), nil
 }
 
-func (s *ProductService) GetById(ctx context.Context, req *model.GetProductsRequest) (model.ProductResponse, error) {
+// GetById hides products that are not listed from everyone except the owner and admins.
+func (s *ProductService) GetById(ctx context.Context, userId int64, role string, req *model.GetProductsRequest) (model.ProductResponse, error) {
 	resp, err := s.repo.GetProductById(ctx, req.Id)
 	if err != nil {
 		return model.ProductResponse{}, err
 	}
 
-	return model.ProductResponse{
-		Id:          resp.Id,
-		SellerId:    resp.SellerId,
-		CategoryId:  resp.CategoryId,
-		Name:        resp.Name,
-		Description: resp.Description,
-		Price:       resp.Price,
-		Stock:       resp.Stock,
-	}, nil
+	if !resp.Listed() && resp.SellerId != userId && role != policy.RoleAdmin {
+		return model.ProductResponse{}, fmt.Errorf("%w: product not found", errs.NotFoundError)
+	}
+
+	// Views are recorded in the background and never fail or slow down the request.
+	if resp.Listed() && resp.SellerId != userId {
+		s.views.Record(userId, resp.Id)
+	}
+
+	res := toProductResponse(resp)
+	if err = s.pricer.ApplyDiscounts(ctx, []*model.ProductResponse{&res}); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	return res, nil
 }
 
-func (s *ProductService) UpdateById(ctx context.Context, sellerId int64, req *model.UpdateProductRequest) (model.ProductResponse, error) {
-	p := model.Product{
-		SellerId:    sellerId,
-		CategoryId:  *req.CategoryId,
-		Name:        *req.Name,
-		Description: *req.Description,
-		Price:       *req.Price,
-		Stock:       *req.Stock,
+// GetBySlug finds the product by its slug. A former slug of the product returns the
+// current one instead, so the caller can redirect old links.
+func (s *ProductService) GetBySlug(ctx context.Context, userId int64, role string, req *model.GetProductBySlugRequest) (model.ProductResponse, string, error) {
+	id, current, err := s.repo.ResolveProductSlug(ctx, req.Slug)
+	if err != nil {
+		return model.ProductResponse{}, "", err
+	}
+
+	if id == 0 {
+		return model.ProductResponse{}, "", fmt.Errorf("%w: product not found", errs.NotFoundError)
+	}
+
+	if current == req.Slug {
+		res, err := s.GetById(ctx, userId, role, &model.GetProductsRequest{Id: id})
+		return res, "", err
+	}
+
+	// The redirect must not reveal the new slug of a product the user can't see.
+	p, err := s.repo.GetProductById(ctx, id)
+	if err != nil {
+		return model.ProductResponse{}, "", err
 	}
 
-	err := s.repo.UpdateProductById(ctx, &p)
+	if !p.Listed() && p.SellerId != userId && role != policy.RoleAdmin {
+		return model.ProductResponse{}, "", fmt.Errorf("%w: product not found", errs.NotFoundError)
+	}
+
+	return model.ProductResponse{}, current, nil
+}
+
+// UpdateById sends approved and rejected products back to review when key fields change.
+// Automated checks rerun on every change of a non-draft product.
+func (s *ProductService) UpdateById(ctx context.Context, userId int64, role string, req *model.UpdateProductRequest) (model.ProductResponse, error) {
+	p, err := s.repo.GetProductById(ctx, req.Id)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = ensureNotDeleted(p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	// The stock is only written when the seller changes it, the one sent back unchanged
+	// may already be stale because of orders placed since it was read.
+	var stock *int
+	if req.Stock != nil && *req.Stock != p.Stock {
+		if err = ensureStockEditable(p); err != nil {
+			return model.ProductResponse{}, err
+		}
+		stock = req.Stock
+	}
+
+	description := p.Description
+	if req.Description != nil {
+		description = *req.Description
+	}
+
+	keyFieldsChanged := p.CategoryId != *req.CategoryId || p.Name != *req.Name || p.Description != description
+	reviewRequired := keyFieldsChanged || p.Price != *req.Price
+
+	p.CategoryId = *req.CategoryId
+	p.Name = *req.Name
+	p.Description = description
+	p.Price = *req.Price
+	if p.Price < p.LowestPrice30d {
+		p.LowestPrice30d = p.Price
+	}
+	if req.ImageUrl != nil {
+		p.ImageUrl = *req.ImageUrl
+	}
+
+	if keyFieldsChanged && (p.Status == model.ProductStatusApproved || p.Status == model.ProductStatusRejected) {
+		p.Status = model.ProductStatusPendingReview
+		p.RejectionReason = ""
+	}
+
+	var flags []model.ModerationFlag
+	if reviewRequired && p.Status != model.ProductStatusDraft {
+		var reason string
+		flags, reason, err = s.review(ctx, p)
+		if err != nil {
+			return model.ProductResponse{}, err
+		}
+
+		if reason != "" {
+			p.Status = model.ProductStatusRejected
+			p.RejectionReason = reason
+		}
+	}
+
+	before, err := s.repo.UpdateProductById(ctx, p, stock, userId)
 	if err != nil {
 		return model.ProductResponse{}, err
 	}
 
+	if reviewRequired && p.Status != model.ProductStatusDraft {
+		if err = s.moderator.SaveFlags(ctx, p.Id, flags); err != nil {
+			return model.ProductResponse{}, err
+		}
+	}
+
 	s.cache.Del(ctx, "products:all")
-	return model.ProductResponse{
-		Id:          p.Id,
-		SellerId:    p.SellerId,
-		CategoryId:  p.CategoryId,
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       p.Price,
-		Stock:       p.Stock,
-	}, nil
+	if err = s.index.Index(ctx, p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if before == 0 {
+		s.detectRestock(p)
+	}
+	if p.Stock < before {
+		s.lowStock.StockDecreased([]int64{p.Id})
+	}
+
+	return toProductResponse(p), nil
 }
 
-func (s *ProductService) DeleteById(ctx context.Context, req *model.DeleteProductRequest) error {
-	err := s.repo.DeleteProductById(ctx, req.Id)
+func (s *ProductService) DeleteById(ctx context.Context, userId int64, role string, req *model.DeleteProductRequest) error {
+	p, err := s.repo.GetProductById(ctx, req.Id)
+	if err != nil {
+		return err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return err
+	}
+
+	if err = ensureNotDeleted(p); err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteProductById(ctx, req.Id)
 	if err != nil {
 		return err
 	}
 
 	s.cache.Del(ctx, "products:all")
-	return nil
+	return s.index.Delete(ctx, req.Id)
+}
+
+// Archive hides the product from the catalog without deleting it.
+func (s *ProductService) Archive(ctx context.Context, userId int64, role string, req *model.ArchiveProductRequest) (model.ProductResponse, error) {
+	p, err := s.repo.GetProductById(ctx, req.Id)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = ensureNotDeleted(p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if p.ArchivedAt != nil {
+		return model.ProductResponse{}, fmt.Errorf("%w: product is already archived", errs.ConflictError)
+	}
+
+	if err = s.repo.ArchiveProduct(ctx, req.Id); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	now := time.Now()
+	p.ArchivedAt = &now
+
+	s.cache.Del(ctx, "products:all")
+	if err = s.index.Index(ctx, p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	return toProductResponse(p), nil
+}
+
+// Restore returns an archived or deleted product to its moderation status.
+func (s *ProductService) Restore(ctx context.Context, userId int64, role string, req *model.RestoreProductRequest) (model.ProductResponse, error) {
+	p, err := s.repo.GetProductById(ctx, req.Id)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if p.ArchivedAt == nil && p.DeletedAt == nil {
+		return model.ProductResponse{}, fmt.Errorf("%w: product is neither archived nor deleted", errs.ConflictError)
+	}
+
+	if err = s.repo.RestoreProduct(ctx, req.Id); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	p.ArchivedAt = nil
+	p.DeletedAt = nil
+
+	s.cache.Del(ctx, "products:all")
+	if err = s.index.Index(ctx, p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	return toProductResponse(p), nil
+}
+
+func (s *ProductService) Restock(ctx context.Context, userId int64, role string, req *model.RestockProductRequest) (model.ProductResponse, error) {
+	p, err := s.repo.GetProductById(ctx, req.Id)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = ensureNotDeleted(p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = ensureStockEditable(p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	p.Stock, err = s.repo.RestockProduct(ctx, req.Id, req.Quantity, userId)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	s.cache.Del(ctx, "products:all")
+	if err = s.index.Index(ctx, p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	// The new stock comes from the database, so concurrent restocks agree on which one ended the sell out.
+	if p.Stock-req.Quantity <= 0 {
+		s.detectRestock(p)
+	}
+
+	return toProductResponse(p), nil
 }
 
-func (s *ProductService) GetAll(ctx context.Context, page, limit int) ([]model.ProductResponse, int64, error) {
+type productPage struct {
+	Products   []model.ProductResponse `json:"products"`
+	NextCursor string                  `json:"next_cursor"`
+}
+
+func (s *ProductService) GetAll(ctx context.Context, limit int, req *model.ListProductsRequest) ([]model.ProductResponse, string, error) {
 	const cachedKey = "products:all"
 
-	cachedData, err := s.cache.Get(ctx, cachedKey).Bytes()
-	if err == nil {
-		var products []model.ProductResponse
-		err = json.Unmarshal(cachedData, &products)
+	order := req.Sort
+	if order == "" {
+		order = model.SortNewest
+	}
+
+	// Only first pages are cached, one hash field per sort and page size,
+	// so a single DEL of the key invalidates all of them.
+	cachedField := fmt.Sprintf("%s:%d", order, limit)
+	if req.Cursor == "" {
+		cachedData, err := s.cache.HGet(ctx, cachedKey, cachedField).Bytes()
 		if err == nil {
-			return products, int64(len(products)), nil
+			var page productPage
+			err = json.Unmarshal(cachedData, &page)
+			if err == nil {
+				if err = s.applyDiscounts(ctx, page.Products); err != nil {
+					return []model.ProductResponse{}, "", err
+				}
+				return page.Products, page.NextCursor, nil
+			}
 		}
 	}
 
-	offset := (page - 1) * limit
-	products, total, err := s.repo.GetAllProducts(ctx, offset, limit)
+	cursor, err := decodeCursor(order, req.Cursor)
 	if err != nil {
-		return []model.ProductResponse{}, 0, err
+		return []model.ProductResponse{}, "", err
 	}
 
-	var result []model.ProductResponse
-	for _, product := range *products {
-		resp := model.ProductResponse{
-			Id:          product.Id,
-			SellerId:    product.SellerId,
-			CategoryId:  product.CategoryId,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			Stock:       product.Stock,
+	products, err := s.repo.GetAllProducts(ctx, order, cursor, limit+1)
+	if err != nil {
+		return []model.ProductResponse{}, "", err
+	}
+
+	result, nextCursor, err := toProductPage(order, *products, limit)
+	if err != nil {
+		return []model.ProductResponse{}, "", err
+	}
+
+	if req.Cursor == "" {
+		dataToCache, err := json.Marshal(productPage{Products: result, NextCursor: nextCursor})
+		if err != nil {
+			return []model.ProductResponse{}, "", err
+		}
+
+		cacheExpiration := 5 * time.Minute
+		s.cache.HSet(ctx, cachedKey, cachedField, string(dataToCache))
+		s.cache.Expire(ctx, cachedKey, cacheExpiration)
+	}
+
+	// Discounts start and end on their own schedule, so the cache keeps base prices only.
+	if err = s.applyDiscounts(ctx, result); err != nil {
+		return []model.ProductResponse{}, "", err
+	}
+
+	return result, nextCursor, nil
+}
+
+func (s *ProductService) Search(ctx context.Context, limit int, req *model.SearchProductsRequest) ([]model.ProductResponse, string, error) {
+	order := req.Sort
+	if order == "" {
+		order = model.SortNewest
+	}
+
+	cursor, err := decodeCursor(order, req.Cursor)
+	if err != nil {
+		return []model.ProductResponse{}, "", err
+	}
+
+	products, err := s.index.Search(ctx, search.Query{
+		Text:       req.Text,
+		CategoryId: req.CategoryId,
+		Min:        req.Min,
+		Max:        req.Max,
+		Sort:       order,
+		Cursor:     cursor,
+		Limit:      limit + 1,
+	})
+	if err != nil {
+		return []model.ProductResponse{}, "", err
+	}
+
+	result, nextCursor, err := toProductPage(order, *products, limit)
+	if err != nil {
+		return []model.ProductResponse{}, "", err
+	}
+
+	if err = s.applyDiscounts(ctx, result); err != nil {
+		return []model.ProductResponse{}, "", err
+	}
+
+	return result, nextCursor, nil
+}
+
+// SellerProducts lists the seller's own products in any status with their views and sales.
+func (s *ProductService) SellerProducts(ctx context.Context, sellerId int64, limit int, req *model.SellerProductsRequest) ([]model.SellerProductResponse, string, error) {
+	order := req.Sort
+	if order == "" {
+		order = model.SortNewest
+	}
+
+	cursor, err := decodeCursor(order, req.Cursor)
+	if err != nil {
+		return []model.SellerProductResponse{}, "", err
+	}
+
+	filter := model.SellerProductFilter{
+		State:      req.State,
+		Status:     req.Status,
+		Stock:      req.Stock,
+		CategoryId: req.CategoryId,
+	}
+
+	products, err := s.repo.GetSellerProducts(ctx, sellerId, &filter, order, cursor, limit+1)
+	if err != nil {
+		return []model.SellerProductResponse{}, "", err
+	}
+
+	page := *products
+	var nextCursor string
+	if len(page) > limit {
+		page = page[:limit]
+		nextCursor, err = utils.EncodeCursor(model.NewProductCursor(order, &page[limit-1].Product))
+		if err != nil {
+			return []model.SellerProductResponse{}, "", err
 		}
+	}
+
+	var result []model.SellerProductResponse
+	for _, p := range page {
+		result = append(result, model.SellerProductResponse{
+			ProductResponse: toProductResponse(&p.Product),
+			Views:           p.ViewCount,
+			UnitsSold:       p.UnitsSold,
+			Revenue:         p.Revenue,
+		})
+	}
+
+	responses := make([]*model.ProductResponse, 0, len(result))
+	for i := range result {
+		responses = append(responses, &result[i].ProductResponse)
+	}
+	if err = s.pricer.ApplyDiscounts(ctx, responses); err != nil {
+		return []model.SellerProductResponse{}, "", err
+	}
+
+	return result, nextCursor, nil
+}
+
+// PriceHistory returns the price timeline of the product with the same visibility rules as GetById.
+func (s *ProductService) PriceHistory(ctx context.Context, userId int64, role string, req *model.PriceHistoryRequest) ([]model.PricePointResponse, error) {
+	p, err := s.repo.GetProductById(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.Listed() && p.SellerId != userId && role != policy.RoleAdmin {
+		return nil, fmt.Errorf("%w: product not found", errs.NotFoundError)
+	}
+
+	history, err := s.repo.GetPriceHistory(ctx, p.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]model.PricePointResponse, 0, len(*history))
+	for _, change := range *history {
+		res = append(res, model.PricePointResponse{
+			Price:     change.Price,
+			ChangedAt: change.ChangedAt,
+		})
+	}
+
+	return res, nil
+}
+
+// Related returns the listed products similar to the given one.
+func (s *ProductService) Related(ctx context.Context, limit int, req *model.RecommendationsRequest) ([]model.ProductResponse, error) {
+	return s.recommended(ctx, limit, req.Id, s.recommender.Related)
+}
+
+// BoughtTogether returns the listed products most often ordered together with the given one.
+func (s *ProductService) BoughtTogether(ctx context.Context, limit int, req *model.RecommendationsRequest) ([]model.ProductResponse, error) {
+	return s.recommended(ctx, limit, req.Id, s.recommender.BoughtTogether)
+}
+
+func (s *ProductService) recommended(
+	ctx context.Context,
+	limit int,
+	productId int64,
+	pick func(ctx context.Context, p *model.Product, limit int) ([]int64, error),
+) ([]model.ProductResponse, error) {
+	p, err := s.repo.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.Listed() {
+		return nil, fmt.Errorf("%w: product not found", errs.NotFoundError)
+	}
+
+	ids, err := pick(ctx, p, limit)
+	if err != nil {
+		return nil, err
+	}
 
-		result = append(result, resp)
+	products, err := s.listedByIds(ctx, ids)
+	if err != nil {
+		return nil, err
 	}
 
-	dataToCache, err := json.Marshal(result)
+	return s.toPricedResponses(ctx, products)
+}
+
+// RecentlyViewed returns the listed products the user viewed last, most recent first.
+func (s *ProductService) RecentlyViewed(ctx context.Context, userId int64, limit int) ([]model.ProductResponse, error) {
+	ids, err := s.views.Recent(ctx, userId, limit)
 	if err != nil {
-		return []model.ProductResponse{}, 0, err
+		return nil, err
 	}
 
-	cacheExpiration := 5 * time.Minute
-	s.cache.Set(ctx, cachedKey, string(dataToCache), cacheExpiration)
+	return s.ListedByIds(ctx, ids)
+}
+
+// ListedByIds returns the listed products among ids with their current prices, keeping the order of ids.
+func (s *ProductService) ListedByIds(ctx context.Context, ids []int64) ([]model.ProductResponse, error) {
+	products, err := s.listedByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
 
-	return result, total, nil
+	return s.toPricedResponses(ctx, products)
 }
 
-func (s *ProductService) Search(ctx context.Context, page, limit int, req *model.SearchProductsRequest) ([]model.ProductResponse, int64, error) {
-	offset := (page - 1) * limit
-	products, total, err := s.repo.SearchProducts(
-		ctx,
-		req.Text,
-		req.CategoryId,
-		req.Min, req.Max,
-		offset, limit,
+// Feed blends bestsellers of the categories the user viewed recently with the overall
+// bestsellers and new arrivals. Products the user has just viewed are left out.
+func (s *ProductService) Feed(ctx context.Context, userId int64, limit int) ([]model.FeedItemResponse, error) {
+	recentIds, err := s.views.Recent(ctx, userId, feedHistorySize)
+	if err != nil {
+		return nil, err
+	}
+
+	viewed, err := s.listedByIds(ctx, recentIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every viewed category is a source of its own, so the feed alternates between them.
+	var sources []feedSource
+	for _, categoryId := range recentCategories(viewed, feedCategories) {
+		bestsellers, err := s.repo.GetCategoryBestsellers(ctx, categoryId, limit)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, feedSource{name: model.FeedSourceViewedCategory, products: *bestsellers})
+	}
+
+	bestsellers, err := s.repo.GetAllProducts(ctx, model.SortPopular, nil, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	arrivals, err := s.repo.GetAllProducts(ctx, model.SortNewest, nil, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]struct{}, len(viewed))
+	for _, p := range viewed {
+		seen[p.Id] = struct{}{}
+	}
+
+	sources = append(sources,
+		feedSource{name: model.FeedSourceBestseller, products: *bestsellers},
+		feedSource{name: model.FeedSourceNewArrival, products: *arrivals},
 	)
+	items := blendFeed(sources, seen, limit)
+
+	responses := make([]*model.ProductResponse, 0, len(items))
+	for i := range items {
+		responses = append(responses, &items[i].ProductResponse)
+	}
+	if err = s.pricer.ApplyDiscounts(ctx, responses); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// listedByIds loads the listed products among ids keeping the order of ids. Products
+// that left the catalog since the ids were stored are skipped.
+func (s *ProductService) listedByIds(ctx context.Context, ids []int64) ([]model.Product, error) {
+	if len(ids) == 0 {
+		return []model.Product{}, nil
+	}
+
+	products, err := s.repo.GetProductsByIds(ctx, ids)
 	if err != nil {
-		return []model.ProductResponse{}, 0, err
+		return nil, err
 	}
 
-	var result []model.ProductResponse
-	for _, product := range *products {
-		resp := model.ProductResponse{
-			Id:          product.Id,
-			SellerId:    product.SellerId,
-			CategoryId:  product.CategoryId,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			Stock:       product.Stock,
+	byId := make(map[int64]*model.Product, len(*products))
+	for i := range *products {
+		byId[(*products)[i].Id] = &(*products)[i]
+	}
+
+	res := make([]model.Product, 0, len(ids))
+	for _, id := range ids {
+		if product, ok := byId[id]; ok {
+			res = append(res, *product)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *ProductService) toPricedResponses(ctx context.Context, products []model.Product) ([]model.ProductResponse, error) {
+	res := make([]model.ProductResponse, 0, len(products))
+	for i := range products {
+		res = append(res, toProductResponse(&products[i]))
+	}
+
+	if err := s.applyDiscounts(ctx, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Submit sends a draft or a rejected product to the moderation queue. Products that
+// fail high severity automated checks are rejected right away.
+func (s *ProductService) Submit(ctx context.Context, userId int64, role string, req *model.SubmitProductRequest) (model.ProductResponse, error) {
+	p, err := s.repo.GetProductById(ctx, req.Id)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	flags, reason, err := s.review(ctx, p)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	t := submitTransition
+	if reason != "" {
+		t.to = model.ProductStatusRejected
+	}
+
+	if err = s.transition(ctx, p, t, reason); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = s.moderator.SaveFlags(ctx, p.Id, flags); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	return toProductResponse(p), nil
+}
+
+func (s *ProductService) Moderate(ctx context.Context, req *model.ModerateProductRequest) (model.ProductResponse, error) {
+	t, ok := moderationTransitions[req.Action]
+	if !ok {
+		return model.ProductResponse{}, fmt.Errorf("%w: unknown moderation action %q", errs.ValidationError, req.Action)
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if req.Action == model.ModerationActionApprove {
+		reason = ""
+	} else if reason == "" {
+		return model.ProductResponse{}, fmt.Errorf("%w: reason is required", errs.ValidationError)
+	}
+
+	p, err := s.repo.GetProductById(ctx, req.Id)
+	if err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	if err = s.transition(ctx, p, t, reason); err != nil {
+		return model.ProductResponse{}, err
+	}
+
+	return toProductResponse(p), nil
+}
+
+func (s *ProductService) ModerationQueue(ctx context.Context, limit int, req *model.ModerationQueueRequest) ([]model.ProductResponse, string, error) {
+	cursor, err := decodeCursor(model.SortOldest, req.Cursor)
+	if err != nil {
+		return []model.ProductResponse{}, "", err
+	}
+
+	products, err := s.repo.GetProductsByStatus(ctx, model.ProductStatusPendingReview, cursor, limit+1)
+	if err != nil {
+		return []model.ProductResponse{}, "", err
+	}
+
+	return toProductPage(model.SortOldest, *products, limit)
+}
+
+func (s *ProductService) transition(ctx context.Context, p *model.Product, t statusTransition, reason string) error {
+	if err := ensureNotDeleted(p); err != nil {
+		return err
+	}
+
+	if !slices.Contains(t.from, p.Status) {
+		return fmt.Errorf("%w: product in status %s can't be moved to %s", errs.ConflictError, p.Status, t.to)
+	}
+
+	if err := s.repo.UpdateProductStatus(ctx, p.Id, t.from, t.to, reason); err != nil {
+		return err
+	}
+
+	p.Status = t.to
+	p.RejectionReason = reason
+
+	s.cache.Del(ctx, "products:all")
+	return s.index.Index(ctx, p)
+}
+
+// review returns the raised flags and, if any of them is high severity,
+// the rejection reason built from their messages.
+func (s *ProductService) review(ctx context.Context, p *model.Product) ([]model.ModerationFlag, string, error) {
+	flags, err := s.moderator.Review(ctx, p)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var messages []string
+	for _, flag := range flags {
+		if flag.Severity == model.SeverityHigh {
+			messages = append(messages, flag.Message)
+		}
+	}
+
+	return flags, strings.Join(messages, "; "), nil
+}
+
+// detectRestock tells the listener about a product that was sold out before the change.
+func (s *ProductService) detectRestock(p *model.Product) {
+	if p.Stock > 0 && p.Listed() {
+		s.restocks.Restocked(p.Id, p.Name)
+	}
+}
+
+func (s *ProductService) Reindex(ctx context.Context) error {
+	return s.index.Reindex(ctx)
+}
+
+func toProductResponse(p *model.Product) model.ProductResponse {
+	return model.ProductResponse{
+		Id:              p.Id,
+		SellerId:        p.SellerId,
+		Sku:             p.Sku,
+		Slug:            p.Slug,
+		CategoryId:      p.CategoryId,
+		Name:            p.Name,
+		Description:     p.Description,
+		Price:           p.Price,
+		CurrentPrice:    p.Price,
+		LowestPrice30d:  p.LowestPrice30d,
+		Rating:          p.Rating,
+		ReviewCount:     p.ReviewCount,
+		Stock:           p.Stock,
+		Bundle:          p.Bundle,
+		ImageUrl:        p.ImageUrl,
+		Status:          p.Status,
+		RejectionReason: p.RejectionReason,
+		ArchivedAt:      p.ArchivedAt,
+		DeletedAt:       p.DeletedAt,
+	}
+}
+
+func (s *ProductService) applyDiscounts(ctx context.Context, products []model.ProductResponse) error {
+	responses := make([]*model.ProductResponse, 0, len(products))
+	for i := range products {
+		responses = append(responses, &products[i])
+	}
+
+	return s.pricer.ApplyDiscounts(ctx, responses)
+}
+
+func ensureNotDeleted(p *model.Product) error {
+	if p.DeletedAt != nil {
+		return fmt.Errorf("%w: product is deleted, restore it first", errs.ConflictError)
+	}
+
+	return nil
+}
+
+// ensureStockEditable rejects direct stock changes of warehoused products, their stock
+// is the sum over warehouses and changes per warehouse only, and of bundles, whose
+// stock comes from their components.
+func ensureStockEditable(p *model.Product) error {
+	if p.Warehoused {
+		return fmt.Errorf("%w: stock of product %d is managed per warehouse", errs.ConflictError, p.Id)
+	}
+
+	if p.Bundle {
+		return fmt.Errorf("%w: stock of bundle %d comes from its components", errs.ConflictError, p.Id)
+	}
+
+	return nil
+}
+
+// toProductPage expects up to limit+1 products, the extra one only signals that
+// there is a next page.
+func toProductPage(order string, products []model.Product, limit int) ([]model.ProductResponse, string, error) {
+	var nextCursor string
+	if len(products) > limit {
+		products = products[:limit]
+		cursor, err := utils.EncodeCursor(model.NewProductCursor(order, &products[limit-1]))
+		if err != nil {
+			return []model.ProductResponse{}, "", err
 		}
+		nextCursor = cursor
+	}
+
+	var result []model.ProductResponse
+	for _, product := range products {
+		result = append(result, toProductResponse(&product))
+	}
+
+	return result, nextCursor, nil
+}
+
+func decodeCursor(order, raw string) (*model.ProductCursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	cursor := new(model.ProductCursor)
+	if err := utils.DecodeCursor(raw, cursor); err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", errs.ValidationError)
+	}
 
-		result = append(result, resp)
+	if cursor.Sort != order {
+		return nil, fmt.Errorf("%w: cursor does not match sort", errs.ValidationError)
 	}
 
-	return result, total, nil
+	return cursor, nil
 }
@@ -9,17 +9,31 @@ import (
 	"time"
 
 	redismock "github.com/go-redis/redismock/v9"
+	"github.com/niklvrr/myMarketplace/internal/errs"
 	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/search"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
 	"github.com/redis/go-redis/v9"
 )
 
 type mockRepo struct {
 	CreateProductFn     func(ctx context.Context, product *model.Product) error
 	GetProductByIdFn    func(ctx context.Context, productId int64) (*model.Product, error)
-	UpdateProductByIdFn func(ctx context.Context, product *model.Product) error
+	ResolveSlugFn       func(ctx context.Context, slug string) (int64, string, error)
+	UpdateProductByIdFn func(ctx context.Context, product *model.Product, stock *int) error
 	DeleteProductByIdFn func(ctx context.Context, productId int64) error
-	GetAllProductsFn    func(ctx context.Context, offset, limit int) (*[]model.Product, int64, error)
-	SearchProductsFn    func(ctx context.Context, text *string, categoryId *int64, min, max *float64, offset, limit int) (*[]model.Product, int64, error)
+	RestockProductFn    func(ctx context.Context, productId int64, quantity int) (int, error)
+	ArchiveProductFn    func(ctx context.Context, productId int64) error
+	RestoreProductFn    func(ctx context.Context, productId int64) error
+	PriceHistoryFn      func(ctx context.Context, productId int64) (*[]model.PriceChange, error)
+	SellerProductsFn    func(ctx context.Context, sellerId int64, filter *model.SellerProductFilter, sort string, cursor *model.ProductCursor, limit int) (*[]model.SellerProduct, error)
+	GetAllProductsFn    func(ctx context.Context, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error)
+	SearchProductsFn    func(ctx context.Context, text *string, categoryId *int64, min, max *float64, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error)
+	ListAllProductsFn   func(ctx context.Context) (*[]model.Product, error)
+	UpdateStatusFn      func(ctx context.Context, productId int64, from []string, status, reason string) error
+	GetByStatusFn       func(ctx context.Context, status string, cursor *model.ProductCursor, limit int) (*[]model.Product, error)
+	GetByIdsFn          func(ctx context.Context, ids []int64) (*[]model.Product, error)
+	BestsellersFn       func(ctx context.Context, categoryId int64, limit int) (*[]model.Product, error)
 }
 
 func (m *mockRepo) CreateProduct(ctx context.Context, product *model.Product) error {
@@ -28,17 +42,123 @@ func (m *mockRepo) CreateProduct(ctx context.Context, product *model.Product) er
 func (m *mockRepo) GetProductById(ctx context.Context, productId int64) (*model.Product, error) {
 	return m.GetProductByIdFn(ctx, productId)
 }
-func (m *mockRepo) UpdateProductById(ctx context.Context, product *model.Product) error {
-	return m.UpdateProductByIdFn(ctx, product)
+func (m *mockRepo) ResolveProductSlug(ctx context.Context, slug string) (int64, string, error) {
+	return m.ResolveSlugFn(ctx, slug)
+}
+func (m *mockRepo) UpdateProductById(ctx context.Context, product *model.Product, stock *int, actorId int64) (int, error) {
+	before := product.Stock
+	if stock != nil {
+		product.Stock = *stock
+	}
+	return before, m.UpdateProductByIdFn(ctx, product, stock)
 }
 func (m *mockRepo) DeleteProductById(ctx context.Context, productId int64) error {
 	return m.DeleteProductByIdFn(ctx, productId)
 }
-func (m *mockRepo) GetAllProducts(ctx context.Context, offset, limit int) (*[]model.Product, int64, error) {
-	return m.GetAllProductsFn(ctx, offset, limit)
+func (m *mockRepo) RestockProduct(ctx context.Context, productId int64, quantity int, actorId int64) (int, error) {
+	return m.RestockProductFn(ctx, productId, quantity)
+}
+func (m *mockRepo) ArchiveProduct(ctx context.Context, productId int64) error {
+	return m.ArchiveProductFn(ctx, productId)
+}
+func (m *mockRepo) RestoreProduct(ctx context.Context, productId int64) error {
+	return m.RestoreProductFn(ctx, productId)
+}
+func (m *mockRepo) GetPriceHistory(ctx context.Context, productId int64) (*[]model.PriceChange, error) {
+	return m.PriceHistoryFn(ctx, productId)
+}
+func (m *mockRepo) GetSellerProducts(ctx context.Context, sellerId int64, filter *model.SellerProductFilter, sort string, cursor *model.ProductCursor, limit int) (*[]model.SellerProduct, error) {
+	return m.SellerProductsFn(ctx, sellerId, filter, sort, cursor, limit)
+}
+func (m *mockRepo) GetAllProducts(ctx context.Context, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
+	return m.GetAllProductsFn(ctx, sort, cursor, limit)
+}
+func (m *mockRepo) SearchProducts(ctx context.Context, text *string, categoryId *int64, min, max *float64, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
+	return m.SearchProductsFn(ctx, text, categoryId, min, max, sort, cursor, limit)
+}
+func (m *mockRepo) ListAllProducts(ctx context.Context) (*[]model.Product, error) {
+	return m.ListAllProductsFn(ctx)
+}
+func (m *mockRepo) UpdateProductStatus(ctx context.Context, productId int64, from []string, status, reason string) error {
+	return m.UpdateStatusFn(ctx, productId, from, status, reason)
+}
+func (m *mockRepo) GetProductsByStatus(ctx context.Context, status string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
+	return m.GetByStatusFn(ctx, status, cursor, limit)
+}
+func (m *mockRepo) GetProductsByIds(ctx context.Context, ids []int64) (*[]model.Product, error) {
+	return m.GetByIdsFn(ctx, ids)
+}
+func (m *mockRepo) GetCategoryBestsellers(ctx context.Context, categoryId int64, limit int) (*[]model.Product, error) {
+	return m.BestsellersFn(ctx, categoryId, limit)
+}
+
+type mockModerator struct {
+	ReviewFn    func(ctx context.Context, p *model.Product) ([]model.ModerationFlag, error)
+	SaveFlagsFn func(ctx context.Context, productId int64, flags []model.ModerationFlag) error
+}
+
+func (m *mockModerator) Review(ctx context.Context, p *model.Product) ([]model.ModerationFlag, error) {
+	if m.ReviewFn == nil {
+		return nil, nil
+	}
+	return m.ReviewFn(ctx, p)
+}
+func (m *mockModerator) SaveFlags(ctx context.Context, productId int64, flags []model.ModerationFlag) error {
+	if m.SaveFlagsFn == nil {
+		return nil
+	}
+	return m.SaveFlagsFn(ctx, productId, flags)
 }
-func (m *mockRepo) SearchProducts(ctx context.Context, text *string, categoryId *int64, min, max *float64, offset, limit int) (*[]model.Product, int64, error) {
-	return m.SearchProductsFn(ctx, text, categoryId, min, max, offset, limit)
+
+type mockPricer struct {
+	ApplyDiscountsFn func(ctx context.Context, products []*model.ProductResponse) error
+}
+
+func (m *mockPricer) ApplyDiscounts(ctx context.Context, products []*model.ProductResponse) error {
+	if m.ApplyDiscountsFn == nil {
+		return nil
+	}
+	return m.ApplyDiscountsFn(ctx, products)
+}
+
+type mockRecommender struct {
+	RelatedFn        func(ctx context.Context, p *model.Product, limit int) ([]int64, error)
+	BoughtTogetherFn func(ctx context.Context, p *model.Product, limit int) ([]int64, error)
+}
+
+func (m *mockRecommender) Related(ctx context.Context, p *model.Product, limit int) ([]int64, error) {
+	return m.RelatedFn(ctx, p, limit)
+}
+func (m *mockRecommender) BoughtTogether(ctx context.Context, p *model.Product, limit int) ([]int64, error) {
+	return m.BoughtTogetherFn(ctx, p, limit)
+}
+
+type mockViews struct {
+	recorded []int64
+	RecentFn func(ctx context.Context, userId int64, limit int) ([]int64, error)
+}
+
+func (m *mockViews) Record(userId, productId int64) {
+	m.recorded = append(m.recorded, productId)
+}
+func (m *mockViews) Recent(ctx context.Context, userId int64, limit int) ([]int64, error) {
+	return m.RecentFn(ctx, userId, limit)
+}
+
+type mockRestocks struct {
+	restocked []int64
+}
+
+func (m *mockRestocks) Restocked(productId int64, name string) {
+	m.restocked = append(m.restocked, productId)
+}
+
+type mockLowStock struct {
+	decreased []int64
+}
+
+func (m *mockLowStock) StockDecreased(productIds []int64) {
+	m.decreased = append(m.decreased, productIds...)
 }
 
 func TestProductService_Create(t *testing.T) {
@@ -50,7 +170,7 @@ func TestProductService_Create(t *testing.T) {
 	}
 	client, mock := redismock.NewClientMock()
 	mock.ExpectDel("products:all").SetVal(1)
-	s := NewProductService(repo, client)
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
 	req := &model.CreateProductRequest{
 		CategoryId:  2,
 		Name:        "P",
@@ -74,36 +194,143 @@ func TestProductService_GetById(t *testing.T) {
 	repo := &mockRepo{
 		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
 			if productId == 5 {
-				return &model.Product{Id: 5, SellerId: 2, CategoryId: 3, Name: "X", Price: 10, Stock: 1}, nil
+				return &model.Product{Id: 5, SellerId: 2, CategoryId: 3, Name: "X", Price: 10, Stock: 1, Status: model.ProductStatusApproved}, nil
+			}
+			if productId == 6 {
+				return &model.Product{Id: 6, SellerId: 2, Name: "Y", Status: model.ProductStatusRejected, RejectionReason: "blurry photo"}, nil
 			}
 			return nil, errors.New("not found")
 		},
 	}
+	views := &mockViews{}
 	client, _ := redismock.NewClientMock()
-	s := NewProductService(repo, client)
-	got, err := s.GetById(context.Background(), &model.GetProductsRequest{Id: 5})
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, views, &mockRestocks{}, &mockLowStock{})
+	got, err := s.GetById(context.Background(), 1, "user", &model.GetProductsRequest{Id: 5})
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
 	if got.Id != 5 || got.Name != "X" {
 		t.Fatalf("unexpected got: %+v", got)
 	}
-	_, err = s.GetById(context.Background(), &model.GetProductsRequest{Id: 7})
+	if _, err = s.GetById(context.Background(), 2, "seller", &model.GetProductsRequest{Id: 5}); err != nil || len(views.recorded) != 1 {
+		t.Fatalf("owner views must not be counted: %v %v", views.recorded, err)
+	}
+	_, err = s.GetById(context.Background(), 1, "user", &model.GetProductsRequest{Id: 7})
 	if err == nil {
 		t.Fatalf("expected error")
 	}
+	_, err = s.GetById(context.Background(), 1, "user", &model.GetProductsRequest{Id: 6})
+	if !errors.Is(err, errs.NotFoundError) {
+		t.Fatalf("expected not found for rejected product, got %v", err)
+	}
+	got, err = s.GetById(context.Background(), 2, "seller", &model.GetProductsRequest{Id: 6})
+	if err != nil || got.Status != model.ProductStatusRejected || got.RejectionReason != "blurry photo" {
+		t.Fatalf("owner should see rejection reason: %+v %v", got, err)
+	}
+}
+
+func TestProductService_GetBySlug(t *testing.T) {
+	repo := &mockRepo{
+		ResolveSlugFn: func(ctx context.Context, slug string) (int64, string, error) {
+			switch slug {
+			case "chaynik", "staryy-chaynik":
+				return 5, "chaynik", nil
+			case "skrytyy", "staryy-skrytyy":
+				return 6, "skrytyy", nil
+			}
+			return 0, "", nil
+		},
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			if productId == 5 {
+				return &model.Product{Id: 5, SellerId: 2, Name: "Чайник", Slug: "chaynik", Status: model.ProductStatusApproved}, nil
+			}
+			return &model.Product{Id: 6, SellerId: 2, Name: "Скрытый", Slug: "skrytyy", Status: model.ProductStatusDraft}, nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	s := NewProductService(repo, client, nil, &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+
+	tests := []struct {
+		name             string
+		userId           int64
+		slug             string
+		expectedId       int64
+		expectedRedirect string
+		expectedErr      error
+	}{
+		{name: "current slug", userId: 1, slug: "chaynik", expectedId: 5},
+		{name: "former slug", userId: 1, slug: "staryy-chaynik", expectedRedirect: "chaynik"},
+		{name: "unknown slug", userId: 1, slug: "net-takogo", expectedErr: errs.NotFoundError},
+		{name: "hidden product", userId: 1, slug: "skrytyy", expectedErr: errs.NotFoundError},
+		{name: "former slug of a hidden product", userId: 1, slug: "staryy-skrytyy", expectedErr: errs.NotFoundError},
+		{name: "owner follows a former slug", userId: 2, slug: "staryy-skrytyy", expectedRedirect: "skrytyy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, redirect, err := s.GetBySlug(context.Background(), tt.userId, "user", &model.GetProductBySlugRequest{Slug: tt.slug})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if got.Id != tt.expectedId || redirect != tt.expectedRedirect {
+				t.Fatalf("unexpected result: %+v %q", got, redirect)
+			}
+			if tt.expectedId != 0 && got.Slug != tt.slug {
+				t.Fatalf("the response must have the slug: %+v", got)
+			}
+		})
+	}
+}
+
+func TestProductService_GetById_AppliesDiscount(t *testing.T) {
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 2, Price: 100, Status: model.ProductStatusApproved}, nil
+		},
+	}
+	pricer := &mockPricer{
+		ApplyDiscountsFn: func(ctx context.Context, products []*model.ProductResponse) error {
+			if len(products) != 1 || products[0].CurrentPrice != 100 {
+				t.Fatalf("unexpected products: %+v", products)
+			}
+			discountId := int64(9)
+			products[0].CurrentPrice = 80
+			products[0].DiscountId = &discountId
+			return nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	s := NewProductService(repo, client, nil, &mockModerator{}, pricer, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	got, err := s.GetById(context.Background(), 1, "user", &model.GetProductsRequest{Id: 5})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if got.Price != 100 || got.CurrentPrice != 80 || got.DiscountId == nil || *got.DiscountId != 9 {
+		t.Fatalf("unexpected price: %+v", got)
+	}
+
+	pricer.ApplyDiscountsFn = func(ctx context.Context, products []*model.ProductResponse) error {
+		return errors.New("db")
+	}
+	if _, err = s.GetById(context.Background(), 1, "user", &model.GetProductsRequest{Id: 5}); err == nil {
+		t.Fatalf("expected pricing error")
+	}
 }
 
 func TestProductService_UpdateById(t *testing.T) {
 	repo := &mockRepo{
-		UpdateProductByIdFn: func(ctx context.Context, product *model.Product) error {
+		UpdateProductByIdFn: func(ctx context.Context, product *model.Product, stock *int) error {
 			product.Id = 33
 			return nil
 		},
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 12, Price: 200, LowestPrice30d: 180, Stock: 10}, nil
+		},
 	}
 	client, mock := redismock.NewClientMock()
 	mock.ExpectDel("products:all").SetVal(1)
-	s := NewProductService(repo, client)
+	lowStock := &mockLowStock{}
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, lowStock)
 	cat := int64(3)
 	name := "N"
 	desc := "D"
@@ -116,13 +343,62 @@ func TestProductService_UpdateById(t *testing.T) {
 		Price:       &price,
 		Stock:       &stock,
 	}
-	got, err := s.UpdateById(context.Background(), 12, req)
+	_, err := s.UpdateById(context.Background(), 13, "seller", req)
+	if !errors.Is(err, errs.NotOwnerError) {
+		t.Fatalf("expected not owner error, got %v", err)
+	}
+	got, err := s.UpdateById(context.Background(), 12, "seller", req)
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
 	if got.Id != 33 {
 		t.Fatalf("unexpected id: %d", got.Id)
 	}
+	if got.Price != 150 || got.LowestPrice30d != 150 {
+		t.Fatalf("a price drop must lower the 30 day minimum: %+v", got)
+	}
+	if len(lowStock.decreased) != 1 || lowStock.decreased[0] != 33 {
+		t.Fatalf("a stock decrease must be checked, got %v", lowStock.decreased)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestProductService_UpdateById_KeepsStock(t *testing.T) {
+	var written []*int
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 12, CategoryId: 3, Name: "N", Price: 100, Stock: 10}, nil
+		},
+		UpdateProductByIdFn: func(ctx context.Context, product *model.Product, stock *int) error {
+			written = append(written, stock)
+			return nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	mock.ExpectDel("products:all").SetVal(1)
+	lowStock := &mockLowStock{}
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, lowStock)
+
+	// The stock sent back as it was read, or not sent at all, is left to the database.
+	cat, name, price := int64(3), "New name", 100.0
+	stock := 10
+	for _, req := range []*model.UpdateProductRequest{
+		{Id: 1, CategoryId: &cat, Name: &name, Price: &price, Stock: &stock},
+		{Id: 1, CategoryId: &cat, Name: &name, Price: &price},
+	} {
+		if _, err := s.UpdateById(context.Background(), 12, "seller", req); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	if len(written) != 2 || written[0] != nil || written[1] != nil {
+		t.Fatalf("the stock must not be written, got %v", written)
+	}
+	if len(lowStock.decreased) != 0 {
+		t.Fatalf("unexpected stock check %v", lowStock.decreased)
+	}
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Fatalf("redis expectations: %v", err)
 	}
@@ -136,14 +412,20 @@ func TestProductService_DeleteById(t *testing.T) {
 			}
 			return errors.New("db")
 		},
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 12}, nil
+		},
 	}
 	client, mock := redismock.NewClientMock()
 	mock.ExpectDel("products:all").SetVal(1)
-	s := NewProductService(repo, client)
-	if err := s.DeleteById(context.Background(), &model.DeleteProductRequest{Id: 4}); err != nil {
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	if err := s.DeleteById(context.Background(), 13, "seller", &model.DeleteProductRequest{Id: 4}); !errors.Is(err, errs.NotOwnerError) {
+		t.Fatalf("expected not owner error, got %v", err)
+	}
+	if err := s.DeleteById(context.Background(), 12, "seller", &model.DeleteProductRequest{Id: 4}); err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
-	if err := s.DeleteById(context.Background(), &model.DeleteProductRequest{Id: 5}); err == nil {
+	if err := s.DeleteById(context.Background(), 1, "admin", &model.DeleteProductRequest{Id: 5}); err == nil {
 		t.Fatalf("expected error")
 	}
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -157,87 +439,795 @@ func TestProductService_GetAll_CacheHitAndMiss(t *testing.T) {
 		{Id: 2, SellerId: 1, CategoryId: 2, Name: "B", Price: 20, Stock: 3},
 	}
 	clientHit, mockHit := redismock.NewClientMock()
-	data, _ := json.Marshal(products)
-	mockHit.ExpectGet("products:all").SetVal(string(data))
-	sHit := NewProductService(nil, clientHit)
-	got, total, err := sHit.GetAll(context.Background(), 1, 20)
+	data, _ := json.Marshal(productPage{Products: products, NextCursor: "abc"})
+	mockHit.ExpectHGet("products:all", "newest:20").SetVal(string(data))
+	sHit := NewProductService(nil, clientHit, nil, &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	got, next, err := sHit.GetAll(context.Background(), 20, &model.ListProductsRequest{})
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
-	if total != int64(len(products)) || !reflect.DeepEqual(got, products) {
-		t.Fatalf("unexpected cached result got %+v %d", got, total)
+	if next != "abc" || !reflect.DeepEqual(got, products) {
+		t.Fatalf("unexpected cached result got %+v %s", got, next)
 	}
 	if err := mockHit.ExpectationsWereMet(); err != nil {
 		t.Fatalf("redis expectations: %v", err)
 	}
 
 	repo := &mockRepo{
-		GetAllProductsFn: func(ctx context.Context, offset, limit int) (*[]model.Product, int64, error) {
+		GetAllProductsFn: func(ctx context.Context, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
+			if sort != model.SortPriceAsc || cursor != nil || limit != 2 {
+				t.Fatalf("unexpected args %s %+v %d", sort, cursor, limit)
+			}
 			prod := []model.Product{
 				{Id: 3, SellerId: 2, CategoryId: 4, Name: "C", Price: 30, Stock: 2},
+				{Id: 4, SellerId: 2, CategoryId: 4, Name: "D", Price: 40, Stock: 2},
 			}
-			return &prod, 1, nil
+			return &prod, nil
 		},
 	}
 	clientMiss, mockMiss := redismock.NewClientMock()
-	mockMiss.ExpectGet("products:all").SetErr(redis.Nil)
-	expectedResult := []model.ProductResponse{{Id: 3, SellerId: 2, CategoryId: 4, Name: "C", Price: 30, Stock: 2}}
-	dataToCache, _ := json.Marshal(expectedResult)
-	mockMiss.ExpectSet("products:all", string(dataToCache), 5*time.Minute).SetVal("OK")
-	sMiss := NewProductService(repo, clientMiss)
-	got2, total2, err := sMiss.GetAll(context.Background(), 1, 20)
+	mockMiss.ExpectHGet("products:all", "price_asc:1").SetErr(redis.Nil)
+	expectedResult := []model.ProductResponse{{Id: 3, SellerId: 2, CategoryId: 4, Name: "C", Price: 30, CurrentPrice: 30, Stock: 2}}
+	expectedCursor, _ := utils.EncodeCursor(model.ProductCursor{Sort: model.SortPriceAsc, Id: 3, Price: 30})
+	dataToCache, _ := json.Marshal(productPage{Products: expectedResult, NextCursor: expectedCursor})
+	mockMiss.ExpectHSet("products:all", "price_asc:1", string(dataToCache)).SetVal(1)
+	mockMiss.ExpectExpire("products:all", 5*time.Minute).SetVal(true)
+	sMiss := NewProductService(repo, clientMiss, nil, &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	got2, next2, err := sMiss.GetAll(context.Background(), 1, &model.ListProductsRequest{Sort: model.SortPriceAsc})
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
-	if total2 != 1 || !reflect.DeepEqual(got2, expectedResult) {
-		t.Fatalf("unexpected result got %+v %d", got2, total2)
+	if next2 != expectedCursor || !reflect.DeepEqual(got2, expectedResult) {
+		t.Fatalf("unexpected result got %+v %s", got2, next2)
 	}
 	if err := mockMiss.ExpectationsWereMet(); err != nil {
 		t.Fatalf("redis expectations: %v", err)
 	}
 
 	repoErr := &mockRepo{
-		GetAllProductsFn: func(ctx context.Context, offset, limit int) (*[]model.Product, int64, error) {
-			return nil, 0, errors.New("db")
+		GetAllProductsFn: func(ctx context.Context, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
+			return nil, errors.New("db")
 		},
 	}
 	clientErr, _ := redismock.NewClientMock()
-	sErr := NewProductService(repoErr, clientErr)
-	_, _, err = sErr.GetAll(context.Background(), 1, 20)
+	sErr := NewProductService(repoErr, clientErr, nil, &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	_, _, err = sErr.GetAll(context.Background(), 20, &model.ListProductsRequest{})
 	if err == nil {
 		t.Fatalf("expected error")
 	}
 }
 
+func TestProductService_GetAll_Cursor(t *testing.T) {
+	cursor, _ := utils.EncodeCursor(model.ProductCursor{Sort: model.SortPopular, Id: 9, SoldCount: 15})
+	repo := &mockRepo{
+		GetAllProductsFn: func(ctx context.Context, sort string, c *model.ProductCursor, limit int) (*[]model.Product, error) {
+			if c == nil || c.Id != 9 || c.SoldCount != 15 {
+				t.Fatalf("unexpected cursor %+v", c)
+			}
+			return &[]model.Product{{Id: 10}}, nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	s := NewProductService(repo, client, nil, &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	got, next, err := s.GetAll(context.Background(), 20, &model.ListProductsRequest{Sort: model.SortPopular, Cursor: cursor})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(got) != 1 || next != "" {
+		t.Fatalf("unexpected result got %+v %s", got, next)
+	}
+
+	_, _, err = s.GetAll(context.Background(), 20, &model.ListProductsRequest{Sort: model.SortRating, Cursor: cursor})
+	if !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	_, _, err = s.GetAll(context.Background(), 20, &model.ListProductsRequest{Sort: model.SortPopular, Cursor: "%%%"})
+	if !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
 func TestProductService_Search(t *testing.T) {
 	repo := &mockRepo{
-		SearchProductsFn: func(ctx context.Context, text *string, categoryId *int64, min, max *float64, offset, limit int) (*[]model.Product, int64, error) {
+		SearchProductsFn: func(ctx context.Context, text *string, categoryId *int64, min, max *float64, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
 			prod := []model.Product{
 				{Id: 7, SellerId: 3, CategoryId: 5, Name: "S", Price: 99, Stock: 1},
 			}
-			return &prod, 1, nil
+			return &prod, nil
 		},
 	}
 	client, _ := redismock.NewClientMock()
-	s := NewProductService(repo, client)
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
 	text := "q"
 	req := &model.SearchProductsRequest{Text: &text}
-	got, total, err := s.Search(context.Background(), 1, 10, req)
+	got, next, err := s.Search(context.Background(), 10, req)
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
-	if total != 1 || len(got) != 1 || got[0].Id != 7 {
-		t.Fatalf("unexpected result: %+v %d", got, total)
+	if next != "" || len(got) != 1 || got[0].Id != 7 {
+		t.Fatalf("unexpected result: %+v %s", got, next)
 	}
 
 	repoErr := &mockRepo{
-		SearchProductsFn: func(ctx context.Context, text *string, categoryId *int64, min, max *float64, offset, limit int) (*[]model.Product, int64, error) {
-			return nil, 0, errors.New("db")
+		SearchProductsFn: func(ctx context.Context, text *string, categoryId *int64, min, max *float64, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
+			return nil, errors.New("db")
 		},
 	}
-	sErr := NewProductService(repoErr, client)
-	_, _, err = sErr.Search(context.Background(), 1, 10, req)
+	sErr := NewProductService(repoErr, client, search.NewPostgresIndex(repoErr), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	_, _, err = sErr.Search(context.Background(), 10, req)
 	if err == nil {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestProductService_MemoryIndex(t *testing.T) {
+	repo := &mockRepo{
+		ListAllProductsFn: func(ctx context.Context) (*[]model.Product, error) {
+			prod := []model.Product{
+				{Id: 1, SellerId: 1, CategoryId: 2, Name: "Red phone", Price: 100, Stock: 1, Status: model.ProductStatusApproved},
+			}
+			return &prod, nil
+		},
+		CreateProductFn: func(ctx context.Context, product *model.Product) error {
+			product.Id = 2
+			return nil
+		},
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: 2, SellerId: 3, CategoryId: 2, Name: "Blue phone", Price: 50, Stock: 2, Status: model.ProductStatusPendingReview}, nil
+		},
+		UpdateStatusFn: func(ctx context.Context, productId int64, from []string, status, reason string) error {
+			return nil
+		},
+		DeleteProductByIdFn: func(ctx context.Context, productId int64) error {
+			return nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	mock.ExpectDel("products:all").SetVal(1)
+	mock.ExpectDel("products:all").SetVal(1)
+	s := NewProductService(repo, client, search.NewMemoryIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	if err := s.Reindex(context.Background()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	_, err := s.Create(context.Background(), 3, &model.CreateProductRequest{CategoryId: 2, Name: "Blue phone", Price: 50, Stock: 2})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	text := "blue"
+	got, _, err := s.Search(context.Background(), 10, &model.SearchProductsRequest{Text: &text})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("pending product must not be searchable: %+v %v", got, err)
+	}
+
+	_, err = s.Moderate(context.Background(), &model.ModerateProductRequest{Id: 2, Action: model.ModerationActionApprove})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	text = "phone"
+	got, next, err := s.Search(context.Background(), 1, &model.SearchProductsRequest{Text: &text, Sort: model.SortPriceAsc})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(got) != 1 || got[0].Id != 2 || next == "" {
+		t.Fatalf("unexpected first page: %+v %s", got, next)
+	}
+	got, next, err = s.Search(context.Background(), 1, &model.SearchProductsRequest{Text: &text, Sort: model.SortPriceAsc, Cursor: next})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(got) != 1 || got[0].Id != 1 || next != "" {
+		t.Fatalf("unexpected second page: %+v %s", got, next)
+	}
+
+	if err := s.DeleteById(context.Background(), 1, "admin", &model.DeleteProductRequest{Id: 1}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	got, _, err = s.Search(context.Background(), 10, &model.SearchProductsRequest{Text: &text})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(got) != 1 || got[0].Id != 2 {
+		t.Fatalf("unexpected result after delete: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestProductService_UpdateById_ReReview(t *testing.T) {
+	var saved *model.Product
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 12, CategoryId: 3, Name: "N", Description: "D", Price: 100, Stock: 1, Status: model.ProductStatusApproved}, nil
+		},
+		UpdateProductByIdFn: func(ctx context.Context, product *model.Product, stock *int) error {
+			saved = product
+			return nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	mock.ExpectDel("products:all").SetVal(1)
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	cat := int64(3)
+	name := "N"
+	desc := "D"
+	price := 90.0
+	stock := 4
+	req := &model.UpdateProductRequest{Id: 1, CategoryId: &cat, Name: &name, Description: &desc, Price: &price, Stock: &stock}
+	got, err := s.UpdateById(context.Background(), 12, "seller", req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if got.Status != model.ProductStatusApproved || saved.Price != 90 {
+		t.Fatalf("price change must not trigger review: %+v", got)
+	}
+
+	newName := "Renamed"
+	req.Name = &newName
+	got, err = s.UpdateById(context.Background(), 12, "seller", req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if got.Status != model.ProductStatusPendingReview || saved.Status != model.ProductStatusPendingReview {
+		t.Fatalf("name change must trigger review: %+v", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestProductService_Moderate(t *testing.T) {
+	product := model.Product{Id: 4, SellerId: 2, Status: model.ProductStatusPendingReview}
+	var gotStatus, gotReason string
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			p := product
+			return &p, nil
+		},
+		UpdateStatusFn: func(ctx context.Context, productId int64, from []string, status, reason string) error {
+			gotStatus, gotReason = status, reason
+			return nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+
+	_, err := s.Moderate(context.Background(), &model.ModerateProductRequest{Id: 4, Action: model.ModerationActionReject})
+	if !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error for missing reason, got %v", err)
+	}
+
+	got, err := s.Moderate(context.Background(), &model.ModerateProductRequest{Id: 4, Action: model.ModerationActionReject, Reason: " no photos "})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if got.Status != model.ProductStatusRejected || gotStatus != model.ProductStatusRejected || gotReason != "no photos" {
+		t.Fatalf("unexpected result: %+v %s %s", got, gotStatus, gotReason)
+	}
+
+	_, err = s.Moderate(context.Background(), &model.ModerateProductRequest{Id: 4, Action: model.ModerationActionSuspend, Reason: "fake"})
+	if !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("expected conflict for suspending pending product, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestProductService_Submit(t *testing.T) {
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 2, Status: model.ProductStatusDraft}, nil
+		},
+		UpdateStatusFn: func(ctx context.Context, productId int64, from []string, status, reason string) error {
+			return nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+
+	_, err := s.Submit(context.Background(), 3, "seller", &model.SubmitProductRequest{Id: 1})
+	if !errors.Is(err, errs.NotOwnerError) {
+		t.Fatalf("expected forbidden for foreign product, got %v", err)
+	}
+
+	got, err := s.Submit(context.Background(), 2, "seller", &model.SubmitProductRequest{Id: 1})
+	if err != nil || got.Status != model.ProductStatusPendingReview {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+}
+
+func TestProductService_ModerationQueue(t *testing.T) {
+	repo := &mockRepo{
+		GetByStatusFn: func(ctx context.Context, status string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
+			if status != model.ProductStatusPendingReview || limit != 2 {
+				t.Fatalf("unexpected args %s %d", status, limit)
+			}
+			return &[]model.Product{{Id: 1}, {Id: 2}}, nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	s := NewProductService(repo, client, nil, &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	got, next, err := s.ModerationQueue(context.Background(), 1, &model.ModerationQueueRequest{})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(got) != 1 || next == "" {
+		t.Fatalf("unexpected result: %+v %s", got, next)
+	}
+}
+
+func TestProductService_ContentRules(t *testing.T) {
+	var saved []model.ModerationFlag
+	moderator := &mockModerator{
+		ReviewFn: func(ctx context.Context, p *model.Product) ([]model.ModerationFlag, error) {
+			return []model.ModerationFlag{
+				{Code: model.FlagCodeSuspiciousPrice, Severity: model.SeverityMedium, Message: "price"},
+				{Code: model.FlagCodeContactDetails, Severity: model.SeverityHigh, Message: "contacts"},
+			}, nil
+		},
+		SaveFlagsFn: func(ctx context.Context, productId int64, flags []model.ModerationFlag) error {
+			if productId != 21 {
+				t.Fatalf("unexpected product id %d", productId)
+			}
+			saved = flags
+			return nil
+		},
+	}
+	repo := &mockRepo{
+		CreateProductFn: func(ctx context.Context, product *model.Product) error {
+			product.Id = 21
+			return nil
+		},
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 9, Status: model.ProductStatusDraft}, nil
+		},
+		UpdateStatusFn: func(ctx context.Context, productId int64, from []string, status, reason string) error {
+			if status != model.ProductStatusRejected || reason != "contacts" {
+				t.Fatalf("unexpected transition %s %q", status, reason)
+			}
+			return nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	mock.ExpectDel("products:all").SetVal(1)
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), moderator, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+
+	got, err := s.Create(context.Background(), 9, &model.CreateProductRequest{Name: "P", Price: 1})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if got.Status != model.ProductStatusRejected || got.RejectionReason != "contacts" || len(saved) != 2 {
+		t.Fatalf("unexpected result: %+v %+v", got, saved)
+	}
+
+	got, err = s.Submit(context.Background(), 9, "seller", &model.SubmitProductRequest{Id: 21})
+	if err != nil || got.Status != model.ProductStatusRejected {
+		t.Fatalf("unexpected submit result: %+v %v", got, err)
+	}
+}
+
+func TestProductService_Restock(t *testing.T) {
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 5, Stock: 2, Status: model.ProductStatusApproved}, nil
+		},
+		RestockProductFn: func(ctx context.Context, productId int64, quantity int) (int, error) {
+			return 2 + quantity, nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	mock.ExpectDel("products:all").SetVal(1)
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+
+	_, err := s.Restock(context.Background(), 6, "seller", &model.RestockProductRequest{Id: 1, Quantity: 3})
+	if !errors.Is(err, errs.NotOwnerError) {
+		t.Fatalf("expected not owner error, got %v", err)
+	}
+
+	got, err := s.Restock(context.Background(), 5, "seller", &model.RestockProductRequest{Id: 1, Quantity: 3})
+	if err != nil || got.Stock != 5 {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+
+	got, err = s.Restock(context.Background(), 1, "admin", &model.RestockProductRequest{Id: 1, Quantity: 1})
+	if err != nil || got.Stock != 3 {
+		t.Fatalf("admin should restock any product: %+v %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestProductService_WarehousedStock(t *testing.T) {
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 5, CategoryId: 3, Name: "N", Stock: 4, Warehoused: true}, nil
+		},
+		RestockProductFn: func(ctx context.Context, productId int64, quantity int) (int, error) {
+			t.Fatalf("stock of a warehoused product must not be restocked directly")
+			return 0, nil
+		},
+		UpdateProductByIdFn: func(ctx context.Context, product *model.Product, stock *int) error {
+			return nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+
+	if _, err := s.Restock(context.Background(), 5, "seller", &model.RestockProductRequest{Id: 1, Quantity: 3}); !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+
+	cat, name, price := int64(3), "N", 10.0
+	stock := 9
+	req := &model.UpdateProductRequest{Id: 1, CategoryId: &cat, Name: &name, Price: &price, Stock: &stock}
+	if _, err := s.UpdateById(context.Background(), 5, "seller", req); !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+
+	stock = 4
+	if _, err := s.UpdateById(context.Background(), 5, "seller", req); err != nil {
+		t.Fatalf("an update keeping the stock must pass: %v", err)
+	}
+}
+
+func TestProductService_BundleStock(t *testing.T) {
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 5, CategoryId: 3, Name: "N", Stock: 2, Bundle: true}, nil
+		},
+		RestockProductFn: func(ctx context.Context, productId int64, quantity int) (int, error) {
+			t.Fatalf("stock of a bundle must not be restocked directly")
+			return 0, nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+
+	if _, err := s.Restock(context.Background(), 5, "seller", &model.RestockProductRequest{Id: 1, Quantity: 3}); !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+
+	cat, name, price := int64(3), "N", 10.0
+	stock := 9
+	req := &model.UpdateProductRequest{Id: 1, CategoryId: &cat, Name: &name, Price: &price, Stock: &stock}
+	if _, err := s.UpdateById(context.Background(), 5, "seller", req); !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+}
+
+func TestProductService_RestockDetection(t *testing.T) {
+	stock := 0
+	status := model.ProductStatusApproved
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return &model.Product{Id: productId, SellerId: 5, Stock: stock, Status: status}, nil
+		},
+		RestockProductFn: func(ctx context.Context, productId int64, quantity int) (int, error) {
+			return stock + quantity, nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	restocks := &mockRestocks{}
+	s := NewProductService(repo, client, search.NewMemoryIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, restocks, &mockLowStock{})
+
+	if _, err := s.Restock(context.Background(), 5, "seller", &model.RestockProductRequest{Id: 1, Quantity: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(restocks.restocked) != 1 || restocks.restocked[0] != 1 {
+		t.Fatalf("expected a restock of product 1, got %v", restocks.restocked)
+	}
+
+	stock = 2
+	if _, err := s.Restock(context.Background(), 5, "seller", &model.RestockProductRequest{Id: 1, Quantity: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(restocks.restocked) != 1 {
+		t.Fatalf("a product in stock must not be reported again: %v", restocks.restocked)
+	}
+
+	stock, status = 0, model.ProductStatusDraft
+	if _, err := s.Restock(context.Background(), 5, "seller", &model.RestockProductRequest{Id: 1, Quantity: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(restocks.restocked) != 1 {
+		t.Fatalf("unlisted products must not be reported: %v", restocks.restocked)
+	}
+}
+
+func TestProductService_SellerProducts(t *testing.T) {
+	category := int64(3)
+	repo := &mockRepo{
+		SellerProductsFn: func(ctx context.Context, sellerId int64, filter *model.SellerProductFilter, sort string, cursor *model.ProductCursor, limit int) (*[]model.SellerProduct, error) {
+			if sellerId != 7 || filter.Status != model.ProductStatusDraft || filter.Stock != model.StockFilterOutOfStock || *filter.CategoryId != 3 {
+				t.Fatalf("unexpected filter: %d %+v", sellerId, filter)
+			}
+			if sort != model.SortPriceAsc || limit != 2 {
+				t.Fatalf("unexpected page args: %s %d", sort, limit)
+			}
+			if cursor != nil {
+				return &[]model.SellerProduct{}, nil
+			}
+			return &[]model.SellerProduct{
+				{Product: model.Product{Id: 1, SellerId: 7, Price: 10, Status: model.ProductStatusDraft}, ViewCount: 4, UnitsSold: 2, Revenue: 20},
+				{Product: model.Product{Id: 2, SellerId: 7, Price: 20, Status: model.ProductStatusDraft}},
+			}, nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	s := NewProductService(repo, client, nil, &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	req := &model.SellerProductsRequest{
+		Status:     model.ProductStatusDraft,
+		Stock:      model.StockFilterOutOfStock,
+		CategoryId: &category,
+		Sort:       model.SortPriceAsc,
+	}
+	got, next, err := s.SellerProducts(context.Background(), 7, 1, req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(got) != 1 || got[0].Id != 1 || got[0].Views != 4 || got[0].UnitsSold != 2 || got[0].Revenue != 20 || next == "" {
+		t.Fatalf("unexpected first page: %+v %s", got, next)
+	}
+
+	req.Cursor = next
+	got, next, err = s.SellerProducts(context.Background(), 7, 1, req)
+	if err != nil || len(got) != 0 || next != "" {
+		t.Fatalf("unexpected second page: %+v %s %v", got, next, err)
+	}
+
+	req.Sort = model.SortNewest
+	if _, _, err = s.SellerProducts(context.Background(), 7, 1, req); !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error for cursor of another sort, got %v", err)
+	}
+}
+
+func TestProductService_ArchiveAndRestore(t *testing.T) {
+	deletedAt := time.Now()
+	products := map[int64]*model.Product{
+		1: {Id: 1, SellerId: 4, Name: "Lamp", Status: model.ProductStatusApproved},
+		2: {Id: 2, SellerId: 4, Name: "Desk", Status: model.ProductStatusApproved, DeletedAt: &deletedAt},
+	}
+	repo := &mockRepo{
+		ListAllProductsFn: func(ctx context.Context) (*[]model.Product, error) {
+			return &[]model.Product{*products[1]}, nil
+		},
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			p := *products[productId]
+			return &p, nil
+		},
+		ArchiveProductFn: func(ctx context.Context, productId int64) error {
+			return nil
+		},
+		RestoreProductFn: func(ctx context.Context, productId int64) error {
+			return nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	mock.ExpectDel("products:all").SetVal(1)
+	mock.ExpectDel("products:all").SetVal(1)
+	mock.ExpectDel("products:all").SetVal(1)
+	s := NewProductService(repo, client, search.NewMemoryIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	if err := s.Reindex(context.Background()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	text := "lamp"
+
+	got, err := s.Archive(context.Background(), 4, "seller", &model.ArchiveProductRequest{Id: 1})
+	if err != nil || got.ArchivedAt == nil {
+		t.Fatalf("unexpected archive result: %+v %v", got, err)
+	}
+	found, _, _ := s.Search(context.Background(), 10, &model.SearchProductsRequest{Text: &text})
+	if len(found) != 0 {
+		t.Fatalf("archived product must not be searchable: %+v", found)
+	}
+
+	_, err = s.Restore(context.Background(), 4, "seller", &model.RestoreProductRequest{Id: 1})
+	if !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("expected conflict for product that is not archived, got %v", err)
+	}
+
+	archivedAt := time.Now()
+	products[1].ArchivedAt = &archivedAt
+	got, err = s.Restore(context.Background(), 4, "seller", &model.RestoreProductRequest{Id: 1})
+	if err != nil || got.ArchivedAt != nil {
+		t.Fatalf("unexpected restore result: %+v %v", got, err)
+	}
+	found, _, _ = s.Search(context.Background(), 10, &model.SearchProductsRequest{Text: &text})
+	if len(found) != 1 {
+		t.Fatalf("restored product must be searchable again: %+v", found)
+	}
+
+	if _, err = s.Restore(context.Background(), 5, "seller", &model.RestoreProductRequest{Id: 2}); !errors.Is(err, errs.NotOwnerError) {
+		t.Fatalf("expected not owner error, got %v", err)
+	}
+
+	price, stock, name, cat := 10.0, 1, "Desk", int64(0)
+	_, err = s.UpdateById(context.Background(), 4, "seller", &model.UpdateProductRequest{Id: 2, CategoryId: &cat, Name: &name, Price: &price, Stock: &stock})
+	if !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("deleted product must not be updated, got %v", err)
+	}
+	if _, err = s.Archive(context.Background(), 4, "seller", &model.ArchiveProductRequest{Id: 2}); !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("deleted product must not be archived, got %v", err)
+	}
+	if err = s.DeleteById(context.Background(), 4, "seller", &model.DeleteProductRequest{Id: 2}); !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("deleted product must not be deleted twice, got %v", err)
+	}
+
+	got, err = s.GetById(context.Background(), 9, "user", &model.GetProductsRequest{Id: 2})
+	if !errors.Is(err, errs.NotFoundError) {
+		t.Fatalf("deleted product must be hidden from buyers, got %+v %v", got, err)
+	}
+
+	got, err = s.Restore(context.Background(), 1, "admin", &model.RestoreProductRequest{Id: 2})
+	if err != nil || got.DeletedAt != nil {
+		t.Fatalf("unexpected restore result: %+v %v", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestProductService_PriceHistory(t *testing.T) {
+	product := &model.Product{Id: 4, SellerId: 12, Status: model.ProductStatusApproved}
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return product, nil
+		},
+		PriceHistoryFn: func(ctx context.Context, productId int64) (*[]model.PriceChange, error) {
+			return &[]model.PriceChange{
+				{ProductId: productId, Price: 100, ChangedAt: day},
+				{ProductId: productId, Price: 80, ChangedAt: day.AddDate(0, 0, 10)},
+			}, nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, &mockRecommender{}, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+	req := &model.PriceHistoryRequest{Id: 4}
+
+	history, err := s.PriceHistory(context.Background(), 99, "user", req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(history) != 2 || history[0].Price != 100 || !history[1].ChangedAt.Equal(day.AddDate(0, 0, 10)) {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	product.Status = model.ProductStatusDraft
+	if _, err = s.PriceHistory(context.Background(), 99, "user", req); !errors.Is(err, errs.NotFoundError) {
+		t.Fatalf("expected not found for a hidden product, got %v", err)
+	}
+	if _, err = s.PriceHistory(context.Background(), 12, "seller", req); err != nil {
+		t.Fatalf("owner must see the history of a draft: %v", err)
+	}
+}
+
+func TestProductService_Related(t *testing.T) {
+	products := map[int64]*model.Product{
+		5: {Id: 5, CategoryId: 2, Status: model.ProductStatusApproved},
+		6: {Id: 6, CategoryId: 2, Status: model.ProductStatusDraft},
+	}
+	repo := &mockRepo{
+		GetProductByIdFn: func(ctx context.Context, productId int64) (*model.Product, error) {
+			return products[productId], nil
+		},
+		GetByIdsFn: func(ctx context.Context, ids []int64) (*[]model.Product, error) {
+			if !reflect.DeepEqual(ids, []int64{7, 8, 9}) {
+				t.Fatalf("unexpected ids: %v", ids)
+			}
+			// Product 8 has left the catalog since the recommendations were computed.
+			return &[]model.Product{{Id: 9, Price: 90}, {Id: 7, Price: 70}}, nil
+		},
+	}
+	recommender := &mockRecommender{
+		RelatedFn: func(ctx context.Context, p *model.Product, limit int) ([]int64, error) {
+			if p.Id != 5 || limit != 3 {
+				t.Fatalf("unexpected args: %d %d", p.Id, limit)
+			}
+			return []int64{7, 8, 9}, nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	s := NewProductService(repo, client, search.NewPostgresIndex(repo), &mockModerator{}, &mockPricer{}, recommender, &mockViews{}, &mockRestocks{}, &mockLowStock{})
+
+	got, err := s.Related(context.Background(), 3, &model.RecommendationsRequest{Id: 5})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(got) != 2 || got[0].Id != 7 || got[1].Id != 9 || got[0].CurrentPrice != 70 {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+
+	if _, err = s.Related(context.Background(), 3, &model.RecommendationsRequest{Id: 6}); !errors.Is(err, errs.NotFoundError) {
+		t.Fatalf("expected not found for unlisted product, got %v", err)
+	}
+}
+
+func TestProductService_RecentlyViewed(t *testing.T) {
+	repo := &mockRepo{
+		GetByIdsFn: func(ctx context.Context, ids []int64) (*[]model.Product, error) {
+			return &[]model.Product{{Id: 4}, {Id: 8}}, nil
+		},
+	}
+	views := &mockViews{
+		RecentFn: func(ctx context.Context, userId int64, limit int) ([]int64, error) {
+			if userId != 1 || limit != 20 {
+				t.Fatalf("unexpected args: %d %d", userId, limit)
+			}
+			return []int64{8, 3, 4}, nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	s := NewProductService(repo, client, nil, &mockModerator{}, &mockPricer{}, &mockRecommender{}, views, &mockRestocks{}, &mockLowStock{})
+
+	got, err := s.RecentlyViewed(context.Background(), 1, 20)
+	if err != nil || len(got) != 2 || got[0].Id != 8 || got[1].Id != 4 {
+		t.Fatalf("expected listed products in view order: %+v %v", got, err)
+	}
+}
+
+func TestProductService_Feed(t *testing.T) {
+	repo := &mockRepo{
+		GetByIdsFn: func(ctx context.Context, ids []int64) (*[]model.Product, error) {
+			return &[]model.Product{{Id: 1, CategoryId: 7}, {Id: 2, CategoryId: 7}, {Id: 3, CategoryId: 9}}, nil
+		},
+		BestsellersFn: func(ctx context.Context, categoryId int64, limit int) (*[]model.Product, error) {
+			if categoryId == 7 {
+				return &[]model.Product{{Id: 1}, {Id: 10}, {Id: 11}}, nil
+			}
+			return &[]model.Product{{Id: 20}}, nil
+		},
+		GetAllProductsFn: func(ctx context.Context, sort string, cursor *model.ProductCursor, limit int) (*[]model.Product, error) {
+			if sort == model.SortPopular {
+				return &[]model.Product{{Id: 10}, {Id: 30}}, nil
+			}
+			return &[]model.Product{{Id: 40}, {Id: 41}}, nil
+		},
+	}
+	views := &mockViews{
+		RecentFn: func(ctx context.Context, userId int64, limit int) ([]int64, error) {
+			return []int64{1, 2, 3}, nil
+		},
+	}
+	client, _ := redismock.NewClientMock()
+	s := NewProductService(repo, client, nil, &mockModerator{}, &mockPricer{}, &mockRecommender{}, views, &mockRestocks{}, &mockLowStock{})
+
+	got, err := s.Feed(context.Background(), 1, 6)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// Viewed product 1 and duplicate 10 are skipped, the sources take turns.
+	expected := []struct {
+		id     int64
+		source string
+	}{
+		{10, model.FeedSourceViewedCategory},
+		{20, model.FeedSourceViewedCategory},
+		{30, model.FeedSourceBestseller},
+		{40, model.FeedSourceNewArrival},
+		{11, model.FeedSourceViewedCategory},
+		{41, model.FeedSourceNewArrival},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("unexpected feed: %+v", got)
+	}
+	for i, e := range expected {
+		if got[i].Id != e.id || got[i].Source != e.source {
+			t.Fatalf("item %d: expected %d from %s, got %d from %s", i, e.id, e.source, got[i].Id, got[i].Source)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package questionService
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/policy"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
+)
+
+type IQuestionRepository interface {
+	CreateQuestion(ctx context.Context, q *model.Question) error
+	GetQuestionById(ctx context.Context, id int64) (*model.Question, error)
+	GetQuestionsByProduct(ctx context.Context, productId int64, sort string, cursor *model.QuestionCursor, limit int) (*[]model.Question, error)
+	GetUnansweredQuestions(ctx context.Context, sellerId int64, cursor *model.QuestionCursor, limit int) (*[]model.Question, error)
+	AnswerQuestion(ctx context.Context, id int64, answer string, answeredBy int64, at time.Time) error
+	DeleteQuestion(ctx context.Context, id int64) error
+	AddQuestionVote(ctx context.Context, questionId, userId int64) error
+	RemoveQuestionVote(ctx context.Context, questionId, userId int64) error
+}
+
+type IProductReader interface {
+	GetProductById(ctx context.Context, productId int64) (*model.Product, error)
+}
+
+type IContentModerator interface {
+	ReviewText(ctx context.Context, text string) ([]model.ModerationFlag, error)
+}
+
+type QuestionService struct {
+	repo      IQuestionRepository
+	products  IProductReader
+	moderator IContentModerator
+	now       func() time.Time
+}
+
+func NewQuestionService(repo IQuestionRepository, products IProductReader, moderator IContentModerator) *QuestionService {
+	return &QuestionService{
+		repo:      repo,
+		products:  products,
+		moderator: moderator,
+		now:       time.Now,
+	}
+}
+
+// Ask publishes a question about a product from the public catalog.
+func (s *QuestionService) Ask(ctx context.Context, userId int64, req *model.AskQuestionRequest) (model.QuestionResponse, error) {
+	p, err := s.products.GetProductById(ctx, req.ProductId)
+	if err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	if !p.Listed() {
+		return model.QuestionResponse{}, fmt.Errorf("%w: product %d", errs.NotFoundError, p.Id)
+	}
+
+	if err = s.moderate(ctx, req.Text); err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	q := model.Question{
+		ProductId: p.Id,
+		UserId:    userId,
+		Text:      req.Text,
+	}
+	if err = s.repo.CreateQuestion(ctx, &q); err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	return toQuestionResponse(&q), nil
+}
+
+func (s *QuestionService) List(ctx context.Context, limit int, req *model.ListQuestionsRequest) ([]model.QuestionResponse, string, error) {
+	order := req.Sort
+	if order == "" {
+		order = model.QuestionSortNewest
+	}
+
+	cursor, err := decodeCursor(order, req.Cursor)
+	if err != nil {
+		return []model.QuestionResponse{}, "", err
+	}
+
+	questions, err := s.repo.GetQuestionsByProduct(ctx, req.ProductId, order, cursor, limit+1)
+	if err != nil {
+		return []model.QuestionResponse{}, "", err
+	}
+
+	return toQuestionPage(order, *questions, limit)
+}
+
+// Inbox returns the unanswered questions about the seller's products, oldest first.
+func (s *QuestionService) Inbox(ctx context.Context, sellerId int64, limit int, req *model.QuestionInboxRequest) ([]model.QuestionResponse, string, error) {
+	cursor, err := decodeCursor(model.QuestionSortOldest, req.Cursor)
+	if err != nil {
+		return []model.QuestionResponse{}, "", err
+	}
+
+	questions, err := s.repo.GetUnansweredQuestions(ctx, sellerId, cursor, limit+1)
+	if err != nil {
+		return []model.QuestionResponse{}, "", err
+	}
+
+	return toQuestionPage(model.QuestionSortOldest, *questions, limit)
+}
+
+// Answer sets the answer of the product owner, a new answer replaces the previous one.
+func (s *QuestionService) Answer(ctx context.Context, userId int64, role string, req *model.AnswerQuestionRequest) (model.QuestionResponse, error) {
+	q, err := s.getQuestion(ctx, req.ProductId, req.Id)
+	if err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	p, err := s.products.GetProductById(ctx, q.ProductId)
+	if err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	if err = s.moderate(ctx, req.Text); err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	now := s.now()
+	if err = s.repo.AnswerQuestion(ctx, q.Id, req.Text, userId, now); err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	q.Answer = &req.Text
+	q.AnsweredBy = &userId
+	q.AnsweredAt = &now
+
+	return toQuestionResponse(q), nil
+}
+
+// Delete removes the question. The author and admins can delete it.
+func (s *QuestionService) Delete(ctx context.Context, userId int64, role string, req *model.QuestionActionRequest) error {
+	q, err := s.getQuestion(ctx, req.ProductId, req.Id)
+	if err != nil {
+		return err
+	}
+
+	if role != policy.RoleAdmin && q.UserId != userId {
+		return fmt.Errorf("%w: question %d belongs to another user", errs.NotOwnerError, q.Id)
+	}
+
+	return s.repo.DeleteQuestion(ctx, q.Id)
+}
+
+// Upvote counts the user's vote for the question once, authors can't vote for their own questions.
+func (s *QuestionService) Upvote(ctx context.Context, userId int64, req *model.QuestionActionRequest) (model.QuestionResponse, error) {
+	q, err := s.getQuestion(ctx, req.ProductId, req.Id)
+	if err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	if q.UserId == userId {
+		return model.QuestionResponse{}, fmt.Errorf("%w: can't vote for own question", errs.ForbiddenError)
+	}
+
+	if err = s.repo.AddQuestionVote(ctx, q.Id, userId); err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	return s.reload(ctx, q.Id)
+}
+
+func (s *QuestionService) RemoveUpvote(ctx context.Context, userId int64, req *model.QuestionActionRequest) (model.QuestionResponse, error) {
+	q, err := s.getQuestion(ctx, req.ProductId, req.Id)
+	if err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	if err = s.repo.RemoveQuestionVote(ctx, q.Id, userId); err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	return s.reload(ctx, q.Id)
+}
+
+// moderate rejects the text if the content checks raise a high severity flag.
+func (s *QuestionService) moderate(ctx context.Context, text string) error {
+	flags, err := s.moderator.ReviewText(ctx, text)
+	if err != nil {
+		return err
+	}
+
+	var messages []string
+	for _, flag := range flags {
+		if flag.Severity == model.SeverityHigh {
+			messages = append(messages, flag.Message)
+		}
+	}
+
+	if len(messages) > 0 {
+		return fmt.Errorf("%w: rejected by moderation: %s", errs.ValidationError, strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+// getQuestion loads the question and checks that it belongs to the product from the url.
+func (s *QuestionService) getQuestion(ctx context.Context, productId, id int64) (*model.Question, error) {
+	q, err := s.repo.GetQuestionById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.ProductId != productId {
+		return nil, fmt.Errorf("%w: question %d", errs.NotFoundError, id)
+	}
+
+	return q, nil
+}
+
+func (s *QuestionService) reload(ctx context.Context, id int64) (model.QuestionResponse, error) {
+	q, err := s.repo.GetQuestionById(ctx, id)
+	if err != nil {
+		return model.QuestionResponse{}, err
+	}
+
+	return toQuestionResponse(q), nil
+}
+
+func decodeCursor(order, raw string) (*model.QuestionCursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	cursor := new(model.QuestionCursor)
+	if err := utils.DecodeCursor(raw, cursor); err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", errs.ValidationError)
+	}
+
+	if cursor.Sort != order {
+		return nil, fmt.Errorf("%w: cursor does not match sort", errs.ValidationError)
+	}
+
+	return cursor, nil
+}
+
+func toQuestionPage(order string, questions []model.Question, limit int) ([]model.QuestionResponse, string, error) {
+	var nextCursor string
+	if len(questions) > limit {
+		questions = questions[:limit]
+		cursor, err := utils.EncodeCursor(model.NewQuestionCursor(order, &questions[limit-1]))
+		if err != nil {
+			return []model.QuestionResponse{}, "", err
+		}
+		nextCursor = cursor
+	}
+
+	result := make([]model.QuestionResponse, 0, len(questions))
+	for i := range questions {
+		result = append(result, toQuestionResponse(&questions[i]))
+	}
+
+	return result, nextCursor, nil
+}
+
+func toQuestionResponse(q *model.Question) model.QuestionResponse {
+	res := model.QuestionResponse{
+		Id:         q.Id,
+		ProductId:  q.ProductId,
+		UserId:     q.UserId,
+		Text:       q.Text,
+		AnsweredAt: q.AnsweredAt,
+		Upvotes:    q.Upvotes,
+		CreatedAt:  q.CreatedAt,
+	}
+	if q.Answer != nil {
+		res.Answer = *q.Answer
+	}
+
+	return res
+}
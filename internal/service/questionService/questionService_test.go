@@ -0,0 +1,257 @@
+package questionService
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
+)
+
+type mockRepo struct {
+	CreateQuestionFn         func(ctx context.Context, q *model.Question) error
+	GetQuestionByIdFn        func(ctx context.Context, id int64) (*model.Question, error)
+	GetQuestionsByProductFn  func(ctx context.Context, productId int64, sort string, cursor *model.QuestionCursor, limit int) (*[]model.Question, error)
+	GetUnansweredQuestionsFn func(ctx context.Context, sellerId int64, cursor *model.QuestionCursor, limit int) (*[]model.Question, error)
+	AnswerQuestionFn         func(ctx context.Context, id int64, answer string, answeredBy int64, at time.Time) error
+	DeleteQuestionFn         func(ctx context.Context, id int64) error
+	AddQuestionVoteFn        func(ctx context.Context, questionId, userId int64) error
+	RemoveQuestionVoteFn     func(ctx context.Context, questionId, userId int64) error
+}
+
+func (m *mockRepo) CreateQuestion(ctx context.Context, q *model.Question) error {
+	return m.CreateQuestionFn(ctx, q)
+}
+func (m *mockRepo) GetQuestionById(ctx context.Context, id int64) (*model.Question, error) {
+	return m.GetQuestionByIdFn(ctx, id)
+}
+func (m *mockRepo) GetQuestionsByProduct(ctx context.Context, productId int64, sort string, cursor *model.QuestionCursor, limit int) (*[]model.Question, error) {
+	return m.GetQuestionsByProductFn(ctx, productId, sort, cursor, limit)
+}
+func (m *mockRepo) GetUnansweredQuestions(ctx context.Context, sellerId int64, cursor *model.QuestionCursor, limit int) (*[]model.Question, error) {
+	return m.GetUnansweredQuestionsFn(ctx, sellerId, cursor, limit)
+}
+func (m *mockRepo) AnswerQuestion(ctx context.Context, id int64, answer string, answeredBy int64, at time.Time) error {
+	return m.AnswerQuestionFn(ctx, id, answer, answeredBy, at)
+}
+func (m *mockRepo) DeleteQuestion(ctx context.Context, id int64) error {
+	return m.DeleteQuestionFn(ctx, id)
+}
+func (m *mockRepo) AddQuestionVote(ctx context.Context, questionId, userId int64) error {
+	return m.AddQuestionVoteFn(ctx, questionId, userId)
+}
+func (m *mockRepo) RemoveQuestionVote(ctx context.Context, questionId, userId int64) error {
+	return m.RemoveQuestionVoteFn(ctx, questionId, userId)
+}
+
+type mockProducts struct {
+	products map[int64]*model.Product
+}
+
+func (m *mockProducts) GetProductById(ctx context.Context, productId int64) (*model.Product, error) {
+	p, ok := m.products[productId]
+	if !ok {
+		return nil, errors.New("product not found")
+	}
+	return p, nil
+}
+
+type mockModerator struct {
+	flags []model.ModerationFlag
+}
+
+func (m *mockModerator) ReviewText(ctx context.Context, text string) ([]model.ModerationFlag, error) {
+	return m.flags, nil
+}
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService(repo *mockRepo, moderator *mockModerator) *QuestionService {
+	products := &mockProducts{products: map[int64]*model.Product{
+		5: {Id: 5, SellerId: 2, Status: model.ProductStatusApproved},
+		6: {Id: 6, SellerId: 2, Status: model.ProductStatusPendingReview},
+	}}
+	s := NewQuestionService(repo, products, moderator)
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+func TestQuestionService_Ask(t *testing.T) {
+	tests := []struct {
+		name        string
+		productId   int64
+		flags       []model.ModerationFlag
+		expectedErr error
+	}{
+		{"success", 5, nil, nil},
+		{"low severity flag is allowed", 5, []model.ModerationFlag{{Code: "caps", Severity: model.SeverityLow}}, nil},
+		{"contacts rejected", 5, []model.ModerationFlag{{Code: "contacts", Severity: model.SeverityHigh, Message: "contact details (phone) in text"}}, errs.ValidationError},
+		{"product not listed", 6, nil, errs.NotFoundError},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			repo := &mockRepo{
+				CreateQuestionFn: func(ctx context.Context, q *model.Question) error {
+					created = true
+					if q.ProductId != 5 || q.UserId != 9 || q.Text != "is it waterproof?" {
+						t.Fatalf("unexpected question: %+v", q)
+					}
+					q.Id = 1
+					return nil
+				},
+			}
+			s := newTestService(repo, &mockModerator{flags: tt.flags})
+
+			got, err := s.Ask(context.Background(), 9, &model.AskQuestionRequest{ProductId: tt.productId, Text: "is it waterproof?"})
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) || created {
+					t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil || got.Id != 1 {
+				t.Fatalf("unexpected result: %+v %v", got, err)
+			}
+		})
+	}
+}
+
+func TestQuestionService_Answer(t *testing.T) {
+	answered := false
+	repo := &mockRepo{
+		GetQuestionByIdFn: func(ctx context.Context, id int64) (*model.Question, error) {
+			return &model.Question{Id: id, ProductId: 5, UserId: 9}, nil
+		},
+		AnswerQuestionFn: func(ctx context.Context, id int64, answer string, answeredBy int64, at time.Time) error {
+			answered = true
+			if answer != "yes" || answeredBy != 2 || !at.Equal(testNow) {
+				t.Fatalf("unexpected answer: %q %d %v", answer, answeredBy, at)
+			}
+			return nil
+		},
+	}
+	moderator := &mockModerator{}
+	s := newTestService(repo, moderator)
+
+	_, err := s.Answer(context.Background(), 3, "seller", &model.AnswerQuestionRequest{Id: 1, ProductId: 5, Text: "yes"})
+	if !errors.Is(err, errs.NotOwnerError) || answered {
+		t.Fatalf("expected not owner, got %v", err)
+	}
+
+	_, err = s.Answer(context.Background(), 2, "seller", &model.AnswerQuestionRequest{Id: 1, ProductId: 7, Text: "yes"})
+	if !errors.Is(err, errs.NotFoundError) || answered {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	moderator.flags = []model.ModerationFlag{{Severity: model.SeverityHigh, Message: "forbidden word"}}
+	_, err = s.Answer(context.Background(), 2, "seller", &model.AnswerQuestionRequest{Id: 1, ProductId: 5, Text: "yes"})
+	if !errors.Is(err, errs.ValidationError) || answered {
+		t.Fatalf("expected moderation rejection, got %v", err)
+	}
+
+	moderator.flags = nil
+	got, err := s.Answer(context.Background(), 2, "seller", &model.AnswerQuestionRequest{Id: 1, ProductId: 5, Text: "yes"})
+	if err != nil || !answered || got.Answer != "yes" || got.AnsweredAt == nil {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+}
+
+func TestQuestionService_Delete(t *testing.T) {
+	deleted := false
+	repo := &mockRepo{
+		GetQuestionByIdFn: func(ctx context.Context, id int64) (*model.Question, error) {
+			return &model.Question{Id: id, ProductId: 5, UserId: 9}, nil
+		},
+		DeleteQuestionFn: func(ctx context.Context, id int64) error {
+			deleted = true
+			return nil
+		},
+	}
+	s := newTestService(repo, &mockModerator{})
+
+	err := s.Delete(context.Background(), 10, "user", &model.QuestionActionRequest{Id: 1, ProductId: 5})
+	if !errors.Is(err, errs.NotOwnerError) || deleted {
+		t.Fatalf("expected not owner, got %v", err)
+	}
+
+	if err = s.Delete(context.Background(), 1, "admin", &model.QuestionActionRequest{Id: 1, ProductId: 5}); err != nil || !deleted {
+		t.Fatalf("admin should delete any question: %v", err)
+	}
+}
+
+func TestQuestionService_Upvote(t *testing.T) {
+	upvotes := 0
+	repo := &mockRepo{
+		GetQuestionByIdFn: func(ctx context.Context, id int64) (*model.Question, error) {
+			return &model.Question{Id: id, ProductId: 5, UserId: 9, Upvotes: upvotes}, nil
+		},
+		AddQuestionVoteFn: func(ctx context.Context, questionId, userId int64) error {
+			upvotes++
+			return nil
+		},
+	}
+	s := newTestService(repo, &mockModerator{})
+
+	if _, err := s.Upvote(context.Background(), 9, &model.QuestionActionRequest{Id: 1, ProductId: 5}); !errors.Is(err, errs.ForbiddenError) {
+		t.Fatalf("author must not upvote, got %v", err)
+	}
+
+	got, err := s.Upvote(context.Background(), 10, &model.QuestionActionRequest{Id: 1, ProductId: 5})
+	if err != nil || got.Upvotes != 1 {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+}
+
+func TestQuestionService_List(t *testing.T) {
+	repo := &mockRepo{
+		GetQuestionsByProductFn: func(ctx context.Context, productId int64, sort string, cursor *model.QuestionCursor, limit int) (*[]model.Question, error) {
+			if productId != 5 || sort != model.QuestionSortTop || limit != 3 {
+				t.Fatalf("unexpected args: %d %s %d", productId, sort, limit)
+			}
+			questions := []model.Question{
+				{Id: 3, ProductId: 5, Upvotes: 7},
+				{Id: 1, ProductId: 5, Upvotes: 3},
+				{Id: 2, ProductId: 5, Upvotes: 0},
+			}
+			return &questions, nil
+		},
+	}
+	s := newTestService(repo, &mockModerator{})
+
+	got, next, err := s.List(context.Background(), 2, &model.ListQuestionsRequest{ProductId: 5, Sort: model.QuestionSortTop})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+	var cursor model.QuestionCursor
+	if err = utils.DecodeCursor(next, &cursor); err != nil || cursor.Id != 1 || cursor.Upvotes != 3 {
+		t.Fatalf("unexpected cursor: %+v %v", cursor, err)
+	}
+
+	_, _, err = s.List(context.Background(), 2, &model.ListQuestionsRequest{ProductId: 5, Cursor: next})
+	if !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected cursor sort mismatch, got %v", err)
+	}
+}
+
+func TestQuestionService_Inbox(t *testing.T) {
+	repo := &mockRepo{
+		GetUnansweredQuestionsFn: func(ctx context.Context, sellerId int64, cursor *model.QuestionCursor, limit int) (*[]model.Question, error) {
+			if sellerId != 2 || cursor != nil || limit != 21 {
+				t.Fatalf("unexpected args: %d %+v %d", sellerId, cursor, limit)
+			}
+			questions := []model.Question{{Id: 4, ProductId: 5, CreatedAt: testNow}}
+			return &questions, nil
+		},
+	}
+	s := newTestService(repo, &mockModerator{})
+
+	got, next, err := s.Inbox(context.Background(), 2, 20, &model.QuestionInboxRequest{})
+	if err != nil || len(got) != 1 || next != "" {
+		t.Fatalf("unexpected result: %+v %q %v", got, next, err)
+	}
+}
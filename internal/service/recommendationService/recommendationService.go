@@ -0,0 +1,180 @@
+package recommendationService
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultSize           = 20
+	defaultMinCoPurchases = 2
+
+	relatedKeyPrefix        = "recommendations:related:"
+	boughtTogetherKeyPrefix = "recommendations:bought_together:"
+)
+
+type IRecommendationRepository interface {
+	EachCoPurchase(ctx context.Context, minOrders int, fn func(c *model.CoPurchase) error) error
+}
+
+type ICatalogReader interface {
+	ListAllProducts(ctx context.Context) (*[]model.Product, error)
+	GetCategoryBestsellers(ctx context.Context, categoryId int64, limit int) (*[]model.Product, error)
+}
+
+// RecommendationService precomputes "related" and "bought together" product lists
+// into Redis and serves them, falling back to bestsellers of the same category.
+type RecommendationService struct {
+	repo    IRecommendationRepository
+	catalog ICatalogReader
+	cache   *redis.Client
+	cfg     config.RecommendationConfig
+}
+
+func NewRecommendationService(repo IRecommendationRepository, catalog ICatalogReader, cache *redis.Client, cfg config.RecommendationConfig) *RecommendationService {
+	if cfg.Size <= 0 {
+		cfg.Size = defaultSize
+	}
+	if cfg.MinCoPurchases <= 0 {
+		cfg.MinCoPurchases = defaultMinCoPurchases
+	}
+
+	return &RecommendationService{
+		repo:    repo,
+		catalog: catalog,
+		cache:   cache,
+		cfg:     cfg,
+	}
+}
+
+// Related returns the ids of up to limit products similar to p.
+func (s *RecommendationService) Related(ctx context.Context, p *model.Product, limit int) ([]int64, error) {
+	return s.read(ctx, relatedKeyPrefix, p, limit)
+}
+
+// BoughtTogether returns the ids of up to limit products most often ordered together with p.
+func (s *RecommendationService) BoughtTogether(ctx context.Context, p *model.Product, limit int) ([]int64, error) {
+	return s.read(ctx, boughtTogetherKeyPrefix, p, limit)
+}
+
+// Rebuild recomputes the recommendations of every listed product and stores them in Redis.
+func (s *RecommendationService) Rebuild(ctx context.Context) error {
+	products, err := s.catalog.ListAllProducts(ctx)
+	if err != nil {
+		return err
+	}
+
+	catalog := newCatalog(*products)
+
+	coPurchases := make(map[int64][]model.CoPurchase)
+	err = s.repo.EachCoPurchase(ctx, s.cfg.MinCoPurchases, func(c *model.CoPurchase) error {
+		if catalog.listed(c.ProductId) && catalog.listed(c.RelatedId) {
+			coPurchases[c.ProductId] = append(coPurchases[c.ProductId], *c)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The lists outlive a couple of job runs, so a stuck job serves stale
+	// recommendations for a while before the bestseller fallback kicks in.
+	var ttl time.Duration
+	if s.cfg.Interval > 0 {
+		ttl = 3 * s.cfg.Interval
+	}
+
+	pipe := s.cache.Pipeline()
+	for _, p := range catalog.products {
+		bought := boughtTogether(coPurchases[p.id], s.cfg.Size)
+		related := catalog.related(p, s.cfg.Size)
+
+		if err = setIds(ctx, pipe, boughtTogetherKeyPrefix, p.id, catalog.fill(p, bought, s.cfg.Size), ttl); err != nil {
+			return err
+		}
+		if err = setIds(ctx, pipe, relatedKeyPrefix, p.id, catalog.fill(p, related, s.cfg.Size), ttl); err != nil {
+			return err
+		}
+	}
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *RecommendationService) read(ctx context.Context, prefix string, p *model.Product, limit int) ([]int64, error) {
+	data, err := s.cache.Get(ctx, prefix+strconv.FormatInt(p.Id, 10)).Bytes()
+	if err == nil {
+		var ids []int64
+		if err = json.Unmarshal(data, &ids); err != nil {
+			return nil, err
+		}
+
+		if len(ids) > limit {
+			ids = ids[:limit]
+		}
+		return ids, nil
+	}
+
+	if !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	return s.bestsellers(ctx, p, limit)
+}
+
+// bestsellers is the fallback for products the job hasn't processed yet.
+func (s *RecommendationService) bestsellers(ctx context.Context, p *model.Product, limit int) ([]int64, error) {
+	if p.CategoryId == 0 {
+		return []int64{}, nil
+	}
+
+	products, err := s.catalog.GetCategoryBestsellers(ctx, p.CategoryId, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, limit)
+	for _, bestseller := range *products {
+		if bestseller.Id != p.Id && len(ids) < limit {
+			ids = append(ids, bestseller.Id)
+		}
+	}
+
+	return ids, nil
+}
+
+func boughtTogether(pairs []model.CoPurchase, size int) []int64 {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Orders != pairs[j].Orders {
+			return pairs[i].Orders > pairs[j].Orders
+		}
+		return pairs[i].RelatedId < pairs[j].RelatedId
+	})
+
+	ids := make([]int64, 0, size)
+	for _, pair := range pairs {
+		if len(ids) == size {
+			break
+		}
+		ids = append(ids, pair.RelatedId)
+	}
+
+	return ids
+}
+
+func setIds(ctx context.Context, pipe redis.Pipeliner, prefix string, productId int64, ids []int64, ttl time.Duration) error {
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+
+	return pipe.Set(ctx, prefix+strconv.FormatInt(productId, 10), data, ttl).Err()
+}
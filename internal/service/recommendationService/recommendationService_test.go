@@ -0,0 +1,141 @@
+package recommendationService
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockRepo struct {
+	coPurchases []model.CoPurchase
+}
+
+func (m *mockRepo) EachCoPurchase(ctx context.Context, minOrders int, fn func(c *model.CoPurchase) error) error {
+	for i := range m.coPurchases {
+		if m.coPurchases[i].Orders < int64(minOrders) {
+			continue
+		}
+		if err := fn(&m.coPurchases[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mockCatalog struct {
+	ListAllProductsFn        func(ctx context.Context) (*[]model.Product, error)
+	GetCategoryBestsellersFn func(ctx context.Context, categoryId int64, limit int) (*[]model.Product, error)
+}
+
+func (m *mockCatalog) ListAllProducts(ctx context.Context) (*[]model.Product, error) {
+	return m.ListAllProductsFn(ctx)
+}
+func (m *mockCatalog) GetCategoryBestsellers(ctx context.Context, categoryId int64, limit int) (*[]model.Product, error) {
+	return m.GetCategoryBestsellersFn(ctx, categoryId, limit)
+}
+
+func testProducts() []model.Product {
+	return []model.Product{
+		{Id: 1, CategoryId: 1, Name: "Wireless headphones Sony", Price: 100, SoldCount: 50},
+		{Id: 2, CategoryId: 1, Name: "Wireless headphones JBL", Price: 90, SoldCount: 10},
+		{Id: 3, CategoryId: 1, Name: "Phone case", Price: 10, SoldCount: 100},
+		{Id: 4, CategoryId: 2, Name: "Headphones stand", Price: 20, SoldCount: 5},
+	}
+}
+
+func TestRecommendationService_Rebuild(t *testing.T) {
+	repo := &mockRepo{coPurchases: []model.CoPurchase{
+		{ProductId: 1, RelatedId: 4, Orders: 5},
+		{ProductId: 4, RelatedId: 1, Orders: 5},
+		{ProductId: 1, RelatedId: 3, Orders: 2},
+		{ProductId: 3, RelatedId: 1, Orders: 2},
+		// Below the threshold and a product that left the catalog.
+		{ProductId: 2, RelatedId: 4, Orders: 1},
+		{ProductId: 1, RelatedId: 99, Orders: 7},
+	}}
+	catalog := &mockCatalog{
+		ListAllProductsFn: func(ctx context.Context) (*[]model.Product, error) {
+			products := testProducts()
+			return &products, nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	s := NewRecommendationService(repo, catalog, client, config.RecommendationConfig{Interval: time.Hour, Size: 3})
+
+	ttl := 3 * time.Hour
+	expected := []struct {
+		id             string
+		boughtTogether string
+		related        string
+	}{
+		{"3", "[1,2]", "[2,1]"},
+		{"1", "[4,3,2]", "[2,3,4]"},
+		{"2", "[3,1]", "[1,3,4]"},
+		{"4", "[1]", "[2,1]"},
+	}
+	for _, e := range expected {
+		mock.ExpectSet(boughtTogetherKeyPrefix+e.id, []byte(e.boughtTogether), ttl).SetVal("OK")
+		mock.ExpectSet(relatedKeyPrefix+e.id, []byte(e.related), ttl).SetVal("OK")
+	}
+
+	if err := s.Rebuild(context.Background()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestRecommendationService_Read(t *testing.T) {
+	product := &model.Product{Id: 5, CategoryId: 1}
+	catalog := &mockCatalog{
+		GetCategoryBestsellersFn: func(ctx context.Context, categoryId int64, limit int) (*[]model.Product, error) {
+			if categoryId != 1 || limit != 3 {
+				t.Fatalf("unexpected args: %d %d", categoryId, limit)
+			}
+			return &[]model.Product{{Id: 8}, {Id: 5}, {Id: 6}}, nil
+		},
+	}
+	client, mock := redismock.NewClientMock()
+	s := NewRecommendationService(&mockRepo{}, catalog, client, config.RecommendationConfig{})
+
+	mock.ExpectGet(relatedKeyPrefix + "5").SetVal("[7,9,11]")
+	got, err := s.Related(context.Background(), product, 2)
+	if err != nil || !reflect.DeepEqual(got, []int64{7, 9}) {
+		t.Fatalf("unexpected precomputed result: %v %v", got, err)
+	}
+
+	mock.ExpectGet(boughtTogetherKeyPrefix + "5").RedisNil()
+	got, err = s.BoughtTogether(context.Background(), product, 2)
+	if err != nil || !reflect.DeepEqual(got, []int64{8, 6}) {
+		t.Fatalf("expected category bestsellers without the product itself, got %v %v", got, err)
+	}
+
+	mock.ExpectGet(relatedKeyPrefix + "5").SetErr(errors.New("connection refused"))
+	if _, err = s.Related(context.Background(), product, 2); err == nil {
+		t.Fatalf("expected cache error")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	headphones := &productFeatures{categoryId: 1, price: 100, tokens: tokenize("Беспроводные наушники Sony")}
+	other := &productFeatures{categoryId: 1, price: 100, tokens: tokenize("беспроводные НАУШНИКИ, sony!")}
+	if got := similarity(headphones, other); got < 0.999 {
+		t.Fatalf("identical products must score 1, got %v", got)
+	}
+
+	unrelated := &productFeatures{categoryId: 2, tokens: tokenize("Чехол для телефона")}
+	if got := similarity(headphones, unrelated); got != 0 {
+		t.Fatalf("unrelated products must score 0, got %v", got)
+	}
+
+	if _, ok := tokenize("a to be or USB")["usb"]; !ok || len(tokenize("a to be or USB")) != 1 {
+		t.Fatalf("short words must be dropped: %v", tokenize("a to be or USB"))
+	}
+}
@@ -0,0 +1,180 @@
+package recommendationService
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+// Weights of the similarity signals, a product of the same category with the
+// same words and price scores 1.
+const (
+	categoryWeight = 0.4
+	textWeight     = 0.4
+	priceWeight    = 0.2
+
+	minTokenLength = 3
+)
+
+type productFeatures struct {
+	id         int64
+	categoryId int64
+	price      float64
+	tokens     map[string]struct{}
+}
+
+// catalog holds the features of the listed products used to compute recommendations.
+type catalog struct {
+	products    []*productFeatures
+	byId        map[int64]*productFeatures
+	byCategory  map[int64][]*productFeatures
+	byToken     map[string][]*productFeatures
+	bestsellers map[int64][]int64
+}
+
+func newCatalog(products []model.Product) *catalog {
+	c := &catalog{
+		products:    make([]*productFeatures, 0, len(products)),
+		byId:        make(map[int64]*productFeatures, len(products)),
+		byCategory:  make(map[int64][]*productFeatures),
+		byToken:     make(map[string][]*productFeatures),
+		bestsellers: make(map[int64][]int64),
+	}
+
+	sorted := make([]model.Product, len(products))
+	copy(sorted, products)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].SoldCount != sorted[j].SoldCount {
+			return sorted[i].SoldCount > sorted[j].SoldCount
+		}
+		return sorted[i].Id < sorted[j].Id
+	})
+
+	for i := range sorted {
+		p := &sorted[i]
+		f := &productFeatures{
+			id:         p.Id,
+			categoryId: p.CategoryId,
+			price:      p.Price,
+			tokens:     tokenize(p.Name + " " + p.Description),
+		}
+
+		c.products = append(c.products, f)
+		c.byId[f.id] = f
+		if f.categoryId != 0 {
+			c.byCategory[f.categoryId] = append(c.byCategory[f.categoryId], f)
+			c.bestsellers[f.categoryId] = append(c.bestsellers[f.categoryId], f.id)
+		}
+		for token := range f.tokens {
+			c.byToken[token] = append(c.byToken[token], f)
+		}
+	}
+
+	return c
+}
+
+func (c *catalog) listed(id int64) bool {
+	_, ok := c.byId[id]
+	return ok
+}
+
+// related ranks the products that share the category or at least one word with p.
+func (c *catalog) related(p *productFeatures, size int) []int64 {
+	scores := make(map[int64]float64)
+	for _, candidate := range c.byCategory[p.categoryId] {
+		scores[candidate.id] = 0
+	}
+	for token := range p.tokens {
+		for _, candidate := range c.byToken[token] {
+			scores[candidate.id] = 0
+		}
+	}
+	delete(scores, p.id)
+
+	ids := make([]int64, 0, len(scores))
+	for id := range scores {
+		scores[id] = similarity(p, c.byId[id])
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	if len(ids) > size {
+		ids = ids[:size]
+	}
+	return ids
+}
+
+// fill tops ids up to size with bestsellers of the product category.
+func (c *catalog) fill(p *productFeatures, ids []int64, size int) []int64 {
+	if len(ids) >= size {
+		return ids
+	}
+
+	seen := make(map[int64]struct{}, len(ids)+1)
+	seen[p.id] = struct{}{}
+	for _, id := range ids {
+		seen[id] = struct{}{}
+	}
+
+	for _, id := range c.bestsellers[p.categoryId] {
+		if len(ids) == size {
+			break
+		}
+		if _, ok := seen[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func similarity(a, b *productFeatures) float64 {
+	score := textWeight * jaccard(a.tokens, b.tokens)
+	if a.categoryId != 0 && a.categoryId == b.categoryId {
+		score += categoryWeight
+	}
+	if a.price > 0 && b.price > 0 {
+		score += priceWeight * math.Min(a.price, b.price) / math.Max(a.price, b.price)
+	}
+
+	return score
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	common := 0
+	for token := range a {
+		if _, ok := b[token]; ok {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// tokenize splits the text into a set of lowercase words, short words are too common to be a signal.
+func tokenize(text string) map[string]struct{} {
+	tokens := make(map[string]struct{})
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(word) >= minTokenLength {
+			tokens[word] = struct{}{}
+		}
+	}
+
+	return tokens
+}
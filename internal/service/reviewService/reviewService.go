@@ -0,0 +1,318 @@
+package reviewService
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/policy"
+	"github.com/niklvrr/myMarketplace/internal/search"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultEditWindow = 30 * 24 * time.Hour
+
+type IReviewRepository interface {
+	HasCompletedPurchase(ctx context.Context, userId, productId int64) (bool, error)
+	CreateReview(ctx context.Context, review *model.Review) (bool, error)
+	GetReviewById(ctx context.Context, id int64) (*model.Review, error)
+	GetReviewsByProduct(ctx context.Context, productId int64, sort string, cursor *model.ReviewCursor, limit int) (*[]model.Review, error)
+	UpdateReview(ctx context.Context, review *model.Review) error
+	DeleteReview(ctx context.Context, review *model.Review) error
+	ReplyToReview(ctx context.Context, id int64, reply string, at time.Time) error
+	AddReviewVote(ctx context.Context, reviewId, userId int64) error
+	RemoveReviewVote(ctx context.Context, reviewId, userId int64) error
+}
+
+type IProductReader interface {
+	GetProductById(ctx context.Context, productId int64) (*model.Product, error)
+}
+
+type ReviewService struct {
+	repo     IReviewRepository
+	products IProductReader
+	cache    *redis.Client
+	index    search.SearchIndex
+	cfg      config.ReviewConfig
+	now      func() time.Time
+}
+
+func NewReviewService(repo IReviewRepository, products IProductReader, cache *redis.Client, index search.SearchIndex, cfg config.ReviewConfig) *ReviewService {
+	if cfg.EditWindow <= 0 {
+		cfg.EditWindow = defaultEditWindow
+	}
+
+	return &ReviewService{
+		repo:     repo,
+		products: products,
+		cache:    cache,
+		index:    index,
+		cfg:      cfg,
+		now:      time.Now,
+	}
+}
+
+// Create saves the review of a verified buyer: the user needs a completed order
+// containing the product and may review it only once.
+func (s *ReviewService) Create(ctx context.Context, userId int64, req *model.CreateReviewRequest) (model.ReviewResponse, error) {
+	p, err := s.products.GetProductById(ctx, req.ProductId)
+	if err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	if p.DeletedAt != nil {
+		return model.ReviewResponse{}, fmt.Errorf("%w: product %d", errs.NotFoundError, p.Id)
+	}
+
+	bought, err := s.repo.HasCompletedPurchase(ctx, userId, p.Id)
+	if err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	if !bought {
+		return model.ReviewResponse{}, fmt.Errorf("%w: only buyers with a completed order can review the product", errs.ForbiddenError)
+	}
+
+	review := model.Review{
+		ProductId: p.Id,
+		UserId:    userId,
+		Rating:    req.Rating,
+		Text:      req.Text,
+		Photos:    photosOrEmpty(req.Photos),
+	}
+
+	created, err := s.repo.CreateReview(ctx, &review)
+	if err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	if !created {
+		return model.ReviewResponse{}, fmt.Errorf("%w: product %d is already reviewed", errs.ConflictError, p.Id)
+	}
+
+	if err = s.refreshProduct(ctx, p.Id); err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	return s.toReviewResponse(&review), nil
+}
+
+func (s *ReviewService) List(ctx context.Context, limit int, req *model.ListReviewsRequest) ([]model.ReviewResponse, string, error) {
+	order := req.Sort
+	if order == "" {
+		order = model.ReviewSortNewest
+	}
+
+	var cursor *model.ReviewCursor
+	if req.Cursor != "" {
+		cursor = new(model.ReviewCursor)
+		if err := utils.DecodeCursor(req.Cursor, cursor); err != nil {
+			return []model.ReviewResponse{}, "", fmt.Errorf("%w: invalid cursor", errs.ValidationError)
+		}
+
+		if cursor.Sort != order {
+			return []model.ReviewResponse{}, "", fmt.Errorf("%w: cursor does not match sort", errs.ValidationError)
+		}
+	}
+
+	reviews, err := s.repo.GetReviewsByProduct(ctx, req.ProductId, order, cursor, limit+1)
+	if err != nil {
+		return []model.ReviewResponse{}, "", err
+	}
+
+	page := *reviews
+	var nextCursor string
+	if len(page) > limit {
+		page = page[:limit]
+		nextCursor, err = utils.EncodeCursor(model.NewReviewCursor(order, &page[limit-1]))
+		if err != nil {
+			return []model.ReviewResponse{}, "", err
+		}
+	}
+
+	result := make([]model.ReviewResponse, 0, len(page))
+	for i := range page {
+		result = append(result, s.toReviewResponse(&page[i]))
+	}
+
+	return result, nextCursor, nil
+}
+
+// Update edits the review. Only the author can do it and only within the edit window.
+func (s *ReviewService) Update(ctx context.Context, userId int64, req *model.UpdateReviewRequest) (model.ReviewResponse, error) {
+	review, err := s.getReview(ctx, req.ProductId, req.Id)
+	if err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	if review.UserId != userId {
+		return model.ReviewResponse{}, fmt.Errorf("%w: review %d belongs to another user", errs.NotOwnerError, review.Id)
+	}
+
+	if !s.now().Before(review.CreatedAt.Add(s.cfg.EditWindow)) {
+		return model.ReviewResponse{}, fmt.Errorf("%w: review %d can no longer be edited", errs.ForbiddenError, review.Id)
+	}
+
+	if req.Rating != nil {
+		review.Rating = *req.Rating
+	}
+	if req.Text != nil {
+		review.Text = *req.Text
+	}
+	if req.Photos != nil {
+		review.Photos = photosOrEmpty(*req.Photos)
+	}
+
+	if err = s.repo.UpdateReview(ctx, review); err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	if err = s.refreshProduct(ctx, review.ProductId); err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	return s.toReviewResponse(review), nil
+}
+
+// Delete removes the review. The author and admins can delete it at any time.
+func (s *ReviewService) Delete(ctx context.Context, userId int64, role string, req *model.DeleteReviewRequest) error {
+	review, err := s.getReview(ctx, req.ProductId, req.Id)
+	if err != nil {
+		return err
+	}
+
+	if role != policy.RoleAdmin && review.UserId != userId {
+		return fmt.Errorf("%w: review %d belongs to another user", errs.NotOwnerError, review.Id)
+	}
+
+	if err = s.repo.DeleteReview(ctx, review); err != nil {
+		return err
+	}
+
+	return s.refreshProduct(ctx, review.ProductId)
+}
+
+// Reply sets the public answer of the product owner to the review, a new reply replaces the previous one.
+func (s *ReviewService) Reply(ctx context.Context, userId int64, role string, req *model.ReplyReviewRequest) (model.ReviewResponse, error) {
+	review, err := s.getReview(ctx, req.ProductId, req.Id)
+	if err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	p, err := s.products.GetProductById(ctx, review.ProductId)
+	if err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	if err = policy.CanManageProduct(userId, role, p); err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	now := s.now()
+	if err = s.repo.ReplyToReview(ctx, review.Id, req.Text, now); err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	review.SellerReply = &req.Text
+	review.RepliedAt = &now
+
+	return s.toReviewResponse(review), nil
+}
+
+// Vote marks the review as helpful. Voting twice has no effect and authors can't vote for their own reviews.
+func (s *ReviewService) Vote(ctx context.Context, userId int64, req *model.VoteReviewRequest) (model.ReviewResponse, error) {
+	review, err := s.getReview(ctx, req.ProductId, req.Id)
+	if err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	if review.UserId == userId {
+		return model.ReviewResponse{}, fmt.Errorf("%w: can't vote for own review", errs.ForbiddenError)
+	}
+
+	if err = s.repo.AddReviewVote(ctx, review.Id, userId); err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	return s.reload(ctx, review.Id)
+}
+
+func (s *ReviewService) Unvote(ctx context.Context, userId int64, req *model.VoteReviewRequest) (model.ReviewResponse, error) {
+	review, err := s.getReview(ctx, req.ProductId, req.Id)
+	if err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	if err = s.repo.RemoveReviewVote(ctx, review.Id, userId); err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	return s.reload(ctx, review.Id)
+}
+
+// getReview loads the review and checks that it belongs to the product from the url.
+func (s *ReviewService) getReview(ctx context.Context, productId, id int64) (*model.Review, error) {
+	review, err := s.repo.GetReviewById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.ProductId != productId {
+		return nil, fmt.Errorf("%w: review %d", errs.NotFoundError, id)
+	}
+
+	return review, nil
+}
+
+func (s *ReviewService) reload(ctx context.Context, id int64) (model.ReviewResponse, error) {
+	review, err := s.repo.GetReviewById(ctx, id)
+	if err != nil {
+		return model.ReviewResponse{}, err
+	}
+
+	return s.toReviewResponse(review), nil
+}
+
+// refreshProduct drops cached listings and reindexes the product after its rating has changed.
+func (s *ReviewService) refreshProduct(ctx context.Context, productId int64) error {
+	s.cache.Del(ctx, "products:all")
+
+	p, err := s.products.GetProductById(ctx, productId)
+	if err != nil {
+		return err
+	}
+
+	return s.index.Index(ctx, p)
+}
+
+func (s *ReviewService) toReviewResponse(r *model.Review) model.ReviewResponse {
+	res := model.ReviewResponse{
+		Id:            r.Id,
+		ProductId:     r.ProductId,
+		UserId:        r.UserId,
+		Rating:        r.Rating,
+		Text:          r.Text,
+		Photos:        photosOrEmpty(r.Photos),
+		RepliedAt:     r.RepliedAt,
+		HelpfulCount:  r.HelpfulCount,
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+		EditableUntil: r.CreatedAt.Add(s.cfg.EditWindow),
+	}
+	if r.SellerReply != nil {
+		res.SellerReply = *r.SellerReply
+	}
+
+	return res
+}
+
+func photosOrEmpty(photos []string) []string {
+	if photos == nil {
+		return []string{}
+	}
+	return photos
+}
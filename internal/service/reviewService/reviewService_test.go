@@ -0,0 +1,310 @@
+package reviewService
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/niklvrr/myMarketplace/internal/search"
+	"github.com/niklvrr/myMarketplace/pkg/utils"
+)
+
+type mockRepo struct {
+	HasCompletedPurchaseFn func(ctx context.Context, userId, productId int64) (bool, error)
+	CreateReviewFn         func(ctx context.Context, review *model.Review) (bool, error)
+	GetReviewByIdFn        func(ctx context.Context, id int64) (*model.Review, error)
+	GetReviewsByProductFn  func(ctx context.Context, productId int64, sort string, cursor *model.ReviewCursor, limit int) (*[]model.Review, error)
+	UpdateReviewFn         func(ctx context.Context, review *model.Review) error
+	DeleteReviewFn         func(ctx context.Context, review *model.Review) error
+	ReplyToReviewFn        func(ctx context.Context, id int64, reply string, at time.Time) error
+	AddReviewVoteFn        func(ctx context.Context, reviewId, userId int64) error
+	RemoveReviewVoteFn     func(ctx context.Context, reviewId, userId int64) error
+}
+
+func (m *mockRepo) HasCompletedPurchase(ctx context.Context, userId, productId int64) (bool, error) {
+	return m.HasCompletedPurchaseFn(ctx, userId, productId)
+}
+func (m *mockRepo) CreateReview(ctx context.Context, review *model.Review) (bool, error) {
+	return m.CreateReviewFn(ctx, review)
+}
+func (m *mockRepo) GetReviewById(ctx context.Context, id int64) (*model.Review, error) {
+	return m.GetReviewByIdFn(ctx, id)
+}
+func (m *mockRepo) GetReviewsByProduct(ctx context.Context, productId int64, sort string, cursor *model.ReviewCursor, limit int) (*[]model.Review, error) {
+	return m.GetReviewsByProductFn(ctx, productId, sort, cursor, limit)
+}
+func (m *mockRepo) UpdateReview(ctx context.Context, review *model.Review) error {
+	return m.UpdateReviewFn(ctx, review)
+}
+func (m *mockRepo) DeleteReview(ctx context.Context, review *model.Review) error {
+	return m.DeleteReviewFn(ctx, review)
+}
+func (m *mockRepo) ReplyToReview(ctx context.Context, id int64, reply string, at time.Time) error {
+	return m.ReplyToReviewFn(ctx, id, reply, at)
+}
+func (m *mockRepo) AddReviewVote(ctx context.Context, reviewId, userId int64) error {
+	return m.AddReviewVoteFn(ctx, reviewId, userId)
+}
+func (m *mockRepo) RemoveReviewVote(ctx context.Context, reviewId, userId int64) error {
+	return m.RemoveReviewVoteFn(ctx, reviewId, userId)
+}
+
+type mockProducts struct {
+	products map[int64]*model.Product
+}
+
+func (m *mockProducts) GetProductById(ctx context.Context, productId int64) (*model.Product, error) {
+	p, ok := m.products[productId]
+	if !ok {
+		return nil, errors.New("product not found")
+	}
+	return p, nil
+}
+
+type mockIndex struct {
+	indexed []int64
+}
+
+func (m *mockIndex) Search(ctx context.Context, q search.Query) (*[]model.Product, error) {
+	return nil, nil
+}
+func (m *mockIndex) Index(ctx context.Context, product *model.Product) error {
+	m.indexed = append(m.indexed, product.Id)
+	return nil
+}
+func (m *mockIndex) Delete(ctx context.Context, productId int64) error { return nil }
+func (m *mockIndex) Reindex(ctx context.Context) error                 { return nil }
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func testProducts() *mockProducts {
+	deletedAt := testNow.Add(-time.Hour)
+	return &mockProducts{products: map[int64]*model.Product{
+		5: {Id: 5, SellerId: 2, Status: model.ProductStatusApproved, Rating: 4.5, ReviewCount: 2},
+		6: {Id: 6, SellerId: 2, Status: model.ProductStatusApproved, DeletedAt: &deletedAt},
+	}}
+}
+
+func newTestService(repo *mockRepo, index *mockIndex) (*ReviewService, redismock.ClientMock) {
+	client, mock := redismock.NewClientMock()
+	s := NewReviewService(repo, testProducts(), client, index, config.ReviewConfig{EditWindow: 72 * time.Hour})
+	s.now = func() time.Time { return testNow }
+	return s, mock
+}
+
+func TestReviewService_Create(t *testing.T) {
+	tests := []struct {
+		name        string
+		productId   int64
+		bought      bool
+		created     bool
+		expectedErr error
+	}{
+		{"verified buyer", 5, true, true, nil},
+		{"not a buyer", 5, false, true, errs.ForbiddenError},
+		{"second review", 5, true, false, errs.ConflictError},
+		{"deleted product", 6, true, true, errs.NotFoundError},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				HasCompletedPurchaseFn: func(ctx context.Context, userId, productId int64) (bool, error) {
+					if userId != 9 || productId != tt.productId {
+						t.Fatalf("unexpected args: %d %d", userId, productId)
+					}
+					return tt.bought, nil
+				},
+				CreateReviewFn: func(ctx context.Context, review *model.Review) (bool, error) {
+					if review.Photos == nil || review.Rating != 4 {
+						t.Fatalf("unexpected review: %+v", review)
+					}
+					review.Id = 1
+					review.CreatedAt = testNow
+					return tt.created, nil
+				},
+			}
+			index := &mockIndex{}
+			s, mock := newTestService(repo, index)
+			if tt.expectedErr == nil {
+				mock.ExpectDel("products:all").SetVal(1)
+			}
+
+			got, err := s.Create(context.Background(), 9, &model.CreateReviewRequest{ProductId: tt.productId, Rating: 4, Text: "good"})
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got.Id != 1 || !got.EditableUntil.Equal(testNow.Add(72*time.Hour)) {
+				t.Fatalf("unexpected response: %+v", got)
+			}
+			if len(index.indexed) != 1 || index.indexed[0] != 5 {
+				t.Fatalf("product must be reindexed: %v", index.indexed)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("redis expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestReviewService_Update(t *testing.T) {
+	rating := 2
+	tests := []struct {
+		name        string
+		userId      int64
+		productId   int64
+		createdAt   time.Time
+		expectedErr error
+	}{
+		{"within window", 9, 5, testNow.Add(-time.Hour), nil},
+		{"window expired", 9, 5, testNow.Add(-72 * time.Hour), errs.ForbiddenError},
+		{"other user", 10, 5, testNow.Add(-time.Hour), errs.NotOwnerError},
+		{"other product", 9, 7, testNow.Add(-time.Hour), errs.NotFoundError},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			updated := false
+			repo := &mockRepo{
+				GetReviewByIdFn: func(ctx context.Context, id int64) (*model.Review, error) {
+					return &model.Review{Id: id, ProductId: 5, UserId: 9, Rating: 5, Text: "old", CreatedAt: tt.createdAt}, nil
+				},
+				UpdateReviewFn: func(ctx context.Context, review *model.Review) error {
+					updated = true
+					if review.Rating != 2 || review.Text != "old" {
+						t.Fatalf("unexpected review: %+v", review)
+					}
+					return nil
+				},
+			}
+			s, mock := newTestService(repo, &mockIndex{})
+			mock.ExpectDel("products:all").SetVal(1)
+
+			_, err := s.Update(context.Background(), tt.userId, &model.UpdateReviewRequest{Id: 1, ProductId: tt.productId, Rating: &rating})
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) || updated {
+					t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil || !updated {
+				t.Fatalf("unexpected err: %v", err)
+			}
+		})
+	}
+}
+
+func TestReviewService_Delete(t *testing.T) {
+	repo := &mockRepo{
+		GetReviewByIdFn: func(ctx context.Context, id int64) (*model.Review, error) {
+			return &model.Review{Id: id, ProductId: 5, UserId: 9}, nil
+		},
+		DeleteReviewFn: func(ctx context.Context, review *model.Review) error { return nil },
+	}
+	s, mock := newTestService(repo, &mockIndex{})
+
+	err := s.Delete(context.Background(), 10, "user", &model.DeleteReviewRequest{Id: 1, ProductId: 5})
+	if !errors.Is(err, errs.NotOwnerError) {
+		t.Fatalf("expected not owner, got %v", err)
+	}
+
+	mock.ExpectDel("products:all").SetVal(1)
+	if err = s.Delete(context.Background(), 1, "admin", &model.DeleteReviewRequest{Id: 1, ProductId: 5}); err != nil {
+		t.Fatalf("admin should delete any review: %v", err)
+	}
+}
+
+func TestReviewService_Reply(t *testing.T) {
+	replied := false
+	repo := &mockRepo{
+		GetReviewByIdFn: func(ctx context.Context, id int64) (*model.Review, error) {
+			return &model.Review{Id: id, ProductId: 5, UserId: 9}, nil
+		},
+		ReplyToReviewFn: func(ctx context.Context, id int64, reply string, at time.Time) error {
+			replied = true
+			if reply != "thanks" || !at.Equal(testNow) {
+				t.Fatalf("unexpected reply: %q %v", reply, at)
+			}
+			return nil
+		},
+	}
+	s, _ := newTestService(repo, &mockIndex{})
+
+	_, err := s.Reply(context.Background(), 3, "seller", &model.ReplyReviewRequest{Id: 1, ProductId: 5, Text: "thanks"})
+	if !errors.Is(err, errs.NotOwnerError) || replied {
+		t.Fatalf("expected not owner, got %v", err)
+	}
+
+	got, err := s.Reply(context.Background(), 2, "seller", &model.ReplyReviewRequest{Id: 1, ProductId: 5, Text: "thanks"})
+	if err != nil || !replied || got.SellerReply != "thanks" || got.RepliedAt == nil {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+}
+
+func TestReviewService_Vote(t *testing.T) {
+	votes := 0
+	repo := &mockRepo{
+		GetReviewByIdFn: func(ctx context.Context, id int64) (*model.Review, error) {
+			return &model.Review{Id: id, ProductId: 5, UserId: 9, HelpfulCount: votes}, nil
+		},
+		AddReviewVoteFn: func(ctx context.Context, reviewId, userId int64) error {
+			votes++
+			return nil
+		},
+	}
+	s, _ := newTestService(repo, &mockIndex{})
+
+	if _, err := s.Vote(context.Background(), 9, &model.VoteReviewRequest{Id: 1, ProductId: 5}); !errors.Is(err, errs.ForbiddenError) {
+		t.Fatalf("author must not vote, got %v", err)
+	}
+
+	got, err := s.Vote(context.Background(), 10, &model.VoteReviewRequest{Id: 1, ProductId: 5})
+	if err != nil || got.HelpfulCount != 1 {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+}
+
+func TestReviewService_List(t *testing.T) {
+	repo := &mockRepo{
+		GetReviewsByProductFn: func(ctx context.Context, productId int64, sort string, cursor *model.ReviewCursor, limit int) (*[]model.Review, error) {
+			if productId != 5 || sort != model.ReviewSortHelpful || limit != 3 {
+				t.Fatalf("unexpected args: %d %s %d", productId, sort, limit)
+			}
+			reviews := []model.Review{
+				{Id: 3, ProductId: 5, HelpfulCount: 10},
+				{Id: 1, ProductId: 5, HelpfulCount: 4},
+				{Id: 2, ProductId: 5, HelpfulCount: 1},
+			}
+			return &reviews, nil
+		},
+	}
+	s, _ := newTestService(repo, &mockIndex{})
+
+	got, next, err := s.List(context.Background(), 2, &model.ListReviewsRequest{ProductId: 5, Sort: model.ReviewSortHelpful})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+	var cursor model.ReviewCursor
+	if err = utils.DecodeCursor(next, &cursor); err != nil || cursor.Id != 1 || cursor.HelpfulCount != 4 {
+		t.Fatalf("unexpected cursor: %+v %v", cursor, err)
+	}
+	if got[0].Photos == nil {
+		t.Fatalf("photos must be an empty list")
+	}
+
+	_, _, err = s.List(context.Background(), 2, &model.ListReviewsRequest{ProductId: 5, Cursor: next})
+	if !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected cursor sort mismatch, got %v", err)
+	}
+}
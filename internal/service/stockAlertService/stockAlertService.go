@@ -0,0 +1,164 @@
+package stockAlertService
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const (
+	defaultSubscriptionTTL = 90 * 24 * time.Hour
+	defaultBatchSize       = 500
+)
+
+type IStockSubscriptionRepository interface {
+	SubscribeStock(ctx context.Context, sub *model.StockSubscription) error
+	UnsubscribeStock(ctx context.Context, productId, userId int64) (bool, error)
+	GetStockSubscribers(ctx context.Context, productId int64, now time.Time, afterUserId int64, limit int) ([]int64, error)
+	DeleteStockSubscriptions(ctx context.Context, productId int64, userIds []int64) error
+	PurgeExpiredSubscriptions(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IProductReader interface {
+	GetProductById(ctx context.Context, productId int64) (*model.Product, error)
+}
+
+type INotifier interface {
+	Notify(ctx context.Context, notifications []model.Notification) error
+}
+
+// StockAlertService keeps back in stock subscriptions of buyers and notifies them
+// once the product is restocked.
+type StockAlertService struct {
+	repo     IStockSubscriptionRepository
+	products IProductReader
+	notifier INotifier
+	cfg      config.StockAlertConfig
+	now      func() time.Time
+}
+
+func NewStockAlertService(repo IStockSubscriptionRepository, products IProductReader, notifier INotifier, cfg config.StockAlertConfig) *StockAlertService {
+	if cfg.SubscriptionTTL <= 0 {
+		cfg.SubscriptionTTL = defaultSubscriptionTTL
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+
+	return &StockAlertService{
+		repo:     repo,
+		products: products,
+		notifier: notifier,
+		cfg:      cfg,
+		now:      time.Now,
+	}
+}
+
+// Subscribe records the user's subscription to a sold out product. Subscribing
+// again doesn't create a duplicate, it extends the expiry.
+func (s *StockAlertService) Subscribe(ctx context.Context, userId int64, req *model.StockSubscriptionRequest) (model.StockSubscriptionResponse, error) {
+	p, err := s.products.GetProductById(ctx, req.ProductId)
+	if err != nil {
+		return model.StockSubscriptionResponse{}, err
+	}
+
+	if !p.Listed() {
+		return model.StockSubscriptionResponse{}, fmt.Errorf("%w: product %d", errs.NotFoundError, p.Id)
+	}
+
+	if p.Stock > 0 {
+		return model.StockSubscriptionResponse{}, fmt.Errorf("%w: product %d is in stock", errs.ConflictError, p.Id)
+	}
+
+	now := s.now()
+	sub := model.StockSubscription{
+		ProductId: p.Id,
+		UserId:    userId,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.cfg.SubscriptionTTL),
+	}
+	if err = s.repo.SubscribeStock(ctx, &sub); err != nil {
+		return model.StockSubscriptionResponse{}, err
+	}
+
+	return model.StockSubscriptionResponse{
+		ProductId: sub.ProductId,
+		CreatedAt: sub.CreatedAt,
+		ExpiresAt: sub.ExpiresAt,
+	}, nil
+}
+
+func (s *StockAlertService) Unsubscribe(ctx context.Context, userId int64, req *model.StockSubscriptionRequest) error {
+	removed, err := s.repo.UnsubscribeStock(ctx, req.ProductId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return fmt.Errorf("%w: no subscription to product %d", errs.NotFoundError, req.ProductId)
+	}
+
+	return nil
+}
+
+// Restocked notifies the subscribers in the background, so the seller's request
+// doesn't wait for them.
+func (s *StockAlertService) Restocked(productId int64, name string) {
+	go func() {
+		if err := s.NotifySubscribers(context.Background(), productId, name); err != nil {
+			slog.Error("back in stock notification failed", "product_id", productId, "error", err)
+		}
+	}()
+}
+
+// NotifySubscribers sends the back in stock notification to the active subscribers
+// in batches. Every subscription fires once and is removed after the notification.
+func (s *StockAlertService) NotifySubscribers(ctx context.Context, productId int64, name string) error {
+	now := s.now()
+	var afterUserId int64
+	for {
+		userIds, err := s.repo.GetStockSubscribers(ctx, productId, now, afterUserId, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		if len(userIds) == 0 {
+			return nil
+		}
+
+		notifications := make([]model.Notification, 0, len(userIds))
+		for _, userId := range userIds {
+			id := productId
+			notifications = append(notifications, model.Notification{
+				UserId:    userId,
+				Kind:      model.NotificationBackInStock,
+				Title:     "Back in stock: " + name,
+				Body:      name + " is available again.",
+				ProductId: &id,
+			})
+		}
+
+		if err = s.notifier.Notify(ctx, notifications); err != nil {
+			return err
+		}
+
+		if err = s.repo.DeleteStockSubscriptions(ctx, productId, userIds); err != nil {
+			return err
+		}
+
+		if len(userIds) < s.cfg.BatchSize {
+			return nil
+		}
+		afterUserId = userIds[len(userIds)-1]
+	}
+}
+
+// PurgeExpired removes the subscriptions that expired without a restock.
+func (s *StockAlertService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.PurgeExpiredSubscriptions(ctx, s.now())
+}
@@ -0,0 +1,162 @@
+package stockAlertService
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockRepo struct {
+	subscribers []int64
+	saved       *model.StockSubscription
+	deleted     []int64
+	pages       int
+	removed     bool
+}
+
+func (m *mockRepo) SubscribeStock(ctx context.Context, sub *model.StockSubscription) error {
+	m.saved = sub
+	return nil
+}
+
+func (m *mockRepo) UnsubscribeStock(ctx context.Context, productId, userId int64) (bool, error) {
+	return m.removed, nil
+}
+
+func (m *mockRepo) GetStockSubscribers(ctx context.Context, productId int64, now time.Time, afterUserId int64, limit int) ([]int64, error) {
+	m.pages++
+	var page []int64
+	for _, id := range m.subscribers {
+		if id > afterUserId {
+			page = append(page, id)
+		}
+		if len(page) == limit {
+			break
+		}
+	}
+	return page, nil
+}
+
+func (m *mockRepo) DeleteStockSubscriptions(ctx context.Context, productId int64, userIds []int64) error {
+	m.deleted = append(m.deleted, userIds...)
+	return nil
+}
+
+func (m *mockRepo) PurgeExpiredSubscriptions(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+type mockProducts struct {
+	product *model.Product
+}
+
+func (m *mockProducts) GetProductById(ctx context.Context, productId int64) (*model.Product, error) {
+	return m.product, nil
+}
+
+type mockNotifier struct {
+	sent []model.Notification
+	err  error
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, notifications []model.Notification) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, notifications...)
+	return nil
+}
+
+func TestStockAlertService_Subscribe(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		product     model.Product
+		expectedErr error
+	}{
+		{
+			name:    "sold out",
+			product: model.Product{Id: 5, Stock: 0, Status: model.ProductStatusApproved},
+		},
+		{
+			name:        "in stock",
+			product:     model.Product{Id: 5, Stock: 3, Status: model.ProductStatusApproved},
+			expectedErr: errs.ConflictError,
+		},
+		{
+			name:        "not listed",
+			product:     model.Product{Id: 5, Stock: 0, Status: model.ProductStatusDraft},
+			expectedErr: errs.NotFoundError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{}
+			s := NewStockAlertService(repo, &mockProducts{product: &tt.product}, &mockNotifier{}, config.StockAlertConfig{SubscriptionTTL: 24 * time.Hour})
+			s.now = func() time.Time { return now }
+
+			sub, err := s.Subscribe(context.Background(), 9, &model.StockSubscriptionRequest{ProductId: 5})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr != nil {
+				if repo.saved != nil {
+					t.Fatalf("subscription must not be saved")
+				}
+				return
+			}
+
+			if repo.saved.UserId != 9 || !sub.ExpiresAt.Equal(now.Add(24*time.Hour)) {
+				t.Fatalf("unexpected subscription: %+v", sub)
+			}
+		})
+	}
+}
+
+func TestStockAlertService_Unsubscribe(t *testing.T) {
+	s := NewStockAlertService(&mockRepo{}, &mockProducts{}, &mockNotifier{}, config.StockAlertConfig{})
+
+	err := s.Unsubscribe(context.Background(), 9, &model.StockSubscriptionRequest{ProductId: 5})
+	if !errors.Is(err, errs.NotFoundError) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestStockAlertService_NotifySubscribers(t *testing.T) {
+	repo := &mockRepo{subscribers: []int64{1, 2, 3, 4, 5}}
+	notifier := &mockNotifier{}
+	s := NewStockAlertService(repo, &mockProducts{}, notifier, config.StockAlertConfig{BatchSize: 2})
+
+	if err := s.NotifySubscribers(context.Background(), 7, "Kettle"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo.pages != 3 {
+		t.Fatalf("expected 3 pages, got %d", repo.pages)
+	}
+	if len(notifier.sent) != 5 || len(repo.deleted) != 5 {
+		t.Fatalf("every subscriber must be notified once: sent %d, deleted %d", len(notifier.sent), len(repo.deleted))
+	}
+	n := notifier.sent[0]
+	if n.Kind != model.NotificationBackInStock || *n.ProductId != 7 {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+}
+
+func TestStockAlertService_NotifyFailure(t *testing.T) {
+	repo := &mockRepo{subscribers: []int64{1, 2}}
+	s := NewStockAlertService(repo, &mockProducts{}, &mockNotifier{err: errors.New("db is down")}, config.StockAlertConfig{})
+
+	if err := s.NotifySubscribers(context.Background(), 7, "Kettle"); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if len(repo.deleted) != 0 {
+		t.Fatalf("subscriptions must be kept when the notification failed")
+	}
+}
@@ -0,0 +1,139 @@
+package viewService
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultHistorySize   = 50
+	defaultHistoryTTL    = 30 * 24 * time.Hour
+	defaultFlushInterval = time.Minute
+	defaultQueueSize     = 4096
+
+	recentKeyPrefix = "views:recent:"
+)
+
+type IViewRepository interface {
+	IncrementViewCounts(ctx context.Context, counts map[int64]int64) error
+}
+
+// ViewService records product views off the request path: views are queued in memory,
+// the worker keeps the per-user history in Redis and adds the aggregated counts to the
+// product counters in batches.
+type ViewService struct {
+	repo   IViewRepository
+	cache  *redis.Client
+	cfg    config.ViewConfig
+	queue  chan model.ProductView
+	logger *slog.Logger
+}
+
+func NewViewService(repo IViewRepository, cache *redis.Client, cfg config.ViewConfig, logger *slog.Logger) *ViewService {
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = defaultHistorySize
+	}
+	if cfg.HistoryTTL <= 0 {
+		cfg.HistoryTTL = defaultHistoryTTL
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+
+	return &ViewService{
+		repo:   repo,
+		cache:  cache,
+		cfg:    cfg,
+		queue:  make(chan model.ProductView, cfg.QueueSize),
+		logger: logger,
+	}
+}
+
+// Record queues the view and returns immediately. Views are a best effort signal,
+// so they are dropped when the queue is full instead of slowing down the product page.
+func (s *ViewService) Record(userId, productId int64) {
+	select {
+	case s.queue <- model.ProductView{UserId: userId, ProductId: productId}:
+	default:
+	}
+}
+
+// Recent returns the ids of the products the user viewed last, most recent first.
+func (s *ViewService) Recent(ctx context.Context, userId int64, limit int) ([]int64, error) {
+	values, err := s.cache.LRange(ctx, recentKey(userId), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Run processes queued views until ctx is canceled, then flushes the remaining counts.
+func (s *ViewService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	counts := make(map[int64]int64)
+	for {
+		select {
+		case <-ctx.Done():
+			s.flush(context.Background(), counts)
+			return
+		case view := <-s.queue:
+			counts[view.ProductId]++
+			if err := s.remember(ctx, view); err != nil {
+				s.logger.Error("recently viewed update failed", "err", err)
+			}
+		case <-ticker.C:
+			s.flush(ctx, counts)
+			counts = make(map[int64]int64)
+		}
+	}
+}
+
+// remember moves the product to the head of the user history and caps its length.
+func (s *ViewService) remember(ctx context.Context, view model.ProductView) error {
+	key := recentKey(view.UserId)
+	id := strconv.FormatInt(view.ProductId, 10)
+
+	pipe := s.cache.Pipeline()
+	pipe.LRem(ctx, key, 0, id)
+	pipe.LPush(ctx, key, id)
+	pipe.LTrim(ctx, key, 0, int64(s.cfg.HistorySize-1))
+	pipe.Expire(ctx, key, s.cfg.HistoryTTL)
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+func (s *ViewService) flush(ctx context.Context, counts map[int64]int64) {
+	if len(counts) == 0 {
+		return
+	}
+
+	if err := s.repo.IncrementViewCounts(ctx, counts); err != nil {
+		s.logger.Error("view counters flush failed", "err", err)
+	}
+}
+
+func recentKey(userId int64) string {
+	return recentKeyPrefix + strconv.FormatInt(userId, 10)
+}
@@ -0,0 +1,99 @@
+package viewService
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	redismock "github.com/go-redis/redismock/v9"
+	"github.com/niklvrr/myMarketplace/internal/config"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type mockRepo struct {
+	flushed chan map[int64]int64
+}
+
+func (m *mockRepo) IncrementViewCounts(ctx context.Context, counts map[int64]int64) error {
+	m.flushed <- counts
+	return nil
+}
+
+func TestViewService_Record(t *testing.T) {
+	client, _ := redismock.NewClientMock()
+	s := NewViewService(&mockRepo{}, client, config.ViewConfig{QueueSize: 2}, testLogger)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			s.Record(1, int64(i))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("record must not block when the queue is full")
+	}
+	if len(s.queue) != 2 {
+		t.Fatalf("expected views above the queue size to be dropped, got %d queued", len(s.queue))
+	}
+}
+
+func TestViewService_Run(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	repo := &mockRepo{flushed: make(chan map[int64]int64, 1)}
+	s := NewViewService(repo, client, config.ViewConfig{HistorySize: 3, HistoryTTL: time.Hour, FlushInterval: time.Hour}, testLogger)
+
+	for _, id := range []string{"5", "6", "5"} {
+		mock.ExpectLRem("views:recent:1", 0, id).SetVal(0)
+		mock.ExpectLPush("views:recent:1", id).SetVal(1)
+		mock.ExpectLTrim("views:recent:1", 0, 2).SetVal("OK")
+		mock.ExpectExpire("views:recent:1", time.Hour).SetVal(true)
+	}
+	s.Record(1, 5)
+	s.Record(1, 6)
+	s.Record(1, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(stopped)
+	}()
+
+	for len(s.queue) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	<-stopped
+
+	select {
+	case counts := <-repo.flushed:
+		if !reflect.DeepEqual(counts, map[int64]int64{5: 2, 6: 1}) {
+			t.Fatalf("unexpected counts: %v", counts)
+		}
+	default:
+		t.Fatalf("remaining counts must be flushed on shutdown")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("redis expectations: %v", err)
+	}
+}
+
+func TestViewService_Recent(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	s := NewViewService(&mockRepo{}, client, config.ViewConfig{}, testLogger)
+
+	mock.ExpectLRange("views:recent:1", 0, 9).SetVal([]string{"8", "junk", "3"})
+	got, err := s.Recent(context.Background(), 1, 10)
+	if err != nil || !reflect.DeepEqual(got, []int64{8, 3}) {
+		t.Fatalf("unexpected result: %v %v", got, err)
+	}
+}
@@ -0,0 +1,178 @@
+package wishlistService
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const defaultAlertBatchSize = 500
+
+type IAlertRepository interface {
+	GetWishlistAlerts(ctx context.Context, afterWishlistId, afterProductId int64, limit int) (*[]model.WishlistAlert, error)
+	UpdateWishlistAlerts(ctx context.Context, items []model.WishlistItem) error
+}
+
+// IPriceResolver sets the current price of products according to active discounts.
+type IPriceResolver interface {
+	ApplyDiscounts(ctx context.Context, products []*model.ProductResponse) error
+}
+
+type INotifier interface {
+	Notify(ctx context.Context, notifications []model.Notification) error
+}
+
+// AlertChecker compares wishlisted products against the state users were last
+// notified about and sends price drop and back in stock notifications.
+type AlertChecker struct {
+	repo      IAlertRepository
+	pricer    IPriceResolver
+	notifier  INotifier
+	batchSize int
+}
+
+func NewAlertChecker(repo IAlertRepository, pricer IPriceResolver, notifier INotifier, cfg config.WishlistConfig) *AlertChecker {
+	if cfg.AlertBatchSize <= 0 {
+		cfg.AlertBatchSize = defaultAlertBatchSize
+	}
+
+	return &AlertChecker{
+		repo:      repo,
+		pricer:    pricer,
+		notifier:  notifier,
+		batchSize: cfg.AlertBatchSize,
+	}
+}
+
+// CheckAlerts walks all items with opted in alerts page by page. A user who keeps
+// the same product in several wishlists is notified once per run.
+func (c *AlertChecker) CheckAlerts(ctx context.Context) error {
+	sent := make(map[string]struct{})
+	var afterWishlistId, afterProductId int64
+	for {
+		page, err := c.repo.GetWishlistAlerts(ctx, afterWishlistId, afterProductId, c.batchSize)
+		if err != nil {
+			return err
+		}
+
+		alerts := *page
+		if len(alerts) == 0 {
+			return nil
+		}
+
+		notifications, changed, err := c.check(ctx, alerts, sent)
+		if err != nil {
+			return err
+		}
+
+		// Notifications go first: if saving the state fails they may repeat, but are never lost.
+		if err = c.notifier.Notify(ctx, notifications); err != nil {
+			return err
+		}
+
+		if err = c.repo.UpdateWishlistAlerts(ctx, changed); err != nil {
+			return err
+		}
+
+		if len(alerts) < c.batchSize {
+			return nil
+		}
+
+		last := alerts[len(alerts)-1]
+		afterWishlistId, afterProductId = last.WishlistId, last.ProductId
+	}
+}
+
+// check returns the notifications for a page of alerts and the items whose state has changed.
+func (c *AlertChecker) check(ctx context.Context, alerts []model.WishlistAlert, sent map[string]struct{}) ([]model.Notification, []model.WishlistItem, error) {
+	prices, err := c.currentPrices(ctx, alerts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var notifications []model.Notification
+	var changed []model.WishlistItem
+	notify := func(a *model.WishlistAlert, kind, title, body string) {
+		key := fmt.Sprintf("%d:%d:%s", a.UserId, a.ProductId, kind)
+		if _, ok := sent[key]; ok {
+			return
+		}
+		sent[key] = struct{}{}
+
+		productId := a.ProductId
+		notifications = append(notifications, model.Notification{
+			UserId:    a.UserId,
+			Kind:      kind,
+			Title:     title,
+			Body:      body,
+			ProductId: &productId,
+		})
+	}
+
+	for i := range alerts {
+		a := &alerts[i]
+		item := a.WishlistItem
+		dirty := false
+
+		price := prices[a.ProductId]
+		if a.NotifyPriceDrop && price < a.LastNotifiedPrice {
+			notify(a, model.NotificationPriceDrop,
+				"Price drop: "+a.Name,
+				fmt.Sprintf("%s is now %.2f, down from %.2f.", a.Name, price, a.LastNotifiedPrice))
+			item.LastNotifiedPrice = price
+			dirty = true
+		}
+
+		// The stock state is tracked even without the opt in, so turning it on later
+		// doesn't report a product that has been in stock all along.
+		inStock := a.Stock > 0
+		if inStock != a.LastInStock {
+			if inStock && a.NotifyBackInStock {
+				notify(a, model.NotificationBackInStock,
+					"Back in stock: "+a.Name,
+					a.Name+" is available again.")
+			}
+			item.LastInStock = inStock
+			dirty = true
+		}
+
+		if dirty {
+			changed = append(changed, item)
+		}
+	}
+
+	return notifications, changed, nil
+}
+
+// currentPrices resolves the effective price of every product of the page once.
+func (c *AlertChecker) currentPrices(ctx context.Context, alerts []model.WishlistAlert) (map[int64]float64, error) {
+	var products []*model.ProductResponse
+	seen := make(map[int64]struct{}, len(alerts))
+	for _, a := range alerts {
+		if _, ok := seen[a.ProductId]; ok {
+			continue
+		}
+		seen[a.ProductId] = struct{}{}
+
+		products = append(products, &model.ProductResponse{
+			Id:           a.ProductId,
+			SellerId:     a.SellerId,
+			CategoryId:   a.CategoryId,
+			Price:        a.Price,
+			CurrentPrice: a.Price,
+		})
+	}
+
+	if err := c.pricer.ApplyDiscounts(ctx, products); err != nil {
+		return nil, err
+	}
+
+	prices := make(map[int64]float64, len(products))
+	for _, p := range products {
+		prices[p.Id] = p.CurrentPrice
+	}
+
+	return prices, nil
+}
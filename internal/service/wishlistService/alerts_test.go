@@ -0,0 +1,134 @@
+package wishlistService
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockAlertRepo struct {
+	alerts  []model.WishlistAlert
+	pages   int
+	updated []model.WishlistItem
+}
+
+func (m *mockAlertRepo) GetWishlistAlerts(ctx context.Context, afterWishlistId, afterProductId int64, limit int) (*[]model.WishlistAlert, error) {
+	m.pages++
+	page := []model.WishlistAlert{}
+	for _, a := range m.alerts {
+		if a.WishlistId > afterWishlistId || (a.WishlistId == afterWishlistId && a.ProductId > afterProductId) {
+			page = append(page, a)
+		}
+		if len(page) == limit {
+			break
+		}
+	}
+	return &page, nil
+}
+
+func (m *mockAlertRepo) UpdateWishlistAlerts(ctx context.Context, items []model.WishlistItem) error {
+	m.updated = append(m.updated, items...)
+	return nil
+}
+
+// mockPricer takes 10% off the products of seller 2.
+type mockPricer struct{}
+
+func (m *mockPricer) ApplyDiscounts(ctx context.Context, products []*model.ProductResponse) error {
+	for _, p := range products {
+		if p.SellerId == 2 {
+			p.CurrentPrice = p.Price * 0.9
+		}
+	}
+	return nil
+}
+
+type mockNotifier struct {
+	sent []model.Notification
+	err  error
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, notifications []model.Notification) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, notifications...)
+	return nil
+}
+
+func alert(wishlistId, userId, productId, sellerId int64, price float64, stock int, item model.WishlistItem) model.WishlistAlert {
+	item.WishlistId, item.ProductId = wishlistId, productId
+	return model.WishlistAlert{
+		WishlistItem: item,
+		UserId:       userId,
+		Name:         "Kettle",
+		SellerId:     sellerId,
+		Price:        price,
+		Stock:        stock,
+	}
+}
+
+func TestAlertChecker_CheckAlerts(t *testing.T) {
+	repo := &mockAlertRepo{alerts: []model.WishlistAlert{
+		// discounted below the last notified price
+		alert(1, 9, 5, 2, 100, 1, model.WishlistItem{NotifyPriceDrop: true, LastNotifiedPrice: 100, LastInStock: true}),
+		// back in stock
+		alert(1, 9, 6, 3, 50, 4, model.WishlistItem{NotifyBackInStock: true, LastNotifiedPrice: 50}),
+		// price went up, nothing to report
+		alert(2, 10, 7, 3, 120, 1, model.WishlistItem{NotifyPriceDrop: true, LastNotifiedPrice: 100, LastInStock: true}),
+		// sold out: the state changes silently
+		alert(2, 10, 8, 3, 30, 0, model.WishlistItem{NotifyBackInStock: true, LastNotifiedPrice: 30, LastInStock: true}),
+		// the same product in another wishlist of user 9
+		alert(3, 9, 5, 2, 100, 1, model.WishlistItem{NotifyPriceDrop: true, LastNotifiedPrice: 100, LastInStock: true}),
+	}}
+	notifier := &mockNotifier{}
+	c := NewAlertChecker(repo, &mockPricer{}, notifier, config.WishlistConfig{AlertBatchSize: 2})
+
+	if err := c.CheckAlerts(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo.pages != 3 {
+		t.Fatalf("expected 3 pages, got %d", repo.pages)
+	}
+
+	if len(notifier.sent) != 2 {
+		t.Fatalf("expected 2 notifications, got %+v", notifier.sent)
+	}
+	drop, restock := notifier.sent[0], notifier.sent[1]
+	if drop.Kind != model.NotificationPriceDrop || drop.UserId != 9 || *drop.ProductId != 5 {
+		t.Fatalf("unexpected price drop notification: %+v", drop)
+	}
+	if restock.Kind != model.NotificationBackInStock || restock.UserId != 9 || *restock.ProductId != 6 {
+		t.Fatalf("unexpected back in stock notification: %+v", restock)
+	}
+
+	if len(repo.updated) != 4 {
+		t.Fatalf("expected 4 changed items, got %+v", repo.updated)
+	}
+	if repo.updated[0].LastNotifiedPrice != 90 || !repo.updated[1].LastInStock || repo.updated[2].LastInStock {
+		t.Fatalf("unexpected alert state: %+v", repo.updated)
+	}
+	if repo.updated[3].WishlistId != 3 || repo.updated[3].LastNotifiedPrice != 90 {
+		t.Fatalf("the baseline of every wishlist must move: %+v", repo.updated[3])
+	}
+}
+
+func TestAlertChecker_NotifyFailure(t *testing.T) {
+	repo := &mockAlertRepo{alerts: []model.WishlistAlert{
+		alert(1, 9, 5, 2, 100, 1, model.WishlistItem{NotifyPriceDrop: true, LastNotifiedPrice: 100, LastInStock: true}),
+	}}
+	notifier := &mockNotifier{err: errors.New("db is down")}
+	c := NewAlertChecker(repo, &mockPricer{}, notifier, config.WishlistConfig{})
+
+	if err := c.CheckAlerts(context.Background()); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if len(repo.updated) != 0 {
+		t.Fatalf("state must not move when notifications were not sent: %+v", repo.updated)
+	}
+}
@@ -0,0 +1,332 @@
+package wishlistService
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+const shareTokenBytes = 24
+
+type IWishlistRepository interface {
+	CreateWishlist(ctx context.Context, w *model.Wishlist) (bool, error)
+	GetWishlistById(ctx context.Context, id int64) (*model.Wishlist, error)
+	GetWishlistByToken(ctx context.Context, token string) (*model.Wishlist, error)
+	GetUserWishlists(ctx context.Context, userId int64) (*[]model.Wishlist, error)
+	RenameWishlist(ctx context.Context, id int64, name string) (bool, error)
+	DeleteWishlist(ctx context.Context, id int64) error
+	SetShareToken(ctx context.Context, id int64, token *string) error
+	GetWishlistItems(ctx context.Context, wishlistId int64) (*[]model.WishlistItem, error)
+	SaveWishlistItem(ctx context.Context, item *model.WishlistItem) error
+	RemoveWishlistItem(ctx context.Context, wishlistId, productId int64) (bool, error)
+}
+
+// IProductCatalog returns listed products with their current prices.
+type IProductCatalog interface {
+	ListedByIds(ctx context.Context, ids []int64) ([]model.ProductResponse, error)
+}
+
+type WishlistService struct {
+	repo     IWishlistRepository
+	catalog  IProductCatalog
+	cfg      config.WishlistConfig
+	newToken func() (string, error)
+}
+
+func NewWishlistService(repo IWishlistRepository, catalog IProductCatalog, cfg config.WishlistConfig) *WishlistService {
+	cfg.ShareBaseUrl = strings.TrimRight(cfg.ShareBaseUrl, "/")
+
+	return &WishlistService{
+		repo:     repo,
+		catalog:  catalog,
+		cfg:      cfg,
+		newToken: newShareToken,
+	}
+}
+
+func (s *WishlistService) Create(ctx context.Context, userId int64, req *model.CreateWishlistRequest) (model.WishlistResponse, error) {
+	w := model.Wishlist{UserId: userId, Name: strings.TrimSpace(req.Name)}
+	if w.Name == "" {
+		return model.WishlistResponse{}, fmt.Errorf("%w: name is required", errs.ValidationError)
+	}
+
+	created, err := s.repo.CreateWishlist(ctx, &w)
+	if err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	if !created {
+		return model.WishlistResponse{}, fmt.Errorf("%w: wishlist %q already exists", errs.ConflictError, w.Name)
+	}
+
+	return s.toWishlistResponse(&w), nil
+}
+
+func (s *WishlistService) List(ctx context.Context, userId int64) ([]model.WishlistResponse, error) {
+	wishlists, err := s.repo.GetUserWishlists(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.WishlistResponse, 0, len(*wishlists))
+	for i := range *wishlists {
+		result = append(result, s.toWishlistResponse(&(*wishlists)[i]))
+	}
+
+	return result, nil
+}
+
+// Get returns the wishlist with its items. Products that left the catalog are not shown.
+func (s *WishlistService) Get(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistDetailsResponse, error) {
+	w, err := s.ownWishlist(ctx, userId, req.Id)
+	if err != nil {
+		return model.WishlistDetailsResponse{}, err
+	}
+
+	items, err := s.repo.GetWishlistItems(ctx, w.Id)
+	if err != nil {
+		return model.WishlistDetailsResponse{}, err
+	}
+
+	products, err := s.itemProducts(ctx, *items)
+	if err != nil {
+		return model.WishlistDetailsResponse{}, err
+	}
+
+	res := model.WishlistDetailsResponse{
+		WishlistResponse: s.toWishlistResponse(w),
+		Items:            make([]model.WishlistItemResponse, 0, len(products)),
+	}
+	for _, item := range *items {
+		if p, ok := products[item.ProductId]; ok {
+			res.Items = append(res.Items, toItemResponse(&item, p))
+		}
+	}
+
+	return res, nil
+}
+
+func (s *WishlistService) Rename(ctx context.Context, userId int64, req *model.RenameWishlistRequest) (model.WishlistResponse, error) {
+	w, err := s.ownWishlist(ctx, userId, req.Id)
+	if err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return model.WishlistResponse{}, fmt.Errorf("%w: name is required", errs.ValidationError)
+	}
+
+	renamed, err := s.repo.RenameWishlist(ctx, w.Id, name)
+	if err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	if !renamed {
+		return model.WishlistResponse{}, fmt.Errorf("%w: wishlist %q already exists", errs.ConflictError, name)
+	}
+	w.Name = name
+
+	return s.toWishlistResponse(w), nil
+}
+
+func (s *WishlistService) Delete(ctx context.Context, userId int64, req *model.WishlistRequest) error {
+	w, err := s.ownWishlist(ctx, userId, req.Id)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteWishlist(ctx, w.Id)
+}
+
+// AddItem adds a listed product to the wishlist. The current price and stock become
+// the starting point of the alerts, adding the product again updates its alert settings.
+func (s *WishlistService) AddItem(ctx context.Context, userId int64, req *model.AddWishlistItemRequest) (model.WishlistItemResponse, error) {
+	w, err := s.ownWishlist(ctx, userId, req.WishlistId)
+	if err != nil {
+		return model.WishlistItemResponse{}, err
+	}
+
+	products, err := s.catalog.ListedByIds(ctx, []int64{req.ProductId})
+	if err != nil {
+		return model.WishlistItemResponse{}, err
+	}
+
+	if len(products) == 0 {
+		return model.WishlistItemResponse{}, fmt.Errorf("%w: product %d", errs.NotFoundError, req.ProductId)
+	}
+	p := &products[0]
+
+	item := model.WishlistItem{
+		WishlistId:        w.Id,
+		ProductId:         p.Id,
+		NotifyPriceDrop:   req.NotifyPriceDrop,
+		NotifyBackInStock: req.NotifyBackInStock,
+		LastNotifiedPrice: p.CurrentPrice,
+		LastInStock:       p.Stock > 0,
+	}
+	if err = s.repo.SaveWishlistItem(ctx, &item); err != nil {
+		return model.WishlistItemResponse{}, err
+	}
+
+	return toItemResponse(&item, p), nil
+}
+
+func (s *WishlistService) RemoveItem(ctx context.Context, userId int64, req *model.RemoveWishlistItemRequest) error {
+	w, err := s.ownWishlist(ctx, userId, req.WishlistId)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.repo.RemoveWishlistItem(ctx, w.Id, req.ProductId)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return fmt.Errorf("%w: product %d is not in the wishlist", errs.NotFoundError, req.ProductId)
+	}
+
+	return nil
+}
+
+// Share makes the wishlist readable by anyone with its link. Sharing an already
+// shared wishlist keeps the existing link.
+func (s *WishlistService) Share(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistResponse, error) {
+	w, err := s.ownWishlist(ctx, userId, req.Id)
+	if err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	if w.ShareToken != nil {
+		return s.toWishlistResponse(w), nil
+	}
+
+	token, err := s.newToken()
+	if err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	if err = s.repo.SetShareToken(ctx, w.Id, &token); err != nil {
+		return model.WishlistResponse{}, err
+	}
+	w.ShareToken = &token
+
+	return s.toWishlistResponse(w), nil
+}
+
+// Unshare revokes the share link, a later Share issues a new one.
+func (s *WishlistService) Unshare(ctx context.Context, userId int64, req *model.WishlistRequest) (model.WishlistResponse, error) {
+	w, err := s.ownWishlist(ctx, userId, req.Id)
+	if err != nil {
+		return model.WishlistResponse{}, err
+	}
+
+	if err = s.repo.SetShareToken(ctx, w.Id, nil); err != nil {
+		return model.WishlistResponse{}, err
+	}
+	w.ShareToken = nil
+
+	return s.toWishlistResponse(w), nil
+}
+
+// Shared returns the public view of the wishlist shared with the token.
+func (s *WishlistService) Shared(ctx context.Context, req *model.SharedWishlistRequest) (model.SharedWishlistResponse, error) {
+	w, err := s.repo.GetWishlistByToken(ctx, req.Token)
+	if err != nil {
+		return model.SharedWishlistResponse{}, err
+	}
+
+	if w == nil {
+		return model.SharedWishlistResponse{}, fmt.Errorf("%w: shared wishlist", errs.NotFoundError)
+	}
+
+	items, err := s.repo.GetWishlistItems(ctx, w.Id)
+	if err != nil {
+		return model.SharedWishlistResponse{}, err
+	}
+
+	ids := make([]int64, 0, len(*items))
+	for _, item := range *items {
+		ids = append(ids, item.ProductId)
+	}
+
+	products, err := s.catalog.ListedByIds(ctx, ids)
+	if err != nil {
+		return model.SharedWishlistResponse{}, err
+	}
+
+	return model.SharedWishlistResponse{Name: w.Name, Items: products}, nil
+}
+
+// ownWishlist loads the wishlist and checks that it belongs to the user.
+func (s *WishlistService) ownWishlist(ctx context.Context, userId, id int64) (*model.Wishlist, error) {
+	w, err := s.repo.GetWishlistById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.UserId != userId {
+		return nil, fmt.Errorf("%w: wishlist %d belongs to another user", errs.NotOwnerError, w.Id)
+	}
+
+	return w, nil
+}
+
+func (s *WishlistService) itemProducts(ctx context.Context, items []model.WishlistItem) (map[int64]*model.ProductResponse, error) {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductId)
+	}
+
+	products, err := s.catalog.ListedByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[int64]*model.ProductResponse, len(products))
+	for i := range products {
+		byId[products[i].Id] = &products[i]
+	}
+
+	return byId, nil
+}
+
+func (s *WishlistService) toWishlistResponse(w *model.Wishlist) model.WishlistResponse {
+	res := model.WishlistResponse{
+		Id:        w.Id,
+		Name:      w.Name,
+		ItemCount: w.ItemCount,
+		CreatedAt: w.CreatedAt,
+	}
+	if w.ShareToken != nil {
+		res.ShareUrl = s.cfg.ShareBaseUrl + "/api/v1/wishlists/shared/" + *w.ShareToken
+	}
+
+	return res
+}
+
+func toItemResponse(item *model.WishlistItem, p *model.ProductResponse) model.WishlistItemResponse {
+	return model.WishlistItemResponse{
+		Product:           *p,
+		NotifyPriceDrop:   item.NotifyPriceDrop,
+		NotifyBackInStock: item.NotifyBackInStock,
+		AddedAt:           item.AddedAt,
+	}
+}
+
+// newShareToken returns a random url safe token that can't be guessed from other tokens.
+func newShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
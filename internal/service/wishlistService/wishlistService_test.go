@@ -0,0 +1,230 @@
+package wishlistService
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/niklvrr/myMarketplace/internal/config"
+	"github.com/niklvrr/myMarketplace/internal/errs"
+	"github.com/niklvrr/myMarketplace/internal/model"
+)
+
+type mockRepo struct {
+	CreateWishlistFn     func(ctx context.Context, w *model.Wishlist) (bool, error)
+	GetWishlistByIdFn    func(ctx context.Context, id int64) (*model.Wishlist, error)
+	GetWishlistByTokenFn func(ctx context.Context, token string) (*model.Wishlist, error)
+	GetUserWishlistsFn   func(ctx context.Context, userId int64) (*[]model.Wishlist, error)
+	RenameWishlistFn     func(ctx context.Context, id int64, name string) (bool, error)
+	DeleteWishlistFn     func(ctx context.Context, id int64) error
+	SetShareTokenFn      func(ctx context.Context, id int64, token *string) error
+	GetWishlistItemsFn   func(ctx context.Context, wishlistId int64) (*[]model.WishlistItem, error)
+	SaveWishlistItemFn   func(ctx context.Context, item *model.WishlistItem) error
+	RemoveWishlistItemFn func(ctx context.Context, wishlistId, productId int64) (bool, error)
+}
+
+func (m *mockRepo) CreateWishlist(ctx context.Context, w *model.Wishlist) (bool, error) {
+	return m.CreateWishlistFn(ctx, w)
+}
+func (m *mockRepo) GetWishlistById(ctx context.Context, id int64) (*model.Wishlist, error) {
+	return m.GetWishlistByIdFn(ctx, id)
+}
+func (m *mockRepo) GetWishlistByToken(ctx context.Context, token string) (*model.Wishlist, error) {
+	return m.GetWishlistByTokenFn(ctx, token)
+}
+func (m *mockRepo) GetUserWishlists(ctx context.Context, userId int64) (*[]model.Wishlist, error) {
+	return m.GetUserWishlistsFn(ctx, userId)
+}
+func (m *mockRepo) RenameWishlist(ctx context.Context, id int64, name string) (bool, error) {
+	return m.RenameWishlistFn(ctx, id, name)
+}
+func (m *mockRepo) DeleteWishlist(ctx context.Context, id int64) error {
+	return m.DeleteWishlistFn(ctx, id)
+}
+func (m *mockRepo) SetShareToken(ctx context.Context, id int64, token *string) error {
+	return m.SetShareTokenFn(ctx, id, token)
+}
+func (m *mockRepo) GetWishlistItems(ctx context.Context, wishlistId int64) (*[]model.WishlistItem, error) {
+	return m.GetWishlistItemsFn(ctx, wishlistId)
+}
+func (m *mockRepo) SaveWishlistItem(ctx context.Context, item *model.WishlistItem) error {
+	return m.SaveWishlistItemFn(ctx, item)
+}
+func (m *mockRepo) RemoveWishlistItem(ctx context.Context, wishlistId, productId int64) (bool, error) {
+	return m.RemoveWishlistItemFn(ctx, wishlistId, productId)
+}
+
+// mockCatalog serves the listed products it knows about, in the order of ids.
+type mockCatalog struct {
+	products map[int64]model.ProductResponse
+}
+
+func (m *mockCatalog) ListedByIds(ctx context.Context, ids []int64) ([]model.ProductResponse, error) {
+	res := []model.ProductResponse{}
+	for _, id := range ids {
+		if p, ok := m.products[id]; ok {
+			res = append(res, p)
+		}
+	}
+	return res, nil
+}
+
+func newTestService(repo *mockRepo) *WishlistService {
+	catalog := &mockCatalog{products: map[int64]model.ProductResponse{
+		5: {Id: 5, Name: "Kettle", Price: 100, CurrentPrice: 80, Stock: 0},
+		6: {Id: 6, Name: "Mug", Price: 10, CurrentPrice: 10, Stock: 3},
+	}}
+	s := NewWishlistService(repo, catalog, config.WishlistConfig{ShareBaseUrl: "https://azon.example/"})
+	s.newToken = func() (string, error) { return "token", nil }
+	return s
+}
+
+func ownedBy(userId int64) func(ctx context.Context, id int64) (*model.Wishlist, error) {
+	return func(ctx context.Context, id int64) (*model.Wishlist, error) {
+		return &model.Wishlist{Id: id, UserId: userId, Name: "Birthday"}, nil
+	}
+}
+
+func TestWishlistService_Create(t *testing.T) {
+	repo := &mockRepo{
+		CreateWishlistFn: func(ctx context.Context, w *model.Wishlist) (bool, error) {
+			if w.Name == "Birthday" {
+				return false, nil
+			}
+			w.Id = 1
+			return true, nil
+		},
+	}
+	s := newTestService(repo)
+
+	got, err := s.Create(context.Background(), 9, &model.CreateWishlistRequest{Name: "  Gifts "})
+	if err != nil || got.Id != 1 || got.Name != "Gifts" {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+
+	if _, err = s.Create(context.Background(), 9, &model.CreateWishlistRequest{Name: "Birthday"}); !errors.Is(err, errs.ConflictError) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+
+	if _, err = s.Create(context.Background(), 9, &model.CreateWishlistRequest{Name: "   "}); !errors.Is(err, errs.ValidationError) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestWishlistService_Get(t *testing.T) {
+	repo := &mockRepo{
+		GetWishlistByIdFn: ownedBy(9),
+		GetWishlistItemsFn: func(ctx context.Context, wishlistId int64) (*[]model.WishlistItem, error) {
+			return &[]model.WishlistItem{
+				{WishlistId: wishlistId, ProductId: 6, NotifyBackInStock: true},
+				{WishlistId: wishlistId, ProductId: 7},
+				{WishlistId: wishlistId, ProductId: 5, NotifyPriceDrop: true},
+			}, nil
+		},
+	}
+	s := newTestService(repo)
+
+	if _, err := s.Get(context.Background(), 10, &model.WishlistRequest{Id: 1}); !errors.Is(err, errs.NotOwnerError) {
+		t.Fatalf("expected not owner, got %v", err)
+	}
+
+	got, err := s.Get(context.Background(), 9, &model.WishlistRequest{Id: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Items) != 2 || got.Items[0].Product.Id != 6 || !got.Items[0].NotifyBackInStock || got.Items[1].Product.Id != 5 {
+		t.Fatalf("delisted products must be skipped keeping the order: %+v", got.Items)
+	}
+}
+
+func TestWishlistService_AddItem(t *testing.T) {
+	var saved *model.WishlistItem
+	repo := &mockRepo{
+		GetWishlistByIdFn: ownedBy(9),
+		SaveWishlistItemFn: func(ctx context.Context, item *model.WishlistItem) error {
+			saved = item
+			return nil
+		},
+	}
+	s := newTestService(repo)
+
+	_, err := s.AddItem(context.Background(), 9, &model.AddWishlistItemRequest{WishlistId: 1, ProductId: 7})
+	if !errors.Is(err, errs.NotFoundError) || saved != nil {
+		t.Fatalf("expected not found for a product outside the catalog, got %v", err)
+	}
+
+	got, err := s.AddItem(context.Background(), 9, &model.AddWishlistItemRequest{WishlistId: 1, ProductId: 5, NotifyPriceDrop: true})
+	if err != nil || got.Product.Id != 5 || !got.NotifyPriceDrop {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+	if saved.LastNotifiedPrice != 80 || saved.LastInStock {
+		t.Fatalf("alerts must start from the current price and stock: %+v", saved)
+	}
+}
+
+func TestWishlistService_Share(t *testing.T) {
+	var stored *string
+	shareCalls := 0
+	repo := &mockRepo{
+		GetWishlistByIdFn: func(ctx context.Context, id int64) (*model.Wishlist, error) {
+			return &model.Wishlist{Id: id, UserId: 9, ShareToken: stored}, nil
+		},
+		SetShareTokenFn: func(ctx context.Context, id int64, token *string) error {
+			shareCalls++
+			stored = token
+			return nil
+		},
+	}
+	s := newTestService(repo)
+
+	got, err := s.Share(context.Background(), 9, &model.WishlistRequest{Id: 1})
+	if err != nil || got.ShareUrl != "https://azon.example/api/v1/wishlists/shared/token" {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+
+	if _, err = s.Share(context.Background(), 9, &model.WishlistRequest{Id: 1}); err != nil || shareCalls != 1 {
+		t.Fatalf("sharing twice must keep the link: %d calls, %v", shareCalls, err)
+	}
+
+	got, err = s.Unshare(context.Background(), 9, &model.WishlistRequest{Id: 1})
+	if err != nil || got.ShareUrl != "" || stored != nil {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+}
+
+func TestWishlistService_Shared(t *testing.T) {
+	repo := &mockRepo{
+		GetWishlistByTokenFn: func(ctx context.Context, token string) (*model.Wishlist, error) {
+			if token != "token" {
+				return nil, nil
+			}
+			return &model.Wishlist{Id: 1, UserId: 9, Name: "Birthday"}, nil
+		},
+		GetWishlistItemsFn: func(ctx context.Context, wishlistId int64) (*[]model.WishlistItem, error) {
+			return &[]model.WishlistItem{{WishlistId: 1, ProductId: 5}, {WishlistId: 1, ProductId: 7}}, nil
+		},
+	}
+	s := newTestService(repo)
+
+	if _, err := s.Shared(context.Background(), &model.SharedWishlistRequest{Token: "guess"}); !errors.Is(err, errs.NotFoundError) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	got, err := s.Shared(context.Background(), &model.SharedWishlistRequest{Token: "token"})
+	if err != nil || got.Name != "Birthday" || len(got.Items) != 1 || got.Items[0].Id != 5 {
+		t.Fatalf("unexpected result: %+v %v", got, err)
+	}
+}
+
+func TestNewShareToken(t *testing.T) {
+	a, err := newShareToken()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := newShareToken()
+
+	if a == b || len(a) != 32 || strings.ContainsAny(a, "+/=") {
+		t.Fatalf("expected distinct url safe tokens, got %q and %q", a, b)
+	}
+}
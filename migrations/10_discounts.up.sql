@@ -0,0 +1,24 @@
+-- Скидки продавца на товар, категорию или весь каталог продавца
+CREATE TABLE IF NOT EXISTS discounts (
+    id SERIAL PRIMARY KEY,
+    seller_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    scope TEXT NOT NULL CHECK (scope IN ('product', 'category', 'seller')),
+    product_id INT
+    REFERENCES products(id) ON DELETE CASCADE,
+    category_id INT
+    REFERENCES categories(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('fixed', 'percent')),
+    value NUMERIC(10,2) NOT NULL CHECK (value > 0),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK (ends_at > starts_at),
+    CHECK (kind <> 'percent' OR value < 100),
+    CHECK ((scope = 'product') = (product_id IS NOT NULL)),
+    CHECK ((scope = 'category') = (category_id IS NOT NULL))
+    );
+
+-- Активные скидки выбираются по продавцу и периоду действия
+CREATE INDEX IF NOT EXISTS idx_discounts_seller_period
+    ON discounts (seller_id, ends_at, starts_at);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_order_items_product_id_order_id;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS review_count;
+
+DROP TABLE IF EXISTS review_votes;
+DROP TABLE IF EXISTS reviews;
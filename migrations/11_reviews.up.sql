@@ -0,0 +1,41 @@
+-- Отзывы покупателей, один отзыв от пользователя на товар
+CREATE TABLE IF NOT EXISTS reviews (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    user_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    text TEXT NOT NULL DEFAULT '',
+    photos TEXT[] NOT NULL DEFAULT '{}',
+    seller_reply TEXT,
+    replied_at TIMESTAMP,
+    helpful_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (product_id, user_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_reviews_product_created_at
+    ON reviews (product_id, created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_product_helpful
+    ON reviews (product_id, helpful_count, id);
+
+-- Голоса за полезность отзыва, один голос от пользователя
+CREATE TABLE IF NOT EXISTS review_votes (
+    review_id INT NOT NULL
+    REFERENCES reviews(id) ON DELETE CASCADE,
+    user_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (review_id, user_id)
+    );
+
+-- Средняя оценка уже хранится в products.rating, количество отзывов хранится рядом
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS review_count INT NOT NULL DEFAULT 0;
+
+-- Проверка покупки ищет позиции заказов по товару
+CREATE INDEX IF NOT EXISTS idx_order_items_product_id_order_id
+    ON order_items (product_id, order_id);
@@ -0,0 +1,36 @@
+-- Вопросы покупателей о товаре и ответы продавца
+CREATE TABLE IF NOT EXISTS product_questions (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    user_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    answer TEXT,
+    answered_by INT
+    REFERENCES users(id) ON DELETE SET NULL,
+    answered_at TIMESTAMP,
+    upvotes INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_questions_product_created_at
+    ON product_questions (product_id, created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_product_questions_product_upvotes
+    ON product_questions (product_id, upvotes, id);
+
+-- Входящие продавца: вопросы без ответа
+CREATE INDEX IF NOT EXISTS idx_product_questions_unanswered
+    ON product_questions (product_id, created_at, id)
+    WHERE answer IS NULL;
+
+-- Голоса за вопрос, один голос от пользователя
+CREATE TABLE IF NOT EXISTS question_votes (
+    question_id INT NOT NULL
+    REFERENCES product_questions(id) ON DELETE CASCADE,
+    user_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (question_id, user_id)
+    );
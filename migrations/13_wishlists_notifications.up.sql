@@ -0,0 +1,50 @@
+-- Уведомления пользователей (внутренний почтовый ящик)
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    product_id INT
+    REFERENCES products(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    read_at TIMESTAMP
+    );
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id
+    ON notifications (user_id, id);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_unread
+    ON notifications (user_id, id)
+    WHERE read_at IS NULL;
+
+-- Именованные списки избранного, share_token открывает публичный доступ по ссылке
+CREATE TABLE IF NOT EXISTS wishlists (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    share_token TEXT UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (user_id, name)
+    );
+
+-- Товары в списках и состояние оповещений: последняя цена, о которой сообщили,
+-- и было ли товар в наличии при последней проверке
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    wishlist_id INT NOT NULL
+    REFERENCES wishlists(id) ON DELETE CASCADE,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    notify_price_drop BOOLEAN NOT NULL DEFAULT FALSE,
+    notify_back_in_stock BOOLEAN NOT NULL DEFAULT FALSE,
+    last_notified_price NUMERIC(12, 2) NOT NULL,
+    last_in_stock BOOLEAN NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (wishlist_id, product_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_wishlist_items_alerts
+    ON wishlist_items (wishlist_id, product_id)
+    WHERE notify_price_drop OR notify_back_in_stock;
@@ -0,0 +1,13 @@
+-- Подписки покупателей на поступление товара, по одной на товар и пользователя
+CREATE TABLE IF NOT EXISTS stock_subscriptions (
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    user_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (product_id, user_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_stock_subscriptions_expires_at
+    ON stock_subscriptions (expires_at);
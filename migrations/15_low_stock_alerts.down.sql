@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS low_stock_alerts;
+DROP TABLE IF EXISTS seller_inventory_settings;
+ALTER TABLE products DROP COLUMN IF EXISTS low_stock_threshold;
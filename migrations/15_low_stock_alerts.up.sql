@@ -0,0 +1,30 @@
+-- Порог низкого остатка товара, NULL — используется порог продавца
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS low_stock_threshold INT CHECK (low_stock_threshold >= 0);
+
+-- Порог низкого остатка по умолчанию для всех товаров продавца
+CREATE TABLE IF NOT EXISTS seller_inventory_settings (
+    seller_id INT PRIMARY KEY
+    REFERENCES users(id) ON DELETE CASCADE,
+    low_stock_threshold INT NOT NULL CHECK (low_stock_threshold >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+-- Оповещения о низком остатке, у товара может быть только одно открытое оповещение
+CREATE TABLE IF NOT EXISTS low_stock_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    seller_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    stock INT NOT NULL,
+    threshold INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    resolved_at TIMESTAMP
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_low_stock_alerts_open_product
+    ON low_stock_alerts (product_id) WHERE resolved_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_low_stock_alerts_open_seller
+    ON low_stock_alerts (seller_id, id) WHERE resolved_at IS NULL;
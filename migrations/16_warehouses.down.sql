@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS order_item_allocations;
+DROP TABLE IF EXISTS inventory_transfers;
+DROP TABLE IF EXISTS inventory_levels;
+DROP TABLE IF EXISTS warehouses;
@@ -0,0 +1,55 @@
+-- Склады продавцов, меньший priority — склад, с которого заказы собираются в первую очередь
+CREATE TABLE IF NOT EXISTS warehouses (
+    id BIGSERIAL PRIMARY KEY,
+    seller_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    priority INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (seller_id, name)
+    );
+
+-- Остатки товаров по складам, products.stock хранит их сумму
+CREATE TABLE IF NOT EXISTS inventory_levels (
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    warehouse_id BIGINT NOT NULL
+    REFERENCES warehouses(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (product_id, warehouse_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_inventory_levels_warehouse_id
+    ON inventory_levels (warehouse_id);
+
+-- Перемещения товара между складами
+CREATE TABLE IF NOT EXISTS inventory_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    from_warehouse_id BIGINT NOT NULL
+    REFERENCES warehouses(id) ON DELETE CASCADE,
+    to_warehouse_id BIGINT NOT NULL
+    REFERENCES warehouses(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    user_id INT
+    REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_inventory_transfers_product_id
+    ON inventory_transfers (product_id, created_at DESC);
+
+-- Склады, с которых списана позиция заказа; история заказа сохраняется и после удаления склада
+CREATE TABLE IF NOT EXISTS order_item_allocations (
+    id BIGSERIAL PRIMARY KEY,
+    order_item_id INT NOT NULL
+    REFERENCES order_items(id) ON DELETE CASCADE,
+    warehouse_id BIGINT
+    REFERENCES warehouses(id) ON DELETE SET NULL,
+    quantity INT NOT NULL CHECK (quantity > 0)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_order_item_allocations_order_item_id
+    ON order_item_allocations (order_item_id);
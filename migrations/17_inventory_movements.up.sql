@@ -0,0 +1,31 @@
+-- Журнал движений остатков: каждое изменение остатка записывается со знаком,
+-- сумма движений товара равна products.stock
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    warehouse_id BIGINT
+    REFERENCES warehouses(id) ON DELETE SET NULL,
+    quantity INT NOT NULL CHECK (quantity <> 0),
+    reason TEXT NOT NULL
+    CHECK (reason IN ('sale', 'restock', 'return', 'adjustment', 'reservation', 'release', 'transfer')),
+    actor_id INT
+    REFERENCES users(id) ON DELETE SET NULL,
+    reference TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_product_id
+    ON inventory_movements (product_id, id);
+
+-- Начальные остатки: по складам для товаров со складским учётом, иначе одним движением на товар
+INSERT INTO inventory_movements (product_id, warehouse_id, quantity, reason, reference)
+SELECT product_id, warehouse_id, quantity, 'adjustment', 'opening_balance'
+FROM inventory_levels
+WHERE quantity > 0;
+
+INSERT INTO inventory_movements (product_id, quantity, reason, reference)
+SELECT p.id, p.stock, 'adjustment', 'opening_balance'
+FROM products p
+WHERE p.stock <> 0
+  AND NOT EXISTS (SELECT 1 FROM inventory_levels il WHERE il.product_id = p.id);
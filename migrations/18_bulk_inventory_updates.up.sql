@@ -0,0 +1,14 @@
+-- Результаты пакетных обновлений остатков и цен по ключу идемпотентности:
+-- повторный запрос с тем же ключом получает сохраненный результат
+CREATE TABLE IF NOT EXISTS bulk_inventory_updates (
+    seller_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    result JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (seller_id, idempotency_key)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_bulk_inventory_updates_created_at
+    ON bulk_inventory_updates (created_at);
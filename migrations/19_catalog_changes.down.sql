@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS trg_categories_catalog_changes ON categories;
+DROP TRIGGER IF EXISTS trg_products_catalog_changes ON products;
+DROP FUNCTION IF EXISTS record_category_change();
+DROP FUNCTION IF EXISTS record_product_change();
+DROP FUNCTION IF EXISTS catalog_category(categories);
+DROP FUNCTION IF EXISTS catalog_product(products);
+
+DROP TABLE IF EXISTS catalog_changes;
@@ -0,0 +1,100 @@
+-- Лента изменений каталога для инкрементальной синхронизации партнеров.
+-- tx_id — транзакция изменения: лента отдает изменения только завершенных транзакций
+-- в порядке (tx_id, id), поэтому курсор не пропускает изменения долгих транзакций
+CREATE TABLE IF NOT EXISTS catalog_changes (
+    id BIGSERIAL PRIMARY KEY,
+    tx_id BIGINT NOT NULL DEFAULT pg_current_xact_id()::text::BIGINT,
+    entity TEXT NOT NULL
+    CHECK (entity IN ('product', 'category')),
+    entity_id BIGINT NOT NULL,
+    op TEXT NOT NULL
+    CHECK (op IN ('create', 'update', 'delete')),
+    data JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_catalog_changes_tx_id
+    ON catalog_changes (tx_id, id);
+
+CREATE INDEX IF NOT EXISTS idx_catalog_changes_created_at
+    ON catalog_changes (created_at);
+
+-- Товар в том виде, в каком его видят партнеры; NULL, если товара нет в публичном каталоге
+CREATE OR REPLACE FUNCTION catalog_product(p products) RETURNS JSONB AS $$
+BEGIN
+    IF p.status <> 'approved' OR p.archived_at IS NOT NULL OR p.deleted_at IS NOT NULL THEN
+        RETURN NULL;
+    END IF;
+
+    RETURN jsonb_build_object(
+        'id', p.id,
+        'seller_id', p.seller_id,
+        'category_id', COALESCE(p.category_id, 0),
+        'name', p.name,
+        'description', COALESCE(p.description, ''),
+        'price', p.price,
+        'stock', p.stock,
+        'sku', COALESCE(p.sku, ''),
+        'image_url', COALESCE(p.image_url, ''));
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION catalog_category(c categories) RETURNS JSONB AS $$
+BEGIN
+    RETURN jsonb_build_object(
+        'id', c.id,
+        'name', c.name,
+        'description', COALESCE(c.description, ''));
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Товар меняется из многих мест (редактирование, импорт, заказы, модерация), поэтому
+-- изменения пишутся триггером. Появление товара в каталоге — create, исчезновение
+-- (модерация, архив, удаление) — delete, изменение видимых полей — update
+CREATE OR REPLACE FUNCTION record_product_change() RETURNS TRIGGER AS $$
+DECLARE
+    old_data JSONB;
+    new_data JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_data := catalog_product(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        new_data := catalog_product(NEW);
+    END IF;
+
+    IF old_data IS NULL AND new_data IS NOT NULL THEN
+        INSERT INTO catalog_changes (entity, entity_id, op, data) VALUES ('product', NEW.id, 'create', new_data);
+    ELSIF old_data IS NOT NULL AND new_data IS NULL THEN
+        INSERT INTO catalog_changes (entity, entity_id, op) VALUES ('product', OLD.id, 'delete');
+    ELSIF old_data IS DISTINCT FROM new_data THEN
+        INSERT INTO catalog_changes (entity, entity_id, op, data) VALUES ('product', NEW.id, 'update', new_data);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION record_category_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO catalog_changes (entity, entity_id, op, data) VALUES ('category', NEW.id, 'create', catalog_category(NEW));
+    ELSIF TG_OP = 'DELETE' THEN
+        INSERT INTO catalog_changes (entity, entity_id, op) VALUES ('category', OLD.id, 'delete');
+    ELSIF catalog_category(OLD) IS DISTINCT FROM catalog_category(NEW) THEN
+        INSERT INTO catalog_changes (entity, entity_id, op, data) VALUES ('category', NEW.id, 'update', catalog_category(NEW));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Счетчики (продажи, рейтинг) меняются часто и в ленту не попадают
+CREATE TRIGGER trg_products_catalog_changes
+    AFTER INSERT OR DELETE OR UPDATE OF seller_id, category_id, name, description, price, stock, sku, image_url, status, archived_at, deleted_at
+    ON products
+    FOR EACH ROW
+    EXECUTE FUNCTION record_product_change();
+
+CREATE TRIGGER trg_categories_catalog_changes
+    AFTER INSERT OR UPDATE OR DELETE ON categories
+    FOR EACH ROW
+    EXECUTE FUNCTION record_category_change();
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS trg_products_slug ON products;
+DROP FUNCTION IF EXISTS assign_product_slug();
+DROP FUNCTION IF EXISTS slugify(TEXT);
+
+DROP TABLE IF EXISTS product_slug_history;
+
+DROP INDEX IF EXISTS idx_products_slug;
+ALTER TABLE products
+    DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,109 @@
+-- Человекочитаемый адрес товара, транслитерация названия
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS slug TEXT;
+
+-- Прежние адреса товаров, по ним отдается редирект на текущий адрес
+CREATE TABLE IF NOT EXISTS product_slug_history (
+    slug TEXT PRIMARY KEY,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_slug_history_product_id
+    ON product_slug_history (product_id);
+
+-- Транслитерация кириллицы латиницей: строчные буквы, цифры и дефисы
+CREATE OR REPLACE FUNCTION slugify(value TEXT) RETURNS TEXT AS $$
+DECLARE
+    slug TEXT;
+BEGIN
+    -- lower() не переводит кириллицу в строчные при локали C
+    slug := lower(translate(value,
+        'АБВГДЕЁЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯ',
+        'абвгдеёжзийклмнопрстуфхцчшщъыьэюя'));
+
+    slug := replace(slug, 'щ', 'shch');
+    slug := replace(slug, 'ж', 'zh');
+    slug := replace(slug, 'х', 'kh');
+    slug := replace(slug, 'ц', 'ts');
+    slug := replace(slug, 'ч', 'ch');
+    slug := replace(slug, 'ш', 'sh');
+    slug := replace(slug, 'ю', 'yu');
+    slug := replace(slug, 'я', 'ya');
+    -- ъ и ь отбрасываются: во второй строке для них нет пары
+    slug := translate(slug, 'абвгдеёзийклмнопрстуфыэъь', 'abvgdeeziyklmnoprstufye');
+
+    slug := regexp_replace(slug, '[^a-z0-9]+', '-', 'g');
+    RETURN trim(BOTH '-' FROM left(trim(BOTH '-' FROM slug), 80));
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Товары создаются и переименовываются из разных мест (редактирование, импорт), поэтому
+-- адрес назначается триггером. При совпадении добавляется номер: chaynik, chaynik-2.
+-- Адрес не меняется, если новое название дает тот же адрес; прежний адрес сохраняется в истории
+CREATE OR REPLACE FUNCTION assign_product_slug() RETURNS TRIGGER AS $$
+DECLARE
+    base TEXT;
+    candidate TEXT;
+    n INT := 1;
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.slug IS NOT NULL AND NEW.name IS NOT DISTINCT FROM OLD.name THEN
+        RETURN NEW;
+    END IF;
+
+    base := slugify(NEW.name);
+    IF base = '' THEN
+        base := 'product';
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND OLD.slug IS NOT NULL AND (OLD.slug = base OR OLD.slug ~ ('^' || base || '-[0-9]+$')) THEN
+        NEW.slug := OLD.slug;
+        RETURN NEW;
+    END IF;
+
+    -- Одинаковые адреса подбираются по очереди до конца транзакции
+    PERFORM pg_advisory_xact_lock(hashtext('product_slug:' || base));
+
+    candidate := base;
+    WHILE EXISTS (SELECT 1 FROM products WHERE slug = candidate AND id <> NEW.id)
+       OR EXISTS (SELECT 1 FROM product_slug_history WHERE slug = candidate AND product_id <> NEW.id) LOOP
+        n := n + 1;
+        candidate := base || '-' || n;
+    END LOOP;
+
+    IF TG_OP = 'UPDATE' AND OLD.slug IS NOT NULL THEN
+        INSERT INTO product_slug_history (slug, product_id)
+        VALUES (OLD.slug, NEW.id)
+        ON CONFLICT (slug) DO NOTHING;
+    END IF;
+
+    -- Товар, которому вернули прежнее название, возвращает себе прежний адрес
+    DELETE FROM product_slug_history WHERE slug = candidate;
+
+    NEW.slug := candidate;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_slug
+    BEFORE INSERT OR UPDATE OF name, slug ON products
+    FOR EACH ROW
+    EXECUTE FUNCTION assign_product_slug();
+
+-- Адреса существующих товаров, номера при совпадении — в порядке создания
+DO $$
+DECLARE
+    r RECORD;
+BEGIN
+    FOR r IN SELECT id FROM products WHERE slug IS NULL ORDER BY id LOOP
+        UPDATE products SET slug = NULL WHERE id = r.id;
+    END LOOP;
+END;
+$$;
+
+ALTER TABLE products
+    ALTER COLUMN slug SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug
+    ON products (slug);
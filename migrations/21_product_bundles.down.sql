@@ -0,0 +1,16 @@
+DROP TRIGGER IF EXISTS trg_products_refresh_bundles ON products;
+DROP TRIGGER IF EXISTS trg_products_bundle_stock ON products;
+DROP FUNCTION IF EXISTS refresh_bundle_stock();
+DROP FUNCTION IF EXISTS derive_bundle_stock();
+DROP FUNCTION IF EXISTS bundle_stock(INT);
+
+-- Комплектам возвращается их собственный остаток по журналу движений
+UPDATE products p
+SET stock = COALESCE((SELECT SUM(m.quantity) FROM inventory_movements m WHERE m.product_id = p.id), 0)
+WHERE p.id IN (SELECT bundle_id FROM bundle_components);
+
+ALTER TABLE order_item_allocations
+    DROP COLUMN IF EXISTS product_id;
+
+DROP TABLE IF EXISTS order_item_components;
+DROP TABLE IF EXISTS bundle_components;
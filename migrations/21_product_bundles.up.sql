@@ -0,0 +1,87 @@
+-- Состав комплектов: комплект — обычный товар со своей ценой, продается из остатков
+-- входящих в него товаров. Комплекты не вкладываются друг в друга
+CREATE TABLE IF NOT EXISTS bundle_components (
+    bundle_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    component_id INT NOT NULL
+    REFERENCES products(id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (bundle_id, component_id),
+    CHECK (bundle_id <> component_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_bundle_components_component_id
+    ON bundle_components (component_id);
+
+-- Товары, списанные по позиции-комплекту; состав комплекта может измениться после заказа
+CREATE TABLE IF NOT EXISTS order_item_components (
+    order_item_id INT NOT NULL
+    REFERENCES order_items(id) ON DELETE CASCADE,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (order_item_id, product_id)
+    );
+
+-- Позиция-комплект списывается с нескольких товаров, поэтому распределение по складам
+-- хранит товар, с которого списано
+ALTER TABLE order_item_allocations
+    ADD COLUMN IF NOT EXISTS product_id INT
+    REFERENCES products(id) ON DELETE CASCADE;
+
+UPDATE order_item_allocations a
+SET product_id = oi.product_id
+FROM order_items oi
+WHERE oi.id = a.order_item_id;
+
+ALTER TABLE order_item_allocations
+    ALTER COLUMN product_id SET NOT NULL;
+
+-- Остаток комплекта — сколько комплектов можно собрать из остатков товаров;
+-- архивный или удаленный товар собрать не дает
+CREATE OR REPLACE FUNCTION bundle_stock(bundle INT) RETURNS INT AS $$
+    SELECT COALESCE(MIN(
+        CASE
+            WHEN c.archived_at IS NULL AND c.deleted_at IS NULL THEN c.stock / bc.quantity
+            ELSE 0
+        END), 0)::INT
+    FROM bundle_components bc
+    JOIN products c ON c.id = bc.component_id
+    WHERE bc.bundle_id = bundle;
+$$ LANGUAGE sql STABLE;
+
+-- Остаток комплекта всегда вычисляется, записать его напрямую нельзя
+CREATE OR REPLACE FUNCTION derive_bundle_stock() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM bundle_components WHERE bundle_id = NEW.id) THEN
+        NEW.stock := bundle_stock(NEW.id);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Остаток товара меняется из многих мест (заказы, поставки, склады, импорт), поэтому
+-- комплекты с этим товаром пересчитываются триггером
+CREATE OR REPLACE FUNCTION refresh_bundle_stock() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products
+    SET stock = stock
+    WHERE id IN (SELECT bundle_id FROM bundle_components WHERE component_id = NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_bundle_stock
+    BEFORE UPDATE OF stock
+    ON products
+    FOR EACH ROW
+    EXECUTE FUNCTION derive_bundle_stock();
+
+CREATE TRIGGER trg_products_refresh_bundles
+    AFTER UPDATE OF stock, archived_at, deleted_at
+    ON products
+    FOR EACH ROW
+    WHEN (OLD.stock IS DISTINCT FROM NEW.stock
+        OR OLD.archived_at IS DISTINCT FROM NEW.archived_at
+        OR OLD.deleted_at IS DISTINCT FROM NEW.deleted_at)
+    EXECUTE FUNCTION refresh_bundle_stock();
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS price_tiers;
+
+ALTER TABLE users DROP COLUMN IF EXISTS price_list_id;
+
+DROP TABLE IF EXISTS price_lists;
@@ -0,0 +1,28 @@
+-- Прайс-листы групп покупателей, например оптовых клиентов; покупатель состоит
+-- не более чем в одной группе
+CREATE TABLE IF NOT EXISTS price_lists (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS price_list_id INT
+    REFERENCES price_lists(id) ON DELETE SET NULL;
+
+-- Цена товара от количества: ступень действует начиная с min_quantity штук.
+-- Ступени без прайс-листа видны всем покупателям, с прайс-листом — только его группе
+CREATE TABLE IF NOT EXISTS price_tiers (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    price_list_id INT
+    REFERENCES price_lists(id) ON DELETE CASCADE,
+    min_quantity INT NOT NULL CHECK (min_quantity > 0),
+    price NUMERIC(10,2) NOT NULL CHECK (price > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+-- Одна ступень на количество в каждом прайс-листе товара, общие ступени — отдельный список
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_tiers_product_list_quantity
+    ON price_tiers (product_id, COALESCE(price_list_id, 0), min_quantity);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_products_rating_id;
+DROP INDEX IF EXISTS idx_products_sold_count_id;
+DROP INDEX IF EXISTS idx_products_created_at_id;
+DROP INDEX IF EXISTS idx_products_price_id;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS rating,
+    DROP COLUMN IF EXISTS sold_count;
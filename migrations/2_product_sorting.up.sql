@@ -0,0 +1,26 @@
+-- Денормализованные счетчики для сортировки каталога
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS sold_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating NUMERIC(3,2) NOT NULL DEFAULT 0;
+
+UPDATE products p
+SET sold_count = s.sold
+FROM (
+    SELECT product_id, SUM(quantity) AS sold
+    FROM order_items
+    GROUP BY product_id
+) s
+WHERE s.product_id = p.id;
+
+-- Индексы для keyset-пагинации
+CREATE INDEX IF NOT EXISTS idx_products_price_id
+    ON products (price, id);
+
+CREATE INDEX IF NOT EXISTS idx_products_created_at_id
+    ON products (created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_products_sold_count_id
+    ON products (sold_count, id);
+
+CREATE INDEX IF NOT EXISTS idx_products_rating_id
+    ON products (rating, id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_products_status_created_at;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_approved BOOLEAN DEFAULT TRUE;
+
+UPDATE products SET is_approved = (status = 'approved');
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS moderated_at,
+    DROP COLUMN IF EXISTS rejection_reason,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,14 @@
+-- Статусы модерации товара: 'draft', 'pending_review', 'approved', 'rejected', 'suspended'
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending_review'
+        CHECK (status IN ('draft', 'pending_review', 'approved', 'rejected', 'suspended')),
+    ADD COLUMN IF NOT EXISTS rejection_reason TEXT,
+    ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP;
+
+UPDATE products
+SET status = CASE WHEN is_approved THEN 'approved' ELSE 'pending_review' END;
+
+ALTER TABLE products DROP COLUMN IF EXISTS is_approved;
+
+CREATE INDEX IF NOT EXISTS idx_products_status_created_at
+    ON products (status, created_at, id);
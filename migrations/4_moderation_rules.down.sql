@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_product_moderation_flags_product_id;
+
+DROP TABLE IF EXISTS product_moderation_flags;
+DROP TABLE IF EXISTS moderation_rules;
@@ -0,0 +1,26 @@
+-- Правила автоматической модерации: 'banned_word', 'regex'
+CREATE TABLE IF NOT EXISTS moderation_rules (
+    id SERIAL PRIMARY KEY,
+    kind TEXT NOT NULL CHECK (kind IN ('banned_word', 'regex')),
+    pattern TEXT NOT NULL,
+    severity TEXT NOT NULL CHECK (severity IN ('low', 'medium', 'high')),
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+-- Флаги, выставленные товару при последней автоматической проверке
+CREATE TABLE IF NOT EXISTS product_moderation_flags (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    rule_id INT
+    REFERENCES moderation_rules(id) ON DELETE SET NULL,
+    code TEXT NOT NULL,
+    severity TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_moderation_flags_product_id
+    ON product_moderation_flags (product_id);
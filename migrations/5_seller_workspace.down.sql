@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_order_items_product_id;
+DROP INDEX IF EXISTS idx_products_seller_created_at_id;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS view_count;
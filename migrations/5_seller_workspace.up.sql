@@ -0,0 +1,10 @@
+-- Счетчик просмотров карточки товара
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS view_count BIGINT NOT NULL DEFAULT 0;
+
+-- Индексы для списка товаров продавца и подсчета продаж
+CREATE INDEX IF NOT EXISTS idx_products_seller_created_at_id
+    ON products (seller_id, created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_product_id
+    ON order_items (product_id);
@@ -0,0 +1,10 @@
+ALTER TABLE order_items
+    DROP CONSTRAINT IF EXISTS order_items_product_id_fkey,
+    ADD CONSTRAINT order_items_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_products_deleted_at;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS archived_at;
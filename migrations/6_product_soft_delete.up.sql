@@ -0,0 +1,14 @@
+-- Мягкое удаление и архивирование товаров
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at
+    ON products (deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+-- Товар, на который ссылаются заказы, больше нельзя удалить вместе с историей заказов
+ALTER TABLE order_items
+    DROP CONSTRAINT IF EXISTS order_items_product_id_fkey,
+    ADD CONSTRAINT order_items_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT;
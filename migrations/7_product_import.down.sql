@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS product_import_errors;
+DROP TABLE IF EXISTS product_import_jobs;
+
+DROP INDEX IF EXISTS idx_products_seller_sku;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS sku;
@@ -0,0 +1,41 @@
+-- Артикул продавца, по нему выполняется upsert при импорте
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS sku TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_seller_sku
+    ON products (seller_id, sku)
+    WHERE sku IS NOT NULL;
+
+-- Задачи массового импорта товаров
+CREATE TABLE IF NOT EXISTS product_import_jobs (
+    id SERIAL PRIMARY KEY,
+    seller_id INT NOT NULL
+    REFERENCES users(id) ON DELETE CASCADE,
+    format TEXT NOT NULL CHECK (format IN ('csv', 'jsonl')),
+    status TEXT NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    total_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    created_count INT NOT NULL DEFAULT 0,
+    updated_count INT NOT NULL DEFAULT 0,
+    failed_count INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    finished_at TIMESTAMP
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_import_jobs_seller_id
+    ON product_import_jobs (seller_id, created_at);
+
+-- Ошибки по строкам файла импорта
+CREATE TABLE IF NOT EXISTS product_import_errors (
+    id SERIAL PRIMARY KEY,
+    job_id INT NOT NULL
+    REFERENCES product_import_jobs(id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    sku TEXT,
+    reason TEXT NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_import_errors_job_id
+    ON product_import_errors (job_id, row_number);
@@ -0,0 +1,3 @@
+-- Ссылка на изображение товара, выгружается в прайс-агрегаторы
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS image_url TEXT;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_products_price_history ON products;
+DROP FUNCTION IF EXISTS record_product_price();
+
+DROP TABLE IF EXISTS product_price_history;
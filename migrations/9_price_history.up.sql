@@ -0,0 +1,32 @@
+-- История цен товара, нужна для расчета минимальной цены за 30 дней
+CREATE TABLE IF NOT EXISTS product_price_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL
+    REFERENCES products(id) ON DELETE CASCADE,
+    price NUMERIC(10,2) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_price_history_product_id
+    ON product_price_history (product_id, changed_at);
+
+-- Текущие цены существующих товаров становятся первой записью истории
+INSERT INTO product_price_history (product_id, price, changed_at)
+SELECT id, price, created_at
+FROM products;
+
+-- Цена меняется из разных мест (редактирование, импорт), поэтому история пишется триггером
+CREATE OR REPLACE FUNCTION record_product_price() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.price IS DISTINCT FROM OLD.price THEN
+        INSERT INTO product_price_history (product_id, price, changed_at)
+        VALUES (NEW.id, NEW.price, now());
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_products_price_history
+    AFTER INSERT OR UPDATE OF price ON products
+    FOR EACH ROW
+    EXECUTE FUNCTION record_product_price();
@@ -1 +1,25 @@
 package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// EncodeCursor packs a pagination position into an opaque url-safe token.
+func EncodeCursor(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func DecodeCursor(cursor string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}